@@ -0,0 +1,68 @@
+/*
+Package api holds the OpenAPI 3 specification of the backend.
+The spec is written by hand, it has to be changed together with
+the routes in delivery/http/v1
+*/
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	//go:embed openapi.yaml
+	spec []byte
+
+	// SwaggerIndex is the page of Swagger UI that loads the spec from /openapi.json
+	//go:embed swagger.html
+	SwaggerIndex []byte
+)
+
+// Load parses the spec and checks it is a valid OpenAPI 3 document
+func Load() (*openapi3.T, error) {
+	const op = "api.Load"
+
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return doc, nil
+}
+
+var (
+	jsonOnce sync.Once
+	jsonSpec []byte
+	jsonErr  error
+)
+
+// JSON returns the spec as JSON, it is built once and served at /openapi.json
+func JSON() ([]byte, error) {
+	const op = "api.JSON"
+
+	jsonOnce.Do(func() {
+		doc, err := Load()
+		if err != nil {
+			jsonErr = err
+			return
+		}
+
+		jsonSpec, jsonErr = json.Marshal(doc)
+	})
+
+	if jsonErr != nil {
+		return nil, fmt.Errorf("%s: %w", op, jsonErr)
+	}
+
+	return jsonSpec, nil
+}
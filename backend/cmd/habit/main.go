@@ -1,10 +1,22 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/aidos-dev/habit-tracker/backend/internal/app"
 	_ "github.com/jackc/pgx/v5"
 )
 
 func main() {
+	// habit-tracker migrate up|down|status|force
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.Migrate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app.Run()
 }
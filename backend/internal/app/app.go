@@ -5,18 +5,30 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/aidos-dev/habit-tracker/backend/api"
 	"github.com/aidos-dev/habit-tracker/backend/internal/config"
 	v1 "github.com/aidos-dev/habit-tracker/backend/internal/delivery/http/v1"
-	"github.com/aidos-dev/habit-tracker/backend/internal/repository/postgres"
+	"github.com/aidos-dev/habit-tracker/backend/internal/mailer"
+	"github.com/aidos-dev/habit-tracker/backend/internal/ratelimit"
 	"github.com/aidos-dev/habit-tracker/backend/internal/server"
 	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/health"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
+	"github.com/gin-gonic/gin"
 	_ "github.com/jackc/pgx/v5"
 	"golang.org/x/exp/slog"
 )
 
+const (
+	envProd = "prod"
+	// serviceName names the service in the spans it sends
+	serviceName = "habit-tracker-backend"
+)
+
 func Run() {
 	// init config: cleanenv
 	cfg := config.MustLoad()
@@ -31,34 +43,96 @@ func Run() {
 	)
 	log.Debug("debug messages are enabled")
 
-	dbpool, err := postgres.NewPostgresDB(cfg)
+	/*
+		ctx is the parent of all the work the app does. It is cancelled
+		on shut down, so requests and jobs left by then are stopped
+	*/
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shutdownTracing, err := tracing.Setup(ctx, serviceName, cfg.Tracing)
 	if err != nil {
-		log.Error("failed to initialize db", sl.Err(err))
+		log.Error("failed to initialize tracing", sl.Err(err))
 		return
 	}
 
-	repos := postgres.NewPostgresRepository(dbpool)
-	services := service.NewService(repos)
+	ready := health.New(cfg.Health.ReadyTimeout)
+
+	repos, closeDB, err := newRepository(ctx, log, cfg, ready)
+	if err != nil {
+		log.Error("failed to initialize db", sl.Err(err))
+		return
+	}
+	mail := mailer.NewSMTPMailer(cfg.Mail)
+	limits := ratelimit.NewMemoryStore(cfg.RateLimit.SignInLockout.Window)
+	services := service.NewService(repos, cfg, mail, limits)
 	handlers := v1.NewHandler(log, services)
 
+	middlewares := []gin.HandlerFunc{v1.NewRequestTimeout(cfg.HTTPServer.RequestTimeout)}
+
+	if cfg.OpenAPI.Validate && cfg.Env != envProd {
+		validator, err := newOpenAPIValidator(log)
+		if err != nil {
+			log.Error("failed to initialize openapi validator", sl.Err(err))
+			return
+		}
+
+		middlewares = append(middlewares, validator)
+		log.Info("requests and responses are checked against the openapi spec")
+	}
+
+	router, err := handlers.InitRoutes(cfg.HTTPServer.TrustedProxies, middlewares...)
+	if err != nil {
+		log.Error("failed to initialize routes", sl.Err(err))
+		return
+	}
+	ready.Routes(router)
+
 	srv := new(server.Server)
 
 	go func() {
-		if err := srv.Run(cfg, log, handlers.InitRoutes()); err != nil {
+		if err := srv.Run(ctx, cfg, log, router); err != nil {
 			log.Error("failed to run http server", sl.Err(err))
 			return
 		}
 	}()
 
+	go runAccountPurge(ctx, log, services.User, cfg.Account.PurgeInterval)
+	go runTokenPurge(ctx, log, services.Authorization, cfg.Auth.TokenPurgeInterval)
+
 	log.Info("HabbitTrackerApp Started")
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
 
-	if err := srv.Shutdown(context.Background()); err != nil {
+	// the readiness probe fails first, requests are still served during the drain delay
+	ready.Shutdown()
+	time.Sleep(cfg.Health.DrainDelay)
+
+	shutdownCtx, stopShutdown := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer stopShutdown()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Error("error occured on server shutting down", sl.Err(err))
 	}
 
-	defer dbpool.Close()
+	// requests still in flight after the shut down timeout are cancelled
+	cancel()
+
+	closeDB()
+
+	// spans of the last requests are sent before the exit
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("failed to flush spans", sl.Err(err))
+	}
+}
+
+func newOpenAPIValidator(log *slog.Logger) (gin.HandlerFunc, error) {
+	doc, err := api.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return v1.NewOpenAPIValidator(log, doc)
 }
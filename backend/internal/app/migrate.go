@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/migrate"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository/postgres"
+	"github.com/aidos-dev/habit-tracker/backend/migrations"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"golang.org/x/exp/slog"
+)
+
+const migrateUsage = `usage: habit-tracker migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [N]       roll back the last N migrations, 1 by default
+  status         print the version of the database and pending migrations
+  force VERSION  set the version without running migrations, 0 clears it`
+
+var errMigrateUsage = errors.New(migrateUsage)
+
+/*
+Migrate runs the migrate subcommand with args that follow it.
+Migrations are built into the binary, so it needs only the config
+*/
+func Migrate(args []string) error {
+	const op = "app.migrate.Migrate"
+
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	command, arg := args[0], ""
+	if len(args) > 1 {
+		arg = args[1]
+	}
+
+	cfg := config.MustLoad()
+	log := loggs.SetupLogger(cfg.Env)
+
+	if cfg.DB.Driver == driverSQLite {
+		return fmt.Errorf("%s: the sqlite schema is migrated when the app opens the database", op)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	dbpool, err := postgres.NewPostgresDB(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer dbpool.Close()
+
+	migrator, err := migrate.NewMigrator(log, dbpool, migrations.FS)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		fmt.Printf("%d migrations applied\n", applied)
+	case "down":
+		steps := 1
+		if arg != "" {
+			steps, err = strconv.Atoi(arg)
+			if err != nil || steps <= 0 {
+				return errMigrateUsage
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		fmt.Printf("%d migrations rolled back\n", reverted)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		printMigrateStatus(status)
+	case "force":
+		version, err := strconv.Atoi(arg)
+		if err != nil || version < 0 {
+			return errMigrateUsage
+		}
+
+		if err := migrator.Force(ctx, version); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		fmt.Printf("version is set to %d\n", version)
+	default:
+		return errMigrateUsage
+	}
+
+	return nil
+}
+
+func printMigrateStatus(status migrate.Status) {
+	fmt.Printf("version: %d\n", status.Version)
+	fmt.Printf("dirty: %t\n", status.Dirty)
+	fmt.Printf("latest: %d\n", status.Latest)
+
+	if len(status.Pending) == 0 {
+		fmt.Println("pending: none")
+		return
+	}
+
+	fmt.Println("pending:")
+	for _, migration := range status.Pending {
+		fmt.Printf("  %06d_%s\n", migration.Version, migration.Name)
+	}
+}
+
+// migrateUp applies pending migrations on start, when it is turned on in the config
+func migrateUp(ctx context.Context, log *slog.Logger, migrator *migrate.Migrator) error {
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info("database schema is up to date", slog.Int("applied migrations", applied))
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"golang.org/x/exp/slog"
+)
+
+/*
+runAccountPurge periodically removes accounts which deletion
+grace period is over. It stops when ctx is cancelled
+*/
+func runAccountPurge(ctx context.Context, log *slog.Logger, users service.User, interval time.Duration) {
+	const op = "app.purge.runAccountPurge"
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := users.PurgeDeletedUsers(ctx)
+			if err != nil {
+				log.Error(op+": failed to purge deleted accounts", sl.Err(err))
+				continue
+			}
+
+			if purged > 0 {
+				log.Info(op+": deleted accounts are purged", slog.Int("count", purged))
+			}
+		}
+	}
+}
+
+/*
+runTokenPurge periodically removes expired tokens, the ones kept to
+reject a reuse of one-time, revoked and rotated tokens. It stops when
+ctx is cancelled
+*/
+func runTokenPurge(ctx context.Context, log *slog.Logger, auth service.Authorization, interval time.Duration) {
+	const op = "app.purge.runTokenPurge"
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := auth.PurgeExpiredTokens(ctx)
+			if err != nil {
+				log.Error(op+": failed to purge expired tokens", sl.Err(err))
+				continue
+			}
+
+			if purged > 0 {
+				log.Info(op+": expired tokens are purged", slog.Int("count", purged))
+			}
+		}
+	}
+}
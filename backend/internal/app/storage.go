@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	appmetrics "github.com/aidos-dev/habit-tracker/backend/internal/metrics"
+	"github.com/aidos-dev/habit-tracker/backend/internal/migrate"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository/postgres"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository/sqlite"
+	"github.com/aidos-dev/habit-tracker/backend/migrations"
+	"github.com/aidos-dev/habit-tracker/pkg/health"
+	"github.com/aidos-dev/habit-tracker/pkg/metrics"
+	"golang.org/x/exp/slog"
+)
+
+// drivers of config.DB.Driver
+const (
+	driverPostgres = "postgres"
+	driverSQLite   = "sqlite"
+)
+
+/*
+newRepository connects to the database of the configured driver and adds
+the checks of the database to the readiness probe.
+The returned func closes the connection on shut down
+*/
+func newRepository(ctx context.Context, log *slog.Logger, cfg *config.Config, ready *health.Health) (*repository.Repository, func(), error) {
+	const op = "app.storage.newRepository"
+
+	switch cfg.DB.Driver {
+	case driverPostgres:
+		dbpool, err := postgres.NewPostgresDB(ctx, cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		migrator, err := migrate.NewMigrator(log, dbpool, migrations.FS)
+		if err != nil {
+			dbpool.Close()
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if cfg.DB.AutoMigrate {
+			if err := migrateUp(ctx, log, migrator); err != nil {
+				dbpool.Close()
+				return nil, nil, fmt.Errorf("%s: failed to apply migrations: %w", op, err)
+			}
+		}
+
+		if err := metrics.Register(appmetrics.NewPoolCollector(dbpool)); err != nil {
+			dbpool.Close()
+			return nil, nil, fmt.Errorf("%s: failed to register pool metrics: %w", op, err)
+		}
+
+		ready.Add("db", dbpool.Ping)
+		ready.Add("migrations", migrationsReady(migrator))
+
+		return postgres.NewPostgresRepository(dbpool), dbpool.Close, nil
+	case driverSQLite:
+		// the sqlite schema is always migrated on open, there is a single instance per file
+		db, err := sqlite.NewSQLiteDB(ctx, cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Info("sqlite database is used", slog.String("path", cfg.DB.Path))
+
+		ready.Add("db", db.PingContext)
+
+		return sqlite.NewSQLiteRepository(db), func() { db.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("%s: unknown db driver %q: use %q or %q", op, cfg.DB.Driver, driverPostgres, driverSQLite)
+	}
+}
+
+// migrationStatus is the part of migrate.Migrator the readiness probe uses
+type migrationStatus interface {
+	Status(ctx context.Context) (migrate.Status, error)
+}
+
+/*
+migrationsReady fails while the schema is behind the migrations built
+into the binary or a migration has failed, the queries of this version
+of the app would fail on such a schema
+*/
+func migrationsReady(migrator migrationStatus) health.Check {
+	return func(ctx context.Context) error {
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		if status.Dirty {
+			return fmt.Errorf("migration %d failed, the schema is dirty", status.Version)
+		}
+
+		if len(status.Pending) > 0 {
+			return fmt.Errorf("%d migrations are pending, the schema is at version %d of %d", len(status.Pending), status.Version, status.Latest)
+		}
+
+		return nil
+	}
+}
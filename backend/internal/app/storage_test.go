@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/migrate"
+)
+
+type fakeMigrationStatus struct {
+	status migrate.Status
+	err    error
+}
+
+func (f fakeMigrationStatus) Status(ctx context.Context) (migrate.Status, error) {
+	return f.status, f.err
+}
+
+func Test_migrationsReady(t *testing.T) {
+	pending := []migrate.Migration{{Version: 13, Name: "version"}, {Version: 14, Name: "idempotency_headers"}}
+
+	testTable := []struct {
+		name          string
+		status        fakeMigrationStatus
+		expectedError string
+	}{
+		{
+			name:   "Up To Date",
+			status: fakeMigrationStatus{status: migrate.Status{Version: 14, Latest: 14}},
+		},
+		{
+			name:          "Pending Migrations",
+			status:        fakeMigrationStatus{status: migrate.Status{Version: 12, Latest: 14, Pending: pending}},
+			expectedError: "2 migrations are pending, the schema is at version 12 of 14",
+		},
+		{
+			name:          "Dirty",
+			status:        fakeMigrationStatus{status: migrate.Status{Version: 14, Latest: 14, Dirty: true}},
+			expectedError: "migration 14 failed, the schema is dirty",
+		},
+		{
+			name:          "Status Failure",
+			status:        fakeMigrationStatus{err: errors.New("connection refused")},
+			expectedError: "connection refused",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := migrationsReady(testCase.status)(context.Background())
+
+			if testCase.expectedError == "" {
+				if err != nil {
+					t.Errorf("Expected the schema to be ready but got %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != testCase.expectedError {
+				t.Errorf("Expected error '%s' but got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
@@ -13,21 +15,183 @@ type Config struct {
 	Env        string `yaml:"env" env-required:"true"`
 	HTTPServer `yaml:"http_server"`
 	DB
+	Account     `yaml:"account"`
+	Auth        `yaml:"auth"`
+	Mail        `yaml:"mail"`
+	OIDC        `yaml:"oidc"`
+	ServiceAuth `yaml:"service_auth"`
+	Telegram    `yaml:"telegram"`
+	RateLimit   `yaml:"rate_limit"`
+	Idempotency `yaml:"idempotency"`
+	OpenAPI     `yaml:"openapi"`
+	Health      `yaml:"health"`
+	Tracing     tracing.Config `yaml:"tracing"`
 }
 
 type HTTPServer struct {
 	Port        string        `yaml:"port" env-default:"8082"`
 	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	// RequestTimeout is the deadline of the work a request does, queries included
+	RequestTimeout time.Duration `yaml:"request_timeout" env-default:"3s"`
+	/*
+		ShutdownTimeout is how long requests in flight can finish on shut down,
+		the ones left after it are cancelled
+	*/
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"10s"`
+	/*
+		TrustedProxies are the addresses or CIDRs of the proxies which
+		X-Forwarded-For is believed, the client ip is used by the rate limit
+		and the sign in lockout. Empty by default, so the header is ignored
+	*/
+	TrustedProxies []string `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES"`
+}
+
+type Health struct {
+	// ReadyTimeout is the deadline of the checks of the readiness probe
+	ReadyTimeout time.Duration `yaml:"ready_timeout" env-default:"2s"`
+	/*
+		DrainDelay is how long requests are still served on shut down after
+		the readiness probe fails, so load balancers stop sending them first
+	*/
+	DrainDelay time.Duration `yaml:"drain_delay" env-default:"0s"`
 }
 
 type DB struct {
+	// Driver is "postgres" or "sqlite", the sqlite one needs no database server
+	Driver string `yaml:"driver" env:"DB_DRIVER" env-default:"postgres"`
+	// Path is the file of the sqlite database, it is created on the first start
+	Path     string `yaml:"path" env:"DB_PATH" env-default:"habit-tracker.db"`
 	Host     string `yaml:"host"`
 	Port     string `yaml:"port"`
 	Username string `yaml:"username"`
 	Password string
 	DBName   string `yaml:"dbname"`
 	SSLMode  string `yaml:"sslmode"`
+	/*
+		AutoMigrate applies pending migrations on start. Instances started
+		at the same time take turns, the migrations are applied once
+	*/
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE" env-default:"false"`
+}
+
+type Account struct {
+	// DeletionGracePeriod is the time a deleted account can be restored
+	DeletionGracePeriod time.Duration `yaml:"deletion_grace_period" env-default:"720h"`
+	// PurgeInterval is how often accounts with expired grace period are purged
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"`
+}
+
+type Auth struct {
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" env-default:"15m"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env-default:"720h"`
+	/*
+		SigningKeys maps a key id to a secret. Tokens are signed with the
+		active key and its id is put into the "kid" header, so a new key
+		can be activated while tokens signed with the old one are still valid.
+		Format of the env variable: "kid1:secret1,kid2:secret2"
+	*/
+	SigningKeys map[string]string `yaml:"signing_keys" env:"JWT_SIGNING_KEYS"`
+	ActiveKeyId string            `yaml:"active_key_id" env:"JWT_ACTIVE_KEY_ID"`
+	// VerifyTokenTTL and ResetTokenTTL are lifetimes of one-time tokens sent by email
+	VerifyTokenTTL time.Duration `yaml:"verify_token_ttl" env-default:"24h"`
+	ResetTokenTTL  time.Duration `yaml:"reset_token_ttl" env-default:"1h"`
+	// PersonalTokenTTL is used when a personal access token is created without a lifetime
+	PersonalTokenTTL    time.Duration `yaml:"personal_token_ttl" env-default:"2160h"`
+	PersonalTokenMaxTTL time.Duration `yaml:"personal_token_max_ttl" env-default:"8760h"`
+	// TokenPurgeInterval is how often expired revoked, used and refresh tokens are removed
+	TokenPurgeInterval time.Duration `yaml:"token_purge_interval" env-default:"1h"`
+}
+
+type Mail struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port" env-default:"25"`
+	Username string `yaml:"username"`
+	Password string `env:"SMTP_PASSWORD"`
+	From     string `yaml:"from"`
+	// AppURL is the address of the web client links in emails lead to
+	AppURL string `yaml:"app_url"`
+}
+
+// ServiceAuth holds secrets of the services calling the backend on behalf of users
+type ServiceAuth struct {
+	/*
+		Keys maps a service id to a secret the service signs requests with.
+		Format of the env variable: "service1:secret1,service2:secret2"
+	*/
+	Keys map[string]string `yaml:"keys" env:"SERVICE_AUTH_KEYS"`
+	// MaxClockSkew is how far the time of a signed request can be from the server time
+	MaxClockSkew time.Duration `yaml:"max_clock_skew" env-default:"5m"`
+}
+
+type Telegram struct {
+	// BotName is the user name of the bot, it is used in deep links
+	BotName string `yaml:"bot_name"`
+	// LinkCodeTTL is how long a code linking a telegram chat to a web account works
+	LinkCodeTTL time.Duration `yaml:"link_code_ttl" env-default:"10m"`
+}
+
+type Idempotency struct {
+	// TTL is how long a response is replayed to requests with the same key
+	TTL time.Duration `yaml:"ttl" env-default:"24h"`
+}
+
+/*
+OpenAPI turns on the check of requests and responses against the spec.
+It is for dev and test environments only, it is never used in prod
+*/
+type OpenAPI struct {
+	Validate bool `yaml:"validate" env:"OPENAPI_VALIDATE" env-default:"false"`
+}
+
+type RateLimit struct {
+	Enabled bool `yaml:"enabled" env-default:"true"`
+	// Default is the budget of a route which is not listed in Routes
+	Default RateLimitRule `yaml:"default"`
+	/*
+		Routes maps a route to its budget. A route is written as it is
+		registered in the router, e.g. "GET /:client/api/habits/"
+	*/
+	Routes map[string]RateLimitRule `yaml:"routes"`
+	// Address is the budget of an address over all the api routes, it is taken before the token is checked
+	Address       RateLimitRule `yaml:"address"`
+	SignInLockout `yaml:"sign_in_lockout"`
+}
+
+// RateLimitRule lets a client make Requests in Per, with bursts up to Burst requests
+type RateLimitRule struct {
+	Requests int           `yaml:"requests" env-default:"120"`
+	Per      time.Duration `yaml:"per" env-default:"1m"`
+	Burst    int           `yaml:"burst" env-default:"30"`
+}
+
+/*
+SignInLockout locks sign in for a user name and an address after Threshold
+failures in a row. The lock lasts BaseDelay and doubles with every next
+failure up to MaxDelay. Failures are forgotten after Window without new ones.
+A user name is locked from every address after UserThreshold failures
+from any of them, 0 turns it off
+*/
+type SignInLockout struct {
+	Threshold     int           `yaml:"threshold" env-default:"5"`
+	UserThreshold int           `yaml:"user_threshold" env-default:"20"`
+	BaseDelay     time.Duration `yaml:"base_delay" env-default:"30s"`
+	MaxDelay      time.Duration `yaml:"max_delay" env-default:"1h"`
+	Window        time.Duration `yaml:"window" env-default:"15m"`
+}
+
+// OIDC holds identity providers a web user can sign in with, by name
+type OIDC struct {
+	Providers map[string]OIDCProvider `yaml:"providers"`
+}
+
+type OIDCProvider struct {
+	IssuerURL string `yaml:"issuer_url"`
+	ClientID  string `yaml:"client_id"`
+	// ClientSecret is set by OIDC_<PROVIDER>_CLIENT_SECRET env variable
+	ClientSecret string   `yaml:"-"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
 }
 
 func MustLoad() *Config {
@@ -55,5 +219,15 @@ func MustLoad() *Config {
 
 	cfg.DB.Password = os.Getenv("DB_PASSWORD")
 
+	for name, provider := range cfg.OIDC.Providers {
+		provider.ClientSecret = os.Getenv("OIDC_" + strings.ToUpper(name) + "_CLIENT_SECRET")
+		cfg.OIDC.Providers[name] = provider
+	}
+
+	// an empty secret would sign tokens anyone can forge
+	if strings.TrimSpace(cfg.Auth.SigningKeys[cfg.Auth.ActiveKeyId]) == "" {
+		log.Fatalf("signing key with id %q is not set", cfg.Auth.ActiveKeyId)
+	}
+
 	return &cfg
 }
@@ -9,41 +9,122 @@ import (
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
 )
 
 /*
-adminPass ensures that only admin users have access to admin functionality
+requirePermission lets through only users which role has the permission.
+The current role of the admin is checked, not the one in the token, so
+a role taken away works before the token expires.
+Roles and their permissions are managed by admins, see admin_role_handler.go
 */
-func (h *Handler) adminPass(c *gin.Context) {
-	const op = "delivery.http.v1.admin_middleware.adminPass"
+func (h *Handler) requirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "delivery.http.v1.admin_middleware.requirePermission"
 
-	userRole, err := getUserRole(c)
-	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "user role not found")
-		h.log.Error(fmt.Sprintf("%s: failed to get user role", op), sl.Err(err))
-		return
+		actorId, err := getActorId(c)
+		if err != nil {
+			newErrorResponse(c, http.StatusInternalServerError, "failed to check permissions")
+			h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
+			return
+		}
+
+		userRole, err := h.services.AdminRole.UserRole(c.Request.Context(), actorId)
+		if err != nil {
+			newErrorResponse(c, http.StatusInternalServerError, "failed to check permissions")
+			h.logger(c).Error(fmt.Sprintf("%s: failed to get user role", op), sl.Err(err))
+			return
+		}
+
+		allowed, err := h.services.AdminRole.HasPermission(c.Request.Context(), userRole, permission)
+		if err != nil {
+			newErrorResponse(c, http.StatusInternalServerError, "failed to check permissions")
+			h.logger(c).Error(fmt.Sprintf("%s: failed to check permissions", op), sl.Err(err))
+			return
+		}
+
+		if !allowed {
+			newErrorResponse(c, http.StatusForbidden, fmt.Sprintf("access denied: %s permission is required", permission))
+			h.logger(c).Error(
+				fmt.Sprintf("%s: access denied", op),
+				slog.String("role", userRole),
+				slog.String("permission", permission),
+			)
+			return
+		}
 	}
+}
+
+/*
+adminActor keeps the id of the user making a change on the admin routes
+for the audit log, the id of the request is set by requestId middleware
+*/
+func (h *Handler) adminActor(c *gin.Context) {
+	const op = "delivery.http.v1.admin_middleware.adminActor"
 
-	if userRole != models.Administrator {
-		newErrorResponse(c, http.StatusBadRequest, "error: not an admin user: access denied")
-		h.log.Error(fmt.Sprintf("%s: not an admin user: access denied", op), sl.Err(err))
+	actorId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
+
+	/*
+		managedUser replaces the user id in context with the id of
+		a managed user, so the admin's own id is kept separately
+		to know who made a change
+	*/
+	c.Set(actorCtx, actorId)
 }
 
 /*
-adminUserPass allows administrator to pass any user id to context
-so it can have access and manage users accounts
+managedUser puts the id of a user managed on the admin routes to context,
+so the handlers shared with the user routes work with that user
 */
-func (h *Handler) adminUserPass(c *gin.Context) {
-	const op = "delivery.http.v1.admin_middleware.adminUserPass"
+func (h *Handler) managedUser(c *gin.Context) {
+	const op = "delivery.http.v1.admin_middleware.managedUser"
 
 	userId, err := strconv.ParseFloat(strings.TrimSpace(c.Param("userId")), 64)
 	if err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("adminUserIdentity: invalid id param: %v", userId))
-		h.log.Error(fmt.Sprintf("%s: invalid id param", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid id param", op), sl.Err(err))
 		return
 	}
 
 	c.Set(userCtx, userId)
 }
+
+/*
+getAuditMeta returns the admin who makes a change and the id of the request.
+It returns an error outside of the admin routes
+*/
+func getAuditMeta(c *gin.Context) (models.AuditMeta, error) {
+	const op = "delivery.http.v1.admin_middleware.getAuditMeta"
+
+	actorId, err := getActorId(c)
+	if err != nil {
+		return models.AuditMeta{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.AuditMeta{
+		ActorId:   actorId,
+		RequestId: c.GetString(requestIdCtx),
+	}, nil
+}
+
+// getActorId returns the admin who makes a request on the admin routes
+func getActorId(c *gin.Context) (int, error) {
+	const op = "delivery.http.v1.admin_middleware.getActorId"
+
+	actorId, ok := c.Get(actorCtx)
+	if !ok {
+		return 0, fmt.Errorf("%s: admin actor not found", op)
+	}
+
+	actorIdInt, err := convertToInt(actorId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return actorIdInt, nil
+}
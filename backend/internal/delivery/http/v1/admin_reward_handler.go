@@ -14,28 +14,28 @@ import (
 func (h *Handler) createReward(c *gin.Context) {
 	const op = "delivery.http.v1.admin_reward_handler.createReward"
 
-	_, err := getUserId(c)
+	meta, err := getAuditMeta(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
 		return
 	}
 
 	var input models.Reward
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
-	id, err := h.services.AdminReward.Create(input)
+	id, err := h.services.AdminReward.Create(c.Request.Context(), meta, input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to create reward: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to create reward", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to create reward: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to create reward", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(fmt.Sprintf("%s: a new reward has been added", op), slog.Int("id", id))
+	h.logger(c).Info(fmt.Sprintf("%s: a new reward has been added", op), slog.Int("id", id))
 
 	c.JSON(http.StatusOK, map[string]interface{}{
 		"id": id,
@@ -50,21 +50,21 @@ func (h *Handler) getRewardById(c *gin.Context) {
 	_, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
 	rewardId, err := strconv.Atoi(c.Param("rewardId"))
 	if err != nil {
 		newErrorResponse(c, http.StatusBadRequest, "invalid reward id param")
-		h.log.Error(fmt.Sprintf("%s: invalid reward id param", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid reward id param", op), sl.Err(err))
 		return
 	}
 
-	reward, err := h.services.AdminReward.GetById(rewardId)
+	reward, err := h.services.AdminReward.GetById(c.Request.Context(), rewardId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get reward: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get reward", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get reward: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get reward", op), sl.Err(err))
 		return
 	}
 
@@ -79,18 +79,25 @@ func (h *Handler) getAllRewards(c *gin.Context) {
 	_, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	params, err := getListParams(c, models.RewardListFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: invalid list params: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid list params", op), sl.Err(err))
 		return
 	}
 
-	rewards, err := h.services.AdminReward.GetAllRewards()
+	rewards, total, err := h.services.AdminReward.GetAllRewards(c.Request.Context(), params)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get rewards: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get rewards", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get rewards: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get rewards", op), sl.Err(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, rewards)
+	c.JSON(http.StatusOK, models.NewPage(rewards, total, params))
 }
 
 // delete reward from reward table. Independent object, not
@@ -98,28 +105,28 @@ func (h *Handler) getAllRewards(c *gin.Context) {
 func (h *Handler) deleteReward(c *gin.Context) {
 	const op = "delivery.http.v1.admin_reward_handler.deleteReward"
 
-	_, err := getUserId(c)
+	meta, err := getAuditMeta(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
 		return
 	}
 
 	rewardId, err := strconv.Atoi(c.Param("rewardId"))
 	if err != nil {
 		newErrorResponse(c, http.StatusBadRequest, "invalid reward id param")
-		h.log.Error(fmt.Sprintf("%s: invalid reward id param", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid reward id param", op), sl.Err(err))
 		return
 	}
 
-	err = h.services.AdminReward.Delete(rewardId)
+	err = h.services.AdminReward.Delete(c.Request.Context(), meta, rewardId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to delete a reward %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to delete a reward", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to delete a reward %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to delete a reward", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(fmt.Sprintf("%s: a reward is deleted", op), slog.Int("id", rewardId))
+	h.logger(c).Info(fmt.Sprintf("%s: a reward is deleted", op), slog.Int("id", rewardId))
 
 	c.JSON(http.StatusOK, statusResponse{"ok"})
 }
@@ -129,34 +136,34 @@ func (h *Handler) deleteReward(c *gin.Context) {
 func (h *Handler) updateReward(c *gin.Context) {
 	const op = "delivery.http.v1.admin_reward_handler.updateReward"
 
-	_, err := getUserId(c)
+	meta, err := getAuditMeta(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
 		return
 	}
 
 	rewardId, err := strconv.Atoi(c.Param("rewardId"))
 	if err != nil {
 		newErrorResponse(c, http.StatusBadRequest, "invalid reward id param")
-		h.log.Error(fmt.Sprintf("%s: invalid reward id param", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid reward id param", op), sl.Err(err))
 		return
 	}
 
 	var input models.UpdateRewardInput
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
-	if err := h.services.AdminReward.UpdateReward(rewardId, input); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to update a reward %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to update a reward", op), sl.Err(err))
+	if err := h.services.AdminReward.UpdateReward(c.Request.Context(), meta, rewardId, input); err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to update a reward %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to update a reward", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(fmt.Sprintf("%s: a reward has been updated", op), slog.Int("id", rewardId))
+	h.logger(c).Info(fmt.Sprintf("%s: a reward has been updated", op), slog.Int("id", rewardId))
 
 	c.JSON(http.StatusOK, statusResponse{"ok"})
 }
@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/exp/slog"
@@ -16,25 +18,37 @@ func (h *Handler) assignRole(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	meta, err := getAuditMeta(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
 		return
 	}
 
 	var input models.UpdateRoleInput
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
-	id, err := h.services.AdminRole.AssignRole(userId, input)
+	id, err := h.services.AdminRole.AssignRole(c.Request.Context(), meta, userId, input)
+	if errors.Is(err, service.ErrUnknownRole) {
+		newErrorResponse(c, http.StatusNotFound, service.ErrUnknownRole.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: unknown role", op), sl.Err(err))
+		return
+	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to assign role: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to assign role", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to assign role: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to assign role", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(
+	h.logger(c).Info(
 		fmt.Sprintf("%s: a new role has been assigned to user", op),
 		slog.Int("user id", id),
 		slog.String("role", *input.Role),
@@ -45,3 +59,127 @@ func (h *Handler) assignRole(c *gin.Context) {
 		"id":     id,
 	})
 }
+
+func (h *Handler) getAllRoles(c *gin.Context) {
+	const op = "delivery.http.v1.admin_role_handler.getAllRoles"
+
+	roles, err := h.services.AdminRole.GetAllRoles(c.Request.Context())
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get roles: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get roles", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+func (h *Handler) getAllPermissions(c *gin.Context) {
+	const op = "delivery.http.v1.admin_role_handler.getAllPermissions"
+
+	permissions, err := h.services.AdminRole.GetAllPermissions(c.Request.Context())
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get permissions: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get permissions", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+func (h *Handler) createRole(c *gin.Context) {
+	const op = "delivery.http.v1.admin_role_handler.createRole"
+
+	meta, err := getAuditMeta(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
+		return
+	}
+
+	var input models.RoleInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	err = h.services.AdminRole.CreateRole(c.Request.Context(), meta, input)
+	if err != nil {
+		h.roleErrorResponse(c, op, "failed to create role", err)
+		return
+	}
+
+	h.logger(c).Info(fmt.Sprintf("%s: a new role has been added", op), slog.String("role", input.Name))
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+func (h *Handler) updateRole(c *gin.Context) {
+	const op = "delivery.http.v1.admin_role_handler.updateRole"
+
+	meta, err := getAuditMeta(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
+		return
+	}
+
+	var input models.RoleInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	roleName := c.Param("roleName")
+
+	err = h.services.AdminRole.UpdateRole(c.Request.Context(), meta, roleName, input)
+	if err != nil {
+		h.roleErrorResponse(c, op, "failed to update role", err)
+		return
+	}
+
+	h.logger(c).Info(fmt.Sprintf("%s: role has been updated", op), slog.String("role", roleName))
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+func (h *Handler) deleteRole(c *gin.Context) {
+	const op = "delivery.http.v1.admin_role_handler.deleteRole"
+
+	meta, err := getAuditMeta(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
+		return
+	}
+
+	roleName := c.Param("roleName")
+
+	err = h.services.AdminRole.DeleteRole(c.Request.Context(), meta, roleName)
+	if err != nil {
+		h.roleErrorResponse(c, op, "failed to delete role", err)
+		return
+	}
+
+	h.logger(c).Info(fmt.Sprintf("%s: role has been deleted", op), slog.String("role", roleName))
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// roleErrorResponse maps errors of role management to response statuses
+func (h *Handler) roleErrorResponse(c *gin.Context, op, msg string, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrUnknownPermission):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrUnknownRole):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrRoleExists), errors.Is(err, service.ErrRoleInUse), errors.Is(err, service.ErrBuiltInRole):
+		status = http.StatusConflict
+	}
+
+	newErrorResponseErr(c, status, fmt.Sprintf("error: %s: %v", msg, err.Error()), err)
+	h.logger(c).Error(fmt.Sprintf("%s: %s", op, msg), sl.Err(err))
+}
@@ -16,32 +16,39 @@ func (h *Handler) assignReward(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	meta, err := getAuditMeta(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
 	rewardId, err := getRewardId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: reward not found: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get reward Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid reward id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get reward Id", op), sl.Err(err))
 		return
 	}
 
-	id, err := h.services.AdminUserReward.AssignReward(userId, habitId, rewardId)
+	id, err := h.services.AdminUserReward.AssignReward(c.Request.Context(), meta, userId, habitId, rewardId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to assign reward: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to assign reward", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to assign reward: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to assign reward", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(
+	h.logger(c).Info(
 		fmt.Sprintf("%s: a new reward has been assigned to user", op),
 		slog.Int("user id", userId),
 		slog.Int("habit id", habitId),
@@ -60,32 +67,39 @@ func (h *Handler) removeRewardFromUser(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	meta, err := getAuditMeta(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
 	rewardId, err := getRewardId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: reward not found: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get reward Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid reward id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get reward Id", op), sl.Err(err))
 		return
 	}
 
-	err = h.services.AdminUserReward.RemoveFromUser(userId, habitId, rewardId)
+	err = h.services.AdminUserReward.RemoveFromUser(c.Request.Context(), meta, userId, habitId, rewardId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to remove reward from user %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to remove reward from user", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to remove reward from user %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to remove reward from user", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(
+	h.logger(c).Info(
 		fmt.Sprintf("%s: a reward is removed from user", op),
 		slog.Int("user id", userId),
 		slog.Int("habit id", habitId),
@@ -102,38 +116,45 @@ func (h *Handler) updateUserReward(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	meta, err := getAuditMeta(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get admin actor: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get admin actor", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
 	rewardId, err := getRewardId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: reward not found: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get reward Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid reward id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get reward Id", op), sl.Err(err))
 		return
 	}
 
 	var input models.UpdateUserRewardInput
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
-	if err := h.services.AdminUserReward.UpdateUserReward(userId, habitId, rewardId, input); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to assign reward: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to assign reward", op), sl.Err(err))
+	if err := h.services.AdminUserReward.UpdateUserReward(c.Request.Context(), meta, userId, habitId, rewardId, input); err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to assign reward: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to assign reward", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(
+	h.logger(c).Info(
 		fmt.Sprintf("%s: a new reward has been assigned to user", op),
 		slog.Int("user id", userId),
 		slog.Int("habit id", habitId),
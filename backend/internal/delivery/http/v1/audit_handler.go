@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/gin-gonic/gin"
+)
+
+// get entries of the audit log of administrative actions
+func (h *Handler) getAuditLog(c *gin.Context) {
+	const op = "delivery.http.v1.audit_handler.getAuditLog"
+
+	params, err := getListParams(c, models.AuditListFields)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: invalid list params: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid list params", op), sl.Err(err))
+		return
+	}
+
+	entries, total, err := h.services.Audit.GetAll(c.Request.Context(), params)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get audit log: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get audit log", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewPage(entries, total, params))
+}
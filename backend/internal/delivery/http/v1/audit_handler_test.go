@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_handler_getAuditLog(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAudit)
+
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?limit=1&sort=-id&action=role.assign&actor_id=5",
+			mockBehavior: func(s *mock_service.MockAudit) {
+				params := models.ListParams{
+					Limit: 1,
+					Sort:  "id",
+					Desc:  true,
+					Filters: []models.Filter{
+						{Field: "action", Kind: models.FilterExact, Value: "role.assign"},
+						{Field: "actor_id", Kind: models.FilterInt, Value: "5"},
+					},
+				}
+				entries := []models.AuditEntry{{
+					Id:           9,
+					ActorId:      5,
+					TargetUserId: 7,
+					Action:       models.AuditRoleAssign,
+					Before:       json.RawMessage(`{"role":"user_basic"}`),
+					After:        json.RawMessage(`{"role":"moderator"}`),
+					RequestId:    "req-1",
+					CreatedAt:    createdAt,
+				}}
+				s.EXPECT().GetAll(gomock.Any(), params).Return(entries, 2, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"data":[{"id":9,"actorId":5,"targetUserId":7,"action":"role.assign",` +
+				`"before":{"role":"user_basic"},"after":{"role":"moderator"},"requestId":"req-1","createdAt":"2023-05-01T10:00:00Z"}],` +
+				`"next_cursor":"1","total":2}`,
+		},
+		{
+			name:  "Empty",
+			query: "",
+			mockBehavior: func(s *mock_service.MockAudit) {
+				s.EXPECT().GetAll(gomock.Any(), models.ListParams{Limit: models.DefaultListLimit}).Return(nil, 0, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"data":[],"next_cursor":"","total":0}`,
+		},
+		{
+			name:                 "Invalid Filter",
+			query:                "?actor_id=boss",
+			mockBehavior:         func(s *mock_service.MockAudit) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid list params: delivery.http.v1.middleware.getListParams: invalid value of filter actor_id: must be a number"}`,
+		},
+		{
+			name:                 "Invalid Sort Field",
+			query:                "?sort=action",
+			mockBehavior:         func(s *mock_service.MockAudit) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid list params: delivery.http.v1.middleware.getListParams: invalid sort field: action"}`,
+		},
+		{
+			name:  "Service Failure",
+			query: "",
+			mockBehavior: func(s *mock_service.MockAudit) {
+				s.EXPECT().GetAll(gomock.Any(), models.ListParams{Limit: models.DefaultListLimit}).Return(nil, 0, errors.New("connection refused"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"message":"error: failed to get audit log: connection refused"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			audit := mock_service.NewMockAudit(c)
+			testCase.mockBehavior(audit)
+
+			services := &service.Service{Audit: audit}
+			handler := NewHandler(slogdiscard.NewDiscardLogger(), services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/admin/audit", handler.getAuditLog)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/admin/audit"+testCase.query, nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcFlowCookie     = "oidc_flow"
+	oidcFlowCookiePath = "/web/auth/oidc"
+	oidcFlowCookieAge  = 10 * 60 // seconds
+)
+
+/*
+oidcLogin redirects a client to the identity provider. The signed
+flow state is kept in a cookie until the provider redirects back
+*/
+func (h *Handler) oidcLogin(c *gin.Context) {
+	const op = "delivery.http.v1.auth_oidc.oidcLogin"
+
+	provider := c.Param("provider")
+
+	authURL, flowState, err := h.services.OIDC.AuthURL(c.Request.Context(), provider)
+	if errors.Is(err, service.ErrUnknownProvider) {
+		newErrorResponse(c, http.StatusNotFound, fmt.Sprintf("unknown identity provider: %s", provider))
+		h.logger(c).Error(fmt.Sprintf("%s: unknown identity provider", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponse(c, http.StatusBadGateway, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to start sign in", op), sl.Err(err))
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, flowState, oidcFlowCookieAge, oidcFlowCookiePath, "", c.Request.TLS != nil, true)
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// oidcCallback handles the redirect back from the identity provider
+func (h *Handler) oidcCallback(c *gin.Context) {
+	const op = "delivery.http.v1.auth_oidc.oidcCallback"
+
+	provider := c.Param("provider")
+
+	if errParam := c.Query("error"); errParam != "" {
+		newErrorResponse(c, http.StatusUnauthorized, fmt.Sprintf("sign in is rejected by the provider: %s %s", errParam, c.Query("error_description")))
+		h.logger(c).Error(fmt.Sprintf("%s: sign in is rejected by the provider", op), "error", errParam)
+		return
+	}
+
+	flowState, err := c.Cookie(oidcFlowCookie)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, service.ErrOIDCFlowInvalid.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: no flow cookie", op), sl.Err(err))
+		return
+	}
+
+	// the flow state can't be used twice
+	c.SetCookie(oidcFlowCookie, "", -1, oidcFlowCookiePath, "", c.Request.TLS != nil, true)
+
+	tokens, err := h.services.OIDC.Callback(c.Request.Context(), provider, c.Query("code"), c.Query("state"), flowState)
+	if errors.Is(err, service.ErrUnknownProvider) {
+		newErrorResponse(c, http.StatusNotFound, fmt.Sprintf("unknown identity provider: %s", provider))
+		h.logger(c).Error(fmt.Sprintf("%s: unknown identity provider", op), sl.Err(err))
+		return
+	}
+	if errors.Is(err, service.ErrOIDCFlowInvalid) {
+		newErrorResponse(c, http.StatusBadRequest, service.ErrOIDCFlowInvalid.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid flow", op), sl.Err(err))
+		return
+	}
+	if errors.Is(err, service.ErrAccountDeleted) {
+		newErrorResponse(c, http.StatusForbidden, "account is deleted")
+		h.logger(c).Error(fmt.Sprintf("%s: sign in to a deleted account", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to sign in", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
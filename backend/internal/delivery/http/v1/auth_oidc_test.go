@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_handler_oidcCallback(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockOIDC)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		cookie               string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:   "OK",
+			query:  "?code=code&state=state",
+			cookie: "flow",
+			mockBehavior: func(s *mock_service.MockOIDC) {
+				s.EXPECT().Callback(gomock.Any(), "mock", "code", "state", "flow").Return(models.Tokens{AccessToken: "token", RefreshToken: "refresh"}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"token":"token","refreshToken":"refresh"}`,
+		},
+		{
+			name:                 "No flow cookie",
+			query:                "?code=code&state=state",
+			mockBehavior:         func(s *mock_service.MockOIDC) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"sign in flow is invalid or expired"}`,
+		},
+		{
+			name:   "State mismatch",
+			query:  "?code=code&state=other",
+			cookie: "flow",
+			mockBehavior: func(s *mock_service.MockOIDC) {
+				s.EXPECT().Callback(gomock.Any(), "mock", "code", "other", "flow").Return(models.Tokens{}, service.ErrOIDCFlowInvalid)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"sign in flow is invalid or expired"}`,
+		},
+		{
+			name:                 "Rejected by provider",
+			query:                "?error=access_denied",
+			cookie:               "flow",
+			mockBehavior:         func(s *mock_service.MockOIDC) {},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"message":"sign in is rejected by the provider: access_denied "}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			oidc := mock_service.NewMockOIDC(c)
+			testCase.mockBehavior(oidc)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{OIDC: oidc}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/oidc/:provider/callback", handler.oidcCallback)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/oidc/mock/callback"+testCase.query, nil)
+			if testCase.cookie != "" {
+				req.Header.Set("Cookie", oidcFlowCookie+"="+testCase.cookie)
+			}
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
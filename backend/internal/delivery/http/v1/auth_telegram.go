@@ -17,24 +17,33 @@ func (h *Handler) signUpTelegram(c *gin.Context) {
 
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
+	if input.TgUserId <= 0 {
+		newErrorResponse(c, http.StatusBadRequest, "telegram user id is missing")
+		h.logger(c).Error(fmt.Sprintf("%s: invalid input", op), "telegram user id is missing")
+		return
+	}
+
+	// a telegram user signs in with the bot only
+	input.Password = ""
+
 	if err := input.Validate(); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, err.Error())
-		h.log.Error(fmt.Sprintf("%s: invalid input", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid input", op), sl.Err(err))
 		return
 	}
 
-	id, err := h.services.User.CreateUser(input)
+	id, err := h.services.Authorization.SignUpTelegram(c.Request.Context(), input)
 	if err != nil {
 		newErrorResponse(c, http.StatusBadRequest, err.Error())
-		h.log.Error(fmt.Sprintf("%s: failed to add new user", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to add new user", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(fmt.Sprintf("%s: a new user has been added", op), slog.Int("id", id))
+	h.logger(c).Info(fmt.Sprintf("%s: a telegram user has signed up", op), slog.Int("id", id))
 
 	c.JSON(http.StatusOK, map[string]interface{}{
 		"id": id,
@@ -59,7 +68,7 @@ func (h *Handler) signUpTelegram(c *gin.Context) {
 // 		return
 // 	}
 
-// 	token, err := h.services.Authorization.GenerateToken(input.Username, input.Password)
+// 	token, err := h.services.Authorization.GenerateToken(c.Request.Context(), input.Username, input.Password)
 // 	if err != nil {
 // 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 // 		return
@@ -111,7 +120,7 @@ will not allow to create a user without other credentials
 // 		return
 // 	}
 
-// 	deletedUserId, err := h.services.User.DeleteUser(userId)
+// 	deletedUserId, err := h.services.User.DeleteUser(c.Request.Context(), userId)
 // 	if err != nil {
 // 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error from handler: delete user: %v", err.Error()))
 // 		return
@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/exp/slog"
@@ -17,27 +19,36 @@ func (h *Handler) signUpWeb(c *gin.Context) {
 
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, err.Error())
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
+	// telegram accounts are bound only by the bot, a web client can't set them
+	input.TgUsername = ""
+	input.TgUserId = 0
+
 	if err := input.Validate(); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, err.Error())
-		h.log.Error(fmt.Sprintf("%s: invalid input", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid input", op), sl.Err(err))
 		return
 	}
 
 	// the line bellow only for debugging
-	// h.log.Info("Parsed JSON content", slog.Any("value", input))
+	// h.logger(c).Info("Parsed JSON content", slog.Any("value", input))
 
-	id, err := h.services.User.CreateUser(input)
+	id, err := h.services.User.CreateUser(c.Request.Context(), input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
-		h.log.Error(fmt.Sprintf("%s: failed to add new user", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to add new user", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(fmt.Sprintf("%s: a new user has been added", op), slog.Int("id", id))
+	h.logger(c).Info(fmt.Sprintf("%s: a new user has been added", op), slog.Int("id", id))
+
+	// the account is created anyway, the link can be requested again via /forgot
+	if err := h.services.Account.SendVerification(c.Request.Context(), id); err != nil {
+		h.logger(c).Error(fmt.Sprintf("%s: failed to send verification email", op), sl.Err(err))
+	}
 
 	c.JSON(http.StatusOK, map[string]interface{}{
 		"id": id,
@@ -47,6 +58,8 @@ func (h *Handler) signUpWeb(c *gin.Context) {
 type signInInput struct {
 	Username string `json:"userName" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// Reactivate restores the account if it was deleted during the grace period
+	Reactivate bool `json:"reactivate"`
 }
 
 func (h *Handler) signInWeb(c *gin.Context) {
@@ -56,23 +69,217 @@ func (h *Handler) signInWeb(c *gin.Context) {
 
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, err.Error())
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
 	// the line bellow only for debugging
-	// h.log.Info("Parsed JSON content", slog.Any("value", input))
+	// h.logger(c).Info("Parsed JSON content", slog.Any("value", input))
+
+	/*
+		failures are counted per user name and address, so guessing a password is slowed down
+		without letting anyone lock a user out from everywhere soon. Many more failures from
+		all addresses lock the user name, so guessing from many addresses is slowed down too
+	*/
+	clientIP := c.ClientIP()
+
+	if lockedFor := h.services.RateLimit.SignInLocked(input.Username, clientIP); lockedFor > 0 {
+		tooManyRequests(c, lockedFor)
+		h.logger(c).Error(fmt.Sprintf("%s: sign in is locked", op), slog.String("user name", input.Username))
+		return
+	}
 
-	token, err := h.services.Authorization.GenerateToken(input.Username, input.Password)
+	var tokens models.Tokens
+	var err error
+
+	if input.Reactivate {
+		tokens, err = h.services.Authorization.Reactivate(c.Request.Context(), input.Username, input.Password)
+	} else {
+		tokens, err = h.services.Authorization.GenerateToken(c.Request.Context(), input.Username, input.Password)
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		lockedFor := h.services.RateLimit.SignInFailed(input.Username, clientIP)
+		newErrorResponse(c, http.StatusUnauthorized, service.ErrInvalidCredentials.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid credentials", op), sl.Err(err), slog.Duration("locked for", lockedFor))
+		return
+	}
+	if errors.Is(err, service.ErrAccountDeleted) {
+		newErrorResponse(c, http.StatusForbidden, "account is deleted: sign in with \"reactivate\": true to restore it")
+		h.logger(c).Error(fmt.Sprintf("%s: sign in to a deleted account", op), sl.Err(err))
+		return
+	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
-		h.log.Error(fmt.Sprintf("%s: failed to generate JWT token", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to generate JWT token", op), sl.Err(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"token": token,
-	})
+	h.services.RateLimit.SignInSucceeded(input.Username, clientIP)
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) refreshWeb(c *gin.Context) {
+	const op = "delivery.http.v1.refreshWeb"
+
+	var input models.RefreshInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	tokens, err := h.services.Authorization.Refresh(c.Request.Context(), input.RefreshToken)
+	if errors.Is(err, service.ErrRefreshTokenInvalid) ||
+		errors.Is(err, service.ErrRefreshTokenReused) ||
+		errors.Is(err, service.ErrAccountDeleted) {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: refresh token is rejected", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to refresh tokens", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+/*
+logoutWeb logs out the current device. The refresh token in the body
+is optional: without it only the access token is revoked
+*/
+func (h *Handler) logoutWeb(c *gin.Context) {
+	const op = "delivery.http.v1.logoutWeb"
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	var input struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&input); err != nil {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+			return
+		}
+	}
+
+	if err := h.services.Authorization.Logout(c.Request.Context(), userId, c.GetString(tokenCtx), input.RefreshToken); err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to log out", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "ok"})
+}
+
+// logoutAllWeb logs a user out on all devices
+func (h *Handler) logoutAllWeb(c *gin.Context) {
+	const op = "delivery.http.v1.logoutAllWeb"
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	if err := h.services.Authorization.LogoutAll(c.Request.Context(), userId); err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to log out everywhere", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "ok"})
+}
+
+func (h *Handler) verifyEmail(c *gin.Context) {
+	const op = "delivery.http.v1.verifyEmail"
+
+	var input models.VerifyEmailInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	err := h.services.Account.VerifyEmail(c.Request.Context(), input.Token)
+	if errors.Is(err, service.ErrOneTimeTokenInvalid) {
+		newErrorResponse(c, http.StatusBadRequest, service.ErrOneTimeTokenInvalid.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid token", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to verify email", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "ok"})
+}
+
+/*
+forgotPassword always responds with ok, so it does not tell
+whether an email is registered
+*/
+func (h *Handler) forgotPassword(c *gin.Context) {
+	const op = "delivery.http.v1.forgotPassword"
+
+	var input models.ForgotPasswordInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	if err := h.services.Account.ForgotPassword(c.Request.Context(), input.Email); err != nil {
+		h.logger(c).Error(fmt.Sprintf("%s: failed to send password reset email", op), sl.Err(err))
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "ok"})
+}
+
+func (h *Handler) resetPassword(c *gin.Context) {
+	const op = "delivery.http.v1.resetPassword"
+
+	var input models.ResetPasswordInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	if err := models.ValidatePassword(input.NewPassword); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid input", op), sl.Err(err))
+		return
+	}
+
+	err := h.services.Account.ResetPassword(c.Request.Context(), input)
+	if errors.Is(err, service.ErrOneTimeTokenInvalid) {
+		newErrorResponse(c, http.StatusBadRequest, service.ErrOneTimeTokenInvalid.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid token", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to reset password", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "ok"})
 }
 
 // func (h *Handler) deleteUser(c *gin.Context) {
@@ -80,18 +287,18 @@ func (h *Handler) signInWeb(c *gin.Context) {
 
 // 	userId, err := getUserId(c)
 // 	if err != nil {
-// 		h.log.Error(fmt.Sprintf("%s:failed to find a user by id: %d", op, userId), sl.Err(err))
+// 		h.logger(c).Error(fmt.Sprintf("%s:failed to find a user by id: %d", op, userId), sl.Err(err))
 // 		return
 // 	}
 
-// 	deletedUserId, err := h.services.User.DeleteUser(userId)
+// 	deletedUserId, err := h.services.User.DeleteUser(c.Request.Context(), userId)
 // 	if err != nil {
 // 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("%s:failed to delete a user: %d: %s", op, userId, err.Error()))
-// 		h.log.Error(fmt.Sprintf("%s:failed to delete a user: %d", op, userId), sl.Err(err))
+// 		h.logger(c).Error(fmt.Sprintf("%s:failed to delete a user: %d", op, userId), sl.Err(err))
 // 		return
 // 	}
 
-// 	h.log.Info(fmt.Sprintf("%s:user deleted\n", op), slog.Int("id", deletedUserId))
+// 	h.logger(c).Info(fmt.Sprintf("%s:user deleted\n", op), slog.Int("id", deletedUserId))
 
 // 	// c.JSON(http.StatusOK, map[string]interface{}{
 // 	// 	"Status": statusResponse{
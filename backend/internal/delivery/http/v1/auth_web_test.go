@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/service"
@@ -32,17 +33,17 @@ func Test_handler_signUpWeb(t *testing.T) {
 				"firstName": "testUserName",
 				"lastName": "testUserLastName",
 				"eMail": "testEmail@gmail.com",
-				"password": "qwerty"
+				"password": "qwerty123"
 				}`,
 			inputUser: models.User{
 				Username:  "testUser",
 				FirstName: "testUserName",
 				LastName:  "testUserLastName",
 				Email:     "testEmail@gmail.com",
-				Password:  "qwerty",
+				Password:  "qwerty123",
 			},
 			mockBehavior: func(s *mock_service.MockUser, user models.User) {
-				s.EXPECT().CreateUser(user).Return(1, nil)
+				s.EXPECT().CreateUser(gomock.Any(), user).Return(1, nil)
 			},
 			expectedStatusCode:   200,
 			expectedResponseBody: `{"id":1}`,
@@ -54,18 +55,30 @@ func Test_handler_signUpWeb(t *testing.T) {
 				"firstName": "testUserName",
 				"lastName": "testUserLastName",
 				"eMail": "testEmail@gmail.com",
-				"password": "qwerty"
+				"password": "qwerty123"
 			}`,
 			inputUser: models.User{
 				FirstName: "testUserName",
 				LastName:  "testUserLastName",
 				Email:     "testEmail@gmail.com",
-				Password:  "qwerty",
+				Password:  "qwerty123",
 			},
 			mockBehavior:         func(s *mock_service.MockUser, user models.User) {},
 			expectedStatusCode:   400,
 			expectedResponseBody: `{"message":"user structure has no values"}`,
 		},
+		{
+			name: "Weak password",
+			inputBody: `{
+				"userName": "testUser",
+				"eMail": "testEmail@gmail.com",
+				"password": "qwertyui"
+			}`,
+			inputUser:            models.User{},
+			mockBehavior:         func(s *mock_service.MockUser, user models.User) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"password must contain at least one letter and one digit"}`,
+		},
 		{
 			/*
 				this test case checks only the status code returned
@@ -77,17 +90,17 @@ func Test_handler_signUpWeb(t *testing.T) {
 				"firstName": "testUserName",
 				"lastName": "testUserLastName",
 				"eMail": "testEmail@gmail.com",
-				"password": "qwerty"
+				"password": "qwerty123"
 				}`,
 			inputUser: models.User{
 				Username:  "testUser",
 				FirstName: "testUserName",
 				LastName:  "testUserLastName",
 				Email:     "testEmail@gmail.com",
-				Password:  "qwerty",
+				Password:  "qwerty123",
 			},
 			mockBehavior: func(s *mock_service.MockUser, user models.User) {
-				s.EXPECT().CreateUser(user).Return(0, errors.New("something went wrong"))
+				s.EXPECT().CreateUser(gomock.Any(), user).Return(0, errors.New("something went wrong"))
 			},
 			expectedStatusCode:   500,
 			expectedResponseBody: `{"message":"something went wrong"}`,
@@ -103,9 +116,12 @@ func Test_handler_signUpWeb(t *testing.T) {
 			user := mock_service.NewMockUser(c)
 			testCase.mockBehavior(user, testCase.inputUser)
 
+			account := mock_service.NewMockAccount(c)
+			account.EXPECT().SendVerification(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
 			log := slogdiscard.NewDiscardLogger()
 
-			services := &service.Service{User: user}
+			services := &service.Service{User: user, Account: account}
 			handler := NewHandler(log, services)
 
 			// Init Endpoint
@@ -135,3 +151,192 @@ func Test_handler_signUpWeb(t *testing.T) {
 		})
 	}
 }
+
+func Test_handler_signInWeb(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthorization, r *mock_service.MockRateLimit)
+
+	const clientIP = "192.0.2.1"
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedRetryAfter   string
+		expectedResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"userName": "testUser", "password": "qwerty"}`,
+			mockBehavior: func(s *mock_service.MockAuthorization, r *mock_service.MockRateLimit) {
+				r.EXPECT().SignInLocked("testUser", clientIP).Return(time.Duration(0))
+				s.EXPECT().GenerateToken(gomock.Any(), "testUser", "qwerty").Return(models.Tokens{AccessToken: "token", RefreshToken: "refresh"}, nil)
+				r.EXPECT().SignInSucceeded("testUser", clientIP)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"token":"token","refreshToken":"refresh"}`,
+		},
+		{
+			name:      "Deleted account",
+			inputBody: `{"userName": "testUser", "password": "qwerty"}`,
+			mockBehavior: func(s *mock_service.MockAuthorization, r *mock_service.MockRateLimit) {
+				r.EXPECT().SignInLocked("testUser", clientIP).Return(time.Duration(0))
+				s.EXPECT().GenerateToken(gomock.Any(), "testUser", "qwerty").Return(models.Tokens{}, service.ErrAccountDeleted)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"message":"account is deleted: sign in with \"reactivate\": true to restore it"}`,
+		},
+		{
+			name:      "Reactivate",
+			inputBody: `{"userName": "testUser", "password": "qwerty", "reactivate": true}`,
+			mockBehavior: func(s *mock_service.MockAuthorization, r *mock_service.MockRateLimit) {
+				r.EXPECT().SignInLocked("testUser", clientIP).Return(time.Duration(0))
+				s.EXPECT().Reactivate(gomock.Any(), "testUser", "qwerty").Return(models.Tokens{AccessToken: "token", RefreshToken: "refresh"}, nil)
+				r.EXPECT().SignInSucceeded("testUser", clientIP)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"token":"token","refreshToken":"refresh"}`,
+		},
+		{
+			name:      "Invalid credentials",
+			inputBody: `{"userName": "testUser", "password": "wrong"}`,
+			mockBehavior: func(s *mock_service.MockAuthorization, r *mock_service.MockRateLimit) {
+				r.EXPECT().SignInLocked("testUser", clientIP).Return(time.Duration(0))
+				s.EXPECT().GenerateToken(gomock.Any(), "testUser", "wrong").Return(models.Tokens{}, service.ErrInvalidCredentials)
+				r.EXPECT().SignInFailed("testUser", clientIP).Return(time.Duration(0))
+			},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"message":"invalid user name or password"}`,
+		},
+		{
+			name:      "Locked",
+			inputBody: `{"userName": "testUser", "password": "qwerty"}`,
+			mockBehavior: func(s *mock_service.MockAuthorization, r *mock_service.MockRateLimit) {
+				r.EXPECT().SignInLocked("testUser", clientIP).Return(1500 * time.Millisecond)
+			},
+			expectedStatusCode:   429,
+			expectedRetryAfter:   "2",
+			expectedResponseBody: `{"message":"too many requests: retry in 2 seconds"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthorization(c)
+			rateLimit := mock_service.NewMockRateLimit(c)
+			testCase.mockBehavior(auth, rateLimit)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Authorization: auth, RateLimit: rateLimit}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("sign-in", handler.signInWeb)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(
+				"POST", "/sign-in",
+				bytes.NewBufferString(testCase.inputBody),
+			)
+			req.RemoteAddr = "192.0.2.1:1234"
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Header().Get("Retry-After") != testCase.expectedRetryAfter {
+				t.Errorf("Expected Retry-After '%s' but got '%s'", testCase.expectedRetryAfter, w.Header().Get("Retry-After"))
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handler_refreshWeb(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthorization)
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"refreshToken": "refresh"}`,
+			mockBehavior: func(s *mock_service.MockAuthorization) {
+				s.EXPECT().Refresh(gomock.Any(), "refresh").Return(models.Tokens{AccessToken: "token", RefreshToken: "next"}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"token":"token","refreshToken":"next"}`,
+		},
+		{
+			name:                 "Missing token",
+			inputBody:            `{}`,
+			mockBehavior:         func(s *mock_service.MockAuthorization) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"Key: 'RefreshInput.RefreshToken' Error:Field validation for 'RefreshToken' failed on the 'required' tag"}`,
+		},
+		{
+			name:      "Reused token",
+			inputBody: `{"refreshToken": "refresh"}`,
+			mockBehavior: func(s *mock_service.MockAuthorization) {
+				s.EXPECT().Refresh(gomock.Any(), "refresh").Return(models.Tokens{}, service.ErrRefreshTokenReused)
+			},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"message":"refresh token is reused"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthorization(c)
+			testCase.mockBehavior(auth)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Authorization: auth}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("refresh", handler.refreshWeb)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(
+				"POST", "/refresh",
+				bytes.NewBufferString(testCase.inputBody),
+			)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aidos-dev/habit-tracker/backend/api"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+// getOpenAPISpec serves the OpenAPI 3 spec of the api
+func (h *Handler) getOpenAPISpec(c *gin.Context) {
+	const op = "delivery.http.v1.docs_handler.getOpenAPISpec"
+
+	spec, err := api.JSON()
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "failed to load api spec")
+		h.logger(c).Error(fmt.Sprintf("%s: failed to load api spec", op), sl.Err(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
+
+/*
+swaggerUI serves Swagger UI. The index page is our own, so the UI opens
+the spec from /openapi.json, the scripts and styles come from swaggo/files
+*/
+func (h *Handler) swaggerUI(c *gin.Context) {
+	file := c.Param("any")
+
+	switch file {
+	case "", "/", "/index.html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", api.SwaggerIndex)
+	default:
+		c.FileFromFS(file, swaggerFiles.HTTP)
+	}
+}
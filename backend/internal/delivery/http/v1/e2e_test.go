@@ -0,0 +1,963 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/api"
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	v1 "github.com/aidos-dev/habit-tracker/backend/internal/delivery/http/v1"
+	"github.com/aidos-dev/habit-tracker/backend/internal/mailer"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/ratelimit"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository/memory"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository/sqlite"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/aidos-dev/habit-tracker/pkg/svcauth"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+/*
+end-to-end tests run the routes with the real services over a real
+repository, nothing is mocked but the mail server. They use the api
+as a client does, so they know nothing about the handlers inside.
+Every scenario runs over the in-memory and the SQLite repositories, with
+requests and responses checked against the openapi spec
+*/
+
+// the telegram bot signs its requests with this service id and secret
+const (
+	e2eBotId     = "telegram-bot"
+	e2eBotSecret = "e2e-bot-secret"
+)
+
+// e2eMailer keeps sent emails, so a test can follow the links in them
+type e2eMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *e2eMailer) Send(msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+// token returns the token of the link in the last email sent to the address
+func (m *e2eMailer) token(t *testing.T, to string) string {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To != to {
+			continue
+		}
+
+		_, link, ok := strings.Cut(m.messages[i].Body, "?token=")
+		if !ok {
+			break
+		}
+
+		link, _, _ = strings.Cut(link, "\r\n")
+
+		token, err := url.QueryUnescape(link)
+		if err != nil {
+			t.Fatalf("invalid token in email: %v", err)
+		}
+
+		return token
+	}
+
+	t.Fatalf("no email with a token sent to %s", to)
+
+	return ""
+}
+
+/*
+e2eSpecHandler fails the test when the openapi validator logs a response
+that does not match the spec. A request out of the spec is answered
+with 400 by the validator, so it is caught by the status code
+*/
+type e2eSpecHandler struct {
+	t *testing.T
+}
+
+func (h *e2eSpecHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelError
+}
+
+func (h *e2eSpecHandler) Handle(_ context.Context, r slog.Record) error {
+	if !strings.Contains(r.Message, "response does not match the api spec") {
+		return nil
+	}
+
+	attrs := []string{r.Message}
+	r.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr.String())
+		return true
+	})
+
+	h.t.Error(strings.Join(attrs, " "))
+
+	return nil
+}
+
+func (h *e2eSpecHandler) WithAttrs(_ []slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *e2eSpecHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
+
+type e2eClient struct {
+	t      *testing.T
+	router *gin.Engine
+	mail   *e2eMailer
+}
+
+// e2eUser is a signed up web user
+type e2eUser struct {
+	id    int
+	token string
+}
+
+type e2eErrorResponse struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+type e2ePage struct {
+	Data  []json.RawMessage `json:"data"`
+	Total int               `json:"total"`
+}
+
+type e2eId struct {
+	Id int `json:"id"`
+}
+
+func newE2EConfig() *config.Config {
+	cfg := &config.Config{}
+
+	cfg.Account.DeletionGracePeriod = 720 * time.Hour
+	cfg.Auth.AccessTokenTTL = 15 * time.Minute
+	cfg.Auth.RefreshTokenTTL = 720 * time.Hour
+	cfg.Auth.VerifyTokenTTL = 24 * time.Hour
+	cfg.Auth.ResetTokenTTL = time.Hour
+	cfg.Auth.PersonalTokenTTL = 24 * time.Hour
+	cfg.Auth.PersonalTokenMaxTTL = 48 * time.Hour
+	cfg.Auth.SigningKeys = map[string]string{"e2e": "e2e-signing-key"}
+	cfg.Auth.ActiveKeyId = "e2e"
+	cfg.ServiceAuth.Keys = map[string]string{e2eBotId: e2eBotSecret}
+	cfg.ServiceAuth.MaxClockSkew = time.Minute
+	cfg.Mail.AppURL = "http://habit-tracker.test"
+	cfg.Idempotency.TTL = time.Hour
+	cfg.Telegram.LinkCodeTTL = 10 * time.Minute
+
+	return cfg
+}
+
+func newE2EClient(t *testing.T, repos *repository.Repository) *e2eClient {
+	return newE2EClientConfig(t, repos, newE2EConfig())
+}
+
+func newE2EClientConfig(t *testing.T, repos *repository.Repository, cfg *config.Config) *e2eClient {
+	mail := &e2eMailer{}
+
+	services := service.NewService(repos, cfg, mail, ratelimit.NewMemoryStore(time.Minute))
+	handler := v1.NewHandler(slogdiscard.NewDiscardLogger(), services)
+
+	// requests and responses are checked against the spec the way config.yml asks outside prod
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("failed to load api spec: %v", err)
+	}
+
+	validator, err := v1.NewOpenAPIValidator(slog.New(&e2eSpecHandler{t: t}), doc)
+	if err != nil {
+		t.Fatalf("failed to init openapi validator: %v", err)
+	}
+
+	router, err := handler.InitRoutes(cfg.HTTPServer.TrustedProxies, v1.NewRequestTimeout(5*time.Second), validator)
+	if err != nil {
+		t.Fatalf("failed to init routes: %v", err)
+	}
+
+	return &e2eClient{t: t, router: router, mail: mail}
+}
+
+// newRequest builds a request with the body encoded as json
+func (c *e2eClient) newRequest(method, path string, body any) (*http.Request, []byte) {
+	c.t.Helper()
+
+	var raw []byte
+	if body != nil {
+		var err error
+		if raw, err = json.Marshal(body); err != nil {
+			c.t.Fatalf("failed to encode request body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, raw
+}
+
+/*
+send serves a request and checks the status code of the response. The
+response is decoded into out if it is not nil
+*/
+func (c *e2eClient) send(req *http.Request, expectedStatusCode int, out any) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	w := httptest.NewRecorder()
+	c.router.ServeHTTP(w, req)
+
+	if w.Code != expectedStatusCode {
+		c.t.Fatalf("%s %s: expected status code: %d but got: %d, body: %s", req.Method, req.URL, expectedStatusCode, w.Code, w.Body.String())
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			c.t.Fatalf("%s %s: failed to decode response body '%s': %v", req.Method, req.URL, w.Body.String(), err)
+		}
+	}
+
+	return w
+}
+
+// do sends a request of a web client, the token is sent as a bearer one if it is set
+func (c *e2eClient) do(method, path, token string, header map[string]string, body any, expectedStatusCode int, out any) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	req, _ := c.newRequest(method, path, body)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	return c.send(req, expectedStatusCode, out)
+}
+
+/*
+doBot sends a request the way the telegram bot does: signed by the
+service secret, with the telegram id of the user in the query
+*/
+func (c *e2eClient) doBot(method, path string, tgUserId int64, header map[string]string, body any, expectedStatusCode int, out any) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	if tgUserId != 0 {
+		path = fmt.Sprintf("%s?tgUserId=%d", path, tgUserId)
+	}
+
+	req, raw := c.newRequest(method, path, body)
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	if err := svcauth.SignRequest(req, e2eBotId, e2eBotSecret, raw); err != nil {
+		c.t.Fatalf("failed to sign request: %v", err)
+	}
+
+	return c.send(req, expectedStatusCode, out)
+}
+
+// signUp registers a web user, confirms the email and signs in
+func (c *e2eClient) signUp(userName string) e2eUser {
+	c.t.Helper()
+
+	email := userName + "@habit-tracker.test"
+	user := map[string]string{
+		"userName":  userName,
+		"firstName": "First",
+		"lastName":  "Last",
+		"eMail":     email,
+		"password":  "password1",
+	}
+
+	var created e2eId
+	c.do(http.MethodPost, "/web/auth/sign-up", "", nil, user, http.StatusOK, &created)
+	c.do(http.MethodPost, "/web/auth/verify", "", nil, map[string]string{"token": c.mail.token(c.t, email)}, http.StatusOK, nil)
+
+	return e2eUser{id: created.Id, token: c.signIn(userName)}
+}
+
+func (c *e2eClient) signIn(userName string) string {
+	c.t.Helper()
+
+	var tokens struct {
+		Token string `json:"token"`
+	}
+	c.do(http.MethodPost, "/web/auth/sign-in", "", nil, map[string]string{"userName": userName, "password": "password1"}, http.StatusOK, &tokens)
+
+	return tokens.Token
+}
+
+// setRole changes the role of a user right in the repository, as the first admin is made
+func setRole(t *testing.T, repos *repository.Repository, userId int, role string) {
+	t.Helper()
+
+	if _, err := repos.AdminRole.AssignRole(context.Background(), userId, models.UpdateRoleInput{Role: &role}); err != nil {
+		t.Fatalf("failed to assign role %s: %v", role, err)
+	}
+}
+
+func newSQLiteRepository(t *testing.T) *repository.Repository {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.DB.Path = filepath.Join(t.TempDir(), "habit-tracker.db")
+
+	db, err := sqlite.NewSQLiteDB(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return sqlite.NewSQLiteRepository(db)
+}
+
+func newMemoryRepository(t *testing.T) *repository.Repository {
+	return memory.NewMemoryRepository()
+}
+
+// runE2E runs every scenario over a new repository
+func runE2E(t *testing.T, newRepository func(t *testing.T) *repository.Repository) {
+	scenarios := []struct {
+		name string
+		run  func(t *testing.T, repos *repository.Repository)
+	}{
+		{name: "Web Habits", run: runE2EHabits},
+		{name: "Telegram Habits", run: runE2ETelegram},
+		{name: "Admin Rewards", run: runE2EAdminRewards},
+		{name: "Roles", run: runE2ERoles},
+		{name: "Search", run: runE2ESearch},
+		{name: "Unverified Email", run: runE2EUnverifiedEmail},
+		{name: "Personal Tokens", run: runE2EPersonalTokens},
+		{name: "Account", run: runE2EAccount},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			scenario.run(t, newRepository(t))
+		})
+	}
+}
+
+func Test_E2E_Memory(t *testing.T) {
+	runE2E(t, newMemoryRepository)
+}
+
+func Test_E2E_SQLite(t *testing.T) {
+	runE2E(t, newSQLiteRepository)
+}
+
+/*
+Test_E2E_ForwardedFor checks that a client can't get a new address by
+sending X-Forwarded-For, unless the request comes from a trusted proxy.
+httptest requests come from 192.0.2.1
+*/
+func Test_E2E_ForwardedFor(t *testing.T) {
+	signIn := func(c *e2eClient, password, forwardedFor string, expectedStatusCode int) {
+		c.t.Helper()
+
+		input := map[string]string{"userName": "runner", "password": password}
+		c.do(http.MethodPost, "/web/auth/sign-in", "", map[string]string{"X-Forwarded-For": forwardedFor}, input, expectedStatusCode, nil)
+	}
+
+	// newLimitsConfig turns on the rate limit with a large budget and locks sign in after threshold failures
+	newLimitsConfig := func(threshold int) *config.Config {
+		cfg := newE2EConfig()
+		cfg.RateLimit.Enabled = true
+		cfg.RateLimit.Default = config.RateLimitRule{Requests: 100, Per: time.Minute, Burst: 100}
+		cfg.RateLimit.SignInLockout = config.SignInLockout{Threshold: threshold, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+
+		return cfg
+	}
+
+	t.Run("Rate Limit", func(t *testing.T) {
+		cfg := newLimitsConfig(10)
+		cfg.RateLimit.Routes = map[string]config.RateLimitRule{
+			"POST /web/auth/sign-in": {Requests: 1, Per: time.Hour, Burst: 2},
+		}
+
+		c := newE2EClientConfig(t, newMemoryRepository(t), cfg)
+
+		signIn(c, "wrong", "203.0.113.1", http.StatusUnauthorized)
+		signIn(c, "wrong", "203.0.113.2", http.StatusUnauthorized)
+		signIn(c, "wrong", "203.0.113.3", http.StatusTooManyRequests)
+	})
+
+	t.Run("Sign In Lockout", func(t *testing.T) {
+		cfg := newLimitsConfig(2)
+
+		c := newE2EClientConfig(t, newMemoryRepository(t), cfg)
+		c.signUp("runner")
+
+		signIn(c, "wrong", "203.0.113.1", http.StatusUnauthorized)
+		signIn(c, "wrong", "203.0.113.2", http.StatusUnauthorized)
+		signIn(c, "password1", "203.0.113.3", http.StatusTooManyRequests)
+	})
+
+	t.Run("Trusted Proxy", func(t *testing.T) {
+		cfg := newLimitsConfig(2)
+		cfg.HTTPServer.TrustedProxies = []string{"192.0.2.1"}
+
+		c := newE2EClientConfig(t, newMemoryRepository(t), cfg)
+		c.signUp("runner")
+
+		signIn(c, "wrong", "203.0.113.1", http.StatusUnauthorized)
+		signIn(c, "wrong", "203.0.113.1", http.StatusUnauthorized)
+		signIn(c, "password1", "203.0.113.1", http.StatusTooManyRequests)
+
+		// the proxy tells the address of the client, another client is not locked out
+		signIn(c, "password1", "203.0.113.2", http.StatusOK)
+	})
+
+	t.Run("Sign In Lockout Across Addresses", func(t *testing.T) {
+		cfg := newLimitsConfig(2)
+		cfg.RateLimit.SignInLockout.UserThreshold = 3
+		cfg.HTTPServer.TrustedProxies = []string{"192.0.2.1"}
+
+		c := newE2EClientConfig(t, newMemoryRepository(t), cfg)
+		c.signUp("runner")
+
+		// every address fails once only, the user name is locked anyway
+		signIn(c, "wrong", "203.0.113.1", http.StatusUnauthorized)
+		signIn(c, "wrong", "203.0.113.2", http.StatusUnauthorized)
+		signIn(c, "wrong", "203.0.113.3", http.StatusUnauthorized)
+		signIn(c, "password1", "203.0.113.4", http.StatusTooManyRequests)
+	})
+
+	t.Run("Invalid Tokens", func(t *testing.T) {
+		cfg := newLimitsConfig(10)
+		cfg.RateLimit.Address = config.RateLimitRule{Requests: 1, Per: time.Hour, Burst: 2}
+
+		c := newE2EClientConfig(t, newMemoryRepository(t), cfg)
+
+		// the address runs out of its budget before the tokens are checked
+		c.do(http.MethodGet, "/web/api/habits/", "invalid", nil, nil, http.StatusUnauthorized, nil)
+		c.do(http.MethodGet, "/web/api/habits/", "invalid", nil, nil, http.StatusUnauthorized, nil)
+		c.do(http.MethodGet, "/web/api/habits/", "invalid", nil, nil, http.StatusTooManyRequests, nil)
+	})
+}
+
+// runE2EHabits goes through the life of a habit of a web user
+func runE2EHabits(t *testing.T, repos *repository.Repository) {
+	c := newE2EClient(t, repos)
+
+	token := c.signUp("runner").token
+
+	// the user name is taken
+	user := map[string]string{"userName": "runner", "eMail": "other@habit-tracker.test", "password": "password1"}
+	c.do(http.MethodPost, "/web/auth/sign-up", "", nil, user, http.StatusConflict, nil)
+
+	var wrongPassword e2eErrorResponse
+	c.do(http.MethodPost, "/web/auth/sign-in", "", nil, map[string]string{"userName": "runner", "password": "password2"}, http.StatusUnauthorized, &wrongPassword)
+
+	c.do(http.MethodGet, "/web/api/habits/", "", nil, nil, http.StatusUnauthorized, nil)
+
+	var created struct {
+		Id int `json:"habitId"`
+	}
+	c.do(http.MethodPost, "/web/api/habits/", token, nil, map[string]string{"title": "running", "description": "every morning"}, http.StatusOK, &created)
+	c.do(http.MethodPost, "/web/api/habits/", token, nil, map[string]string{"title": "reading"}, http.StatusOK, nil)
+
+	habitPath := fmt.Sprintf("/web/api/habits/%d", created.Id)
+
+	var page e2ePage
+	c.do(http.MethodGet, "/web/api/habits/?title=RUN", token, nil, nil, http.StatusOK, &page)
+	if page.Total != 1 || len(page.Data) != 1 {
+		t.Fatalf("expected a single habit found by title, got %d of %d", len(page.Data), page.Total)
+	}
+
+	w := c.do(http.MethodGet, habitPath, token, nil, nil, http.StatusOK, nil)
+	expectedHabit := fmt.Sprintf(`{"habitId":%d,"title":"running","description":"every morning","version":1}`, created.Id)
+	if w.Body.String() != expectedHabit {
+		t.Fatalf("Expected response body '%s' but got '%s'", expectedHabit, w.Body.String())
+	}
+
+	// an update made on the version read before goes through once
+	ifMatch := map[string]string{"If-Match": w.Header().Get("ETag")}
+	c.do(http.MethodPut, habitPath, token, ifMatch, map[string]string{"title": "jogging"}, http.StatusOK, nil)
+	c.do(http.MethodPut, habitPath, token, ifMatch, map[string]string{"title": "sprinting"}, http.StatusPreconditionFailed, nil)
+
+	// any strong tag of a list matches, a weak one never does
+	c.do(http.MethodPut, habitPath, token, map[string]string{"If-Match": `"1", "2"`}, map[string]string{"title": "sprinting"}, http.StatusOK, nil)
+	c.do(http.MethodPut, habitPath, token, map[string]string{"If-Match": `W/"3"`}, map[string]string{"title": "walking"}, http.StatusPreconditionFailed, nil)
+	w = c.do(http.MethodPut, habitPath, token, map[string]string{"If-Match": `"3"`}, map[string]string{"title": "jogging"}, http.StatusOK, nil)
+
+	// an update tells the version it made, so the next update needs no read
+	if w.Header().Get("ETag") != `"4"` {
+		t.Fatalf("Expected ETag '%s' but got '%s'", `"4"`, w.Header().Get("ETag"))
+	}
+	c.do(http.MethodPut, habitPath, token, map[string]string{"If-Match": w.Header().Get("ETag")}, map[string]string{"title": "running"}, http.StatusOK, nil)
+
+	// a retried update is replayed with the version the first one made
+	retry := map[string]string{"Idempotency-Key": "e2e-rename", "If-Match": `"5"`}
+	c.do(http.MethodPut, habitPath, token, retry, map[string]string{"title": "cycling"}, http.StatusOK, nil)
+	w = c.do(http.MethodPut, habitPath, token, retry, map[string]string{"title": "cycling"}, http.StatusOK, nil)
+	if w.Header().Get("Idempotent-Replayed") != "true" || w.Header().Get("ETag") != `"6"` {
+		t.Fatalf("Expected a replayed response with ETag '%s' but got '%s' replayed: '%s'", `"6"`, w.Header().Get("ETag"), w.Header().Get("Idempotent-Replayed"))
+	}
+
+	tracker := map[string]any{
+		"unit_of_messure": "km",
+		"goal":            "5",
+		"frequency":       "daily",
+		"start_date":      "2023-05-01T00:00:00Z",
+		"end_date":        "2023-06-01T00:00:00Z",
+		"counter":         3,
+		"done":            false,
+	}
+	w = c.do(http.MethodPut, habitPath+"/tracker/", token, nil, tracker, http.StatusOK, nil)
+	if w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("Expected ETag '%s' but got '%s'", `"2"`, w.Header().Get("ETag"))
+	}
+
+	w = c.do(http.MethodGet, habitPath+"/tracker/", token, nil, nil, http.StatusOK, nil)
+	expectedTracker := fmt.Sprintf(`{"trackerId":%d,"habitId":%d,"unit_of_messure":"km","goal":"5","frequency":"daily",`+
+		`"start_date":"2023-05-01T00:00:00Z","end_date":"2023-06-01T00:00:00Z","counter":3,"done":false,"version":2}`, created.Id, created.Id)
+	if w.Body.String() != expectedTracker {
+		t.Fatalf("Expected response body '%s' but got '%s'", expectedTracker, w.Body.String())
+	}
+
+	c.do(http.MethodGet, "/web/api/trackers/?unit_of_messure=km", token, nil, nil, http.StatusOK, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected a single tracker found by unit, got %d", page.Total)
+	}
+
+	// another user does not see the habit
+	otherToken := c.signUp("reader").token
+	c.do(http.MethodGet, habitPath, otherToken, nil, nil, http.StatusNotFound, nil)
+	c.do(http.MethodDelete, habitPath, otherToken, nil, nil, http.StatusNotFound, nil)
+
+	c.do(http.MethodDelete, habitPath, token, nil, nil, http.StatusOK, nil)
+	c.do(http.MethodGet, habitPath, token, nil, nil, http.StatusNotFound, nil)
+
+	c.do(http.MethodGet, "/web/api/habits/", token, nil, nil, http.StatusOK, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected a single habit left, got %d", page.Total)
+	}
+}
+
+// runE2ETelegram goes through the life of a habit of a user of the telegram bot
+func runE2ETelegram(t *testing.T, repos *repository.Repository) {
+	c := newE2EClient(t, repos)
+
+	const tgUserId = 1001
+
+	tgUser := map[string]any{"tg_user_name": "tg_runner", "tg_user_id": tgUserId}
+
+	// nothing but the bot can sign a telegram user up
+	c.do(http.MethodPost, "/telegram/auth/sign-up", "", nil, tgUser, http.StatusUnauthorized, nil)
+	c.doBot(http.MethodPost, "/telegram/auth/sign-up", 0, nil, tgUser, http.StatusOK, nil)
+
+	// an unknown telegram user has to start the bot first
+	c.doBot(http.MethodGet, "/telegram/api/habits/", 2002, nil, nil, http.StatusUnauthorized, nil)
+
+	// a telegram user has no email to verify, so a habit is created at once
+	var created struct {
+		Id int `json:"habitId"`
+	}
+	c.doBot(http.MethodPost, "/telegram/api/habits/", tgUserId, nil, map[string]string{"title": "push-ups"}, http.StatusOK, &created)
+
+	habitPath := fmt.Sprintf("/telegram/api/habits/%d", created.Id)
+
+	var page e2ePage
+	c.doBot(http.MethodGet, "/telegram/api/habits/", tgUserId, nil, nil, http.StatusOK, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected a single habit of the telegram user, got %d", page.Total)
+	}
+
+	c.doBot(http.MethodPut, habitPath, tgUserId, nil, map[string]string{"title": "pull-ups"}, http.StatusOK, nil)
+
+	tracker := map[string]any{"unit_of_messure": "times", "goal": "20", "frequency": "daily", "counter": 5, "done": true}
+	w := c.doBot(http.MethodGet, habitPath+"/tracker/", tgUserId, nil, nil, http.StatusOK, nil)
+	ifMatch := map[string]string{"If-Match": w.Header().Get("ETag")}
+	c.doBot(http.MethodPut, habitPath+"/tracker/", tgUserId, ifMatch, tracker, http.StatusOK, nil)
+	c.doBot(http.MethodPut, habitPath+"/tracker/", tgUserId, ifMatch, tracker, http.StatusPreconditionFailed, nil)
+
+	var updated struct {
+		Counter int  `json:"counter"`
+		Done    bool `json:"done"`
+		Version int  `json:"version"`
+	}
+	c.doBot(http.MethodGet, habitPath+"/tracker/", tgUserId, nil, nil, http.StatusOK, &updated)
+	if updated.Counter != 5 || !updated.Done || updated.Version != 2 {
+		t.Fatalf("unexpected tracker after update: %+v", updated)
+	}
+
+	// a web user can't reach the habit of the telegram user
+	webToken := c.signUp("walker").token
+	c.do(http.MethodGet, fmt.Sprintf("/web/api/habits/%d", created.Id), webToken, nil, nil, http.StatusNotFound, nil)
+
+	c.doBot(http.MethodDelete, habitPath, tgUserId, nil, nil, http.StatusOK, nil)
+	c.doBot(http.MethodGet, habitPath, tgUserId, nil, nil, http.StatusNotFound, nil)
+}
+
+/*
+runE2EAdminRewards goes through the rewards an admin creates and gives
+to a web and a telegram user for their habits
+*/
+func runE2EAdminRewards(t *testing.T, repos *repository.Repository) {
+	c := newE2EClient(t, repos)
+
+	admin := c.signUp("boss")
+	setRole(t, repos, admin.id, models.Administrator)
+	// the role is a claim of the token, so the admin signs in again
+	admin.token = c.signIn("boss")
+
+	var reward e2eId
+	c.do(http.MethodPost, "/web/api/admin/rewardsAdmin/", admin.token, nil, map[string]string{"title": "gold", "description": "a month in a row"}, http.StatusOK, &reward)
+	c.do(http.MethodPost, "/web/api/admin/rewardsAdmin/", admin.token, nil, map[string]string{"title": "gold", "description": "a month in a row"}, http.StatusConflict, nil)
+
+	user := c.signUp("runner")
+
+	var habit struct {
+		Id int `json:"habitId"`
+	}
+	c.do(http.MethodPost, "/web/api/habits/", user.token, nil, map[string]string{"title": "running"}, http.StatusOK, &habit)
+
+	// an update made by the admin tells the new version as well
+	adminHabitPath := fmt.Sprintf("/web/api/admin/users/%d/habits/%d", user.id, habit.Id)
+	w := c.do(http.MethodPut, adminHabitPath, admin.token, map[string]string{"If-Match": `"1"`}, map[string]string{"title": "running"}, http.StatusOK, nil)
+	if w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("Expected ETag '%s' but got '%s'", `"2"`, w.Header().Get("ETag"))
+	}
+
+	tracker := map[string]any{"unit_of_messure": "km", "goal": "5", "frequency": "daily", "counter": 1, "done": false}
+	w = c.do(http.MethodPut, adminHabitPath+"/tracker/", admin.token, nil, tracker, http.StatusOK, nil)
+	if w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("Expected ETag '%s' but got '%s'", `"2"`, w.Header().Get("ETag"))
+	}
+
+	assignPath := fmt.Sprintf("/web/api/admin/users/%d/habits/%d/rewardsUserAdmin/%d", user.id, habit.Id, reward.Id)
+	c.do(http.MethodPost, assignPath, admin.token, map[string]string{"X-Request-ID": "e2e-assign"}, nil, http.StatusOK, nil)
+	c.do(http.MethodPost, assignPath, admin.token, nil, nil, http.StatusConflict, nil)
+
+	// a reward can't be changed to one that does not exist
+	c.do(http.MethodPut, assignPath, admin.token, nil, map[string]int{"rewardId": reward.Id + 100}, http.StatusUnprocessableEntity, nil)
+
+	// a reward can be given only for a habit the user has
+	c.do(http.MethodPost, fmt.Sprintf("/web/api/admin/users/%d/habits/%d/rewardsUserAdmin/%d", admin.id, habit.Id, reward.Id), admin.token, nil, nil, http.StatusNotFound, nil)
+
+	var rewards []struct {
+		Id    int    `json:"rewardId"`
+		Title string `json:"title"`
+	}
+	c.do(http.MethodGet, fmt.Sprintf("/web/api/habits/%d/rewardsUser/", habit.Id), user.token, nil, nil, http.StatusOK, &rewards)
+	if len(rewards) != 1 || rewards[0].Id != reward.Id || rewards[0].Title != "gold" {
+		t.Fatalf("expected the gold reward of the habit, got %+v", rewards)
+	}
+
+	var page e2ePage
+	c.do(http.MethodGet, "/web/api/rewardsUserAll/", user.token, nil, nil, http.StatusOK, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected a single reward of the user, got %d", page.Total)
+	}
+
+	c.do(http.MethodGet, "/web/api/admin/audit?action=user_reward.assign", admin.token, nil, nil, http.StatusOK, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected a single audit entry of the assignment, got %d", page.Total)
+	}
+
+	var entry struct {
+		ActorId      int    `json:"actorId"`
+		TargetUserId int    `json:"targetUserId"`
+		RequestId    string `json:"requestId"`
+	}
+	if err := json.Unmarshal(page.Data[0], &entry); err != nil {
+		t.Fatalf("failed to decode audit entry: %v", err)
+	}
+	if entry.ActorId != admin.id || entry.TargetUserId != user.id || entry.RequestId != "e2e-assign" {
+		t.Fatalf("unexpected audit entry: %+v", entry)
+	}
+
+	// a telegram user gets a reward the same way
+	const tgUserId = 1001
+
+	var tgUser e2eId
+	c.doBot(http.MethodPost, "/telegram/auth/sign-up", 0, nil, map[string]any{"tg_user_name": "tg_runner", "tg_user_id": tgUserId}, http.StatusOK, &tgUser)
+	c.doBot(http.MethodPost, "/telegram/api/habits/", tgUserId, nil, map[string]string{"title": "swimming"}, http.StatusOK, &habit)
+
+	c.do(http.MethodPost, fmt.Sprintf("/web/api/admin/users/%d/habits/%d/rewardsUserAdmin/%d", tgUser.Id, habit.Id, reward.Id), admin.token, nil, nil, http.StatusOK, nil)
+
+	c.doBot(http.MethodGet, "/telegram/api/rewardsUserAll/", tgUserId, nil, nil, http.StatusOK, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected a single reward of the telegram user, got %d", page.Total)
+	}
+
+	// removing the reward from the list takes it from the users
+	c.do(http.MethodDelete, fmt.Sprintf("/web/api/admin/rewardsAdmin/%d", reward.Id), admin.token, nil, nil, http.StatusOK, nil)
+
+	c.do(http.MethodGet, "/web/api/rewardsUserAll/", user.token, nil, nil, http.StatusOK, &page)
+	if page.Total != 0 {
+		t.Fatalf("expected no rewards of the user after the reward is deleted, got %d", page.Total)
+	}
+}
+
+// runE2ERoles checks that the admin routes are open only to roles with the permissions
+func runE2ERoles(t *testing.T, repos *repository.Repository) {
+	c := newE2EClient(t, repos)
+
+	user := c.signUp("runner")
+
+	var forbidden e2eErrorResponse
+	c.do(http.MethodGet, "/web/api/admin/rewardsAdmin/", user.token, nil, nil, http.StatusForbidden, &forbidden)
+	c.do(http.MethodPost, "/web/api/admin/rewardsAdmin/", user.token, nil, map[string]string{"title": "gold"}, http.StatusForbidden, nil)
+	c.do(http.MethodGet, "/web/api/admin/users/", user.token, nil, nil, http.StatusForbidden, nil)
+	c.do(http.MethodPut, fmt.Sprintf("/web/api/admin/users/%d/roles/", user.id), user.token, nil, map[string]string{"role": models.Administrator}, http.StatusForbidden, nil)
+
+	const tgUserId = 1001
+
+	c.doBot(http.MethodPost, "/telegram/auth/sign-up", 0, nil, map[string]any{"tg_user_name": "tg_runner", "tg_user_id": tgUserId}, http.StatusOK, nil)
+	c.doBot(http.MethodGet, "/telegram/api/admin/rewardsAdmin/", tgUserId, nil, nil, http.StatusForbidden, nil)
+	c.doBot(http.MethodGet, "/telegram/api/admin/roles/", tgUserId, nil, nil, http.StatusForbidden, nil)
+
+	// an admin gives the role of a moderator, it manages rewards but not roles
+	admin := c.signUp("boss")
+	setRole(t, repos, admin.id, models.Administrator)
+	admin.token = c.signIn("boss")
+
+	c.do(http.MethodPut, fmt.Sprintf("/web/api/admin/users/%d/roles/", user.id), admin.token, nil, map[string]string{"role": "moderator"}, http.StatusOK, nil)
+	c.do(http.MethodPut, fmt.Sprintf("/web/api/admin/users/%d/roles/", user.id), admin.token, nil, map[string]string{"role": "unknown"}, http.StatusNotFound, nil)
+
+	moderatorToken := c.signIn("runner")
+	c.do(http.MethodGet, "/web/api/admin/rewardsAdmin/", moderatorToken, nil, nil, http.StatusOK, nil)
+	c.do(http.MethodGet, "/web/api/admin/users/", moderatorToken, nil, nil, http.StatusOK, nil)
+	c.do(http.MethodGet, "/web/api/admin/roles/", moderatorToken, nil, nil, http.StatusForbidden, nil)
+	c.do(http.MethodGet, "/web/api/admin/audit", moderatorToken, nil, nil, http.StatusForbidden, nil)
+
+	var users e2ePage
+	c.do(http.MethodGet, "/web/api/admin/users/?role=moderator", admin.token, nil, nil, http.StatusOK, &users)
+	if users.Total != 1 {
+		t.Fatalf("expected a single moderator, got %d", users.Total)
+	}
+
+	// the role of a telegram user is read on every request, no new token is needed
+	tgAccount, err := repos.User.GetUserByTgUserId(context.Background(), tgUserId)
+	if err != nil {
+		t.Fatalf("failed to find the telegram user: %v", err)
+	}
+
+	c.do(http.MethodPut, fmt.Sprintf("/web/api/admin/users/%d/roles/", tgAccount.Id), admin.token, nil, map[string]string{"role": "moderator"}, http.StatusOK, nil)
+	c.doBot(http.MethodGet, "/telegram/api/admin/rewardsAdmin/", tgUserId, nil, nil, http.StatusOK, nil)
+	c.doBot(http.MethodGet, "/telegram/api/admin/roles/", tgUserId, nil, nil, http.StatusForbidden, nil)
+}
+
+// runE2ESearch finds habits and rewards of a user, an admin searches them across all users
+func runE2ESearch(t *testing.T, repos *repository.Repository) {
+	c := newE2EClient(t, repos)
+
+	type searchResult struct {
+		Habits []struct {
+			Id     int    `json:"id"`
+			UserId int    `json:"userId"`
+			Title  string `json:"title"`
+		} `json:"habits"`
+		Rewards []struct {
+			Id    int    `json:"id"`
+			Title string `json:"title"`
+		} `json:"rewards"`
+	}
+
+	user := c.signUp("runner")
+	other := c.signUp("walker")
+
+	var running struct {
+		Id int `json:"habitId"`
+	}
+	c.do(http.MethodPost, "/web/api/habits/", user.token, nil, map[string]string{"title": "running", "description": "every morning"}, http.StatusOK, &running)
+	c.do(http.MethodPost, "/web/api/habits/", user.token, nil, map[string]string{"title": "reading", "description": "before running to bed"}, http.StatusOK, nil)
+	c.do(http.MethodPost, "/web/api/habits/", user.token, nil, map[string]string{"title": "swimming"}, http.StatusOK, nil)
+	c.do(http.MethodPost, "/web/api/habits/", other.token, nil, map[string]string{"title": "running club"}, http.StatusOK, nil)
+
+	// a match in the title ranks above a match in the description, habits of other users are not found
+	var result searchResult
+	c.do(http.MethodGet, "/web/api/search?q=RUNNING", user.token, nil, nil, http.StatusOK, &result)
+	if len(result.Habits) != 2 || result.Habits[0].Title != "running" || result.Habits[1].Title != "reading" {
+		t.Fatalf("expected the running and the reading habits of the user, got %+v", result.Habits)
+	}
+
+	// every word has to match
+	c.do(http.MethodGet, "/web/api/search?q=running+morning", user.token, nil, nil, http.StatusOK, &result)
+	if len(result.Habits) != 1 || result.Habits[0].Title != "running" {
+		t.Fatalf("expected the running habit, got %+v", result.Habits)
+	}
+
+	c.do(http.MethodGet, "/web/api/search?q=running&limit=1&offset=1", user.token, nil, nil, http.StatusOK, &result)
+	if len(result.Habits) != 1 || result.Habits[0].Title != "reading" {
+		t.Fatalf("expected the second hit on the second page, got %+v", result.Habits)
+	}
+
+	c.do(http.MethodGet, "/web/api/search?q=running&offset=5", user.token, nil, nil, http.StatusOK, &result)
+	if len(result.Habits) != 0 || result.Rewards == nil {
+		t.Fatalf("expected empty lists past the last hit, got %+v", result)
+	}
+
+	c.do(http.MethodGet, "/web/api/search?q=", user.token, nil, nil, http.StatusBadRequest, nil)
+	c.do(http.MethodGet, "/web/api/search?q=running&limit=101", user.token, nil, nil, http.StatusBadRequest, nil)
+	c.do(http.MethodGet, "/web/api/search?q=running&offset=-1", user.token, nil, nil, http.StatusBadRequest, nil)
+
+	// a reward is found once it is given to the user
+	admin := c.signUp("boss")
+	setRole(t, repos, admin.id, models.Administrator)
+	admin.token = c.signIn("boss")
+
+	var reward e2eId
+	c.do(http.MethodPost, "/web/api/admin/rewardsAdmin/", admin.token, nil, map[string]string{"title": "running medal"}, http.StatusOK, &reward)
+
+	c.do(http.MethodGet, "/web/api/search?q=medal", user.token, nil, nil, http.StatusOK, &result)
+	if len(result.Rewards) != 0 {
+		t.Fatalf("expected no rewards before the reward is given, got %+v", result.Rewards)
+	}
+
+	c.do(http.MethodPost, fmt.Sprintf("/web/api/admin/users/%d/habits/%d/rewardsUserAdmin/%d", user.id, running.Id, reward.Id), admin.token, nil, nil, http.StatusOK, nil)
+
+	c.do(http.MethodGet, "/web/api/search?q=medal", user.token, nil, nil, http.StatusOK, &result)
+	if len(result.Rewards) != 1 || result.Rewards[0].Id != reward.Id {
+		t.Fatalf("expected the given reward, got %+v", result.Rewards)
+	}
+
+	// the search across all users needs the search:all permission
+	c.do(http.MethodGet, "/web/api/admin/search?q=running", user.token, nil, nil, http.StatusForbidden, nil)
+
+	c.do(http.MethodGet, "/web/api/admin/search?q=club", admin.token, nil, nil, http.StatusOK, &result)
+	if len(result.Habits) != 1 || result.Habits[0].UserId != other.id {
+		t.Fatalf("expected the habit of the other user with its owner, got %+v", result.Habits)
+	}
+
+	c.do(http.MethodGet, "/web/api/admin/search?q=running", admin.token, nil, nil, http.StatusOK, &result)
+	if len(result.Habits) != 3 || len(result.Rewards) != 1 {
+		t.Fatalf("expected the habits of both users and the reward, got %+v", result)
+	}
+}
+
+// runE2EUnverifiedEmail checks what a web user can do before the email is confirmed
+func runE2EUnverifiedEmail(t *testing.T, repos *repository.Repository) {
+	c := newE2EClient(t, repos)
+
+	email := "runner@habit-tracker.test"
+	user := map[string]string{"userName": "runner", "eMail": email, "password": "password1"}
+	c.do(http.MethodPost, "/web/auth/sign-up", "", nil, user, http.StatusOK, nil)
+
+	token := c.signIn("runner")
+
+	// data is read, but not changed, in habits and outside them
+	c.do(http.MethodGet, "/web/api/habits/", token, nil, nil, http.StatusOK, nil)
+	c.do(http.MethodPost, "/web/api/habits/", token, nil, map[string]string{"title": "running"}, http.StatusForbidden, nil)
+
+	personalToken := map[string]any{"name": "cli", "scopes": []string{models.ScopeRead}}
+	c.do(http.MethodPost, "/web/api/account/tokens/", token, nil, personalToken, http.StatusForbidden, nil)
+	c.do(http.MethodPost, "/web/api/account/telegram/link-code", token, nil, nil, http.StatusForbidden, nil)
+
+	c.do(http.MethodPost, "/web/auth/verify", "", nil, map[string]string{"token": c.mail.token(t, email)}, http.StatusOK, nil)
+
+	c.do(http.MethodPost, "/web/api/account/tokens/", token, nil, personalToken, http.StatusOK, nil)
+
+	// the account can be deleted without a confirmed email
+	other := map[string]string{"userName": "reader", "eMail": "reader@habit-tracker.test", "password": "password1"}
+	c.do(http.MethodPost, "/web/auth/sign-up", "", nil, other, http.StatusOK, nil)
+	c.do(http.MethodDelete, "/web/api/account/", c.signIn("reader"), nil, nil, http.StatusOK, nil)
+}
+
+/*
+runE2EPersonalTokens checks that a personal access token of an admin
+does not open the admin routes and stops working after "log out everywhere"
+*/
+func runE2EPersonalTokens(t *testing.T, repos *repository.Repository) {
+	c := newE2EClient(t, repos)
+
+	admin := c.signUp("boss")
+	setRole(t, repos, admin.id, models.Administrator)
+
+	var personalToken models.NewPersonalToken
+	input := map[string]any{"name": "cli", "scopes": []string{models.ScopeRead, models.ScopeWrite}}
+	c.do(http.MethodPost, "/web/api/account/tokens/", admin.token, nil, input, http.StatusOK, &personalToken)
+
+	c.do(http.MethodPost, "/web/api/habits/", personalToken.Token, nil, map[string]string{"title": "running"}, http.StatusOK, nil)
+
+	c.do(http.MethodGet, "/web/api/admin/rewardsAdmin/", admin.token, nil, nil, http.StatusOK, nil)
+	c.do(http.MethodGet, "/web/api/admin/rewardsAdmin/", personalToken.Token, nil, nil, http.StatusForbidden, nil)
+	c.do(http.MethodGet, "/web/api/admin/users/", personalToken.Token, nil, nil, http.StatusForbidden, nil)
+
+	c.do(http.MethodPost, "/web/auth/logout-all", admin.token, nil, nil, http.StatusOK, nil)
+
+	c.do(http.MethodGet, "/web/api/habits/", personalToken.Token, nil, nil, http.StatusUnauthorized, nil)
+}
+
+/*
+runE2EAccount changes and resets a password and restores a deleted account.
+Every flow has its own user, so a flow starts with sessions no other
+flow has revoked
+*/
+func runE2EAccount(t *testing.T, repos *repository.Repository) {
+	c := newE2EClient(t, repos)
+
+	signIn := func(userName, password string, expectedStatusCode int) models.Tokens {
+		t.Helper()
+
+		var tokens models.Tokens
+		c.do(http.MethodPost, "/web/auth/sign-in", "", nil, map[string]any{"userName": userName, "password": password}, expectedStatusCode, &tokens)
+
+		return tokens
+	}
+
+	// a new password ends the sessions made with the old one
+	runner := c.signUp("runner")
+	tokens := signIn("runner", "password1", http.StatusOK)
+
+	c.do(http.MethodPut, "/web/api/account/password", runner.token, nil, map[string]string{"oldPassword": "password1", "newPassword": "password2"}, http.StatusOK, nil)
+	c.do(http.MethodGet, "/web/api/habits/", runner.token, nil, nil, http.StatusUnauthorized, nil)
+	c.do(http.MethodPost, "/web/auth/refresh", "", nil, map[string]string{"refreshToken": tokens.RefreshToken}, http.StatusUnauthorized, nil)
+	signIn("runner", "password1", http.StatusUnauthorized)
+
+	// a session started right after the change is not revoked with the old ones
+	tokens = signIn("runner", "password2", http.StatusOK)
+	c.do(http.MethodGet, "/web/api/habits/", tokens.AccessToken, nil, nil, http.StatusOK, nil)
+
+	// a forgotten password is reset with the token sent by email, once
+	reader := c.signUp("reader")
+	email := "reader@habit-tracker.test"
+
+	c.do(http.MethodPost, "/web/auth/forgot", "", nil, map[string]string{"eMail": email}, http.StatusOK, nil)
+	resetToken := c.mail.token(t, email)
+
+	c.do(http.MethodPost, "/web/auth/reset", "", nil, map[string]string{"token": resetToken, "newPassword": "password3"}, http.StatusOK, nil)
+	c.do(http.MethodPost, "/web/auth/reset", "", nil, map[string]string{"token": resetToken, "newPassword": "password4"}, http.StatusBadRequest, nil)
+	c.do(http.MethodGet, "/web/api/habits/", reader.token, nil, nil, http.StatusUnauthorized, nil)
+	signIn("reader", "password1", http.StatusUnauthorized)
+	tokens = signIn("reader", "password3", http.StatusOK)
+	c.do(http.MethodGet, "/web/api/habits/", tokens.AccessToken, nil, nil, http.StatusOK, nil)
+
+	// a deleted account is restored by a sign in with reactivate in the grace period
+	walker := c.signUp("walker")
+
+	c.do(http.MethodDelete, "/web/api/account/", walker.token, nil, nil, http.StatusOK, nil)
+	c.do(http.MethodGet, "/web/api/habits/", walker.token, nil, nil, http.StatusUnauthorized, nil)
+	signIn("walker", "password1", http.StatusForbidden)
+	c.do(http.MethodPost, "/web/auth/sign-in", "", nil, map[string]any{"userName": "walker", "password": "password1", "reactivate": true}, http.StatusOK, nil)
+	signIn("walker", "password1", http.StatusOK)
+}
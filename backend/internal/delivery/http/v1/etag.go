@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	eTagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// setETag tells the client the version of a habit or a tracker it reads or updates
+func setETag(c *gin.Context, version int) {
+	c.Header(eTagHeader, strconv.Quote(strconv.Itoa(version)))
+}
+
+/*
+getIfMatch returns the versions listed in the If-Match header. It returns
+nil if the header is not sent or is "*", then an update is made on any
+version. The comparison is strong (RFC 9110), weak and unknown tags match
+no version, so a header with only such tags gives an empty list and the
+update is rejected
+*/
+func getIfMatch(c *gin.Context) ([]int, error) {
+	const op = "delivery.http.v1.etag.getIfMatch"
+
+	header := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	versions := []int{}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		weak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' || strings.Contains(tag[1:len(tag)-1], `"`) {
+			return nil, fmt.Errorf("%s: entity tag must be quoted: %s", op, tag)
+		}
+
+		if weak {
+			continue
+		}
+
+		version, err := strconv.Atoi(tag[1 : len(tag)-1])
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
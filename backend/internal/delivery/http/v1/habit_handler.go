@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/exp/slog"
@@ -16,25 +18,31 @@ func (h *Handler) createHabit(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
 	var input models.Habit
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
-	habitId, err := h.services.Habit.Create(userId, input)
+	var habitId int
+
+	if meta, metaErr := getAuditMeta(c); metaErr == nil {
+		habitId, err = h.services.AdminUser.CreateHabit(c.Request.Context(), meta, userId, input)
+	} else {
+		habitId, err = h.services.Habit.Create(c.Request.Context(), userId, input)
+	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to create a habit: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to create a habit", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to create a habit: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to create a habit", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(
+	h.logger(c).Info(
 		fmt.Sprintf("%s: habit created:", op),
 		slog.Int("habitId", habitId),
 		slog.String("input", input.Title),
@@ -47,30 +55,31 @@ func (h *Handler) createHabit(c *gin.Context) {
 	})
 }
 
-type getAllHabitsResponse struct {
-	Data []models.Habit `json:"data"`
-}
-
 func (h *Handler) getAllHabits(c *gin.Context) {
 	const op = "delivery.http.v1.habit_handler.getAllHabits"
 
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
-	habits, err := h.services.Habit.GetAll(userId)
+	params, err := getListParams(c, models.HabitListFields)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get habits: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habits", op), sl.Err(err))
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: invalid list params: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid list params", op), sl.Err(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, getAllHabitsResponse{
-		Data: habits,
-	})
+	habits, total, err := h.services.Habit.GetAll(c.Request.Context(), userId, params)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get habits: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habits", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewPage(habits, total, params))
 }
 
 func (h *Handler) getHabitById(c *gin.Context) {
@@ -79,24 +88,26 @@ func (h *Handler) getHabitById(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
-	habit, err := h.services.Habit.GetById(userId, habitId)
+	habit, err := h.services.Habit.GetById(c.Request.Context(), userId, habitId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: habit not found: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to find a habit by Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: habit not found: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to find a habit by Id", op), sl.Err(err))
 		return
 	}
 
+	setETag(c, habit.Version)
+
 	c.JSON(http.StatusOK, habit)
 }
 
@@ -106,25 +117,29 @@ func (h *Handler) deleteHabit(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
-	err = h.services.Habit.Delete(userId, habitId)
+	if meta, metaErr := getAuditMeta(c); metaErr == nil {
+		err = h.services.AdminUser.DeleteHabit(c.Request.Context(), meta, userId, habitId)
+	} else {
+		err = h.services.Habit.Delete(c.Request.Context(), userId, habitId)
+	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to delete a habit %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to delete a habit", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to delete a habit %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to delete a habit", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(fmt.Sprintf("%s: a habit is deleted", op), slog.Int("id", habitId))
+	h.logger(c).Info(fmt.Sprintf("%s: a habit is deleted", op), slog.Int("id", habitId))
 
 	c.JSON(http.StatusOK, statusResponse{
 		Status: "ok",
@@ -137,14 +152,14 @@ func (h *Handler) updateHabit(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
@@ -152,17 +167,38 @@ func (h *Handler) updateHabit(c *gin.Context) {
 
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
-	if err := h.services.Habit.Update(userId, habitId, input); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to update a habit %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to update a habit", op), sl.Err(err))
+	input.Versions, err = getIfMatch(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid If-Match header")
+		h.logger(c).Error(fmt.Sprintf("%s: invalid If-Match header", op), sl.Err(err))
+		return
+	}
+
+	var version int
+
+	if meta, metaErr := getAuditMeta(c); metaErr == nil {
+		version, err = h.services.AdminUser.UpdateHabit(c.Request.Context(), meta, userId, habitId, input)
+	} else {
+		version, err = h.services.Habit.Update(c.Request.Context(), userId, habitId, input)
+	}
+	if errors.Is(err, service.ErrVersionMismatch) {
+		newErrorResponse(c, http.StatusPreconditionFailed, service.ErrVersionMismatch.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: habit version does not match", op), sl.Err(err))
 		return
 	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to update a habit %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to update a habit", op), sl.Err(err))
+		return
+	}
+
+	h.logger(c).Info(fmt.Sprintf("%s: a habit has been updated", op), slog.Int("id", habitId))
 
-	h.log.Info(fmt.Sprintf("%s: a habit has been updated", op), slog.Int("id", habitId))
+	setETag(c, version)
 
 	c.JSON(http.StatusOK, statusResponse{"ok"})
 }
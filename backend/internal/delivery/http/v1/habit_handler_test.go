@@ -0,0 +1,297 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_handler_getAllHabits(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockHabit)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?limit=1&sort=-title&title=run",
+			mockBehavior: func(s *mock_service.MockHabit) {
+				params := models.ListParams{
+					Limit: 1,
+					Sort:  "title",
+					Desc:  true,
+					Filters: []models.Filter{
+						{Field: "title", Kind: models.FilterContains, Value: "run"},
+					},
+				}
+				habits := []models.Habit{{Id: 3, Title: "running", Version: 2}}
+				s.EXPECT().GetAll(gomock.Any(), 1, params).Return(habits, 2, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"data":[{"habitId":3,"title":"running","description":"","version":2}],"next_cursor":"1","total":2}`,
+		},
+		{
+			name:  "Last page",
+			query: "?cursor=1",
+			mockBehavior: func(s *mock_service.MockHabit) {
+				params := models.ListParams{Limit: models.DefaultListLimit, Offset: 1}
+				s.EXPECT().GetAll(gomock.Any(), 1, params).Return(nil, 1, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"data":[],"next_cursor":"","total":1}`,
+		},
+		{
+			name:                 "Invalid limit",
+			query:                "?limit=1000",
+			mockBehavior:         func(s *mock_service.MockHabit) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid list params: delivery.http.v1.middleware.getListParams: invalid limit: must be a number between 1 and 100"}`,
+		},
+		{
+			name:                 "Invalid sort field",
+			query:                "?sort=password",
+			mockBehavior:         func(s *mock_service.MockHabit) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid list params: delivery.http.v1.middleware.getListParams: invalid sort field: password"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			habit := mock_service.NewMockHabit(c)
+			testCase.mockBehavior(habit)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Habit: habit}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/habits", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getAllHabits)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/habits"+testCase.query, nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handler_getHabitById(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	habit := mock_service.NewMockHabit(c)
+	habit.EXPECT().GetById(gomock.Any(), 1, 3).Return(models.Habit{Id: 3, Title: "running", Version: 4}, nil)
+
+	log := slogdiscard.NewDiscardLogger()
+
+	services := &service.Service{Habit: habit}
+	handler := NewHandler(log, services)
+
+	r := gin.New()
+	r.GET("/habits/:habitId", func(c *gin.Context) {
+		c.Set(userCtx, 1)
+	}, handler.getHabitById)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/habits/3", nil)
+
+	r.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("Expected status code: %d but got: %d", 200, w.Code)
+	}
+
+	if w.Header().Get("ETag") != `"4"` {
+		t.Errorf("Expected ETag '%s' but got '%s'", `"4"`, w.Header().Get("ETag"))
+	}
+}
+
+func Test_handler_updateHabit(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockHabit)
+
+	title := "running"
+
+	testTable := []struct {
+		name                 string
+		habitId              string
+		ifMatch              string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedETag         string
+	}{
+		{
+			name:    "OK",
+			ifMatch: `"4"`,
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().Update(gomock.Any(), 1, 3, models.UpdateHabitInput{Title: &title, Versions: []int{4}}).Return(5, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+			expectedETag:         `"5"`,
+		},
+		{
+			name: "Without If-Match",
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().Update(gomock.Any(), 1, 3, models.UpdateHabitInput{Title: &title}).Return(5, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+			expectedETag:         `"5"`,
+		},
+		{
+			name:    "Changed By Another Request",
+			ifMatch: `"4"`,
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().Update(gomock.Any(), 1, 3, models.UpdateHabitInput{Title: &title, Versions: []int{4}}).
+					Return(0, fmt.Errorf("service.habit_service.Update: %w", service.ErrVersionMismatch))
+			},
+			expectedStatusCode:   412,
+			expectedResponseBody: `{"message":"version does not match: the resource has been changed, read it again"}`,
+		},
+		{
+			name:    "List",
+			ifMatch: `"3", W/"5", "4"`,
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().Update(gomock.Any(), 1, 3, models.UpdateHabitInput{Title: &title, Versions: []int{3, 4}}).Return(5, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+			expectedETag:         `"5"`,
+		},
+		{
+			name:    "Weak Tag",
+			ifMatch: `W/"4"`,
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().Update(gomock.Any(), 1, 3, models.UpdateHabitInput{Title: &title, Versions: []int{}}).
+					Return(0, fmt.Errorf("service.habit_service.Update: %w", service.ErrVersionMismatch))
+			},
+			expectedStatusCode:   412,
+			expectedResponseBody: `{"message":"version does not match: the resource has been changed, read it again"}`,
+		},
+		{
+			name:    "Any Version",
+			ifMatch: `*`,
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().Update(gomock.Any(), 1, 3, models.UpdateHabitInput{Title: &title}).Return(5, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+			expectedETag:         `"5"`,
+		},
+		{
+			name:                 "Invalid Id",
+			habitId:              "three",
+			mockBehavior:         func(s *mock_service.MockHabit) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid habit id param: validation failed: strconv.Atoi: parsing \"three\": invalid syntax","code":"invalid_id"}`,
+		},
+		{
+			name:                 "Invalid If-Match",
+			ifMatch:              `W/4`,
+			mockBehavior:         func(s *mock_service.MockHabit) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"invalid If-Match header"}`,
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().Update(gomock.Any(), 1, 3, models.UpdateHabitInput{Title: &title}).
+					Return(0, fmt.Errorf("service.habit_service.Update: %w", errs.NotFound("habit_not_found", errors.New("no rows in result set"))))
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"message":"error: failed to update a habit service.habit_service.Update: not found: no rows in result set","code":"habit_not_found"}`,
+		},
+		{
+			name: "Invalid Input",
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().Update(gomock.Any(), 1, 3, models.UpdateHabitInput{Title: &title}).
+					Return(0, fmt.Errorf("service.habit_service.Update: %w", errs.Validation("invalid_habit", errors.New("habit update structure has no values"))))
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"message":"error: failed to update a habit service.habit_service.Update: validation failed: habit update structure has no values","code":"invalid_habit"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			habit := mock_service.NewMockHabit(c)
+			testCase.mockBehavior(habit)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Habit: habit}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.PUT("/habits/:habitId", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.updateHabit)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			habitId := testCase.habitId
+			if habitId == "" {
+				habitId = "3"
+			}
+
+			req := httptest.NewRequest("PUT", "/habits/"+habitId, bytes.NewBufferString(`{"title":"running"}`))
+			if testCase.ifMatch != "" {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+
+			if w.Header().Get("ETag") != testCase.expectedETag {
+				t.Errorf("Expected ETag '%s' but got '%s'", testCase.expectedETag, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/exp/slog"
@@ -16,18 +18,25 @@ func (h *Handler) getAllHabitTrackers(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
-	trackers, err := h.services.HabitTracker.GetAll(userId)
+	params, err := getListParams(c, models.TrackerListFields)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get all habit trackers: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get all habit trackers", op), sl.Err(err))
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: invalid list params: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid list params", op), sl.Err(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, trackers)
+	trackers, total, err := h.services.HabitTracker.GetAll(c.Request.Context(), userId, params)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get all habit trackers: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get all habit trackers", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewPage(trackers, total, params))
 }
 
 func (h *Handler) getHabitTrackerById(c *gin.Context) {
@@ -36,66 +45,89 @@ func (h *Handler) getHabitTrackerById(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
-	tracker, err := h.services.HabitTracker.GetById(userId, habitId)
+	tracker, err := h.services.HabitTracker.GetById(c.Request.Context(), userId, habitId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: habit tracker not found: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to find habit tracker by Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: habit tracker not found: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to find habit tracker by Id", op), sl.Err(err))
 		return
 	}
 
+	setETag(c, tracker.Version)
+
 	c.JSON(http.StatusOK, tracker)
 }
 
 func (h *Handler) updateHabitTracker(c *gin.Context) {
 	const op = "delivery.http.v1.habit_tracker_handler.updateHabitTracker"
 
-	h.log.Info(
+	h.logger(c).Info(
 		fmt.Sprintf("%s: tracker update method was called", op),
 	)
 
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
 	var input models.UpdateTrackerInput
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
 		return
 	}
 
-	if err := h.services.HabitTracker.Update(userId, habitId, input); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to update a habit tracker %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to update a habit tracker", op), sl.Err(err))
+	input.Versions, err = getIfMatch(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid If-Match header")
+		h.logger(c).Error(fmt.Sprintf("%s: invalid If-Match header", op), sl.Err(err))
 		return
 	}
 
-	h.log.Info(
+	var version int
+
+	if meta, metaErr := getAuditMeta(c); metaErr == nil {
+		version, err = h.services.AdminUser.UpdateTracker(c.Request.Context(), meta, userId, habitId, input)
+	} else {
+		version, err = h.services.HabitTracker.Update(c.Request.Context(), userId, habitId, input)
+	}
+	if errors.Is(err, service.ErrVersionMismatch) {
+		newErrorResponse(c, http.StatusPreconditionFailed, service.ErrVersionMismatch.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: habit tracker version does not match", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to update a habit tracker %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to update a habit tracker", op), sl.Err(err))
+		return
+	}
+
+	h.logger(c).Info(
 		fmt.Sprintf("%s: a habit tracker has been updated", op),
 		slog.Int("habit id", habitId),
 	)
 
+	setETag(c, version)
+
 	c.JSON(http.StatusOK, statusResponse{"ok"})
 
 	// c.JSON(http.StatusOK, map[string]interface{}{
@@ -131,7 +163,7 @@ This method is not deleted because it might work for some future functionality
 // 		return
 // 	}
 
-// 	id, err := h.services.HabitTracker.Create(userHabitId, input)
+// 	id, err := h.services.HabitTracker.Create(c.Request.Context(), userHabitId, input)
 // 	if err != nil {
 // 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 // 		return
@@ -162,7 +194,7 @@ method Delete is commented out for the same reasons as method Create
 // 		return
 // 	}
 
-// 	err = h.services.HabitTracker.Delete(userId, habitId)
+// 	err = h.services.HabitTracker.Delete(c.Request.Context(), userId, habitId)
 // 	if err != nil {
 // 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error from handler: deleteHabitTracker: %v", err.Error()))
 // 		return
@@ -1,7 +1,12 @@
 package v1
 
 import (
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/metrics"
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/exp/slog"
 )
@@ -18,27 +23,60 @@ func NewHandler(log *slog.Logger, services *service.Service) *Handler {
 	}
 }
 
-func (h *Handler) InitRoutes() *gin.Engine {
+/*
+InitRoutes registers the routes of the api. Middlewares are run before
+every route, like the OpenAPI validator in dev and test environments.
+The duration of every request is observed and served at /metrics,
+and every request gets a span.
+The client ip is taken from X-Forwarded-For only behind trustedProxies
+*/
+func (h *Handler) InitRoutes(trustedProxies []string, middlewares ...gin.HandlerFunc) (*gin.Engine, error) {
+	const op = "delivery.http.v1.handler.InitRoutes"
+
 	router := gin.New()
 
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("%s: invalid trusted proxies: %w", op, err)
+	}
+
+	router.Use(metrics.HTTP(), tracing.Gin(), h.requestId)
+	router.Use(middlewares...)
+
+	router.GET("/metrics", metrics.Handler())
+	router.GET("/openapi.json", h.getOpenAPISpec)
+	router.GET("/swagger/*any", h.swaggerUI)
+
 	routerWeb := router.Group("/web")
 	routerTelegram := router.Group("/telegram")
 
-	authWeb := routerWeb.Group("/auth")
+	authWeb := routerWeb.Group("/auth", h.rateLimit)
 	{
 		authWeb.POST("/sign-up", h.signUpWeb)
 		authWeb.POST("/sign-in", h.signInWeb)
+		authWeb.POST("/refresh", h.refreshWeb)
+		authWeb.POST("/logout", h.webUserIdentity, h.sessionOnly, h.logoutWeb)
+		authWeb.POST("/logout-all", h.webUserIdentity, h.sessionOnly, h.logoutAllWeb)
+		authWeb.POST("/verify", h.verifyEmail)
+		authWeb.POST("/forgot", h.forgotPassword)
+		authWeb.POST("/reset", h.resetPassword)
+
+		oidc := authWeb.Group("/oidc/:provider")
+		{
+			oidc.GET("/login", h.oidcLogin)
+			oidc.GET("/callback", h.oidcCallback)
+		}
 	}
 
-	authTelegram := routerTelegram.Group("/auth")
+	authTelegram := routerTelegram.Group("/auth", h.serviceIdentity)
 	{
 		authTelegram.POST("/sign-up", h.signUpTelegram)
+		authTelegram.POST("/link", h.linkTelegram)
 		// authTelegram.POST("/tg/:tgUser", h.tgUser)
 		// authTelegram.GET("/exist", h.tgUserIdentity)
 		// authTelegram.POST("/sign-in", h.signInTelegram)
 	}
 
-	api := router.Group("/:client/api", h.userIdentity)
+	api := router.Group("/:client/api", h.addressRateLimit, h.userIdentity, h.rateLimit, h.verifiedEmail, h.idempotency)
 	{
 		habits := api.Group("/habits")
 		{
@@ -70,39 +108,63 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			rewardsUserAll.GET("/", h.getAllPersonalRewards)
 		}
 
-		userAccount := api.Group("/account")
+		userAccount := api.Group("/account", h.sessionOnly)
 		{
 			userAccount.DELETE("/", h.deleteUser)
+			userAccount.PUT("/password", h.changePassword)
+			userAccount.POST("/telegram/link-code", h.createLinkCode)
+
+			tokens := userAccount.Group("/tokens")
+			{
+				tokens.POST("/", h.createPersonalToken)
+				tokens.GET("/", h.getPersonalTokens)
+				tokens.DELETE("/:tokenId", h.revokePersonalToken)
+			}
 		}
 
-		admin := api.Group("/admin", h.adminPass)
+		api.GET("/search", h.search)
+
+		admin := api.Group("/admin", h.sessionOnly, h.adminActor)
 		{
+			var (
+				usersRead     = h.requirePermission(models.PermUsersRead)
+				usersWrite    = h.requirePermission(models.PermUsersWrite)
+				habitsRead    = h.requirePermission(models.PermHabitsRead)
+				habitsWrite   = h.requirePermission(models.PermHabitsWrite)
+				rewardsRead   = h.requirePermission(models.PermRewardsRead)
+				rewardsWrite  = h.requirePermission(models.PermRewardsWrite)
+				rewardsAssign = h.requirePermission(models.PermRewardsAssign)
+				rolesRead     = h.requirePermission(models.PermRolesRead)
+				rolesWrite    = h.requirePermission(models.PermRolesWrite)
+				rolesAssign   = h.requirePermission(models.PermRolesAssign)
+			)
+
 			users := admin.Group("/users")
 			{
-				users.GET("/", h.getAllUsers)
+				users.GET("/", usersRead, h.getAllUsers)
 
-				userApi := users.Group("/:userId", h.adminUserPass)
+				userApi := users.Group("/:userId", h.managedUser)
 				{
 					habits := userApi.Group("/habits")
 					{
-						habits.POST("/", h.createHabit)
-						habits.GET("/", h.getAllHabits)
-						habits.GET("/:habitIdAdmin", h.getHabitById)
-						habits.PUT("/:habitIdAdmin", h.updateHabit)
-						habits.DELETE("/:habitIdAdmin", h.deleteHabit)
+						habits.POST("/", habitsWrite, h.createHabit)
+						habits.GET("/", habitsRead, h.getAllHabits)
+						habits.GET("/:habitIdAdmin", habitsRead, h.getHabitById)
+						habits.PUT("/:habitIdAdmin", habitsWrite, h.updateHabit)
+						habits.DELETE("/:habitIdAdmin", habitsWrite, h.deleteHabit)
 
 						tracker := habits.Group(":habitIdAdmin/tracker")
 						{
-							tracker.GET("/", h.getHabitTrackerById)
-							tracker.PUT("/", h.updateHabitTracker)
+							tracker.GET("/", habitsRead, h.getHabitTrackerById)
+							tracker.PUT("/", habitsWrite, h.updateHabitTracker)
 						}
 
 						rewardsUser := habits.Group(":habitIdAdmin/rewardsUser")
 						{
-							rewardsUser.GET("/", h.getPersonalRewardsByHabitId)
+							rewardsUser.GET("/", habitsRead, h.getPersonalRewardsByHabitId)
 						}
 
-						rewardsUserAdmin := habits.Group(":habitIdAdmin/rewardsUserAdmin")
+						rewardsUserAdmin := habits.Group(":habitIdAdmin/rewardsUserAdmin", rewardsAssign)
 						{
 							rewardsUserAdmin.POST("/:rewardIdAdmin", h.assignReward)
 							rewardsUserAdmin.PUT("/:rewardIdAdmin", h.updateUserReward)
@@ -113,41 +175,54 @@ func (h *Handler) InitRoutes() *gin.Engine {
 
 					trackers := userApi.Group("/trackers")
 					{
-						trackers.GET("/", h.getAllHabitTrackers)
+						trackers.GET("/", habitsRead, h.getAllHabitTrackers)
 					}
 
 					rewardsUserAll := userApi.Group("/rewardsUserAll")
 					{
-						rewardsUserAll.GET("/", h.getAllPersonalRewards)
+						rewardsUserAll.GET("/", habitsRead, h.getAllPersonalRewards)
 					}
 
 					roles := userApi.Group("/roles")
 					{
-						roles.PUT("/", h.assignRole)
+						roles.PUT("/", rolesAssign, h.assignRole)
 					}
 
 					userAccount := userApi.Group("/account")
 					{
-						userAccount.GET("/", h.getUserById)
-						userAccount.DELETE("/", h.deleteUser)
-
+						userAccount.GET("/", usersRead, h.getUserById)
+						userAccount.DELETE("/", usersWrite, h.deleteUser)
+						userAccount.PUT("/restore", usersWrite, h.restoreUser)
 					}
 
 				}
 
 			}
 
+			admin.GET("/search", h.requirePermission(models.PermSearchAll), h.searchAll)
+			admin.GET("/audit", h.requirePermission(models.PermAuditRead), h.getAuditLog)
+
 			rewardsAdmin := admin.Group("/rewardsAdmin")
 			{
-				rewardsAdmin.POST("/", h.createReward)
-				rewardsAdmin.GET("/", h.getAllRewards)
-				rewardsAdmin.GET("/:rewardId", h.getRewardById)
-				rewardsAdmin.PUT("/:rewardId", h.updateReward)
-				rewardsAdmin.DELETE("/:rewardId", h.deleteReward)
+				rewardsAdmin.POST("/", rewardsWrite, h.createReward)
+				rewardsAdmin.GET("/", rewardsRead, h.getAllRewards)
+				rewardsAdmin.GET("/:rewardId", rewardsRead, h.getRewardById)
+				rewardsAdmin.PUT("/:rewardId", rewardsWrite, h.updateReward)
+				rewardsAdmin.DELETE("/:rewardId", rewardsWrite, h.deleteReward)
 			}
+
+			roles := admin.Group("/roles")
+			{
+				roles.GET("/", rolesRead, h.getAllRoles)
+				roles.POST("/", rolesWrite, h.createRole)
+				roles.PUT("/:roleName", rolesWrite, h.updateRole)
+				roles.DELETE("/:roleName", rolesWrite, h.deleteRole)
+			}
+
+			admin.GET("/permissions", rolesRead, h.getAllPermissions)
 		}
 
 	}
 
-	return router
+	return router, nil
 }
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// idempotentReplayedHeader marks a response replayed from a previous request
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyStoreTimeout bounds saving the result of a request under its key
+const idempotencyStoreTimeout = 2 * time.Second
+
+// maxIdempotentBodySize limits bodies read to be stored with a key, habits and rewards are small json
+const maxIdempotentBodySize = 1 << 20
+
+// replayedHeaders are the headers of a response stored to replay them with its body
+var replayedHeaders = []string{"Content-Type", eTagHeader}
+
+// bodyRecorder keeps a copy of the response body while it is written to the client
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+/*
+idempotency replays the response to a POST, PUT or DELETE request sent
+again with the same Idempotency-Key header, its Content-Type and ETag
+are replayed as well. Keys are kept per user. A response with a 5xx
+status is not stored, so the request can be retried
+*/
+func (h *Handler) idempotency(c *gin.Context) {
+	const op = "delivery.http.v1.idempotency_middleware.idempotency"
+
+	key := c.GetHeader(models.IdempotencyKeyHeader)
+	if key == "" {
+		return
+	}
+
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+	default:
+		return
+	}
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	body, err := readBody(c, maxIdempotentBodySize)
+	if err != nil {
+		newBodyErrorResponse(c, err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to read request body", op), sl.Err(err))
+		return
+	}
+
+	req := models.IdempotentRequest{
+		Method:     c.Request.Method,
+		RequestURI: c.Request.URL.RequestURI(),
+		Body:       body,
+	}
+
+	record, replay, err := h.services.Idempotency.Begin(c.Request.Context(), userId, key, req)
+	if errors.Is(err, service.ErrIdempotencyKeyInvalid) {
+		newErrorResponse(c, http.StatusBadRequest, service.ErrIdempotencyKeyInvalid.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid idempotency key", op), sl.Err(err))
+		return
+	}
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		newErrorResponse(c, http.StatusUnprocessableEntity, service.ErrIdempotencyKeyReused.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: idempotency key is reused", op), sl.Err(err))
+		return
+	}
+	if errors.Is(err, service.ErrIdempotencyInProgress) {
+		newErrorResponse(c, http.StatusConflict, service.ErrIdempotencyInProgress.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: request is in progress", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to check idempotency key", op), sl.Err(err))
+		return
+	}
+
+	if replay {
+		h.logger(c).Info(fmt.Sprintf("%s: response is replayed", op), slog.Int("user id", userId))
+
+		contentType := "application/json; charset=utf-8"
+		for name, value := range record.ResponseHeaders {
+			if name == "Content-Type" {
+				contentType = value
+				continue
+			}
+			c.Header(name, value)
+		}
+
+		c.Header(idempotentReplayedHeader, "true")
+		c.Data(*record.StatusCode, contentType, record.ResponseBody)
+		c.Abort()
+		return
+	}
+
+	recorder := &bodyRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+
+	c.Next()
+
+	/*
+		the key is freed or completed even if the deadline of the request
+		is over, otherwise it would stay in progress until it expires
+	*/
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+	defer cancel()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		if err := h.services.Idempotency.Abandon(ctx, userId, key); err != nil {
+			h.logger(c).Error(fmt.Sprintf("%s: failed to free idempotency key", op), sl.Err(err))
+		}
+		return
+	}
+
+	headers := make(map[string]string)
+	for _, name := range replayedHeaders {
+		if value := recorder.Header().Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+
+	if err := h.services.Idempotency.Complete(ctx, userId, key, recorder.Status(), headers, recorder.body.Bytes()); err != nil {
+		h.logger(c).Error(fmt.Sprintf("%s: failed to store response", op), sl.Err(err))
+	}
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/aidos-dev/habit-tracker/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_metrics(t *testing.T) {
+	testTable := []struct {
+		name           string
+		path           string
+		expectedSeries string
+	}{
+		{
+			name:           "Route",
+			path:           "/habits/3",
+			expectedSeries: `http_request_duration_seconds_count{method="GET",route="/habits/:habitId",status="200"}`,
+		},
+		{
+			name:           "Unmatched Route",
+			path:           "/unknown/3",
+			expectedSeries: `http_request_duration_seconds_count{method="GET",route="unmatched",status="404"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			habit := mock_service.NewMockHabit(c)
+			habit.EXPECT().GetById(gomock.Any(), 1, 3).Return(models.Habit{Id: 3, Title: "running"}, nil).AnyTimes()
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Habit: habit}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.Use(metrics.HTTP())
+			r.GET("/metrics", metrics.Handler())
+			r.GET("/habits/:habitId", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getHabitById)
+
+			// Make Requests
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", testCase.path, nil))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+			// Assert
+			if w.Code != 200 {
+				t.Errorf("Expected status code: %d but got: %d", 200, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), testCase.expectedSeries) {
+				t.Errorf("Expected series '%s' in the metrics", testCase.expectedSeries)
+			}
+		})
+	}
+}
@@ -1,15 +1,21 @@
 package v1
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/aidos-dev/habit-tracker/pkg/svcauth"
 	"github.com/gin-gonic/gin"
 
 	"golang.org/x/exp/slog"
@@ -17,8 +23,18 @@ import (
 
 const (
 	authorizationHeader = "Authorization"
+	requestIdHeader     = loggs.RequestIdHeader
 	userCtx             = "userId"
 	roleCtx             = "userRole"
+	actorCtx            = "actorId"
+	tokenCtx            = "accessToken"
+	personalTokenCtx    = "personalTokenId"
+	requestIdCtx        = "requestId"
+	loggerCtx           = "logger"
+	tgUserIdQuery       = "tgUserId"
+
+	// maxServiceBodySize limits bodies read before the signature is checked, the bot sends small json
+	maxServiceBodySize = 64 << 10
 )
 
 func (h *Handler) userIdentity(c *gin.Context) {
@@ -32,46 +48,92 @@ func (h *Handler) userIdentity(c *gin.Context) {
 	}
 }
 
-func (h *Handler) tgUserIdentity(c *gin.Context) {
-	const op = "delivery.http.v1.middleware.tgUserIdentity"
-
-	// var TgUserName models.TgUser
+/*
+serviceIdentity lets through only requests signed by a known service,
+such as the telegram bot. Nothing a telegram client sends is trusted
+unless the request is signed
+*/
+func (h *Handler) serviceIdentity(c *gin.Context) {
+	const op = "delivery.http.v1.middleware.serviceIdentity"
 
-	// if err := c.BindJSON(&TgUserName); err != nil {
-	// 	newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
-	// 	h.log.Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
-	// 	return
-	// }
+	body, err := readBody(c, maxServiceBodySize)
+	if err != nil {
+		newBodyErrorResponse(c, err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to read request body", op), sl.Err(err))
+		return
+	}
 
-	TgUserName := c.Query("tgUser")
+	req := models.SignedRequest{
+		ServiceId:  c.GetHeader(svcauth.ServiceIdHeader),
+		Timestamp:  c.GetHeader(svcauth.TimestampHeader),
+		Nonce:      c.GetHeader(svcauth.NonceHeader),
+		Signature:  c.GetHeader(svcauth.SignatureHeader),
+		Method:     c.Request.Method,
+		RequestURI: c.Request.URL.RequestURI(),
+		Body:       body,
+	}
 
-	h.log.Info(
-		fmt.Sprintf("%s: preparing to find a tgUser", op),
-		// slog.String("tgUserName", TgUserName.TgUsername),
-		slog.String("tgUserName", TgUserName),
-	)
+	if err := h.services.ServiceAuth.Authenticate(c.Request.Context(), req); err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, "service request is not authorized")
+		h.logger(c).Error(fmt.Sprintf("%s: unauthorized service request", op), sl.Err(err))
+		return
+	}
+}
 
-	// user, err := h.services.Authorization.FindTgUser(TgUserName.TgUsername)
-	user, err := h.services.Authorization.FindTgUser(TgUserName)
+/*
+readBody reads the body of a request up to limit bytes and puts it back,
+so the handler reads it again
+*/
+func readBody(c *gin.Context, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: a telegram user not found: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to find a telegram user by tg username", op), sl.Err(err))
+		return nil, err
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// newBodyErrorResponse answers 413 to a body over the limit of readBody, 400 to other errors
+func newBodyErrorResponse(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		newErrorResponse(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than %d bytes", maxBytesErr.Limit))
 		return
 	}
 
-	h.log.Info(
-		fmt.Sprintf("%s: a tgUser is found and ready to be sent to context", op),
-		slog.Any("tgUse", user),
-	)
+	newErrorResponse(c, http.StatusBadRequest, "failed to read request body")
+}
 
-	// h.log.Info(
-	// 	fmt.Sprintf("%s: Data type of user.Id", op),
-	// 	slog.Any("type", reflect.TypeOf(user.Id)),
-	// )
+/*
+tgUserIdentity finds a telegram user by the numeric telegram id.
+The id is a part of the signed url, so only the bot can set it
+*/
+func (h *Handler) tgUserIdentity(c *gin.Context) {
+	const op = "delivery.http.v1.middleware.tgUserIdentity"
 
-	log.Printf("%s: Data type of user.Id: %v\n", op, reflect.TypeOf(user.Id))
+	h.serviceIdentity(c)
+	if c.IsAborted() {
+		return
+	}
+
+	tgUserId, err := strconv.ParseInt(c.Query(tgUserIdQuery), 10, 64)
+	if err != nil || tgUserId <= 0 {
+		newErrorResponse(c, http.StatusBadRequest, "invalid telegram user id")
+		h.logger(c).Error(fmt.Sprintf("%s: invalid telegram user id", op), slog.String("value", c.Query(tgUserIdQuery)))
+		return
+	}
+
+	user, err := h.services.Authorization.FindTgUser(c.Request.Context(), tgUserId)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, "telegram user not found: start the bot to sign up")
+		h.logger(c).Error(fmt.Sprintf("%s: failed to find a telegram user by tg user id", op), sl.Err(err))
+		return
+	}
 
 	c.Set(userCtx, user.Id)
+	h.logUser(c, user.Id)
 	c.Set(roleCtx, user.Role)
 }
 
@@ -81,7 +143,7 @@ func (h *Handler) webUserIdentity(c *gin.Context) {
 	header := c.GetHeader(authorizationHeader)
 	if header == "" {
 		newErrorResponse(c, http.StatusUnauthorized, "empty auth header")
-		h.log.Error(fmt.Sprintf("%s: error", op), "empty auth header")
+		h.logger(c).Error(fmt.Sprintf("%s: error", op), "empty auth header")
 		return
 	}
 
@@ -89,38 +151,141 @@ func (h *Handler) webUserIdentity(c *gin.Context) {
 
 	if len(headerParts) != 2 {
 		newErrorResponse(c, http.StatusUnauthorized, "invalid auth header: auth fields are missing")
-		h.log.Error(fmt.Sprintf("%s: error", op), "invalid auth header: auth fields are missing")
+		h.logger(c).Error(fmt.Sprintf("%s: error", op), "invalid auth header: auth fields are missing")
 		return
 	}
 
 	if headerParts[0] != "Bearer" {
 		newErrorResponse(c, http.StatusUnauthorized, "invalid auth header: wrong auth method")
-		h.log.Error(fmt.Sprintf("%s: error", op), "invalid auth header: wrong auth method")
+		h.logger(c).Error(fmt.Sprintf("%s: error", op), "invalid auth header: wrong auth method")
 		return
 	}
 
 	if headerParts[1] == "" {
 		newErrorResponse(c, http.StatusUnauthorized, "invalid auth header: token is missing")
-		h.log.Error(fmt.Sprintf("%s: error", op), "invalid auth header: token is missing")
+		h.logger(c).Error(fmt.Sprintf("%s: error", op), "invalid auth header: token is missing")
+		return
+	}
+
+	if strings.HasPrefix(headerParts[1], models.PersonalTokenPrefix) {
+		h.personalTokenIdentity(c, headerParts[1])
 		return
 	}
 
-	userId, userRole, err := h.services.Authorization.ParseToken(headerParts[1])
+	userId, userRole, err := h.services.Authorization.ParseToken(c.Request.Context(), headerParts[1])
+	if errors.Is(err, service.ErrTokenRevoked) {
+		newErrorResponse(c, http.StatusUnauthorized, "token is revoked")
+		h.logger(c).Error(fmt.Sprintf("%s: revoked token is used", op), sl.Err(err))
+		return
+	}
 	if err != nil {
 		newErrorResponse(c, http.StatusUnauthorized, err.Error())
-		h.log.Error(fmt.Sprintf("%s: failed to parse jwt token", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to parse jwt token", op), sl.Err(err))
 		return
 	}
 
-	// h.log.Info(
-	// 	fmt.Sprintf("%s: Data type of userId", op),
-	// 	slog.Any("type", reflect.TypeOf(userId)),
-	// )
+	c.Set(userCtx, userId)
+	h.logUser(c, userId)
+	c.Set(roleCtx, userRole)
+	c.Set(tokenCtx, headerParts[1])
+}
+
+/*
+personalTokenIdentity accepts a personal access token in place of a JWT.
+A token with the read scope is let through on GET requests only,
+any other method needs the write scope
+*/
+func (h *Handler) personalTokenIdentity(c *gin.Context, rawToken string) {
+	const op = "delivery.http.v1.middleware.personalTokenIdentity"
 
-	log.Printf("%s: Data type of userId: %v\n", op, reflect.TypeOf(userId))
+	token, userRole, err := h.services.PersonalToken.Authenticate(c.Request.Context(), rawToken)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, service.ErrPersonalTokenRejected.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to authenticate personal access token", op), sl.Err(err))
+		return
+	}
 
-	c.Set(userCtx, userId)
+	scope := models.ScopeWrite
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		scope = models.ScopeRead
+	}
+
+	if !token.HasScope(scope) {
+		newErrorResponse(c, http.StatusForbidden, fmt.Sprintf("access denied: token has no %s scope", scope))
+		h.logger(c).Error(
+			fmt.Sprintf("%s: access denied", op),
+			slog.Int("token id", token.Id),
+			slog.String("scope", scope),
+		)
+		return
+	}
+
+	c.Set(userCtx, token.UserId)
+	h.logUser(c, token.UserId)
 	c.Set(roleCtx, userRole)
+	c.Set(personalTokenCtx, token.Id)
+}
+
+/*
+sessionOnly keeps personal access tokens away from the account and admin routes,
+a leaked token must not be enough to change a password, issue more tokens
+or act with the admin role of its owner
+*/
+func (h *Handler) sessionOnly(c *gin.Context) {
+	const op = "delivery.http.v1.middleware.sessionOnly"
+
+	if tokenId, ok := c.Get(personalTokenCtx); ok {
+		newErrorResponse(c, http.StatusForbidden, "access denied: sign in to use this route")
+		h.logger(c).Error(fmt.Sprintf("%s: personal access token is used on session only route", op), slog.Any("token id", tokenId))
+		return
+	}
+}
+
+/*
+unverifiedRoutes are the routes which change data, but are open to web
+users with unconfirmed email, so a user who can't confirm it is still
+able to change the password or delete the account
+*/
+var unverifiedRoutes = map[string]bool{
+	http.MethodDelete + " /:client/api/account/":      true,
+	http.MethodPut + " /:client/api/account/password": true,
+}
+
+/*
+verifiedEmail lets web users with unconfirmed email only read their
+data, but for unverifiedRoutes. Telegram users have no email, so they
+are not limited
+*/
+func (h *Handler) verifiedEmail(c *gin.Context) {
+	const op = "delivery.http.v1.middleware.verifiedEmail"
+
+	if c.Param("client") != models.WebClient || c.Request.Method == http.MethodGet {
+		return
+	}
+
+	if unverifiedRoutes[c.Request.Method+" "+c.FullPath()] {
+		return
+	}
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	verified, err := h.services.Account.IsEmailVerified(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to check email", op), sl.Err(err))
+		return
+	}
+
+	if !verified {
+		newErrorResponse(c, http.StatusForbidden, "email is not verified: confirm it by the link sent to your email")
+		h.logger(c).Error(fmt.Sprintf("%s: error", op), "email is not verified", slog.Int("user id", userId))
+		return
+	}
 }
 
 func getUserId(c *gin.Context) (int, error) {
@@ -132,8 +297,6 @@ func getUserId(c *gin.Context) (int, error) {
 		return 0, fmt.Errorf("%s: user id not found", op)
 	}
 
-	log.Printf("%s: a user id before converting: %d", op, id)
-
 	idInt, err := convertToInt(id)
 	if err != nil {
 		return 0, fmt.Errorf("%s: failed to convert to int", op)
@@ -155,10 +318,8 @@ func convertToInt(id any) (int, error) {
 	switch id.(type) {
 	case int:
 		idInt = id.(int) // converting  to int
-		log.Printf("%s: idInt got value from int type: %d", op, id)
 	case float64:
 		idInt = int(id.(float64)) // converting float64 to int
-		log.Printf("%s: idInt got value from float64 type: %d", op, id)
 	default:
 		return 0, fmt.Errorf("%s: user id is of unknown type", op)
 	}
@@ -195,51 +356,54 @@ func getUserRole(c *gin.Context) (string, error) {
 }
 
 /*
-getHabitId returns a habit id depending on the role of a user
-(sipmple user or admin). This function is required since parsing
-user id and habit id is confusing for c.Param function
+getListParams reads pagination, sorting and filtering params of
+a list endpoint from the query string
 */
-func getHabitId(c *gin.Context) (int, error) {
-	const op = "delivery.http.v1.middleware.getHabitId"
+func getListParams(c *gin.Context, fields models.ListFields) (models.ListParams, error) {
+	const op = "delivery.http.v1.middleware.getListParams"
 
-	userRole, err := getUserRole(c)
+	params, err := models.ParseListParams(c.Request.URL.Query(), fields)
 	if err != nil {
-		return 0, fmt.Errorf("%s: user role not found: %w", op, err)
+		return params, fmt.Errorf("%s: %w", op, err)
 	}
 
-	var habitId int
+	return params, nil
+}
 
-	switch userRole {
-	case models.UserGeneral:
-		habitId, err = strconv.Atoi(c.Param("habitId"))
-	case models.Administrator:
-		habitId, err = strconv.Atoi(c.Param("habitIdAdmin"))
+/*
+getHabitId returns a habit id of a user or an admin route
+(admin routes have their own param name). This function is required since parsing
+user id and habit id is confusing for c.Param function
+*/
+func getHabitId(c *gin.Context) (int, error) {
+	habitId := c.Param("habitIdAdmin")
+	if habitId == "" {
+		habitId = c.Param("habitId")
 	}
 
-	return habitId, err
+	return parseId(habitId)
 }
 
 /*
-getRewardId returns a reward id depending on the role of a user
-(sipmple user or admin). This function is required since parsing
+getRewardId returns a reward id of a user or an admin route
+(admin routes have their own param name). This function is required since parsing
 user id and reward id is confusing for c.Param function
 */
 func getRewardId(c *gin.Context) (int, error) {
-	const op = "delivery.http.v1.middleware.getRewardId"
-
-	userRole, err := getUserRole(c)
-	if err != nil {
-		return 0, fmt.Errorf("%s: user role not found: %w", op, err)
+	rewardId := c.Param("rewardIdAdmin")
+	if rewardId == "" {
+		rewardId = c.Param("rewardId")
 	}
 
-	var rewardId int
+	return parseId(rewardId)
+}
 
-	switch userRole {
-	case models.UserGeneral:
-		rewardId, err = strconv.Atoi(c.Param("rewardId"))
-	case models.Administrator:
-		rewardId, err = strconv.Atoi(c.Param("rewardIdAdmin"))
+// parseId parses an id param of a path, a malformed one is an error of the client
+func parseId(param string) (int, error) {
+	id, err := strconv.Atoi(param)
+	if err != nil {
+		return 0, errs.Validation("invalid_id", err)
 	}
 
-	return rewardId, err
+	return id, nil
 }
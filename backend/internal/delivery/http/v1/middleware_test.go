@@ -1,11 +1,18 @@
 package v1
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/service"
 	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
@@ -37,7 +44,7 @@ func Test_handler_webUserIdentity(t *testing.T) {
 			headerValue: "Bearer token",
 			token:       "token",
 			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
-				s.EXPECT().ParseToken(token).Return(1, "test_role", nil)
+				s.EXPECT().ParseToken(gomock.Any(), token).Return(1, "test_role", nil)
 			},
 			expectedStatusCode: 200,
 			expectedResponseBody: ResponseBody{
@@ -90,7 +97,7 @@ func Test_handler_webUserIdentity(t *testing.T) {
 			headerValue: "Bearer token",
 			token:       "token",
 			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
-				s.EXPECT().ParseToken(token).Return(0, "", errors.New("failed to parse token"))
+				s.EXPECT().ParseToken(gomock.Any(), token).Return(0, "", errors.New("failed to parse token"))
 			},
 			expectedStatusCode: 401,
 			expectedResponseBody: ResponseBody{
@@ -99,6 +106,21 @@ func Test_handler_webUserIdentity(t *testing.T) {
 				Message:  "failed to parse token",
 			},
 		},
+		{
+			name:        "Revoked Token",
+			headerName:  "Authorization",
+			headerValue: "Bearer token",
+			token:       "token",
+			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
+				s.EXPECT().ParseToken(gomock.Any(), token).Return(0, "", fmt.Errorf("parse: %w", service.ErrTokenRevoked))
+			},
+			expectedStatusCode: 401,
+			expectedResponseBody: ResponseBody{
+				UserID:   0,
+				UserRole: "",
+				Message:  "token is revoked",
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -169,3 +191,670 @@ func Test_handler_webUserIdentity(t *testing.T) {
 		})
 	}
 }
+
+func Test_handler_verifiedEmail(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccount)
+
+	testTable := []struct {
+		name                 string
+		method               string
+		client               string
+		route                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:   "Verified",
+			method: "POST",
+			client: "web",
+			route:  "/:client/api/habits/",
+			mockBehavior: func(s *mock_service.MockAccount) {
+				s.EXPECT().IsEmailVerified(gomock.Any(), 1).Return(true, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name:   "Not verified",
+			method: "POST",
+			client: "web",
+			route:  "/:client/api/habits/",
+			mockBehavior: func(s *mock_service.MockAccount) {
+				s.EXPECT().IsEmailVerified(gomock.Any(), 1).Return(false, nil)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"message":"email is not verified: confirm it by the link sent to your email"}`,
+		},
+		{
+			name:   "Not verified outside habits",
+			method: "POST",
+			client: "web",
+			route:  "/:client/api/account/tokens/",
+			mockBehavior: func(s *mock_service.MockAccount) {
+				s.EXPECT().IsEmailVerified(gomock.Any(), 1).Return(false, nil)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"message":"email is not verified: confirm it by the link sent to your email"}`,
+		},
+		{
+			name:                 "Password change is allowed",
+			method:               "PUT",
+			client:               "web",
+			route:                "/:client/api/account/password",
+			mockBehavior:         func(s *mock_service.MockAccount) {},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name:                 "Account deletion is allowed",
+			method:               "DELETE",
+			client:               "web",
+			route:                "/:client/api/account/",
+			mockBehavior:         func(s *mock_service.MockAccount) {},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name:                 "Reading is allowed",
+			method:               "GET",
+			client:               "web",
+			route:                "/:client/api/habits/",
+			mockBehavior:         func(s *mock_service.MockAccount) {},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name:                 "Telegram client",
+			method:               "POST",
+			client:               "telegram",
+			route:                "/:client/api/habits/",
+			mockBehavior:         func(s *mock_service.MockAccount) {},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			account := mock_service.NewMockAccount(c)
+			testCase.mockBehavior(account)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Account: account}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.Handle(testCase.method, testCase.route, func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.verifiedEmail, func(c *gin.Context) {
+				c.JSON(200, statusResponse{Status: "ok"})
+			})
+
+			// Init Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, strings.Replace(testCase.route, ":client", testCase.client, 1), nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handler_tgUserIdentity(t *testing.T) {
+	type mockBehavior func(sa *mock_service.MockServiceAuth, a *mock_service.MockAuthorization)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		body                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?tgUserId=42",
+			mockBehavior: func(sa *mock_service.MockServiceAuth, a *mock_service.MockAuthorization) {
+				sa.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(nil)
+				a.EXPECT().FindTgUser(gomock.Any(), int64(42)).Return(models.GetUser{Id: 1, Role: "user"}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"userId":1}`,
+		},
+		{
+			name:  "Unsigned request",
+			query: "?tgUserId=42",
+			mockBehavior: func(sa *mock_service.MockServiceAuth, a *mock_service.MockAuthorization) {
+				sa.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(service.ErrServiceUnauthorized)
+			},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"message":"service request is not authorized"}`,
+		},
+		{
+			name:  "User name instead of id",
+			query: "?tgUserId=someone",
+			mockBehavior: func(sa *mock_service.MockServiceAuth, a *mock_service.MockAuthorization) {
+				sa.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"invalid telegram user id"}`,
+		},
+		{
+			name:  "User not found",
+			query: "?tgUserId=42",
+			mockBehavior: func(sa *mock_service.MockServiceAuth, a *mock_service.MockAuthorization) {
+				sa.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(nil)
+				a.EXPECT().FindTgUser(gomock.Any(), int64(42)).Return(models.GetUser{}, errors.New("no rows in result set"))
+			},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"message":"telegram user not found: start the bot to sign up"}`,
+		},
+		{
+			// the body is not read past the limit, the signature is not checked
+			name:                 "Body too large",
+			query:                "?tgUserId=42",
+			body:                 strings.Repeat("a", maxServiceBodySize+1),
+			mockBehavior:         func(sa *mock_service.MockServiceAuth, a *mock_service.MockAuthorization) {},
+			expectedStatusCode:   413,
+			expectedResponseBody: `{"message":"request body is larger than 65536 bytes"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			serviceAuth := mock_service.NewMockServiceAuth(c)
+			auth := mock_service.NewMockAuthorization(c)
+			testCase.mockBehavior(serviceAuth, auth)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{ServiceAuth: serviceAuth, Authorization: auth}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/identity", handler.tgUserIdentity, func(c *gin.Context) {
+				userId, _ := c.Get(userCtx)
+				c.JSON(200, map[string]any{"userId": userId})
+			})
+
+			// Init Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/identity"+testCase.query, strings.NewReader(testCase.body))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handler_requirePermission(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAdminRole)
+
+	testTable := []struct {
+		name                 string
+		tokenRole            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "Allowed",
+			tokenRole: "moderator",
+			mockBehavior: func(s *mock_service.MockAdminRole) {
+				s.EXPECT().UserRole(gomock.Any(), 1).Return("moderator", nil)
+				s.EXPECT().HasPermission(gomock.Any(), "moderator", models.PermRewardsRead).Return(true, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name:      "Denied",
+			tokenRole: models.UserGeneral,
+			mockBehavior: func(s *mock_service.MockAdminRole) {
+				s.EXPECT().UserRole(gomock.Any(), 1).Return(models.UserGeneral, nil)
+				s.EXPECT().HasPermission(gomock.Any(), models.UserGeneral, models.PermRewardsRead).Return(false, nil)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"message":"access denied: rewards:read permission is required"}`,
+		},
+		{
+			name:      "Role Taken Away",
+			tokenRole: "moderator",
+			mockBehavior: func(s *mock_service.MockAdminRole) {
+				s.EXPECT().UserRole(gomock.Any(), 1).Return(models.UserGeneral, nil)
+				s.EXPECT().HasPermission(gomock.Any(), models.UserGeneral, models.PermRewardsRead).Return(false, nil)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"message":"access denied: rewards:read permission is required"}`,
+		},
+		{
+			name:      "Role Failure",
+			tokenRole: "moderator",
+			mockBehavior: func(s *mock_service.MockAdminRole) {
+				s.EXPECT().UserRole(gomock.Any(), 1).Return("", errors.New("connection refused"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"message":"failed to check permissions"}`,
+		},
+		{
+			name:      "Service Failure",
+			tokenRole: "moderator",
+			mockBehavior: func(s *mock_service.MockAdminRole) {
+				s.EXPECT().UserRole(gomock.Any(), 1).Return("moderator", nil)
+				s.EXPECT().HasPermission(gomock.Any(), "moderator", models.PermRewardsRead).Return(false, errors.New("connection refused"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"message":"failed to check permissions"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			adminRole := mock_service.NewMockAdminRole(c)
+			testCase.mockBehavior(adminRole)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{AdminRole: adminRole}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/rewards", func(c *gin.Context) {
+				c.Set(actorCtx, 1)
+				c.Set(roleCtx, testCase.tokenRole)
+			}, handler.requirePermission(models.PermRewardsRead), func(c *gin.Context) {
+				c.JSON(200, statusResponse{Status: "ok"})
+			})
+
+			// Init Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/rewards", nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handler_personalTokenIdentity(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockPersonalToken, token string)
+
+	testTable := []struct {
+		name                 string
+		method               string
+		token                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:   "Read",
+			method: "GET",
+			token:  "htpat_token",
+			mockBehavior: func(s *mock_service.MockPersonalToken, token string) {
+				s.EXPECT().Authenticate(gomock.Any(), token).Return(models.PersonalToken{Id: 3, UserId: 1, Scopes: []string{"read"}}, "user_basic", nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"userId":1}`,
+		},
+		{
+			name:   "Write",
+			method: "POST",
+			token:  "htpat_token",
+			mockBehavior: func(s *mock_service.MockPersonalToken, token string) {
+				s.EXPECT().Authenticate(gomock.Any(), token).Return(models.PersonalToken{Id: 3, UserId: 1, Scopes: []string{"read", "write"}}, "user_basic", nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"userId":1}`,
+		},
+		{
+			name:   "No Write Scope",
+			method: "POST",
+			token:  "htpat_token",
+			mockBehavior: func(s *mock_service.MockPersonalToken, token string) {
+				s.EXPECT().Authenticate(gomock.Any(), token).Return(models.PersonalToken{Id: 3, UserId: 1, Scopes: []string{"read"}}, "user_basic", nil)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"message":"access denied: token has no write scope"}`,
+		},
+		{
+			name:   "Revoked Token",
+			method: "GET",
+			token:  "htpat_token",
+			mockBehavior: func(s *mock_service.MockPersonalToken, token string) {
+				s.EXPECT().Authenticate(gomock.Any(), token).Return(models.PersonalToken{}, "", service.ErrPersonalTokenRejected)
+			},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"message":"personal access token is invalid, expired or revoked"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			personalToken := mock_service.NewMockPersonalToken(c)
+			testCase.mockBehavior(personalToken, testCase.token)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{PersonalToken: personalToken}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.Handle(testCase.method, "/identity", handler.webUserIdentity, func(c *gin.Context) {
+				userId, _ := c.Get(userCtx)
+				c.JSON(200, map[string]any{"userId": userId})
+			})
+
+			// Init Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, "/identity", nil)
+			req.Header.Set("Authorization", "Bearer "+testCase.token)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handler_rateLimit(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockRateLimit)
+
+	testTable := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedRetryAfter   string
+		expectedResponseBody string
+	}{
+		{
+			name: "Allowed",
+			mockBehavior: func(s *mock_service.MockRateLimit) {
+				s.EXPECT().Allow("GET /:client/api/habits/", "web|user:1").Return(true, time.Duration(0))
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name: "Limited",
+			mockBehavior: func(s *mock_service.MockRateLimit) {
+				s.EXPECT().Allow("GET /:client/api/habits/", "web|user:1").Return(false, 30*time.Second)
+			},
+			expectedStatusCode:   429,
+			expectedRetryAfter:   "30",
+			expectedResponseBody: `{"message":"too many requests: retry in 30 seconds"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			rateLimit := mock_service.NewMockRateLimit(c)
+			testCase.mockBehavior(rateLimit)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{RateLimit: rateLimit}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/:client/api/habits/", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.rateLimit, func(c *gin.Context) {
+				c.JSON(200, statusResponse{Status: "ok"})
+			})
+
+			// Init Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/web/api/habits/", nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Header().Get("Retry-After") != testCase.expectedRetryAfter {
+				t.Errorf("Expected Retry-After '%s' but got '%s'", testCase.expectedRetryAfter, w.Header().Get("Retry-After"))
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handler_idempotency(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockIdempotency, req models.IdempotentRequest)
+
+	stored := http.StatusOK
+
+	testTable := []struct {
+		name                 string
+		key                  string
+		inputBody            string
+		handlerStatusCode    int
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedReplayed     string
+		expectedHeaders      map[string]string
+		expectedResponseBody string
+	}{
+		{
+			name:                 "No Key",
+			key:                  "",
+			handlerStatusCode:    200,
+			mockBehavior:         func(s *mock_service.MockIdempotency, req models.IdempotentRequest) {},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"id":1}`,
+		},
+		{
+			name:              "First Request",
+			key:               "key-1",
+			handlerStatusCode: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, req models.IdempotentRequest) {
+				s.EXPECT().Begin(gomock.Any(), 1, "key-1", req).Return(models.IdempotencyRecord{}, false, nil)
+				headers := map[string]string{"Content-Type": "application/json; charset=utf-8", "ETag": `"1"`}
+				s.EXPECT().Complete(gomock.Any(), 1, "key-1", 200, headers, []byte(`{"id":1}`)).Return(nil)
+			},
+			expectedStatusCode:   200,
+			expectedHeaders:      map[string]string{"Content-Type": "application/json; charset=utf-8", "ETag": `"1"`},
+			expectedResponseBody: `{"id":1}`,
+		},
+		{
+			name:              "Replay",
+			key:               "key-1",
+			handlerStatusCode: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, req models.IdempotentRequest) {
+				record := models.IdempotencyRecord{
+					StatusCode:      &stored,
+					ResponseBody:    []byte(`{"id":7}`),
+					ResponseHeaders: map[string]string{"Content-Type": "application/problem+json", "ETag": `"7"`},
+				}
+				s.EXPECT().Begin(gomock.Any(), 1, "key-1", req).Return(record, true, nil)
+			},
+			expectedStatusCode:   200,
+			expectedReplayed:     "true",
+			expectedHeaders:      map[string]string{"Content-Type": "application/problem+json", "ETag": `"7"`},
+			expectedResponseBody: `{"id":7}`,
+		},
+		{
+			name:              "Replay Without Headers",
+			key:               "key-1",
+			handlerStatusCode: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, req models.IdempotentRequest) {
+				s.EXPECT().Begin(gomock.Any(), 1, "key-1", req).Return(models.IdempotencyRecord{StatusCode: &stored, ResponseBody: []byte(`{"id":7}`)}, true, nil)
+			},
+			expectedStatusCode:   200,
+			expectedReplayed:     "true",
+			expectedHeaders:      map[string]string{"Content-Type": "application/json; charset=utf-8", "ETag": ""},
+			expectedResponseBody: `{"id":7}`,
+		},
+		{
+			name:              "Key Reused",
+			key:               "key-1",
+			handlerStatusCode: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, req models.IdempotentRequest) {
+				s.EXPECT().Begin(gomock.Any(), 1, "key-1", req).Return(models.IdempotencyRecord{}, false, service.ErrIdempotencyKeyReused)
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"message":"idempotency key is already used for another request"}`,
+		},
+		{
+			name:              "In Progress",
+			key:               "key-1",
+			handlerStatusCode: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, req models.IdempotentRequest) {
+				s.EXPECT().Begin(gomock.Any(), 1, "key-1", req).Return(models.IdempotencyRecord{}, false, service.ErrIdempotencyInProgress)
+			},
+			expectedStatusCode:   409,
+			expectedResponseBody: `{"message":"request with this idempotency key is in progress"}`,
+		},
+		{
+			name:              "Failed Request",
+			key:               "key-1",
+			handlerStatusCode: 500,
+			mockBehavior: func(s *mock_service.MockIdempotency, req models.IdempotentRequest) {
+				s.EXPECT().Begin(gomock.Any(), 1, "key-1", req).Return(models.IdempotencyRecord{}, false, nil)
+				s.EXPECT().Abandon(gomock.Any(), 1, "key-1").Return(nil)
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"id":1}`,
+		},
+		{
+			name:                 "Body Too Large",
+			key:                  "key-1",
+			inputBody:            `{"title":"` + strings.Repeat("a", maxIdempotentBodySize) + `"}`,
+			handlerStatusCode:    200,
+			mockBehavior:         func(s *mock_service.MockIdempotency, req models.IdempotentRequest) {},
+			expectedStatusCode:   413,
+			expectedResponseBody: fmt.Sprintf(`{"message":"request body is larger than %d bytes"}`, maxIdempotentBodySize),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			inputBody := testCase.inputBody
+			if inputBody == "" {
+				inputBody = `{"title":"run"}`
+			}
+
+			idempotency := mock_service.NewMockIdempotency(c)
+			testCase.mockBehavior(idempotency, models.IdempotentRequest{
+				Method:     "POST",
+				RequestURI: "/web/api/habits/",
+				Body:       []byte(inputBody),
+			})
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Idempotency: idempotency}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/:client/api/habits/", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.idempotency, func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				if string(body) != inputBody {
+					t.Errorf("Expected request body '%s' but got '%s'", inputBody, string(body))
+				}
+
+				c.Header("ETag", `"1"`)
+				c.JSON(testCase.handlerStatusCode, map[string]int{"id": 1})
+			})
+
+			// Init Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/web/api/habits/", bytes.NewBufferString(inputBody))
+			if testCase.key != "" {
+				req.Header.Set("Idempotency-Key", testCase.key)
+			}
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Header().Get("Idempotent-Replayed") != testCase.expectedReplayed {
+				t.Errorf("Expected Idempotent-Replayed '%s' but got '%s'", testCase.expectedReplayed, w.Header().Get("Idempotent-Replayed"))
+			}
+
+			for name, value := range testCase.expectedHeaders {
+				if w.Header().Get(name) != value {
+					t.Errorf("Expected %s '%s' but got '%s'", name, value, w.Header().Get(name))
+				}
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
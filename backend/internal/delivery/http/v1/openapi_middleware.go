@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+/*
+NewOpenAPIValidator checks requests and responses against the spec.
+A request that does not match the spec is rejected with 400, a response
+that does not match it is only logged, so a mistake in the spec is found
+without breaking the client. Authorization is left to the handlers.
+It is meant for dev and test environments, it slows every request down
+*/
+func NewOpenAPIValidator(log *slog.Logger, doc *openapi3.T) (gin.HandlerFunc, error) {
+	const op = "delivery.http.v1.openapi_middleware.NewOpenAPIValidator"
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		// defaults are not put into the request, it would break signatures of signed requests
+		SkipSettingDefaults: true,
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			// routes out of the spec, like the docs, are not checked
+			log.Debug(fmt.Sprintf("%s: route is not in the spec", op), slog.String("path", c.Request.URL.Path))
+			return
+		}
+
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), requestInput); err != nil {
+			newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("request does not match the api spec: %v", err.Error()))
+			log.Error(fmt.Sprintf("%s: invalid request", op), sl.Err(err))
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 recorder.Status(),
+			Header:                 recorder.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+			Options:                options,
+		}
+
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), responseInput); err != nil {
+			log.Error(
+				fmt.Sprintf("%s: response does not match the api spec", op),
+				slog.String("method", c.Request.Method),
+				slog.String("route", c.FullPath()),
+				slog.Int("status", recorder.Status()),
+				sl.Err(err),
+			)
+		}
+	}, nil
+}
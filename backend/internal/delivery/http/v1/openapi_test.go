@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/api"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/health"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/gin-gonic/gin"
+)
+
+var ginParam = regexp.MustCompile(`:([A-Za-z]+)`)
+
+// every route of the router, the probes too, has to be described in the spec
+func Test_openAPISpec_coversRoutes(t *testing.T) {
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("failed to load api spec: %v", err)
+	}
+
+	log := slogdiscard.NewDiscardLogger()
+	handler := NewHandler(log, &service.Service{})
+
+	router, err := handler.InitRoutes(nil)
+	if err != nil {
+		t.Fatalf("failed to init routes: %v", err)
+	}
+	health.New(0).Routes(router)
+
+	for _, route := range router.Routes() {
+		if route.Path == "/openapi.json" || route.Path == "/metrics" || strings.HasPrefix(route.Path, "/swagger/") {
+			continue
+		}
+
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+
+		pathItem := doc.Paths.Value(path)
+		if pathItem == nil {
+			t.Errorf("route %s %s is not in the spec", route.Method, path)
+			continue
+		}
+
+		if pathItem.GetOperation(route.Method) == nil {
+			t.Errorf("method %s of %s is not in the spec", route.Method, path)
+		}
+	}
+}
+
+func Test_NewOpenAPIValidator(t *testing.T) {
+	testTable := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK",
+			method:             http.MethodPost,
+			path:               "/web/auth/sign-in",
+			body:               `{"userName":"user","password":"password1"}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Missing required field",
+			method:             http.MethodPost,
+			path:               "/web/auth/sign-in",
+			body:               `{"userName":"user"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Wrong type of a path param",
+			method:             http.MethodGet,
+			path:               "/web/api/habits/first",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Unknown client",
+			method:             http.MethodGet,
+			path:               "/mobile/api/habits/1",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Route out of the spec",
+			method:             http.MethodGet,
+			path:               "/not-in-spec",
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("failed to load api spec: %v", err)
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Dependencies
+			log := slogdiscard.NewDiscardLogger()
+
+			validator, err := NewOpenAPIValidator(log, doc)
+			if err != nil {
+				t.Fatalf("failed to create validator: %v", err)
+			}
+
+			ok := func(c *gin.Context) {
+				c.JSON(http.StatusOK, map[string]interface{}{"token": "access", "refreshToken": "refresh"})
+			}
+
+			// Init Endpoint
+			r := gin.New()
+			r.Use(validator)
+			r.POST("/web/auth/sign-in", ok)
+			r.GET("/:client/api/habits/:habitId", ok)
+			r.GET("/not-in-spec", ok)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.path, bytes.NewBufferString(testCase.body))
+			if testCase.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code %d, got %d: %s", testCase.expectedStatusCode, w.Code, w.Body.String())
+			}
+
+			if testCase.expectedStatusCode == http.StatusBadRequest {
+				var response errorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+
+				if !strings.HasPrefix(response.Message, "request does not match the api spec") {
+					t.Errorf("Unexpected message: %s", response.Message)
+				}
+			}
+		})
+	}
+}
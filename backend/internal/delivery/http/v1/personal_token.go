@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// createPersonalToken issues a token for scripts, it is shown in the response only
+func (h *Handler) createPersonalToken(c *gin.Context) {
+	const op = "delivery.http.v1.personal_token.createPersonalToken"
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	var input models.PersonalTokenInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: failed to get JSON object: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	token, err := h.services.PersonalToken.Create(c.Request.Context(), userId, input)
+	if errors.Is(err, service.ErrInvalidPersonalToken) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid personal access token", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to create personal access token: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to create personal access token", op), sl.Err(err))
+		return
+	}
+
+	h.logger(c).Info(
+		fmt.Sprintf("%s: a new personal access token has been created", op),
+		slog.Int("user id", userId),
+		slog.Int("token id", token.Id),
+	)
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *Handler) getPersonalTokens(c *gin.Context) {
+	const op = "delivery.http.v1.personal_token.getPersonalTokens"
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	tokens, err := h.services.PersonalToken.GetAll(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get personal access tokens: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get personal access tokens", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) revokePersonalToken(c *gin.Context) {
+	const op = "delivery.http.v1.personal_token.revokePersonalToken"
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	tokenId, err := strconv.Atoi(c.Param("tokenId"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid token id param")
+		h.logger(c).Error(fmt.Sprintf("%s: invalid token id param", op), sl.Err(err))
+		return
+	}
+
+	err = h.services.PersonalToken.Revoke(c.Request.Context(), userId, tokenId)
+	if errors.Is(err, service.ErrPersonalTokenNotFound) {
+		newErrorResponse(c, http.StatusNotFound, service.ErrPersonalTokenNotFound.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: personal access token not found", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to revoke personal access token: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to revoke personal access token", op), sl.Err(err))
+		return
+	}
+
+	h.logger(c).Info(
+		fmt.Sprintf("%s: personal access token has been revoked", op),
+		slog.Int("user id", userId),
+		slog.Int("token id", tokenId),
+	)
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+/*
+rateLimit takes a request from the budget of the route. Budgets are kept
+per client type and per user, requests without a user are counted by address.
+On the user routes it goes after the identity middleware
+*/
+func (h *Handler) rateLimit(c *gin.Context) {
+	const op = "delivery.http.v1.rate_limit_middleware.rateLimit"
+
+	route := c.Request.Method + " " + c.FullPath()
+	key := c.Param("client") + "|ip:" + c.ClientIP()
+
+	if userId, ok := c.Get(userCtx); ok {
+		key = fmt.Sprintf("%s|user:%v", c.Param("client"), userId)
+	}
+
+	allowed, retryAfter := h.services.RateLimit.Allow(route, key)
+	if !allowed {
+		tooManyRequests(c, retryAfter)
+		h.logger(c).Error(
+			fmt.Sprintf("%s: rate limit is exceeded", op),
+			slog.String("route", route),
+			slog.String("key", key),
+		)
+		return
+	}
+}
+
+/*
+addressRateLimit takes a request from the budget of the address over all
+the api routes. It goes before the identity middleware, so requests with
+invalid tokens are limited too
+*/
+func (h *Handler) addressRateLimit(c *gin.Context) {
+	const op = "delivery.http.v1.rate_limit_middleware.addressRateLimit"
+
+	allowed, retryAfter := h.services.RateLimit.AllowAddress(c.ClientIP())
+	if !allowed {
+		tooManyRequests(c, retryAfter)
+		h.logger(c).Error(fmt.Sprintf("%s: rate limit of the address is exceeded", op), slog.String("ip", c.ClientIP()))
+		return
+	}
+}
+
+// tooManyRequests aborts a request with 429 and tells the client when to retry
+func tooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	newErrorResponse(c, http.StatusTooManyRequests, fmt.Sprintf("too many requests: retry in %d seconds", seconds))
+}
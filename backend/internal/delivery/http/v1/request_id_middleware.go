@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+)
+
+/*
+requestId takes the id of a request from the X-Request-ID header or
+generates a new one, and sends it back in the response. The request gets
+a child logger with the id, the client and the route, handlers and
+services log with it, so all the messages of a request can be found by the id
+*/
+func (h *Handler) requestId(c *gin.Context) {
+	const op = "delivery.http.v1.request_id_middleware.requestId"
+
+	start := time.Now()
+
+	requestId := c.GetHeader(requestIdHeader)
+	if !loggs.ValidRequestId(requestId) {
+		requestId = loggs.NewRequestId()
+	}
+
+	c.Set(requestIdCtx, requestId)
+	c.Header(requestIdHeader, requestId)
+
+	trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", requestId))
+
+	c.Request = c.Request.WithContext(loggs.WithRequestId(c.Request.Context(), requestId))
+
+	setLogger(c, h.log.With(
+		slog.String("request_id", requestId),
+		slog.String("client", requestClient(c)),
+		slog.String("route", c.FullPath()),
+	))
+
+	c.Next()
+
+	h.logger(c).Info(
+		fmt.Sprintf("%s: request completed", op),
+		slog.String("method", c.Request.Method),
+		slog.Int("status", c.Writer.Status()),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
+
+// logger returns the logger of a request, the one of the handler outside of requestId middleware
+func (h *Handler) logger(c *gin.Context) *slog.Logger {
+	if log, ok := c.Get(loggerCtx); ok {
+		if log, ok := log.(*slog.Logger); ok {
+			return log
+		}
+	}
+
+	return h.log
+}
+
+// logUser adds the id of an authenticated user to the logger of a request
+func (h *Handler) logUser(c *gin.Context, userId int) {
+	setLogger(c, h.logger(c).With(slog.Int("user_id", userId)))
+}
+
+// setLogger puts the logger of a request to the gin context and to the context the services get
+func setLogger(c *gin.Context, log *slog.Logger) {
+	c.Set(loggerCtx, log)
+	c.Request = c.Request.WithContext(loggs.WithLogger(c.Request.Context(), log))
+}
+
+// requestClient is the client of a request by the first segment of the path, empty outside of the client routes
+func requestClient(c *gin.Context) string {
+	client, _, _ := strings.Cut(strings.TrimPrefix(c.Request.URL.Path, "/"), "/")
+
+	switch client {
+	case models.WebClient, models.TelegramClient:
+		return client
+	}
+
+	return ""
+}
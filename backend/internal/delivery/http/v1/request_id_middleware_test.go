@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+var generatedRequestId = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func Test_requestId(t *testing.T) {
+	testTable := []struct {
+		name              string
+		requestId         string
+		expectedRequestId string
+	}{
+		{
+			name:              "Propagated",
+			requestId:         "bot-7f3a",
+			expectedRequestId: "bot-7f3a",
+		},
+		{
+			name: "Generated",
+		},
+		{
+			name:      "Invalid Replaced",
+			requestId: "abc\" injected=\"1",
+		},
+		{
+			name:      "Too Long Replaced",
+			requestId: strings.Repeat("a", 129),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Dependencies
+			var logs bytes.Buffer
+			log := slog.New(slog.NewJSONHandler(&logs, nil))
+
+			handler := NewHandler(log, &service.Service{})
+
+			var serviceRequestId string
+
+			// Init Endpoint
+			r := gin.New()
+			r.Use(handler.requestId)
+			r.GET("/:client/api/habits/:habitId", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+				handler.logUser(c, 1)
+			}, func(c *gin.Context) {
+				// a service gets the logger and the id from the request context
+				ctx := c.Request.Context()
+				serviceRequestId = loggs.RequestId(ctx)
+				loggs.FromContext(ctx).Info("service message")
+			})
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/web/api/habits/3", nil)
+			if testCase.requestId != "" {
+				req.Header.Set(requestIdHeader, testCase.requestId)
+			}
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			requestId := w.Header().Get(requestIdHeader)
+
+			if testCase.expectedRequestId != "" && requestId != testCase.expectedRequestId {
+				t.Errorf("Expected request id '%s' but got '%s'", testCase.expectedRequestId, requestId)
+			}
+
+			if testCase.expectedRequestId == "" && !generatedRequestId.MatchString(requestId) {
+				t.Errorf("Expected a generated request id but got '%s'", requestId)
+			}
+
+			if serviceRequestId != requestId {
+				t.Errorf("Expected request id '%s' in the request context but got '%s'", requestId, serviceRequestId)
+			}
+
+			expectedAttrs := `"request_id":"` + requestId + `","client":"web","route":"/:client/api/habits/:habitId","user_id":1`
+
+			for _, message := range []string{"service message", "request completed"} {
+				line := logLine(logs.String(), message)
+				if line == "" {
+					t.Fatalf("Expected a log line with '%s' in '%s'", message, logs.String())
+				}
+
+				if !strings.Contains(line, expectedAttrs) {
+					t.Errorf("Expected attributes '%s' in log line '%s'", expectedAttrs, line)
+				}
+			}
+		})
+	}
+}
+
+// logLine finds the log line which message contains the text
+func logLine(logs, text string) string {
+	for _, line := range strings.Split(logs, "\n") {
+		if strings.Contains(line, text) {
+			return line
+		}
+	}
+
+	return ""
+}
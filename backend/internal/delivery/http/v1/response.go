@@ -1,11 +1,21 @@
 package v1
 
 import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
 	"github.com/gin-gonic/gin"
 )
 
+// codeTimeout is the code of a request which deadline is over
+const codeTimeout = "timeout"
+
 type errorResponse struct {
 	Message string `json:"message"`
+	// Code is set for typed errors of pkg/errs, clients can rely on it unlike on the message
+	Code string `json:"code,omitempty"`
 }
 
 type statusResponse struct {
@@ -13,5 +23,31 @@ type statusResponse struct {
 }
 
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
-	c.AbortWithStatusJSON(statusCode, errorResponse{message})
+	newErrorResponseErr(c, statusCode, message, nil)
+}
+
+/*
+newErrorResponseErr answers with the status of the kind of err if it is
+a typed error of pkg/errs, with 504 if the deadline of the request is over,
+with statusCode otherwise. A validation error keeps 400 if the caller
+chose it for a malformed request
+*/
+func newErrorResponseErr(c *gin.Context, statusCode int, message string, err error) {
+	code := errs.Code(err)
+
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, errs.ErrConflict):
+		statusCode = http.StatusConflict
+	case errors.Is(err, errs.ErrForbidden):
+		statusCode = http.StatusForbidden
+	case errors.Is(err, errs.ErrValidation) && statusCode != http.StatusBadRequest:
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		statusCode = http.StatusGatewayTimeout
+		code = codeTimeout
+	}
+
+	c.AbortWithStatusJSON(statusCode, errorResponse{Message: message, Code: code})
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/gin-gonic/gin"
 )
@@ -15,21 +16,21 @@ func (h *Handler) getPersonalRewardsByHabitId(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
 	habitId, err := getHabitId(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: invalid id param: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusBadRequest, fmt.Sprintf("error: invalid habit id param: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get habit Id", op), sl.Err(err))
 		return
 	}
 
-	rewards, err := h.services.Reward.GetPersonalRewardsByHabitId(userId, habitId)
+	rewards, err := h.services.Reward.GetPersonalRewardsByHabitId(c.Request.Context(), userId, habitId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get personal rewards by habit id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get personal rewards by habit id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get personal rewards by habit id: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get personal rewards by habit id", op), sl.Err(err))
 		return
 	}
 
@@ -43,16 +44,23 @@ func (h *Handler) getAllPersonalRewards(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
-	rewards, err := h.services.Reward.GetAllPersonalRewards(userId)
+	params, err := getListParams(c, models.RewardListFields)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get all personal rewards: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get all personal rewards", op), sl.Err(err))
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: invalid list params: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid list params", op), sl.Err(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, rewards)
+	rewards, total, err := h.services.Reward.GetAllPersonalRewards(c.Request.Context(), userId, params)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get all personal rewards: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get all personal rewards", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewPage(rewards, total, params))
 }
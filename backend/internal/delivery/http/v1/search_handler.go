@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/gin-gonic/gin"
+)
+
+// search habits and rewards of a certain user
+func (h *Handler) search(c *gin.Context) {
+	const op = "delivery.http.v1.search_handler.search"
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	input, err := getSearchInput(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: invalid search params: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid search params", op), sl.Err(err))
+		return
+	}
+
+	result, err := h.services.Search.Search(c.Request.Context(), userId, input)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to search: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to search", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// search habits of all users and all rewards
+func (h *Handler) searchAll(c *gin.Context) {
+	const op = "delivery.http.v1.search_handler.searchAll"
+
+	input, err := getSearchInput(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: invalid search params: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid search params", op), sl.Err(err))
+		return
+	}
+
+	result, err := h.services.Search.SearchAll(c.Request.Context(), input)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to search: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to search", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func getSearchInput(c *gin.Context) (models.SearchInput, error) {
+	input := models.SearchInput{
+		Query: strings.TrimSpace(c.Query("q")),
+		Limit: models.DefaultListLimit,
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return input, fmt.Errorf("invalid limit: %s", limit)
+		}
+		input.Limit = n
+	}
+
+	if offset := c.Query("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil {
+			return input, fmt.Errorf("invalid offset: %s", offset)
+		}
+		input.Offset = n
+	}
+
+	return input, input.Validate()
+}
@@ -0,0 +1,226 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_handler_search(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockSearch)
+
+	result := models.SearchResult{
+		Habits:  []models.SearchHit{{Id: 3, Title: "running", Rank: 0.5}},
+		Rewards: []models.SearchHit{},
+	}
+
+	testTable := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?q=run&limit=5&offset=10",
+			mockBehavior: func(s *mock_service.MockSearch) {
+				s.EXPECT().Search(gomock.Any(), 1, models.SearchInput{Query: "run", Limit: 5, Offset: 10}).Return(result, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"habits":[{"id":3,"title":"running","description":"","rank":0.5}],"rewards":[]}`,
+		},
+		{
+			name:  "Default Limit",
+			query: "?q=%20run%20",
+			mockBehavior: func(s *mock_service.MockSearch) {
+				s.EXPECT().Search(gomock.Any(), 1, models.SearchInput{Query: "run", Limit: models.DefaultListLimit}).Return(result, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"habits":[{"id":3,"title":"running","description":"","rank":0.5}],"rewards":[]}`,
+		},
+		{
+			name:                 "Empty Query",
+			query:                "",
+			mockBehavior:         func(s *mock_service.MockSearch) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid search params: search query is empty"}`,
+		},
+		{
+			name:                 "Blank Query",
+			query:                "?q=%20%20",
+			mockBehavior:         func(s *mock_service.MockSearch) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid search params: search query is empty"}`,
+		},
+		{
+			name:                 "Zero Limit",
+			query:                "?q=run&limit=0",
+			mockBehavior:         func(s *mock_service.MockSearch) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid search params: invalid limit: must be a number between 1 and 100"}`,
+		},
+		{
+			name:                 "Limit Too Big",
+			query:                "?q=run&limit=101",
+			mockBehavior:         func(s *mock_service.MockSearch) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid search params: invalid limit: must be a number between 1 and 100"}`,
+		},
+		{
+			name:                 "Limit Not A Number",
+			query:                "?q=run&limit=ten",
+			mockBehavior:         func(s *mock_service.MockSearch) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid search params: invalid limit: ten"}`,
+		},
+		{
+			name:                 "Negative Offset",
+			query:                "?q=run&offset=-1",
+			mockBehavior:         func(s *mock_service.MockSearch) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid search params: invalid offset: must not be negative"}`,
+		},
+		{
+			name:                 "Offset Not A Number",
+			query:                "?q=run&offset=ten",
+			mockBehavior:         func(s *mock_service.MockSearch) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid search params: invalid offset: ten"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			search := mock_service.NewMockSearch(c)
+			testCase.mockBehavior(search)
+
+			services := &service.Service{Search: search}
+			handler := NewHandler(slogdiscard.NewDiscardLogger(), services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/search", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.search)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/search"+testCase.query, nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handler_searchAll(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockAdminRole, s *mock_service.MockSearch)
+
+	testTable := []struct {
+		name                 string
+		role                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			role:  models.Administrator,
+			query: "?q=run",
+			mockBehavior: func(r *mock_service.MockAdminRole, s *mock_service.MockSearch) {
+				r.EXPECT().UserRole(gomock.Any(), 1).Return(models.Administrator, nil)
+				r.EXPECT().HasPermission(gomock.Any(), models.Administrator, models.PermSearchAll).Return(true, nil)
+
+				result := models.SearchResult{
+					Habits:  []models.SearchHit{{Id: 3, UserId: 7, Title: "running", Rank: 0.5}},
+					Rewards: []models.SearchHit{},
+				}
+				s.EXPECT().SearchAll(gomock.Any(), models.SearchInput{Query: "run", Limit: models.DefaultListLimit}).Return(result, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"habits":[{"id":3,"userId":7,"title":"running","description":"","rank":0.5}],"rewards":[]}`,
+		},
+		{
+			name:  "No Permission",
+			role:  models.UserGeneral,
+			query: "?q=run",
+			mockBehavior: func(r *mock_service.MockAdminRole, s *mock_service.MockSearch) {
+				r.EXPECT().UserRole(gomock.Any(), 1).Return(models.UserGeneral, nil)
+				r.EXPECT().HasPermission(gomock.Any(), models.UserGeneral, models.PermSearchAll).Return(false, nil)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"message":"access denied: search:all permission is required"}`,
+		},
+		{
+			name:  "Invalid Limit",
+			role:  models.Administrator,
+			query: "?q=run&limit=1000",
+			mockBehavior: func(r *mock_service.MockAdminRole, s *mock_service.MockSearch) {
+				r.EXPECT().UserRole(gomock.Any(), 1).Return(models.Administrator, nil)
+				r.EXPECT().HasPermission(gomock.Any(), models.Administrator, models.PermSearchAll).Return(true, nil)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"error: invalid search params: invalid limit: must be a number between 1 and 100"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			adminRole := mock_service.NewMockAdminRole(c)
+			search := mock_service.NewMockSearch(c)
+			testCase.mockBehavior(adminRole, search)
+
+			services := &service.Service{AdminRole: adminRole, Search: search}
+			handler := NewHandler(slogdiscard.NewDiscardLogger(), services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/admin/search", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+				c.Set(actorCtx, 1)
+				c.Set(roleCtx, testCase.role)
+			}, handler.requirePermission(models.PermSearchAll), handler.searchAll)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/admin/search"+testCase.query, nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+/*
+createLinkCode gives a web user a code to send to the bot,
+so the telegram chat works with the same account
+*/
+func (h *Handler) createLinkCode(c *gin.Context) {
+	const op = "delivery.http.v1.telegram_link.createLinkCode"
+
+	if c.Param("client") != models.WebClient {
+		newErrorResponse(c, http.StatusBadRequest, "link codes are issued to web users only")
+		h.logger(c).Error(fmt.Sprintf("%s: error", op), "link code is requested by a telegram client")
+		return
+	}
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	linkCode, err := h.services.TelegramLink.CreateLinkCode(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to create a link code", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, linkCode)
+}
+
+// linkTelegram is called by the bot when a user sends it a link code
+func (h *Handler) linkTelegram(c *gin.Context) {
+	const op = "delivery.http.v1.telegram_link.linkTelegram"
+
+	var input models.TelegramLinkInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	link, err := h.services.TelegramLink.Link(c.Request.Context(), input)
+	if errors.Is(err, service.ErrLinkCodeInvalid) {
+		newErrorResponse(c, http.StatusBadRequest, service.ErrLinkCodeInvalid.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid link code", op), sl.Err(err))
+		return
+	}
+	if errors.Is(err, service.ErrTelegramAlreadyLinked) {
+		newErrorResponse(c, http.StatusConflict, service.ErrTelegramAlreadyLinked.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: user has another telegram account", op), sl.Err(err))
+		return
+	}
+	if errors.Is(err, service.ErrAccountDeleted) {
+		newErrorResponse(c, http.StatusForbidden, service.ErrAccountDeleted.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: link to a deleted account", op), sl.Err(err))
+		return
+	}
+	if errors.Is(err, service.ErrTelegramLinkedElsewhere) {
+		newErrorResponse(c, http.StatusConflict, service.ErrTelegramLinkedElsewhere.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: telegram account belongs to another user", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to link telegram account", op), sl.Err(err))
+		return
+	}
+
+	h.logger(c).Info(
+		fmt.Sprintf("%s: telegram account is linked", op),
+		slog.Int("user id", link.UserId),
+		slog.Int("merged user id", link.MergedUserId),
+	)
+
+	c.JSON(http.StatusOK, link)
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_handler_linkTelegram(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTelegramLink, input models.TelegramLinkInput)
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		input                models.TelegramLinkInput
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"code": "ABCD2345", "tg_user_id": 42, "tg_user_name": "tg_test"}`,
+			input:     models.TelegramLinkInput{Code: "ABCD2345", TgUserId: 42, TgUsername: "tg_test"},
+			mockBehavior: func(s *mock_service.MockTelegramLink, input models.TelegramLinkInput) {
+				s.EXPECT().Link(gomock.Any(), input).Return(models.TelegramLink{UserId: 1, MergedUserId: 7}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"userId":1,"mergedUserId":7}`,
+		},
+		{
+			name:                 "Missing code",
+			inputBody:            `{"tg_user_id": 42}`,
+			mockBehavior:         func(s *mock_service.MockTelegramLink, input models.TelegramLinkInput) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"Key: 'TelegramLinkInput.Code' Error:Field validation for 'Code' failed on the 'required' tag"}`,
+		},
+		{
+			name:      "Expired code",
+			inputBody: `{"code": "ABCD2345", "tg_user_id": 42}`,
+			input:     models.TelegramLinkInput{Code: "ABCD2345", TgUserId: 42},
+			mockBehavior: func(s *mock_service.MockTelegramLink, input models.TelegramLinkInput) {
+				s.EXPECT().Link(gomock.Any(), input).Return(models.TelegramLink{}, fmt.Errorf("link: %w", service.ErrLinkCodeInvalid))
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"message":"link code is invalid or expired"}`,
+		},
+		{
+			name:      "Telegram account of another user",
+			inputBody: `{"code": "ABCD2345", "tg_user_id": 42}`,
+			input:     models.TelegramLinkInput{Code: "ABCD2345", TgUserId: 42},
+			mockBehavior: func(s *mock_service.MockTelegramLink, input models.TelegramLinkInput) {
+				s.EXPECT().Link(gomock.Any(), input).Return(models.TelegramLink{}, fmt.Errorf("link: %w", service.ErrTelegramLinkedElsewhere))
+			},
+			expectedStatusCode:   409,
+			expectedResponseBody: `{"message":"telegram account is linked to another user"}`,
+		},
+		{
+			name:      "Deleted account",
+			inputBody: `{"code": "ABCD2345", "tg_user_id": 42}`,
+			input:     models.TelegramLinkInput{Code: "ABCD2345", TgUserId: 42},
+			mockBehavior: func(s *mock_service.MockTelegramLink, input models.TelegramLinkInput) {
+				s.EXPECT().Link(gomock.Any(), input).Return(models.TelegramLink{}, fmt.Errorf("link: %w", service.ErrAccountDeleted))
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"message":"account is deleted"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			link := mock_service.NewMockTelegramLink(c)
+			testCase.mockBehavior(link, testCase.input)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{TelegramLink: link}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/link", handler.linkTelegram)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(
+				"POST", "/link",
+				bytes.NewBufferString(testCase.inputBody),
+			)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+NewRequestTimeout puts a deadline on the context of every request. The
+context is passed down to the services and the repositories, so queries
+of a request that takes too long or which client is gone are cancelled.
+A zero timeout turns the deadline off
+*/
+func NewRequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
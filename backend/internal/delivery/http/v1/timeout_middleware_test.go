@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_NewRequestTimeout(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockHabit)
+
+	testTable := []struct {
+		name                 string
+		timeout              time.Duration
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:    "OK",
+			timeout: time.Second,
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().GetById(gomock.Any(), 1, 3).DoAndReturn(func(ctx context.Context, userId, habitId int) (models.Habit, error) {
+					if _, ok := ctx.Deadline(); !ok {
+						return models.Habit{}, fmt.Errorf("no deadline")
+					}
+
+					return models.Habit{Id: 3, Title: "running"}, nil
+				})
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"habitId":3,"title":"running","description":"","version":0}`,
+		},
+		{
+			name:    "Deadline Exceeded",
+			timeout: 10 * time.Millisecond,
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().GetById(gomock.Any(), 1, 3).DoAndReturn(func(ctx context.Context, userId, habitId int) (models.Habit, error) {
+					<-ctx.Done()
+					return models.Habit{}, fmt.Errorf("repository.postgres.GetById: %w", ctx.Err())
+				})
+			},
+			expectedStatusCode:   504,
+			expectedResponseBody: `{"message":"error: habit not found: repository.postgres.GetById: context deadline exceeded","code":"timeout"}`,
+		},
+		{
+			name:    "No Deadline",
+			timeout: 0,
+			mockBehavior: func(s *mock_service.MockHabit) {
+				s.EXPECT().GetById(gomock.Any(), 1, 3).DoAndReturn(func(ctx context.Context, userId, habitId int) (models.Habit, error) {
+					if _, ok := ctx.Deadline(); ok {
+						return models.Habit{}, fmt.Errorf("unexpected deadline")
+					}
+
+					return models.Habit{Id: 3, Title: "running"}, nil
+				})
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"habitId":3,"title":"running","description":"","version":0}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			habit := mock_service.NewMockHabit(c)
+			testCase.mockBehavior(habit)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Habit: habit}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.Use(NewRequestTimeout(testCase.timeout))
+			r.GET("/habits/:habitId", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getHabitById)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/habits/3", nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
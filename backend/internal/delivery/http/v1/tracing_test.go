@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
+	mock_service "github.com/aidos-dev/habit-tracker/backend/internal/service/mocks"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_tracing(t *testing.T) {
+	const (
+		traceId      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanId = "00f067aa0ba902b7"
+	)
+
+	testTable := []struct {
+		name               string
+		traceparent        string
+		expectedName       string
+		expectedParent     bool
+		expectedStatusCode int
+	}{
+		{
+			name:               "Trace Of A Caller",
+			traceparent:        "00-" + traceId + "-" + parentSpanId + "-01",
+			expectedName:       "GET /habits/:habitId",
+			expectedParent:     true,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "New Trace",
+			expectedName:       "GET /habits/:habitId",
+			expectedStatusCode: 200,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Tracing
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			otel.SetTracerProvider(provider)
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			habit := mock_service.NewMockHabit(c)
+			habit.EXPECT().GetById(gomock.Any(), 1, 3).Return(models.Habit{Id: 3, Title: "running"}, nil)
+
+			log := slogdiscard.NewDiscardLogger()
+
+			services := &service.Service{Habit: habit}
+			handler := NewHandler(log, services)
+
+			// Init Endpoint
+			r := gin.New()
+			r.Use(tracing.Gin())
+			r.GET("/habits/:habitId", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getHabitById)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/habits/3", nil)
+			if testCase.traceparent != "" {
+				req.Header.Set("traceparent", testCase.traceparent)
+			}
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("Expected 1 span but got: %d", len(spans))
+			}
+
+			span := spans[0]
+
+			if span.Name() != testCase.expectedName {
+				t.Errorf("Expected span name '%s' but got '%s'", testCase.expectedName, span.Name())
+			}
+
+			if span.Parent().IsValid() != testCase.expectedParent {
+				t.Errorf("Expected parent: %v but got: %v", testCase.expectedParent, span.Parent().IsValid())
+			}
+
+			if testCase.expectedParent && span.SpanContext().TraceID().String() != traceId {
+				t.Errorf("Expected trace id '%s' but got '%s'", traceId, span.SpanContext().TraceID())
+			}
+		})
+	}
+}
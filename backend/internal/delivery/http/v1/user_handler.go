@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/service"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/exp/slog"
@@ -16,37 +18,38 @@ func (h *Handler) getUserById(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
-	user, err := h.services.User.GetUserById(userId)
+	user, err := h.services.User.GetUserById(c.Request.Context(), userId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: a user not found: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to find a user by Id", op), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: a user not found: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to find a user by Id", op), sl.Err(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
 
-type getAllUsersResponse struct {
-	Data []models.GetUser `json:"data"`
-}
-
 func (h *Handler) getAllUsers(c *gin.Context) {
 	const op = "delivery.http.v1.user_handler.getAllUsers"
 
-	users, err := h.services.User.GetAllUsers()
+	params, err := getListParams(c, models.UserListFields)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get all users: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get all users", op), sl.Err(err))
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("error: invalid list params: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: invalid list params", op), sl.Err(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, getAllUsersResponse{
-		Data: users,
-	})
+	users, total, err := h.services.User.GetAllUsers(c.Request.Context(), params)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get all users: %v", err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get all users", op), sl.Err(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewPage(users, total, params))
 }
 
 func (h *Handler) deleteUser(c *gin.Context) {
@@ -55,18 +58,24 @@ func (h *Handler) deleteUser(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
 		return
 	}
 
-	deletedUserId, err := h.services.User.DeleteUser(userId)
+	var deletedUserId int
+
+	if meta, metaErr := getAuditMeta(c); metaErr == nil {
+		deletedUserId, err = h.services.AdminUser.DeleteUser(c.Request.Context(), meta, userId)
+	} else {
+		deletedUserId, err = h.services.User.DeleteUser(c.Request.Context(), userId)
+	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("%s: failed to delete a user by id: %d: %s", op, userId, err.Error()))
-		h.log.Error(fmt.Sprintf("%s: failed to delete a user by id: %d", op, userId), sl.Err(err))
+		newErrorResponseErr(c, http.StatusInternalServerError, fmt.Sprintf("%s: failed to delete a user by id: %d: %s", op, userId, err.Error()), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to delete a user by id: %d", op, userId), sl.Err(err))
 		return
 	}
 
-	h.log.Info(fmt.Sprintf("%s: user is deleted", op), slog.Int("user id", deletedUserId))
+	h.logger(c).Info(fmt.Sprintf("%s: user is deleted", op), slog.Int("user id", deletedUserId))
 
 	// c.JSON(http.StatusOK, map[string]interface{}{
 	// 	"Status": statusResponse{
@@ -82,3 +91,79 @@ func (h *Handler) deleteUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// restoreUser restores an account deleted by its owner during the grace period
+func (h *Handler) restoreUser(c *gin.Context) {
+	const op = "delivery.http.v1.user_handler.restoreUser"
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	meta, err := getAuditMeta(c)
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get audit meta", op), sl.Err(err))
+		return
+	}
+
+	restoredUserId, err := h.services.AdminUser.RestoreUser(c.Request.Context(), meta, userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusConflict, fmt.Sprintf("%s: failed to restore a user by id: %d: %s", op, userId, err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to restore a user by id: %d", op, userId), sl.Err(err))
+		return
+	}
+
+	h.logger(c).Info(fmt.Sprintf("%s: user is restored", op), slog.Int("user id", restoredUserId))
+
+	response := map[string]any{
+		"Status":          "ok",
+		"restored userId": restoredUserId,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) changePassword(c *gin.Context) {
+	const op = "delivery.http.v1.user_handler.changePassword"
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("error: failed to get user Id: %v", err.Error()))
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get user Id", op), sl.Err(err))
+		return
+	}
+
+	var input models.ChangePasswordInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: failed to get JSON object", op), sl.Err(err))
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		h.logger(c).Error(fmt.Sprintf("%s: invalid input", op), sl.Err(err))
+		return
+	}
+
+	err = h.services.Authorization.ChangePassword(c.Request.Context(), userId, input)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		newErrorResponse(c, http.StatusForbidden, "old password is wrong")
+		h.logger(c).Error(fmt.Sprintf("%s: old password is wrong", op), sl.Err(err))
+		return
+	}
+	if err != nil {
+		newErrorResponseErr(c, http.StatusInternalServerError, err.Error(), err)
+		h.logger(c).Error(fmt.Sprintf("%s: failed to change password", op), sl.Err(err))
+		return
+	}
+
+	h.logger(c).Info(fmt.Sprintf("%s: password is changed", op), slog.Int("user id", userId))
+
+	c.JSON(http.StatusOK, statusResponse{Status: "ok"})
+}
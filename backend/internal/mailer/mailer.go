@@ -0,0 +1,15 @@
+package mailer
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+/*
+Mailer sends emails to users. The service layer depends only on
+this interface, so SMTP can be replaced with any other provider
+*/
+type Mailer interface {
+	Send(msg Message) error
+}
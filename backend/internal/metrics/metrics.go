@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// domain counters of the backend, the HTTP metrics are in pkg/metrics
+var (
+	HabitsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "habits_created_total",
+		Help: "Number of habits created",
+	})
+
+	CheckInsRecorded = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "check_ins_recorded_total",
+		Help: "Number of check-ins recorded to habit trackers",
+	})
+)
+
+/*
+PoolCollector reports the stats of a pgx pool. The stats are read
+from the pool on every scrape, so nothing is updated in between
+*/
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns   *prometheus.Desc
+	idleConns       *prometheus.Desc
+	totalConns      *prometheus.Desc
+	maxConns        *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+	canceledAcquire *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("pgxpool_"+name, help, nil, nil)
+	}
+
+	return &PoolCollector{
+		pool:            pool,
+		acquiredConns:   desc("acquired_conns", "Number of connections currently acquired from the pool"),
+		idleConns:       desc("idle_conns", "Number of idle connections in the pool"),
+		totalConns:      desc("total_conns", "Number of connections in the pool"),
+		maxConns:        desc("max_conns", "Maximum size of the pool"),
+		acquireCount:    desc("acquire_total", "Number of successful acquires from the pool"),
+		acquireDuration: desc("acquire_duration_seconds_total", "Total time spent acquiring connections"),
+		emptyAcquire:    desc("empty_acquire_total", "Number of acquires which waited for a connection as the pool was empty"),
+		canceledAcquire: desc("canceled_acquire_total", "Number of acquires canceled by a context"),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquire
+	ch <- c.canceledAcquire
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
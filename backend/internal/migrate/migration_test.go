@@ -0,0 +1,132 @@
+package migrate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func Test_Load(t *testing.T) {
+	testTable := []struct {
+		name               string
+		fsys               fstest.MapFS
+		expectedMigrations []Migration
+		expectedErr        string
+	}{
+		{
+			name: "Sorted By Version",
+			fsys: fstest.MapFS{
+				"000010_rbac.up.sql":     {Data: []byte("up 10")},
+				"000010_rbac.down.sql":   {Data: []byte("down 10")},
+				"000002_search.up.sql":   {Data: []byte("up 2")},
+				"000002_search.down.sql": {Data: []byte("down 2")},
+				"000001_init.up.sql":     {Data: []byte("up 1")},
+				"000001_init.down.sql":   {Data: []byte("down 1")},
+			},
+			expectedMigrations: []Migration{
+				{Version: 1, Name: "init", Up: "up 1", Down: "down 1"},
+				{Version: 2, Name: "search", Up: "up 2", Down: "down 2"},
+				{Version: 10, Name: "rbac", Up: "up 10", Down: "down 10"},
+			},
+		},
+		{
+			// the names sort as 10, 1, 2, the versions are compared as numbers
+			name: "Versions Without Zeros",
+			fsys: fstest.MapFS{
+				"10_rbac.up.sql":    {Data: []byte("up 10")},
+				"10_rbac.down.sql":  {Data: []byte("down 10")},
+				"2_search.up.sql":   {Data: []byte("up 2")},
+				"2_search.down.sql": {Data: []byte("down 2")},
+				"1_init.up.sql":     {Data: []byte("up 1")},
+				"1_init.down.sql":   {Data: []byte("down 1")},
+			},
+			expectedMigrations: []Migration{
+				{Version: 1, Name: "init", Up: "up 1", Down: "down 1"},
+				{Version: 2, Name: "search", Up: "up 2", Down: "down 2"},
+				{Version: 10, Name: "rbac", Up: "up 10", Down: "down 10"},
+			},
+		},
+		{
+			name: "Other Files",
+			fsys: fstest.MapFS{
+				"000001_init.up.sql":        {Data: []byte("up 1")},
+				"000001_init.down.sql":      {Data: []byte("down 1")},
+				"migrations.go":             {Data: []byte("package migrations")},
+				"README.md":                 {Data: []byte("docs")},
+				"sqlite/000001_init.up.sql": {Data: []byte("sqlite up 1")},
+			},
+			expectedMigrations: []Migration{
+				{Version: 1, Name: "init", Up: "up 1", Down: "down 1"},
+			},
+		},
+		{
+			name:               "Empty",
+			fsys:               fstest.MapFS{},
+			expectedMigrations: []Migration{},
+		},
+		{
+			name: "No Down Script",
+			fsys: fstest.MapFS{
+				"000001_init.up.sql":   {Data: []byte("up 1")},
+				"000001_init.down.sql": {Data: []byte("down 1")},
+				"000002_search.up.sql": {Data: []byte("up 2")},
+			},
+			expectedErr: "migration 2_search has no up or down script",
+		},
+		{
+			name: "No Up Script",
+			fsys: fstest.MapFS{
+				"000001_init.down.sql": {Data: []byte("down 1")},
+			},
+			expectedErr: "migration 1_init has no up or down script",
+		},
+		{
+			name: "Empty Script",
+			fsys: fstest.MapFS{
+				"000001_init.up.sql":   {Data: []byte("up 1")},
+				"000001_init.down.sql": {Data: []byte("")},
+			},
+			expectedErr: "migration 1_init has no up or down script",
+		},
+		{
+			name: "Two Names",
+			fsys: fstest.MapFS{
+				"000001_init.up.sql":     {Data: []byte("up 1")},
+				"000001_init.down.sql":   {Data: []byte("down 1")},
+				"000001_search.up.sql":   {Data: []byte("up 1")},
+				"000001_search.down.sql": {Data: []byte("down 1")},
+			},
+			expectedErr: "version 1 has two names: init and search",
+		},
+		{
+			name: "Zero Version",
+			fsys: fstest.MapFS{
+				"000000_init.up.sql":   {Data: []byte("up 0")},
+				"000000_init.down.sql": {Data: []byte("down 0")},
+			},
+			expectedErr: "invalid version of 000000_init",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			migrations, err := Load(testCase.fsys)
+
+			if testCase.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedErr) {
+					t.Fatalf("Expected error '%s' but got %v", testCase.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(migrations, testCase.expectedMigrations) {
+				t.Errorf("Expected migrations %+v but got %+v", testCase.expectedMigrations, migrations)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+/*
+lockKey is the key of the advisory lock taken while migrations run,
+so backend instances started at the same time don't apply them twice
+*/
+const lockKey int64 = 0x68616269745f6d67 // "habit_mg"
+
+var (
+	// ErrDirty means a migration failed half way and the schema has to be fixed by hand
+	ErrDirty = errors.New("database is dirty: fix the schema and force the version")
+	// ErrUnknownVersion means the version is not among the migrations of the binary
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+/*
+the table is the same golang-migrate uses, so a database migrated
+with the CLI before goes on with the versions it already has
+*/
+const createVersionTable = `CREATE TABLE IF NOT EXISTS
+								schema_migrations (
+									version bigint NOT NULL PRIMARY KEY,
+									dirty boolean NOT NULL
+								)`
+
+// Status is the state of the schema of a database
+type Status struct {
+	// Version is the last applied migration, 0 if none is applied
+	Version int
+	Dirty   bool
+	// Latest is the version of the last migration of the binary
+	Latest  int
+	Pending []Migration
+}
+
+type Migrator struct {
+	log        *slog.Logger
+	dbpool     *pgxpool.Pool
+	migrations []Migration
+}
+
+func NewMigrator(log *slog.Logger, dbpool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	const op = "migrate.migrator.NewMigrator"
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{log: log, dbpool: dbpool, migrations: migrations}, nil
+}
+
+// Up applies all the pending migrations and returns how many of them were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	const op = "migrate.migrator.Up"
+
+	applied := 0
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := getVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrDirty, version)
+		}
+
+		for _, migration := range m.pending(version) {
+			if err := m.apply(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			m.log.Info("migration is applied", slog.Int("version", migration.Version), slog.String("name", migration.Name))
+			applied++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return applied, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the last steps migrations and returns how many of them were rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	const op = "migrate.migrator.Down"
+
+	reverted := 0
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := getVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrDirty, version)
+		}
+
+		rollbacks, err := m.downPlan(version, steps)
+		if err != nil {
+			return err
+		}
+
+		for _, rollback := range rollbacks {
+			migration := rollback.Migration
+
+			if err := m.apply(ctx, conn, migration.Down, rollback.Previous); err != nil {
+				return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			m.log.Info("migration is rolled back", slog.Int("version", migration.Version), slog.String("name", migration.Name))
+			reverted++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return reverted, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reverted, nil
+}
+
+/*
+Status only reads the database, it is run by the readiness probe.
+A database without the table of versions is at version 0
+*/
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	const op = "migrate.migrator.Status"
+
+	var status Status
+
+	if len(m.migrations) > 0 {
+		status.Latest = m.migrations[len(m.migrations)-1].Version
+	}
+
+	conn, err := m.dbpool.Acquire(ctx)
+	if err != nil {
+		return status, fmt.Errorf("%s: %w", op, err)
+	}
+	defer conn.Release()
+
+	var tableExists bool
+	if err := conn.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&tableExists); err != nil {
+		return status, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tableExists {
+		status.Version, status.Dirty, err = getVersion(ctx, conn)
+		if err != nil {
+			return status, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	status.Pending = m.pending(status.Version)
+
+	return status, nil
+}
+
+/*
+Force sets the version without running migrations and clears the dirty
+flag. It is used after a failed migration is fixed by hand, or to adopt
+a database which schema was created without the migrator
+*/
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	const op = "migrate.migrator.Force"
+
+	if err := m.checkVersion(version); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := setVersion(ctx, tx, version); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+/*
+apply runs a script and sets the version in one transaction, so a failed
+script leaves neither its changes nor a dirty version behind
+*/
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, script string, version int) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return err
+	}
+
+	if err := setVersion(ctx, tx, version); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+/*
+withLock runs fn on a connection holding the advisory lock. Other
+instances wait for the lock and then find the migrations applied
+*/
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.dbpool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+
+	defer func() {
+		// the lock is released with the session if the unlock fails
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			m.log.Error("failed to release migration lock", sl.Err(err))
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, createVersionTable); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// rollback is a migration to roll back and the version it leaves the database at
+type rollback struct {
+	Migration
+	Previous int
+}
+
+// downPlan returns the migrations rolled back by Down from version, the last one first
+func (m *Migrator) downPlan(version, steps int) ([]rollback, error) {
+	if err := m.checkVersion(version); err != nil {
+		return nil, err
+	}
+
+	var rollbacks []rollback
+
+	for current := m.index(version); current >= 0 && len(rollbacks) < steps; current-- {
+		previous := 0
+		if current > 0 {
+			previous = m.migrations[current-1].Version
+		}
+
+		rollbacks = append(rollbacks, rollback{Migration: m.migrations[current], Previous: previous})
+	}
+
+	return rollbacks, nil
+}
+
+// pending returns the migrations applied by Up to a database at version
+func (m *Migrator) pending(version int) []Migration {
+	var pending []Migration
+
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending
+}
+
+// checkVersion tells if the database can be at version: 0 or a version of a migration
+func (m *Migrator) checkVersion(version int) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return nil
+}
+
+func (m *Migrator) index(version int) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func getVersion(ctx context.Context, conn *pgxpool.Conn) (int, bool, error) {
+	var (
+		version int
+		dirty   bool
+	)
+
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
+
+// setVersion keeps a single row in the table, as golang-migrate does
+func setVersion(ctx context.Context, tx pgx.Tx, version int) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version)
+
+	return err
+}
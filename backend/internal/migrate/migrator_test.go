@@ -0,0 +1,229 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testMigrations are versions 1, 2 and 5, there is a gap like after a removed migration
+var testMigrations = []Migration{
+	{Version: 1, Name: "init", Up: "CREATE TABLE habit (id int)", Down: "DROP TABLE habit"},
+	{Version: 2, Name: "title", Up: "ALTER TABLE habit ADD COLUMN title text", Down: "ALTER TABLE habit DROP COLUMN title"},
+	{Version: 5, Name: "version", Up: "ALTER TABLE habit ADD COLUMN version int", Down: "ALTER TABLE habit DROP COLUMN version"},
+}
+
+func Test_Migrator_downPlan(t *testing.T) {
+	m := &Migrator{migrations: testMigrations}
+
+	testTable := []struct {
+		name              string
+		version           int
+		steps             int
+		expectedRollbacks []rollback
+		expectedErr       error
+	}{
+		{
+			name:              "Last Migration",
+			version:           5,
+			steps:             1,
+			expectedRollbacks: []rollback{{Migration: testMigrations[2], Previous: 2}},
+		},
+		{
+			name:    "Several Migrations",
+			version: 5,
+			steps:   2,
+			expectedRollbacks: []rollback{
+				{Migration: testMigrations[2], Previous: 2},
+				{Migration: testMigrations[1], Previous: 1},
+			},
+		},
+		{
+			name:    "More Steps Than Migrations",
+			version: 2,
+			steps:   10,
+			expectedRollbacks: []rollback{
+				{Migration: testMigrations[1], Previous: 1},
+				{Migration: testMigrations[0], Previous: 0},
+			},
+		},
+		{
+			name:              "Empty Database",
+			version:           0,
+			steps:             1,
+			expectedRollbacks: nil,
+		},
+		{
+			name:        "Unknown Version",
+			version:     3,
+			steps:       1,
+			expectedErr: ErrUnknownVersion,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			rollbacks, err := m.downPlan(testCase.version, testCase.steps)
+
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("Expected error %v but got %v", testCase.expectedErr, err)
+			}
+
+			if !reflect.DeepEqual(rollbacks, testCase.expectedRollbacks) {
+				t.Errorf("Expected rollbacks %+v but got %+v", testCase.expectedRollbacks, rollbacks)
+			}
+		})
+	}
+}
+
+func Test_Migrator_pending(t *testing.T) {
+	m := &Migrator{migrations: testMigrations}
+
+	testTable := []struct {
+		name            string
+		version         int
+		expectedPending []Migration
+	}{
+		{name: "Empty Database", version: 0, expectedPending: testMigrations},
+		{name: "Part Applied", version: 2, expectedPending: testMigrations[2:]},
+		{name: "Between Versions", version: 3, expectedPending: testMigrations[2:]},
+		{name: "All Applied", version: 5, expectedPending: nil},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			pending := m.pending(testCase.version)
+
+			if !reflect.DeepEqual(pending, testCase.expectedPending) {
+				t.Errorf("Expected pending %+v but got %+v", testCase.expectedPending, pending)
+			}
+		})
+	}
+}
+
+func Test_Migrator_checkVersion(t *testing.T) {
+	m := &Migrator{migrations: testMigrations}
+
+	testTable := []struct {
+		name        string
+		version     int
+		expectedErr error
+	}{
+		{name: "No Migrations", version: 0},
+		{name: "First", version: 1},
+		{name: "Last", version: 5},
+		{name: "Gap", version: 3, expectedErr: ErrUnknownVersion},
+		{name: "Above Last", version: 6, expectedErr: ErrUnknownVersion},
+		{name: "Negative", version: -1, expectedErr: ErrUnknownVersion},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			if err := m.checkVersion(testCase.version); !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("Expected error %v but got %v", testCase.expectedErr, err)
+			}
+		})
+	}
+}
+
+/*
+Test_Migrator_postgres runs the migrations up, down and forces a version
+on a new schema of the database at TEST_POSTGRES_URL, it is skipped without it
+*/
+func Test_Migrator_postgres(t *testing.T) {
+	databaseURL := os.Getenv("TEST_POSTGRES_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("test_migrate_%d", time.Now().UnixNano())
+
+	admin, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	t.Cleanup(admin.Close)
+
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE") })
+
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("invalid TEST_POSTGRES_URL: %v", err)
+	}
+	poolConfig.ConnConfig.RuntimeParams["search_path"] = schema
+
+	dbpool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	t.Cleanup(dbpool.Close)
+
+	fsys := fstest.MapFS{}
+	for _, migration := range testMigrations {
+		fsys[fmt.Sprintf("%06d_%s.up.sql", migration.Version, migration.Name)] = &fstest.MapFile{Data: []byte(migration.Up)}
+		fsys[fmt.Sprintf("%06d_%s.down.sql", migration.Version, migration.Name)] = &fstest.MapFile{Data: []byte(migration.Down)}
+	}
+
+	m, err := NewMigrator(slogdiscard.NewDiscardLogger(), dbpool, fsys)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	expectVersion := func(expected int) {
+		t.Helper()
+
+		status, err := m.Status(ctx)
+		if err != nil {
+			t.Fatalf("failed to get status: %v", err)
+		}
+
+		if status.Version != expected || status.Dirty {
+			t.Fatalf("Expected version %d but got %d, dirty: %v", expected, status.Version, status.Dirty)
+		}
+	}
+
+	// the status of a new database is read without creating the table of versions
+	expectVersion(0)
+
+	var tableExists bool
+	if err := dbpool.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&tableExists); err != nil || tableExists {
+		t.Fatalf("Expected no table of versions, exists: %v: %v", tableExists, err)
+	}
+
+	if applied, err := m.Up(ctx); err != nil || applied != 3 {
+		t.Fatalf("Expected 3 migrations applied but got %d: %v", applied, err)
+	}
+	expectVersion(5)
+
+	if reverted, err := m.Down(ctx, 2); err != nil || reverted != 2 {
+		t.Fatalf("Expected 2 migrations rolled back but got %d: %v", reverted, err)
+	}
+	expectVersion(1)
+
+	if err := m.Force(ctx, 3); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("Expected error %v but got %v", ErrUnknownVersion, err)
+	}
+
+	if err := m.Force(ctx, 0); err != nil {
+		t.Fatalf("failed to force version: %v", err)
+	}
+	expectVersion(0)
+
+	// the table of version 1 is left, so the first migration fails and nothing is applied
+	if applied, err := m.Up(ctx); err == nil || applied != 0 {
+		t.Fatalf("Expected the first migration to fail but got %d applied: %v", applied, err)
+	}
+	expectVersion(0)
+}
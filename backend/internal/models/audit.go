@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditRoleAssign       = "role.assign"
+	AuditRoleCreate       = "role.create"
+	AuditRoleUpdate       = "role.update"
+	AuditRoleDelete       = "role.delete"
+	AuditRewardCreate     = "reward.create"
+	AuditRewardUpdate     = "reward.update"
+	AuditRewardDelete     = "reward.delete"
+	AuditUserRewardAssign = "user_reward.assign"
+	AuditUserRewardUpdate = "user_reward.update"
+	AuditUserRewardRemove = "user_reward.remove"
+	AuditHabitCreate      = "habit.create"
+	AuditHabitUpdate      = "habit.update"
+	AuditHabitDelete      = "habit.delete"
+	AuditTrackerUpdate    = "tracker.update"
+	AuditUserDelete       = "user.delete"
+	AuditUserRestore      = "user.restore"
+	AuditUserPurge        = "user.purge"
+	AuditTelegramLink     = "user.telegram_link"
+)
+
+// SystemActorId is the actor of changes made by background jobs
+const SystemActorId = 0
+
+/*
+AuditMeta describes who made an administrative change and within
+which request. It is passed to every admin-scoped service method
+*/
+type AuditMeta struct {
+	ActorId   int
+	RequestId string
+}
+
+/*
+AuditEntry is a single record of the append-only audit log.
+TargetUserId is 0 when a change is not related to a certain user
+(for example a reward is added to the common reward table)
+*/
+type AuditEntry struct {
+	Id           int             `json:"id" db:"id"`
+	ActorId      int             `json:"actorId" db:"actor_id"`
+	TargetUserId int             `json:"targetUserId" db:"target_user_id"`
+	Action       string          `json:"action" db:"action"`
+	Before       json.RawMessage `json:"before" db:"before"`
+	After        json.RawMessage `json:"after" db:"after"`
+	RequestId    string          `json:"requestId" db:"request_id"`
+	CreatedAt    time.Time       `json:"createdAt" db:"created_at"`
+}
+
+var AuditListFields = ListFields{
+	Sort: []string{"id", "created_at"},
+	Filter: map[string]FilterKind{
+		"actor_id":       FilterInt,
+		"target_user_id": FilterInt,
+		"action":         FilterExact,
+		"request_id":     FilterExact,
+	},
+}
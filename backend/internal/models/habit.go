@@ -9,6 +9,7 @@ type Habit struct {
 	Id          int    `json:"habitId" db:"id"`
 	Title       string `json:"title" db:"title" binding:"required"`
 	Description string `json:"description" db:"description"`
+	Version     int    `json:"version" db:"version"`
 }
 
 type UsersHabits struct {
@@ -28,11 +29,17 @@ type HabitTracker struct {
 	EndDate       time.Time `json:"end_date" db:"end_date"`
 	Counter       int       `json:"counter" db:"counter"`
 	Done          bool      `json:"done" db:"done"`
+	Version       int       `json:"version" db:"version"`
 }
 
 type UpdateHabitInput struct {
 	Title       *string `json:"title"`
 	Description *string `json:"description"`
+	/*
+		Versions are taken from the If-Match header, the update is rejected
+		if the habit has none of them. Nil means any version
+	*/
+	Versions []int `json:"-"`
 }
 
 func (i UpdateHabitInput) Validate() error {
@@ -51,6 +58,11 @@ type UpdateTrackerInput struct {
 	EndDate       *time.Time `json:"end_date"`
 	Counter       *int       `json:"counter"`
 	Done          *bool      `json:"done"`
+	/*
+		Versions are taken from the If-Match header, the update is rejected
+		if the tracker has none of them. Nil means any version
+	*/
+	Versions []int `json:"-"`
 }
 
 func (i UpdateTrackerInput) Validate() error {
@@ -60,3 +72,19 @@ func (i UpdateTrackerInput) Validate() error {
 
 	return nil
 }
+
+var HabitListFields = ListFields{
+	Sort: []string{"id", "title"},
+	Filter: map[string]FilterKind{
+		"title": FilterContains,
+	},
+}
+
+var TrackerListFields = ListFields{
+	Sort: []string{"id", "habit_id", "start_date", "end_date", "counter"},
+	Filter: map[string]FilterKind{
+		"unit_of_messure": FilterExact,
+		"frequency":       FilterExact,
+		"done":            FilterBool,
+	},
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// IdempotencyKeyHeader is sent by clients to make a mutating request safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+/*
+IdempotencyRecord keeps the response to the first request with a key.
+StatusCode is nil while that request is in progress. ResponseHeaders
+are nil in records stored before the headers were kept
+*/
+type IdempotencyRecord struct {
+	UserId          int               `db:"user_id"`
+	Key             string            `db:"idem_key"`
+	RequestHash     string            `db:"request_hash"`
+	StatusCode      *int              `db:"status_code"`
+	ResponseBody    []byte            `db:"response_body"`
+	ResponseHeaders map[string]string `db:"response_headers"`
+	ExpiresAt       time.Time         `db:"expires_at"`
+}
+
+// IdempotentRequest is what tells a repeated request from another one sent with the same key
+type IdempotentRequest struct {
+	Method     string
+	RequestURI string
+	Body       []byte
+}
@@ -0,0 +1,13 @@
+package models
+
+/*
+Identity links a user to an account of an external identity
+provider. Subject is the id of the user given by the provider
+*/
+type Identity struct {
+	Id       int    `json:"id" db:"id"`
+	UserId   int    `json:"userId" db:"user_id"`
+	Provider string `json:"provider" db:"provider"`
+	Subject  string `json:"subject" db:"subject"`
+	Email    string `json:"eMail" db:"email"`
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+type FilterKind int
+
+const (
+	FilterContains FilterKind = iota // case insensitive substring match
+	FilterExact
+	FilterBool
+	FilterInt
+)
+
+/*
+ListFields describes which fields of an entity a list endpoint
+can be sorted and filtered by. Field names are the same names
+that are used in query parameters (sort=title, done=true etc.)
+*/
+type ListFields struct {
+	Sort   []string
+	Filter map[string]FilterKind
+}
+
+type Filter struct {
+	Field string
+	Kind  FilterKind
+	Value string
+}
+
+/*
+ListParams is passed down to every repository GetAll* method.
+Offset is decoded from the cursor of the previous page
+*/
+type ListParams struct {
+	Limit   int
+	Offset  int
+	Sort    string
+	Desc    bool
+	Filters []Filter
+}
+
+func DefaultListParams() ListParams {
+	return ListParams{Limit: DefaultListLimit}
+}
+
+/*
+ParseListParams builds ListParams from raw query values and checks them
+against the fields allowed for the entity. Query values that are neither
+paging/sorting params nor allowed filters are ignored, so other params
+(like tgUser) can live next to them
+*/
+func ParseListParams(query map[string][]string, fields ListFields) (ListParams, error) {
+	params := DefaultListParams()
+
+	if limit := firstValue(query, "limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxListLimit {
+			return params, fmt.Errorf("invalid limit: must be a number between 1 and %d", MaxListLimit)
+		}
+		params.Limit = n
+	}
+
+	if cursor := firstValue(query, "cursor"); cursor != "" {
+		offset, err := DecodeCursor(cursor)
+		if err != nil {
+			return params, err
+		}
+		params.Offset = offset
+	}
+
+	if sortField := firstValue(query, "sort"); sortField != "" {
+		if strings.HasPrefix(sortField, "-") {
+			params.Desc = true
+			sortField = strings.TrimPrefix(sortField, "-")
+		}
+
+		if !contains(fields.Sort, sortField) {
+			return params, fmt.Errorf("invalid sort field: %s", sortField)
+		}
+		params.Sort = sortField
+	}
+
+	filterNames := make([]string, 0, len(fields.Filter))
+	for field := range fields.Filter {
+		filterNames = append(filterNames, field)
+	}
+	sort.Strings(filterNames) // keeps the order of sql arguments stable
+
+	for _, field := range filterNames {
+		kind := fields.Filter[field]
+		value := firstValue(query, field)
+		if value == "" {
+			continue
+		}
+
+		if kind == FilterBool {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return params, fmt.Errorf("invalid value of filter %s: must be true or false", field)
+			}
+			value = strconv.FormatBool(b)
+		}
+
+		if kind == FilterInt {
+			if _, err := strconv.Atoi(value); err != nil {
+				return params, fmt.Errorf("invalid value of filter %s: must be a number", field)
+			}
+		}
+
+		params.Filters = append(params.Filters, Filter{Field: field, Kind: kind, Value: value})
+	}
+
+	return params, nil
+}
+
+/*
+NextCursor returns a cursor of the page following the current one
+or an empty string if the current page is the last one
+*/
+func (p ListParams) NextCursor(count, total int) string {
+	next := p.Offset + count
+	if count == 0 || next >= total {
+		return ""
+	}
+
+	return strconv.Itoa(next)
+}
+
+func DecodeCursor(cursor string) (int, error) {
+	offset, err := strconv.Atoi(cursor)
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid cursor")
+	}
+
+	return offset, nil
+}
+
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor"`
+	Total      int    `json:"total"`
+}
+
+func NewPage[T any](data []T, total int, params ListParams) Page[T] {
+	if data == nil {
+		data = []T{}
+	}
+
+	return Page[T]{
+		Data:       data,
+		NextCursor: params.NextCursor(len(data), total),
+		Total:      total,
+	}
+}
+
+func firstValue(query map[string][]string, key string) string {
+	values := query[key]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(values[0])
+}
+
+func contains(list []string, value string) bool {
+	for _, el := range list {
+		if el == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+/*
+PersonalTokenPrefix starts every personal access token, so the token
+is told apart from a JWT without parsing it
+*/
+const PersonalTokenPrefix = "htpat_"
+
+// scopes of a personal access token, read allows GET requests, write the others
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+/*
+PersonalToken is a long-lived token a user creates for scripts and
+automations. It is stored by its hash only, the token itself is shown
+once when it is created
+*/
+type PersonalToken struct {
+	Id         int        `json:"id" db:"id"`
+	UserId     int        `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"-" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+func (t PersonalToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewPersonalToken is returned once on creation, it is the only time the token is shown
+type NewPersonalToken struct {
+	Token string `json:"token"`
+	PersonalToken
+}
+
+type PersonalTokenInput struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// ExpiresInDays is the lifetime of the token, the default one is used if it is 0
+	ExpiresInDays int `json:"expiresInDays"`
+}
+
+func (i PersonalTokenInput) Validate() error {
+	if len(i.Name) > 100 {
+		return errors.New("token name is longer than 100 characters")
+	}
+
+	if len(i.Scopes) == 0 {
+		return errors.New("token has no scopes")
+	}
+
+	for _, scope := range i.Scopes {
+		if scope != ScopeRead && scope != ScopeWrite {
+			return fmt.Errorf("unknown scope %q: use %q or %q", scope, ScopeRead, ScopeWrite)
+		}
+	}
+
+	if i.ExpiresInDays < 0 {
+		return errors.New("token lifetime can't be negative")
+	}
+
+	return nil
+}
@@ -38,3 +38,10 @@ func (i UpdateUserRewardInput) Validate() error {
 	}
 	return nil
 }
+
+var RewardListFields = ListFields{
+	Sort: []string{"id", "title"},
+	Filter: map[string]FilterKind{
+		"title": FilterContains,
+	},
+}
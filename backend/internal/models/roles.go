@@ -1,12 +1,63 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
 
 const (
 	UserGeneral   = "user_basic"
 	Administrator = "admin"
 )
 
+/*
+permissions checked by the admin routes. A role is a set of them,
+the sets are stored in the database and can be changed by an admin
+*/
+const (
+	PermUsersRead     = "users:read"
+	PermUsersWrite    = "users:write"
+	PermHabitsRead    = "habits:read"
+	PermHabitsWrite   = "habits:write"
+	PermRewardsRead   = "rewards:read"
+	PermRewardsWrite  = "rewards:write"
+	PermRewardsAssign = "rewards:assign"
+	PermRolesRead     = "roles:read"
+	PermRolesWrite    = "roles:write"
+	PermRolesAssign   = "roles:assign"
+	PermAuditRead     = "audit:read"
+	PermSearchAll     = "search:all"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+type Role struct {
+	Name        string   `json:"name" db:"name"`
+	Description string   `json:"description" db:"description"`
+	Permissions []string `json:"permissions" db:"permissions"`
+}
+
+type Permission struct {
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+}
+
+// RoleInput creates a role or changes it, a nil field is left as it is
+type RoleInput struct {
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+
+func (i RoleInput) Validate() error {
+	if !roleNamePattern.MatchString(i.Name) {
+		return fmt.Errorf("invalid role name %q: use 2 to 50 lowercase letters, digits and underscores", i.Name)
+	}
+
+	return nil
+}
+
 type UpdateRoleInput struct {
 	Role *string `json:"role"`
 }
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	SearchEntityHabit  = "habit"
+	SearchEntityReward = "reward"
+)
+
+type SearchInput struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+func (i SearchInput) Validate() error {
+	if strings.TrimSpace(i.Query) == "" {
+		return errors.New("search query is empty")
+	}
+
+	if i.Limit < 1 || i.Limit > MaxListLimit {
+		return fmt.Errorf("invalid limit: must be a number between 1 and %d", MaxListLimit)
+	}
+
+	if i.Offset < 0 {
+		return errors.New("invalid offset: must not be negative")
+	}
+
+	return nil
+}
+
+/*
+SearchHit is a single ranked match. UserId is filled only for
+admin searches across all users, where a habit has to be traced
+back to its owner
+*/
+type SearchHit struct {
+	Id          int     `json:"id" db:"id"`
+	UserId      int     `json:"userId,omitempty" db:"user_id"`
+	Title       string  `json:"title" db:"title"`
+	Description string  `json:"description" db:"description"`
+	Rank        float32 `json:"rank" db:"rank"`
+}
+
+// SearchResult groups ranked matches by entity type
+type SearchResult struct {
+	Habits  []SearchHit `json:"habits"`
+	Rewards []SearchHit `json:"rewards"`
+}
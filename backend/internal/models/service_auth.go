@@ -0,0 +1,15 @@
+package models
+
+/*
+SignedRequest is what a service sends to prove a request is its own,
+see pkg/svcauth for how it is signed
+*/
+type SignedRequest struct {
+	ServiceId  string
+	Timestamp  string
+	Nonce      string
+	Signature  string
+	Method     string
+	RequestURI string
+	Body       []byte
+}
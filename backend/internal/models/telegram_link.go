@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// LinkCode is given to a web user to link a telegram chat to the account
+type LinkCode struct {
+	Code string `json:"code"`
+	// DeepLink opens the bot with the code, it is empty if the bot name is not configured
+	DeepLink  string    `json:"deepLink,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// TelegramLinkInput is sent by the bot when a user enters a link code
+type TelegramLinkInput struct {
+	Code       string `json:"code" binding:"required"`
+	TgUserId   int64  `json:"tg_user_id" binding:"required"`
+	TgUsername string `json:"tg_user_name"`
+}
+
+/*
+TelegramLink is the result of linking. MergedUserId is the telegram-only
+account which habits were moved to the user, it is 0 if there was none
+*/
+type TelegramLink struct {
+	UserId       int `json:"userId"`
+	MergedUserId int `json:"mergedUserId"`
+}
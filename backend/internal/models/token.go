@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Tokens is returned to a web client on sign in and on refresh
+type Tokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+/*
+RefreshToken is stored by its hash only. Every refresh rotates the token:
+the used one is revoked and a new one of the same family is issued.
+A family is started on sign in, so revoking it logs out a single device
+*/
+type RefreshToken struct {
+	Id        int        `db:"id"`
+	UserId    int        `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	FamilyId  string     `db:"family_id"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
@@ -1,45 +1,130 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 type User struct {
 	Id         int    `json:"userId" db:"id"`
 	Username   string `json:"userName" db:"user_name"`
 	TgUsername string `json:"tg_user_name" db:"tg_user_name"`
+	TgUserId   int64  `json:"tg_user_id" db:"tg_user_id"`
 	FirstName  string `json:"firstName" db:"first_name"`
 	LastName   string `json:"lastName" db:"last_name"`
 	Email      string `json:"eMail" db:"email"`
 	Password   string `json:"password" db:"password_hash"`
 	Role       string `json:"role" db:"role" `
+	// DeletedAt is set when a user deleted the account, but it is not purged yet
+	DeletedAt *time.Time `json:"-" db:"deleted_at"`
 }
 
 func (u *User) Validate() error {
-	if u.Username == "" && u.TgUsername == "" {
+	if u.Username == "" && u.TgUserId == 0 {
 		return errors.New("user structure has no values")
 	}
 
-	if u.TgUsername == "" {
+	if u.TgUserId == 0 {
 		if u.Username == "" || u.Email == "" || u.Password == "" {
 			return errors.New("user structure has no values")
 		}
 	}
 
+	if u.Password != "" {
+		if err := ValidatePassword(u.Password); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+)
+
+/*
+ValidatePassword checks a password against the password policy:
+from 8 to 128 characters with at least one letter and one digit
+*/
+func ValidatePassword(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < MinPasswordLength || length > MaxPasswordLength {
+		return fmt.Errorf("password must be from %d to %d characters long", MinPasswordLength, MaxPasswordLength)
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+
+	if !hasLetter || !hasDigit {
+		return errors.New("password must contain at least one letter and one digit")
+	}
+
 	return nil
 }
 
+type ChangePasswordInput struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"eMail" binding:"required"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+func (i ChangePasswordInput) Validate() error {
+	if i.OldPassword == i.NewPassword {
+		return errors.New("new password must differ from the old one")
+	}
+
+	return ValidatePassword(i.NewPassword)
+}
+
 /*
 GetUser struct is userd when admin gets the list of all users.
 The difference with User struct is that GetUser has Id field and
 doesn not include Password field
 */
 type GetUser struct {
-	Id         int    `json:"userId" db:"id"`
-	Username   string `json:"userName" db:"user_name" binding:"required"`
-	TgUsername string `json:"tg_user_name" db:"tg_user_name"`
-	FirstName  string `json:"firstName" db:"first_name" binding:"required"`
-	LastName   string `json:"lastName" db:"last_name" binding:"required"`
-	Email      string `json:"eMail" db:"email" binding:"required"`
-	Role       string `json:"role" db:"role" `
+	Id         int        `json:"userId" db:"id"`
+	Username   string     `json:"userName" db:"user_name" binding:"required"`
+	TgUsername string     `json:"tg_user_name" db:"tg_user_name"`
+	TgUserId   int64      `json:"tg_user_id" db:"tg_user_id"`
+	FirstName  string     `json:"firstName" db:"first_name" binding:"required"`
+	LastName   string     `json:"lastName" db:"last_name" binding:"required"`
+	Email      string     `json:"eMail" db:"email" binding:"required"`
+	Role       string     `json:"role" db:"role" `
+	DeletedAt  *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+var UserListFields = ListFields{
+	Sort: []string{"id", "user_name", "tg_user_name", "role"},
+	Filter: map[string]FilterKind{
+		"user_name":    FilterContains,
+		"tg_user_name": FilterContains,
+		"email":        FilterContains,
+		"role":         FilterExact,
+	},
 }
 
 type TgUser struct {
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets and stale failures are removed
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	// full is the time the bucket is full again, it can be forgotten after that
+	full    time.Time
+	updated time.Time
+}
+
+type failure struct {
+	count       int
+	lockedUntil time.Time
+	updated     time.Time
+}
+
+// MemoryStore keeps limits in the memory of a single backend instance
+type MemoryStore struct {
+	mu            sync.Mutex
+	buckets       map[string]*bucket
+	failures      map[string]*failure
+	failureWindow time.Duration
+	sweptAt       time.Time
+}
+
+/*
+NewMemoryStore creates a store. Failed attempts are forgotten after
+failureWindow without new failures, unless the key is still locked
+*/
+func NewMemoryStore(failureWindow time.Duration) *MemoryStore {
+	return &MemoryStore{
+		buckets:       make(map[string]*bucket),
+		failures:      make(map[string]*failure),
+		failureWindow: failureWindow,
+	}
+}
+
+func (s *MemoryStore) Take(key string, rate float64, burst int) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+
+	b.tokens--
+	b.full = now.Add(time.Duration((float64(burst) - b.tokens) / rate * float64(time.Second)))
+
+	return true, 0
+}
+
+func (s *MemoryStore) Fail(key string, threshold int, baseDelay, maxDelay time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	f, ok := s.failures[key]
+	if !ok {
+		f = &failure{}
+		s.failures[key] = f
+	}
+
+	f.count++
+	f.updated = now
+
+	if f.count < threshold {
+		return 0
+	}
+
+	delay := maxDelay
+	if shift := f.count - threshold; shift < 32 {
+		delay = time.Duration(math.Min(float64(baseDelay)*math.Pow(2, float64(shift)), float64(maxDelay)))
+	}
+
+	f.lockedUntil = now.Add(delay)
+
+	return delay
+}
+
+func (s *MemoryStore) Locked(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.failures[key]
+	if !ok {
+		return 0
+	}
+
+	if left := f.lockedUntil.Sub(time.Now()); left > 0 {
+		return left
+	}
+
+	return 0
+}
+
+func (s *MemoryStore) Reset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+}
+
+// sweep is called with the lock held
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < sweepInterval {
+		return
+	}
+
+	s.sweptAt = now
+
+	for key, b := range s.buckets {
+		if now.After(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+
+	for key, f := range s.failures {
+		if now.After(f.lockedUntil) && now.Sub(f.updated) > s.failureWindow {
+			delete(s.failures, key)
+		}
+	}
+}
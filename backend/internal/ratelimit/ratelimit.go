@@ -0,0 +1,27 @@
+package ratelimit
+
+import "time"
+
+/*
+Store keeps token buckets and failed attempts by key. The service
+layer depends only on this interface, so the in-memory store can be
+replaced with a shared one when the backend runs in several instances
+*/
+type Store interface {
+	/*
+		Take removes a token from the bucket of the key. A bucket holds up to
+		burst tokens and gets rate tokens a second. If the bucket is empty,
+		Take returns false and the time until the next token
+	*/
+	Take(key string, rate float64, burst int) (bool, time.Duration)
+	/*
+		Fail counts a failed attempt. Once there are threshold failures in a row,
+		the key is locked for baseDelay, doubled with every next failure up to
+		maxDelay. It returns how long the key is locked for
+	*/
+	Fail(key string, threshold int, baseDelay, maxDelay time.Duration) time.Duration
+	// Locked returns how long the key is still locked for, 0 if it is not
+	Locked(key string) time.Duration
+	// Reset forgets failed attempts of the key
+	Reset(key string)
+}
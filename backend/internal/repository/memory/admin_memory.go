@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type AdminMemory struct {
+	s *store
+	repository.AdminRole
+	repository.AdminReward
+	repository.AdminUserReward
+}
+
+func NewAdminMemory(s *store) repository.Admin {
+	return &AdminMemory{
+		s:               s,
+		AdminRole:       NewAdminRoleMemory(s),
+		AdminReward:     NewAdminRewardMemory(s),
+		AdminUserReward: NewAdminUserRewardMemory(s),
+	}
+}
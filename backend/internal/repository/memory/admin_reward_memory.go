@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type AdminRewardMemory struct {
+	s *store
+}
+
+func NewAdminRewardMemory(s *store) repository.AdminReward {
+	return &AdminRewardMemory{s: s}
+}
+
+// checkReward checks the unique key of a reward: the title with the description
+func (s *store) checkReward(reward models.Reward) error {
+	for id, other := range s.rewards {
+		if id != reward.Id && other.Title == reward.Title && other.Description == reward.Description {
+			return errUnique
+		}
+	}
+
+	return nil
+}
+
+func (r *AdminRewardMemory) Create(ctx context.Context, reward models.Reward) (int, error) {
+	const op = "repository.memory.admin_reward_memory.Create"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	reward.Id = 0
+	if err := r.s.checkReward(reward); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, rewardTable, dbErr("reward", err))
+	}
+
+	reward.Id = r.s.nextId(rewardTable)
+	r.s.rewards[reward.Id] = &reward
+
+	return reward.Id, nil
+}
+
+func (r *AdminRewardMemory) GetById(ctx context.Context, rewardId int) (models.Reward, error) {
+	const op = "repository.memory.admin_reward_memory.GetById"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	reward, ok := r.s.rewards[rewardId]
+	if !ok {
+		return models.Reward{}, fmt.Errorf("%s: %w", op, dbErr("reward", errNoRows))
+	}
+
+	return *reward, nil
+}
+
+func (r *AdminRewardMemory) GetAllRewards(ctx context.Context, params models.ListParams) ([]models.Reward, int, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	rewards := make([]models.Reward, 0, len(r.s.rewards))
+	for _, reward := range r.s.rewards {
+		rewards = append(rewards, *reward)
+	}
+
+	page, total := listPage(rewards, params, rewardFields)
+
+	return page, total, nil
+}
+
+// Delete removes a reward, it is taken from all users who have it
+func (r *AdminRewardMemory) Delete(ctx context.Context, rewardId int) error {
+	const op = "repository.memory.admin_reward_memory.Delete"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.rewards[rewardId]; !ok {
+		return fmt.Errorf("%s: %w", op, dbErr("reward", errNoRows))
+	}
+
+	delete(r.s.rewards, rewardId)
+
+	for id, userReward := range r.s.userRewards {
+		if userReward.RewardId == rewardId {
+			delete(r.s.userRewards, id)
+		}
+	}
+
+	return nil
+}
+
+func (r *AdminRewardMemory) UpdateReward(ctx context.Context, rewardId int, input models.UpdateRewardInput) error {
+	const op = "repository.memory.admin_reward_memory.UpdateReward"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	reward, ok := r.s.rewards[rewardId]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, dbErr("reward", errNoRows))
+	}
+
+	updated := *reward
+	if input.Title != nil {
+		updated.Title = *input.Title
+	}
+	if input.Description != nil {
+		updated.Description = *input.Description
+	}
+
+	if err := r.s.checkReward(updated); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, rewardTable, dbErr("reward", err))
+	}
+
+	*reward = updated
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type AdminRoleMemory struct {
+	s *store
+}
+
+func NewAdminRoleMemory(s *store) repository.AdminRole {
+	return &AdminRoleMemory{s: s}
+}
+
+func toRole(name string, row *roleRow) models.Role {
+	permissions := append([]string{}, row.Permissions...)
+	sort.Strings(permissions)
+
+	return models.Role{Name: name, Description: row.Description, Permissions: permissions}
+}
+
+func (r *AdminRoleMemory) AssignRole(ctx context.Context, userId int, role models.UpdateRoleInput) (int, error) {
+	const op = "repository.memory.AssignRole"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	user, ok := r.s.users[userId]
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", op, dbErr("user", errNoRows))
+	}
+
+	if role.Role == nil {
+		return 0, fmt.Errorf("%s: role is not set", op)
+	}
+
+	if _, ok := r.s.roles[*role.Role]; !ok {
+		return 0, fmt.Errorf("%s:%s: %w", op, roleTable, dbErr("user", errForeignKey))
+	}
+
+	user.Role = *role.Role
+
+	return user.Id, nil
+}
+
+func (r *AdminRoleMemory) GetAllRoles(ctx context.Context) ([]models.Role, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	roles := make([]models.Role, 0, len(r.s.roles))
+	for name, row := range r.s.roles {
+		roles = append(roles, toRole(name, row))
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles, nil
+}
+
+func (r *AdminRoleMemory) GetRole(ctx context.Context, name string) (models.Role, error) {
+	const op = "repository.memory.GetRole"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	row, ok := r.s.roles[name]
+	if !ok {
+		return models.Role{}, fmt.Errorf("%s: %w", op, dbErr("role", errNoRows))
+	}
+
+	return toRole(name, row), nil
+}
+
+func (r *AdminRoleMemory) CreateRole(ctx context.Context, role models.Role) error {
+	const op = "repository.memory.CreateRole"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.roles[role.Name]; ok {
+		return fmt.Errorf("%s:%s: %w", op, roleTable, dbErr("role", errUnique))
+	}
+
+	if err := r.s.checkPermissions(role.Permissions); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.s.roles[role.Name] = &roleRow{
+		Description: role.Description,
+		Permissions: append([]string{}, role.Permissions...),
+	}
+
+	return nil
+}
+
+// UpdateRole replaces the description and the permissions of a role
+func (r *AdminRoleMemory) UpdateRole(ctx context.Context, role models.Role) error {
+	const op = "repository.memory.UpdateRole"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	row, ok := r.s.roles[role.Name]
+	if !ok {
+		// nothing is updated, as an UPDATE of a missing row does, but permissions can't reference the role
+		if len(role.Permissions) > 0 {
+			return fmt.Errorf("%s:%s: %w", op, rolePermissionTable, errForeignKey)
+		}
+
+		return nil
+	}
+
+	if err := r.s.checkPermissions(role.Permissions); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	row.Description = role.Description
+	row.Permissions = append([]string{}, role.Permissions...)
+
+	return nil
+}
+
+// checkPermissions checks the keys of role_permission rows of a role
+func (s *store) checkPermissions(permissions []string) error {
+	seen := make(map[string]bool, len(permissions))
+
+	for _, permission := range permissions {
+		if _, ok := s.permissions[permission]; !ok {
+			return fmt.Errorf("%s: %w", rolePermissionTable, errForeignKey)
+		}
+
+		if seen[permission] {
+			return fmt.Errorf("%s: %w", rolePermissionTable, errUnique)
+		}
+
+		seen[permission] = true
+	}
+
+	return nil
+}
+
+/*
+DeleteRole removes a role that no user has. It returns false
+if the role is not found or is still in use
+*/
+func (r *AdminRoleMemory) DeleteRole(ctx context.Context, name string) (bool, error) {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.roles[name]; !ok {
+		return false, nil
+	}
+
+	for _, user := range r.s.users {
+		if user.Role == name {
+			return false, nil
+		}
+	}
+
+	delete(r.s.roles, name)
+
+	return true, nil
+}
+
+func (r *AdminRoleMemory) GetAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	permissions := make([]models.Permission, 0, len(r.s.permissions))
+	for name, description := range r.s.permissions {
+		permissions = append(permissions, models.Permission{Name: name, Description: description})
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].Name < permissions[j].Name
+	})
+
+	return permissions, nil
+}
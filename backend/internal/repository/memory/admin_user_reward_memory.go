@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type AdminUserRewardMemory struct {
+	s *store
+	repository.Reward
+}
+
+func NewAdminUserRewardMemory(s *store) repository.AdminUserReward {
+	return &AdminUserRewardMemory{s: s, Reward: NewRewardMemory(s)}
+}
+
+// userReward finds a reward given to a user for a habit, nil if there is none
+func (s *store) userReward(userId, habitId, rewardId int) *models.UserReward {
+	for _, userReward := range s.userRewards {
+		if userReward.UserId == userId && userReward.HabitId == habitId && userReward.RewardId == rewardId {
+			return userReward
+		}
+	}
+
+	return nil
+}
+
+// AssignReward gives a reward to a user for a habit the user has
+func (r *AdminUserRewardMemory) AssignReward(ctx context.Context, userId, habitId, rewardId int) (int, error) {
+	const op = "repository.memory.AssignReward"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.rewards[rewardId]; !ok || r.s.userHabit(userId, habitId) == nil {
+		return 0, fmt.Errorf("%s: %w", op, dbErr("user_reward", errNoRows))
+	}
+
+	if r.s.userReward(userId, habitId, rewardId) != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, userRewardTable, dbErr("user_reward", errUnique))
+	}
+
+	userReward := &models.UserReward{
+		Id:       r.s.nextId(userRewardTable),
+		UserId:   userId,
+		HabitId:  habitId,
+		RewardId: rewardId,
+	}
+	r.s.userRewards[userReward.Id] = userReward
+
+	return userReward.Id, nil
+}
+
+// Take away from user
+func (r *AdminUserRewardMemory) RemoveFromUser(ctx context.Context, userId, habitId, rewardId int) error {
+	const op = "repository.memory.RemoveFromUser"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	userReward := r.s.userReward(userId, habitId, rewardId)
+	if userReward == nil {
+		return fmt.Errorf("%s: %w", op, dbErr("user_reward", errNoRows))
+	}
+
+	delete(r.s.userRewards, userReward.Id)
+
+	return nil
+}
+
+func (r *AdminUserRewardMemory) UpdateUserReward(ctx context.Context, userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
+	const op = "repository.memory.UpdateUserReward"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	userReward := r.s.userReward(userId, habitId, rewardId)
+	if userReward == nil {
+		return fmt.Errorf("%s: %w", op, dbErr("user_reward", errNoRows))
+	}
+
+	updated := *userReward
+	if input.HabitId != nil {
+		updated.HabitId = *input.HabitId
+	}
+	if input.RewardId != nil {
+		updated.RewardId = *input.RewardId
+	}
+
+	// the foreign keys of user_reward reference the habit and the reward tables
+	if _, ok := r.s.habits[updated.HabitId]; !ok {
+		return fmt.Errorf("%s:%s: %w", op, userRewardTable, dbErr("user_reward", errForeignKey))
+	}
+	if _, ok := r.s.rewards[updated.RewardId]; !ok {
+		return fmt.Errorf("%s:%s: %w", op, userRewardTable, dbErr("user_reward", errForeignKey))
+	}
+
+	if other := r.s.userReward(updated.UserId, updated.HabitId, updated.RewardId); other != nil && other.Id != updated.Id {
+		return fmt.Errorf("%s:%s: %w", op, userRewardTable, dbErr("user_reward", errUnique))
+	}
+
+	*userReward = updated
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+// AuditMemory keeps the log append-only: there is no way to change or remove an entry
+type AuditMemory struct {
+	s *store
+}
+
+func NewAuditMemory(s *store) repository.Audit {
+	return &AuditMemory{s: s}
+}
+
+// copyJSON copies a json document, an empty one is read as NULL
+func copyJSON(doc json.RawMessage) json.RawMessage {
+	if len(doc) == 0 {
+		return nil
+	}
+
+	return append(json.RawMessage{}, doc...)
+}
+
+func (r *AuditMemory) Create(ctx context.Context, entry models.AuditEntry) (int, error) {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	entry.Id = r.s.nextId(auditTable)
+	entry.Before = copyJSON(entry.Before)
+	entry.After = copyJSON(entry.After)
+	entry.CreatedAt = now()
+
+	r.s.audit = append(r.s.audit, entry)
+
+	return entry.Id, nil
+}
+
+func (r *AuditMemory) GetAll(ctx context.Context, params models.ListParams) ([]models.AuditEntry, int, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	page, total := listPage(r.s.audit, params, auditFields)
+
+	entries := make([]models.AuditEntry, 0, len(page))
+	for _, entry := range page {
+		entry.Before = copyJSON(entry.Before)
+		entry.After = copyJSON(entry.After)
+		entries = append(entries, entry)
+	}
+
+	return entries, total, nil
+}
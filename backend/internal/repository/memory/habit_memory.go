@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type HabitMemory struct {
+	s *store
+}
+
+func NewHabitMemory(s *store) repository.Habit {
+	return &HabitMemory{s: s}
+}
+
+// Create adds a habit with an empty tracker and links both to the user
+func (r *HabitMemory) Create(ctx context.Context, userId int, habit models.Habit) (int, error) {
+	const op = "repository.memory.habit_memory.Create"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.users[userId]; !ok {
+		return 0, fmt.Errorf("%s:%s: %w", op, userHabitTable, errForeignKey)
+	}
+
+	habit.Id = r.s.nextId(habitTable)
+	habit.Version = 1
+	r.s.habits[habit.Id] = &habit
+
+	// fields of an empty tracker are read as "-", as the SQL repositories read NULLs
+	tracker := &models.HabitTracker{
+		Id:            r.s.nextId(trackerTable),
+		HabitId:       habit.Id,
+		UnitOfMessure: "-",
+		Goal:          "-",
+		Frequency:     "-",
+		StartDate:     today(),
+		Version:       1,
+	}
+	r.s.trackers[tracker.Id] = tracker
+
+	link := &userHabitRow{
+		Id:        r.s.nextId(userHabitTable),
+		UserId:    userId,
+		HabitId:   habit.Id,
+		TrackerId: tracker.Id,
+	}
+	r.s.userHabits[link.Id] = link
+
+	return habit.Id, nil
+}
+
+func (r *HabitMemory) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.Habit, int, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	habits := []models.Habit{}
+	for _, link := range r.s.userHabits {
+		if habit, ok := r.s.habits[link.HabitId]; ok && link.UserId == userId {
+			habits = append(habits, *habit)
+		}
+	}
+
+	page, total := listPage(habits, params, habitFields)
+
+	return page, total, nil
+}
+
+func (r *HabitMemory) GetById(ctx context.Context, userId, habitId int) (models.Habit, error) {
+	const op = "repository.memory.habit_memory.GetById"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	link := r.s.userHabit(userId, habitId)
+	if link == nil {
+		return models.Habit{}, fmt.Errorf("%s: %w", op, dbErr("habit", errNoRows))
+	}
+
+	return *r.s.habits[link.HabitId], nil
+}
+
+func (r *HabitMemory) Delete(ctx context.Context, userId, habitId int) error {
+	const op = "repository.memory.habit_memory.Delete"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	link := r.s.userHabit(userId, habitId)
+	if link == nil {
+		return fmt.Errorf("%s:%s: %w", op, habitTable, dbErr("habit", errNoRows))
+	}
+
+	r.s.deleteHabit(link)
+
+	return nil
+}
+
+// hasVersion tells if a habit or a tracker has one of the versions of If-Match
+func hasVersion(versions []int, version int) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+Update changes a habit and increments its version. If input.Versions is set,
+the habit is changed only if it still has one of those versions
+*/
+func (r *HabitMemory) Update(ctx context.Context, userId, habitId int, input models.UpdateHabitInput) (int, error) {
+	const op = "repository.memory.habit_memory.Update"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	link := r.s.userHabit(userId, habitId)
+	if link == nil {
+		return 0, fmt.Errorf("%s: %w", op, dbErr("habit", errNoRows))
+	}
+
+	habit := r.s.habits[link.HabitId]
+	if input.Versions != nil && !hasVersion(input.Versions, habit.Version) {
+		return 0, fmt.Errorf("%s: %w", op, repository.ErrVersionMismatch)
+	}
+
+	if input.Title != nil {
+		habit.Title = *input.Title
+	}
+	if input.Description != nil {
+		habit.Description = *input.Description
+	}
+	habit.Version++
+
+	return habit.Version, nil
+}
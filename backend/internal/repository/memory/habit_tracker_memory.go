@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type HabitTrackerMemory struct {
+	s *store
+}
+
+func NewHabitTrackerMemory(s *store) repository.HabitTracker {
+	return &HabitTrackerMemory{s: s}
+}
+
+// readTracker returns a copy of a tracker, an empty end date is read as the current date
+func readTracker(tracker *models.HabitTracker) models.HabitTracker {
+	read := *tracker
+	if read.EndDate.IsZero() {
+		read.EndDate = today()
+	}
+
+	return read
+}
+
+func (r *HabitTrackerMemory) GetById(ctx context.Context, userId, habitId int) (models.HabitTracker, error) {
+	const op = "repository.memory.habit_tracker_memory.GetById"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	link := r.s.userHabit(userId, habitId)
+	if link == nil {
+		return models.HabitTracker{}, fmt.Errorf("%s: %w", op, dbErr("habit_tracker", errNoRows))
+	}
+
+	tracker, ok := r.s.trackers[link.TrackerId]
+	if !ok {
+		return models.HabitTracker{}, fmt.Errorf("%s: %w", op, dbErr("habit_tracker", errNoRows))
+	}
+
+	return readTracker(tracker), nil
+}
+
+func (r *HabitTrackerMemory) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.HabitTracker, int, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	trackers := []models.HabitTracker{}
+	for _, link := range r.s.userHabits {
+		if tracker, ok := r.s.trackers[link.TrackerId]; ok && link.UserId == userId {
+			trackers = append(trackers, readTracker(tracker))
+		}
+	}
+
+	page, total := listPage(trackers, params, trackerFields)
+
+	return page, total, nil
+}
+
+/*
+Update changes a tracker and increments its version. If input.Versions is set,
+the tracker is changed only if it still has one of those versions, so an edit made
+from another client in the meantime is not overwritten
+*/
+func (r *HabitTrackerMemory) Update(ctx context.Context, userId, habitId int, input models.UpdateTrackerInput) (int, error) {
+	const op = "repository.memory.habit_tracker_memory.Update"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	link := r.s.userHabit(userId, habitId)
+	if link == nil {
+		return 0, fmt.Errorf("%s: %w", op, dbErr("habit_tracker", errNoRows))
+	}
+
+	tracker, ok := r.s.trackers[link.TrackerId]
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", op, dbErr("habit_tracker", errNoRows))
+	}
+
+	if input.Versions != nil && !hasVersion(input.Versions, tracker.Version) {
+		return 0, fmt.Errorf("%s: %w", op, repository.ErrVersionMismatch)
+	}
+
+	if input.UnitOfMessure != nil {
+		tracker.UnitOfMessure = *input.UnitOfMessure
+	}
+	if input.Goal != nil {
+		tracker.Goal = *input.Goal
+	}
+	if input.Frequency != nil {
+		tracker.Frequency = *input.Frequency
+	}
+	if input.StartDate != nil {
+		tracker.StartDate = input.StartDate.UTC()
+	}
+	if input.EndDate != nil {
+		tracker.EndDate = input.EndDate.UTC()
+	}
+	if input.Counter != nil {
+		tracker.Counter = *input.Counter
+	}
+	if input.Done != nil {
+		tracker.Done = *input.Done
+	}
+	tracker.Version++
+
+	return tracker.Version, nil
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type IdempotencyMemory struct {
+	s *store
+}
+
+func NewIdempotencyMemory(s *store) repository.Idempotency {
+	return &IdempotencyMemory{s: s}
+}
+
+func readIdempotencyRecord(record *models.IdempotencyRecord) models.IdempotencyRecord {
+	read := *record
+
+	if record.StatusCode != nil {
+		statusCode := *record.StatusCode
+		read.StatusCode = &statusCode
+	}
+
+	if record.ResponseBody != nil {
+		read.ResponseBody = append([]byte{}, record.ResponseBody...)
+	}
+
+	if record.ResponseHeaders != nil {
+		read.ResponseHeaders = copyHeaders(record.ResponseHeaders)
+	}
+
+	return read
+}
+
+/*
+Reserve stores a key of a request in progress. It reports false if the
+key is taken by a record which is not expired. Expired keys of the user
+are removed on the way, so the store does not grow with old keys
+*/
+func (r *IdempotencyMemory) Reserve(ctx context.Context, record models.IdempotencyRecord) (bool, error) {
+	const op = "repository.memory.idempotency_memory.Reserve"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.users[record.UserId]; !ok {
+		return false, fmt.Errorf("%s:%s: %w", op, idempotencyTable, errForeignKey)
+	}
+
+	reservedAt := now()
+
+	for key, other := range r.s.idempotency {
+		if key.UserId == record.UserId && !other.ExpiresAt.After(reservedAt) {
+			delete(r.s.idempotency, key)
+		}
+	}
+
+	key := idempotencyKey{UserId: record.UserId, Key: record.Key}
+	if _, ok := r.s.idempotency[key]; ok {
+		return false, nil
+	}
+
+	r.s.idempotency[key] = &models.IdempotencyRecord{
+		UserId:      record.UserId,
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		ExpiresAt:   record.ExpiresAt,
+	}
+
+	return true, nil
+}
+
+func (r *IdempotencyMemory) Get(ctx context.Context, userId int, key string) (models.IdempotencyRecord, error) {
+	const op = "repository.memory.idempotency_memory.Get"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	record, ok := r.s.idempotency[idempotencyKey{UserId: userId, Key: key}]
+	if !ok {
+		return models.IdempotencyRecord{}, fmt.Errorf("%s: %w", op, errNoRows)
+	}
+
+	return readIdempotencyRecord(record), nil
+}
+
+// Complete stores the response to the request the key was reserved for
+func (r *IdempotencyMemory) Complete(ctx context.Context, userId int, key string, statusCode int, headers map[string]string, body []byte) error {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	record, ok := r.s.idempotency[idempotencyKey{UserId: userId, Key: key}]
+	if !ok {
+		return nil
+	}
+
+	record.StatusCode = &statusCode
+	record.ResponseHeaders = copyHeaders(headers)
+	record.ResponseBody = append([]byte{}, body...)
+
+	return nil
+}
+
+func copyHeaders(headers map[string]string) map[string]string {
+	copied := make(map[string]string, len(headers))
+	for name, value := range headers {
+		copied[name] = value
+	}
+
+	return copied
+}
+
+// Delete frees a key, so the request can be sent again with it
+func (r *IdempotencyMemory) Delete(ctx context.Context, userId int, key string) error {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	delete(r.s.idempotency, idempotencyKey{UserId: userId, Key: key})
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type IdentityMemory struct {
+	s *store
+}
+
+func NewIdentityMemory(s *store) repository.Identity {
+	return &IdentityMemory{s: s}
+}
+
+func (r *IdentityMemory) Create(ctx context.Context, identity models.Identity) (int, error) {
+	const op = "repository.memory.identity_memory.Create"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.users[identity.UserId]; !ok {
+		return 0, fmt.Errorf("%s:%s: %w", op, identityTable, errForeignKey)
+	}
+
+	for _, other := range r.s.identities {
+		if other.Provider == identity.Provider && other.Subject == identity.Subject {
+			return 0, fmt.Errorf("%s:%s: %w", op, identityTable, errUnique)
+		}
+	}
+
+	identity.Id = r.s.nextId(identityTable)
+	r.s.identities[identity.Id] = &identity
+
+	return identity.Id, nil
+}
+
+// GetUserId returns the id of a user linked to the identity
+func (r *IdentityMemory) GetUserId(ctx context.Context, provider, subject string) (int, error) {
+	const op = "repository.memory.identity_memory.GetUserId"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	for _, identity := range r.s.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity.UserId, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s: %w", op, errNoRows)
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+)
+
+/*
+listFields maps field names of models.ListFields to the values of a row
+the row is filtered and sorted by. Every list has an "id" field
+*/
+type listFields[T any] map[string]func(row T) any
+
+var (
+	habitFields = listFields[models.Habit]{
+		"id":    func(row models.Habit) any { return row.Id },
+		"title": func(row models.Habit) any { return row.Title },
+	}
+
+	trackerFields = listFields[models.HabitTracker]{
+		"id":              func(row models.HabitTracker) any { return row.Id },
+		"habit_id":        func(row models.HabitTracker) any { return row.HabitId },
+		"start_date":      func(row models.HabitTracker) any { return row.StartDate },
+		"end_date":        func(row models.HabitTracker) any { return row.EndDate },
+		"counter":         func(row models.HabitTracker) any { return row.Counter },
+		"unit_of_messure": func(row models.HabitTracker) any { return row.UnitOfMessure },
+		"frequency":       func(row models.HabitTracker) any { return row.Frequency },
+		"done":            func(row models.HabitTracker) any { return row.Done },
+	}
+
+	rewardFields = listFields[models.Reward]{
+		"id":    func(row models.Reward) any { return row.Id },
+		"title": func(row models.Reward) any { return row.Title },
+	}
+
+	userFields = listFields[models.GetUser]{
+		"id":           func(row models.GetUser) any { return row.Id },
+		"user_name":    func(row models.GetUser) any { return row.Username },
+		"tg_user_name": func(row models.GetUser) any { return row.TgUsername },
+		"email":        func(row models.GetUser) any { return row.Email },
+		"role":         func(row models.GetUser) any { return row.Role },
+	}
+
+	auditFields = listFields[models.AuditEntry]{
+		"id":             func(row models.AuditEntry) any { return row.Id },
+		"created_at":     func(row models.AuditEntry) any { return row.CreatedAt },
+		"actor_id":       func(row models.AuditEntry) any { return row.ActorId },
+		"target_user_id": func(row models.AuditEntry) any { return row.TargetUserId },
+		"action":         func(row models.AuditEntry) any { return row.Action },
+		"request_id":     func(row models.AuditEntry) any { return row.RequestId },
+	}
+)
+
+/*
+listPage filters rows, sorts them and cuts the page of list params out.
+It returns the page along with the number of rows matching the filters.
+As the SQL repositories do, the id is always the last sort key
+*/
+func listPage[T any](rows []T, params models.ListParams, fields listFields[T]) ([]T, int) {
+	matched := []T{}
+
+	for _, row := range rows {
+		if matchFilters(row, params.Filters, fields) {
+			matched = append(matched, row)
+		}
+	}
+
+	id := fields["id"]
+	field, ok := fields[params.Sort]
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		order := 0
+		if ok {
+			order = compare(field(matched[i]), field(matched[j]))
+		}
+		if order == 0 {
+			order = compare(id(matched[i]), id(matched[j]))
+		}
+
+		if params.Desc {
+			return order > 0
+		}
+
+		return order < 0
+	})
+
+	total := len(matched)
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = models.DefaultListLimit
+	}
+
+	if params.Offset >= total {
+		return []T{}, total
+	}
+
+	end := params.Offset + limit
+	if end > total {
+		end = total
+	}
+
+	return matched[params.Offset:end], total
+}
+
+func matchFilters[T any](row T, filters []models.Filter, fields listFields[T]) bool {
+	for _, filter := range filters {
+		field, ok := fields[filter.Field]
+		if !ok {
+			continue
+		}
+
+		// values are already validated by models.ParseListParams
+		switch value := field(row).(type) {
+		case string:
+			if filter.Kind == models.FilterContains {
+				if !strings.Contains(strings.ToLower(value), strings.ToLower(filter.Value)) {
+					return false
+				}
+				continue
+			}
+
+			if value != filter.Value {
+				return false
+			}
+		case int:
+			if n, _ := strconv.Atoi(filter.Value); value != n {
+				return false
+			}
+		case bool:
+			if b, _ := strconv.ParseBool(filter.Value); value != b {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// compare orders two values of a field, both have the same type
+func compare(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		return compareOrdered(a, b.(int))
+	case string:
+		return strings.Compare(a, b.(string))
+	case bool:
+		return compareOrdered(boolToInt(a), boolToInt(b.(bool)))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+
+	return 0
+}
+
+func compareOrdered[T int | float32](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,304 @@
+package memory
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+)
+
+/*
+the in-memory repository keeps all the tables in maps guarded by a single
+lock. It runs the services without a database in tests, and it keeps the
+constraints of the SQL schema: unique keys, foreign keys and cascades,
+so the services see the same errors they get from postgres
+*/
+
+var (
+	// errNoRows is returned where a query of the SQL repositories finds no row
+	errNoRows = errors.New("no rows in result set")
+	// errUnique is returned where a SQL insert or update breaks a unique key
+	errUnique = errors.New("unique constraint violated")
+	// errForeignKey is returned where a SQL insert or update breaks a foreign key
+	errForeignKey = errors.New("foreign key constraint violated")
+)
+
+const (
+	habitTable          = "habit-table"
+	trackerTable        = "habit-tracker-table"
+	userHabitTable      = "user-habit-table"
+	rewardTable         = "reward-table"
+	refreshTable        = "refresh-token-table"
+	revokedTable        = "revoked-token-table"
+	personalTokenTable  = "personal-access-token-table"
+	idempotencyTable    = "idempotency-key-table"
+	linkCodeTable       = "telegram-link-code-table"
+	userTable           = "user-account-table"
+	userRewardTable     = "user-reward-table"
+	roleTable           = "role-table"
+	rolePermissionTable = "role-permission-table"
+	identityTable       = "user-identity-table"
+	auditTable          = "audit-log-table"
+)
+
+/*
+dbErr turns a missing row, a unique and a foreign key violation into
+typed errors of pkg/errs, the same way the SQL repositories do
+*/
+func dbErr(entity string, err error) error {
+	if errors.Is(err, errNoRows) {
+		return errs.NotFound(entity+"_not_found", err)
+	}
+
+	if errors.Is(err, errUnique) {
+		return errs.Conflict(entity+"_exists", err)
+	}
+
+	if errors.Is(err, errForeignKey) {
+		return errs.Validation(entity+"_invalid_reference", err)
+	}
+
+	return err
+}
+
+// now is the current time in UTC, the zone the SQL repositories return times in
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// today is the current date, the default of the start date of a tracker
+func today() time.Time {
+	return now().Truncate(24 * time.Hour)
+}
+
+type userRow struct {
+	models.User
+	EmailVerifiedAt *time.Time
+	TokensRevokedAt *time.Time
+}
+
+type roleRow struct {
+	Description string
+	Permissions []string
+}
+
+// userHabitRow links a habit and its tracker to the user the habit belongs to
+type userHabitRow struct {
+	Id        int
+	UserId    int
+	HabitId   int
+	TrackerId int
+}
+
+type revokedRow struct {
+	UserId    int
+	ExpiresAt time.Time
+}
+
+type linkCodeRow struct {
+	CodeHash  string
+	ExpiresAt time.Time
+}
+
+type idempotencyKey struct {
+	UserId int
+	Key    string
+}
+
+type store struct {
+	mu sync.RWMutex
+
+	// lastIds are the last ids given in every table, ids are never reused as autoincrement ones
+	lastIds map[string]int
+
+	users          map[int]*userRow
+	roles          map[string]*roleRow
+	permissions    map[string]string
+	habits         map[int]*models.Habit
+	trackers       map[int]*models.HabitTracker
+	userHabits     map[int]*userHabitRow
+	rewards        map[int]*models.Reward
+	userRewards    map[int]*models.UserReward
+	audit          []models.AuditEntry
+	refreshTokens  map[int]*models.RefreshToken
+	revokedTokens  map[string]revokedRow
+	usedTokens     map[string]time.Time
+	identities     map[int]*models.Identity
+	linkCodes      map[int]linkCodeRow
+	personalTokens map[int]*models.PersonalToken
+	idempotency    map[idempotencyKey]*models.IdempotencyRecord
+}
+
+/*
+newStore creates empty tables with the permissions and the roles
+the migrations of the SQL schema seed
+*/
+func newStore() *store {
+	s := &store{
+		lastIds:        make(map[string]int),
+		users:          make(map[int]*userRow),
+		roles:          make(map[string]*roleRow),
+		permissions:    make(map[string]string),
+		habits:         make(map[int]*models.Habit),
+		trackers:       make(map[int]*models.HabitTracker),
+		userHabits:     make(map[int]*userHabitRow),
+		rewards:        make(map[int]*models.Reward),
+		userRewards:    make(map[int]*models.UserReward),
+		refreshTokens:  make(map[int]*models.RefreshToken),
+		revokedTokens:  make(map[string]revokedRow),
+		usedTokens:     make(map[string]time.Time),
+		identities:     make(map[int]*models.Identity),
+		linkCodes:      make(map[int]linkCodeRow),
+		personalTokens: make(map[int]*models.PersonalToken),
+		idempotency:    make(map[idempotencyKey]*models.IdempotencyRecord),
+	}
+
+	s.permissions = map[string]string{
+		models.PermUsersRead:     "list users and see their accounts",
+		models.PermUsersWrite:    "delete and restore user accounts",
+		models.PermHabitsRead:    "see habits, trackers and rewards of any user",
+		models.PermHabitsWrite:   "create, change and delete habits and trackers of any user",
+		models.PermRewardsRead:   "see the common reward list",
+		models.PermRewardsWrite:  "create, change and delete rewards of the common list",
+		models.PermRewardsAssign: "give rewards to users and take them back",
+		models.PermRolesRead:     "see roles and permissions",
+		models.PermRolesWrite:    "create, change and delete roles",
+		models.PermRolesAssign:   "change the role of a user",
+		models.PermAuditRead:     "read the audit log",
+		models.PermSearchAll:     "search habits and rewards of all users",
+	}
+
+	admin := make([]string, 0, len(s.permissions))
+	for permission := range s.permissions {
+		admin = append(admin, permission)
+	}
+
+	s.roles[models.UserGeneral] = &roleRow{Description: "a regular user, manages only own data", Permissions: []string{}}
+	s.roles[models.Administrator] = &roleRow{Description: "has every permission", Permissions: admin}
+	s.roles["moderator"] = &roleRow{
+		Description: "manages rewards and sees users",
+		Permissions: []string{
+			models.PermUsersRead,
+			models.PermHabitsRead,
+			models.PermRewardsRead,
+			models.PermRewardsWrite,
+			models.PermRewardsAssign,
+		},
+	}
+
+	return s
+}
+
+// nextId returns a new id of the table
+func (s *store) nextId(table string) int {
+	s.lastIds[table]++
+
+	return s.lastIds[table]
+}
+
+// userHabit finds the link of a habit to the user, nil if the user has no such habit
+func (s *store) userHabit(userId, habitId int) *userHabitRow {
+	for _, link := range s.userHabits {
+		if link.UserId == userId && link.HabitId == habitId {
+			return link
+		}
+	}
+
+	return nil
+}
+
+/*
+deleteHabit removes a habit with its tracker, the rows referencing the
+habit are removed as the cascades of the SQL schema do
+*/
+func (s *store) deleteHabit(link *userHabitRow) {
+	delete(s.trackers, link.TrackerId)
+	delete(s.habits, link.HabitId)
+
+	for id, userHabit := range s.userHabits {
+		if userHabit.HabitId == link.HabitId {
+			delete(s.userHabits, id)
+		}
+	}
+
+	for id, userReward := range s.userRewards {
+		if userReward.HabitId == link.HabitId {
+			delete(s.userRewards, id)
+		}
+	}
+}
+
+// deleteUser removes a user with all the rows referencing the user
+func (s *store) deleteUser(userId int) {
+	delete(s.users, userId)
+	delete(s.linkCodes, userId)
+
+	for id, link := range s.userHabits {
+		if link.UserId == userId {
+			delete(s.userHabits, id)
+		}
+	}
+
+	for id, userReward := range s.userRewards {
+		if userReward.UserId == userId {
+			delete(s.userRewards, id)
+		}
+	}
+
+	for id, token := range s.refreshTokens {
+		if token.UserId == userId {
+			delete(s.refreshTokens, id)
+		}
+	}
+
+	for jti, token := range s.revokedTokens {
+		if token.UserId == userId {
+			delete(s.revokedTokens, jti)
+		}
+	}
+
+	for id, identity := range s.identities {
+		if identity.UserId == userId {
+			delete(s.identities, id)
+		}
+	}
+
+	for id, token := range s.personalTokens {
+		if token.UserId == userId {
+			delete(s.personalTokens, id)
+		}
+	}
+
+	for key := range s.idempotency {
+		if key.UserId == userId {
+			delete(s.idempotency, key)
+		}
+	}
+}
+
+// NewMemoryRepository returns a repository which tables share a single in-memory store
+func NewMemoryRepository() *repository.Repository {
+	s := newStore()
+
+	return &repository.Repository{
+		Transactor:      NewTransactorMemory(s),
+		AdminRole:       NewAdminRoleMemory(s),
+		AdminReward:     NewAdminRewardMemory(s),
+		AdminUserReward: NewAdminUserRewardMemory(s),
+		Admin:           NewAdminMemory(s),
+		User:            NewUserMemory(s),
+		Token:           NewTokenMemory(s),
+		PersonalToken:   NewPersonalTokenMemory(s),
+		Idempotency:     NewIdempotencyMemory(s),
+		Identity:        NewIdentityMemory(s),
+		TelegramLink:    NewTelegramLinkMemory(s),
+		Habit:           NewHabitMemory(s),
+		HabitTracker:    NewHabitTrackerMemory(s),
+		Reward:          NewRewardMemory(s),
+		Search:          NewSearchMemory(s),
+		Audit:           NewAuditMemory(s),
+	}
+}
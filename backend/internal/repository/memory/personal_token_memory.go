@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+/*
+touchInterval limits how often the last used time of a token is written,
+as it does in the SQL repositories
+*/
+const touchInterval = time.Minute
+
+type PersonalTokenMemory struct {
+	s *store
+}
+
+func NewPersonalTokenMemory(s *store) repository.PersonalToken {
+	return &PersonalTokenMemory{s: s}
+}
+
+func readPersonalToken(token *models.PersonalToken) models.PersonalToken {
+	read := *token
+	read.Scopes = append([]string{}, token.Scopes...)
+	read.LastUsedAt = copyTime(token.LastUsedAt)
+	read.RevokedAt = copyTime(token.RevokedAt)
+
+	return read
+}
+
+func (r *PersonalTokenMemory) Create(ctx context.Context, token models.PersonalToken) (int, error) {
+	const op = "repository.memory.personal_token_memory.Create"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.users[token.UserId]; !ok {
+		return 0, fmt.Errorf("%s:%s: %w", op, personalTokenTable, errForeignKey)
+	}
+
+	for _, other := range r.s.personalTokens {
+		if other.TokenHash == token.TokenHash {
+			return 0, fmt.Errorf("%s:%s: %w", op, personalTokenTable, errUnique)
+		}
+	}
+
+	created := readPersonalToken(&token)
+	created.Id = r.s.nextId(personalTokenTable)
+	created.LastUsedAt = nil
+	created.RevokedAt = nil
+	created.CreatedAt = now()
+	r.s.personalTokens[created.Id] = &created
+
+	return created.Id, nil
+}
+
+// GetAll returns tokens of a user which are not revoked, expired ones included
+func (r *PersonalTokenMemory) GetAll(ctx context.Context, userId int) ([]models.PersonalToken, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	tokens := []models.PersonalToken{}
+	for _, token := range r.s.personalTokens {
+		if token.UserId == userId && token.RevokedAt == nil {
+			tokens = append(tokens, readPersonalToken(token))
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+
+		return tokens[i].Id > tokens[j].Id
+	})
+
+	return tokens, nil
+}
+
+func (r *PersonalTokenMemory) GetByHash(ctx context.Context, tokenHash string) (models.PersonalToken, error) {
+	const op = "repository.memory.personal_token_memory.GetByHash"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	for _, token := range r.s.personalTokens {
+		if token.TokenHash == tokenHash {
+			return readPersonalToken(token), nil
+		}
+	}
+
+	return models.PersonalToken{}, fmt.Errorf("%s: %w", op, errNoRows)
+}
+
+// Revoke reports false if the user has no such token or it is revoked already
+func (r *PersonalTokenMemory) Revoke(ctx context.Context, userId, tokenId int) (bool, error) {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	token, ok := r.s.personalTokens[tokenId]
+	if !ok || token.UserId != userId || token.RevokedAt != nil {
+		return false, nil
+	}
+
+	revokedAt := now()
+	token.RevokedAt = &revokedAt
+
+	return true, nil
+}
+
+// Touch records that a token is used
+func (r *PersonalTokenMemory) Touch(ctx context.Context, tokenId int) error {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	token, ok := r.s.personalTokens[tokenId]
+	if !ok {
+		return nil
+	}
+
+	usedAt := now()
+	if token.LastUsedAt == nil || token.LastUsedAt.Before(usedAt.Add(-touchInterval)) {
+		token.LastUsedAt = &usedAt
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type RewardMemory struct {
+	s *store
+}
+
+func NewRewardMemory(s *store) repository.Reward {
+	return &RewardMemory{s: s}
+}
+
+/*
+personalRewards returns the rewards given to a user in the order they
+were given, a reward given for several habits is there several times
+*/
+func (s *store) personalRewards(match func(userReward *models.UserReward) bool) []models.Reward {
+	userRewards := make([]*models.UserReward, 0)
+	for _, userReward := range s.userRewards {
+		if match(userReward) {
+			userRewards = append(userRewards, userReward)
+		}
+	}
+
+	sort.Slice(userRewards, func(i, j int) bool {
+		return userRewards[i].Id < userRewards[j].Id
+	})
+
+	rewards := []models.Reward{}
+	for _, userReward := range userRewards {
+		if reward, ok := s.rewards[userReward.RewardId]; ok {
+			rewards = append(rewards, *reward)
+		}
+	}
+
+	return rewards
+}
+
+func (r *RewardMemory) GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	rewards := r.s.personalRewards(func(userReward *models.UserReward) bool {
+		return userReward.UserId == userId && userReward.HabitId == habitId
+	})
+
+	return rewards, nil
+}
+
+func (r *RewardMemory) GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	rewards := r.s.personalRewards(func(userReward *models.UserReward) bool {
+		return userReward.UserId == userId
+	})
+
+	page, total := listPage(rewards, params, rewardFields)
+
+	return page, total, nil
+}
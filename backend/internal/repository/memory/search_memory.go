@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type SearchMemory struct {
+	s *store
+}
+
+func NewSearchMemory(s *store) repository.Search {
+	return &SearchMemory{s: s}
+}
+
+/*
+searchRank ranks a title and a description against the words of a query
+the way the SQLite repository does: every word has to be found in the
+title or the description, a word found in the title weighs more.
+It reports false if the text does not match
+*/
+func searchRank(words []string, title, description string) (float32, bool) {
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	title, description = strings.ToLower(title), strings.ToLower(description)
+
+	var rank float32
+	for _, word := range words {
+		inTitle := strings.Contains(title, word)
+		inDescription := strings.Contains(description, word)
+
+		if !inTitle && !inDescription {
+			return 0, false
+		}
+
+		if inTitle {
+			rank += 1.0
+		}
+		if inDescription {
+			rank += 0.4
+		}
+	}
+
+	return rank, true
+}
+
+// searchWords splits a query into lowercase words, quotes are dropped
+func searchWords(query string) []string {
+	var words []string
+
+	for _, word := range strings.Fields(strings.ReplaceAll(query, `"`, "")) {
+		words = append(words, strings.ToLower(word))
+	}
+
+	return words
+}
+
+// topHits orders hits by rank and id and keeps limit of them after the first offset ones
+func topHits(hits []models.SearchHit, limit, offset int) []models.SearchHit {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+
+		return hits[i].Id < hits[j].Id
+	})
+
+	if offset > len(hits) {
+		offset = len(hits)
+	}
+	hits = hits[offset:]
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits
+}
+
+func (s *store) searchHabits(words []string, match func(link *userHabitRow) bool, limit, offset int) []models.SearchHit {
+	hits := []models.SearchHit{}
+
+	for _, link := range s.userHabits {
+		habit, ok := s.habits[link.HabitId]
+		if !ok || !match(link) {
+			continue
+		}
+
+		if rank, ok := searchRank(words, habit.Title, habit.Description); ok {
+			hits = append(hits, models.SearchHit{
+				Id:          habit.Id,
+				UserId:      link.UserId,
+				Title:       habit.Title,
+				Description: habit.Description,
+				Rank:        rank,
+			})
+		}
+	}
+
+	return topHits(hits, limit, offset)
+}
+
+func (s *store) searchRewards(words []string, match func(reward *models.Reward) bool, limit, offset int) []models.SearchHit {
+	hits := []models.SearchHit{}
+
+	for _, reward := range s.rewards {
+		if !match(reward) {
+			continue
+		}
+
+		if rank, ok := searchRank(words, reward.Title, reward.Description); ok {
+			hits = append(hits, models.SearchHit{
+				Id:          reward.Id,
+				Title:       reward.Title,
+				Description: reward.Description,
+				Rank:        rank,
+			})
+		}
+	}
+
+	return topHits(hits, limit, offset)
+}
+
+/*
+Search looks for the query among habits and rewards of a certain user.
+Words of the query are matched case insensitively as parts of words
+*/
+func (r *SearchMemory) Search(ctx context.Context, userId int, input models.SearchInput) (models.SearchResult, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	words := searchWords(input.Query)
+
+	habits := r.s.searchHabits(words, func(link *userHabitRow) bool {
+		return link.UserId == userId
+	}, input.Limit, input.Offset)
+
+	rewards := r.s.searchRewards(words, func(reward *models.Reward) bool {
+		for _, userReward := range r.s.userRewards {
+			if userReward.UserId == userId && userReward.RewardId == reward.Id {
+				return true
+			}
+		}
+
+		return false
+	}, input.Limit, input.Offset)
+
+	return models.SearchResult{Habits: habits, Rewards: rewards}, nil
+}
+
+// SearchAll looks for the query among habits of all users and all rewards
+func (r *SearchMemory) SearchAll(ctx context.Context, input models.SearchInput) (models.SearchResult, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	words := searchWords(input.Query)
+
+	habits := r.s.searchHabits(words, func(link *userHabitRow) bool {
+		return true
+	}, input.Limit, input.Offset)
+
+	rewards := r.s.searchRewards(words, func(reward *models.Reward) bool {
+		return true
+	}, input.Limit, input.Offset)
+
+	return models.SearchResult{Habits: habits, Rewards: rewards}, nil
+}
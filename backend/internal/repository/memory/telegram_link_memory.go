@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type TelegramLinkMemory struct {
+	s *store
+}
+
+func NewTelegramLinkMemory(s *store) repository.TelegramLink {
+	return &TelegramLinkMemory{s: s}
+}
+
+// CreateCode stores a new link code of a user, a previous code stops working
+func (r *TelegramLinkMemory) CreateCode(ctx context.Context, userId int, codeHash string, expiresAt time.Time) error {
+	const op = "repository.memory.telegram_link_memory.CreateCode"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.users[userId]; !ok {
+		return fmt.Errorf("%s:%s: %w", op, linkCodeTable, errForeignKey)
+	}
+
+	for otherId, code := range r.s.linkCodes {
+		if otherId != userId && code.CodeHash == codeHash {
+			return fmt.Errorf("%s:%s: %w", op, linkCodeTable, errUnique)
+		}
+	}
+
+	r.s.linkCodes[userId] = linkCodeRow{CodeHash: codeHash, ExpiresAt: expiresAt}
+
+	return nil
+}
+
+// UseCode removes a code that is not expired and returns the user it belongs to
+func (r *TelegramLinkMemory) UseCode(ctx context.Context, codeHash string) (int, error) {
+	const op = "repository.memory.telegram_link_memory.UseCode"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	for userId, code := range r.s.linkCodes {
+		if code.CodeHash == codeHash && code.ExpiresAt.After(now()) {
+			delete(r.s.linkCodes, userId)
+
+			return userId, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s: %w", op, errNoRows)
+}
+
+/*
+Link binds a telegram account to a user. If mergeUserId is set, habits
+and rewards of that telegram-only account are moved to the user and the
+account is removed, so the telegram id becomes free before it is bound.
+A deleted account releases the telegram id too, it is not linked back
+if the account is restored. Nothing is changed if any of the steps fails
+*/
+func (r *TelegramLinkMemory) Link(ctx context.Context, userId, mergeUserId int, tgUserId int64, tgUsername string) error {
+	const op = "repository.memory.telegram_link_memory.Link"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	user := r.s.activeUser(userId)
+	if user == nil || (user.TgUserId != 0 && user.TgUserId != tgUserId) {
+		return fmt.Errorf("%s: %w", op, errors.New("user is not found or linked to another telegram account"))
+	}
+
+	if mergeUserId != 0 {
+		// only an account without web credentials is merged
+		merged, ok := r.s.users[mergeUserId]
+		if !ok || merged.TgUserId != tgUserId || merged.Username != "" || merged.Password != "" {
+			return fmt.Errorf("%s: %w", op, errors.New("merged account is not a telegram-only account"))
+		}
+	}
+
+	tgUsernameTaken := false
+
+	var deleted []*userRow
+
+	// the merged account is removed, so its telegram id and user name become free
+	for id, other := range r.s.users {
+		if id == userId || id == mergeUserId {
+			continue
+		}
+
+		if other.TgUserId == tgUserId && other.DeletedAt != nil {
+			deleted = append(deleted, other)
+		} else if other.TgUserId == tgUserId {
+			return fmt.Errorf("%s:%s: %w", op, userTable, errUnique)
+		}
+
+		if tgUsername != "" && other.TgUsername == tgUsername {
+			tgUsernameTaken = true
+		}
+	}
+
+	if mergeUserId != 0 {
+		for _, link := range r.s.userHabits {
+			if link.UserId == mergeUserId {
+				link.UserId = userId
+			}
+		}
+
+		for _, userReward := range r.s.userRewards {
+			if userReward.UserId == mergeUserId {
+				userReward.UserId = userId
+			}
+		}
+
+		r.s.deleteUser(mergeUserId)
+	}
+
+	for _, other := range deleted {
+		other.TgUserId = 0
+	}
+
+	user.TgUserId = tgUserId
+
+	// the user name is kept only if no other account holds it
+	if tgUsername != "" && !tgUsernameTaken {
+		user.TgUsername = tgUsername
+	}
+
+	return nil
+}
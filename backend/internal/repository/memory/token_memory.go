@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type TokenMemory struct {
+	s *store
+}
+
+func NewTokenMemory(s *store) repository.Token {
+	return &TokenMemory{s: s}
+}
+
+// createRefreshToken stores a token checking the keys of refresh_token
+func (s *store) createRefreshToken(token models.RefreshToken) (int, error) {
+	if _, ok := s.users[token.UserId]; !ok {
+		return 0, fmt.Errorf("%s: %w", refreshTable, errForeignKey)
+	}
+
+	for _, other := range s.refreshTokens {
+		if other.TokenHash == token.TokenHash {
+			return 0, fmt.Errorf("%s: %w", refreshTable, errUnique)
+		}
+	}
+
+	token.Id = s.nextId(refreshTable)
+	token.RevokedAt = nil
+	s.refreshTokens[token.Id] = &token
+
+	return token.Id, nil
+}
+
+func (r *TokenMemory) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (int, error) {
+	const op = "repository.memory.token_memory.CreateRefreshToken"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	id, err := r.s.createRefreshToken(token)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func (r *TokenMemory) GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	const op = "repository.memory.token_memory.GetRefreshToken"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	for _, token := range r.s.refreshTokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+			found.RevokedAt = copyTime(token.RevokedAt)
+
+			return found, nil
+		}
+	}
+
+	return models.RefreshToken{}, fmt.Errorf("%s: %w", op, errNoRows)
+}
+
+/*
+RotateRefreshToken revokes the used token and stores the next one
+at once. If the used token has been revoked already (two refresh
+requests with the same token), nothing is stored
+*/
+func (r *TokenMemory) RotateRefreshToken(ctx context.Context, usedTokenId int, next models.RefreshToken) (int, error) {
+	const op = "repository.memory.token_memory.RotateRefreshToken"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	used, ok := r.s.refreshTokens[usedTokenId]
+	if !ok || used.RevokedAt != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, refreshTable, errors.New("token is already used"))
+	}
+
+	id, err := r.s.createRefreshToken(next)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+
+	revokedAt := now()
+	used.RevokedAt = &revokedAt
+
+	return id, nil
+}
+
+func (r *TokenMemory) RevokeTokenFamily(ctx context.Context, userId int, familyId string) error {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	revokedAt := now()
+
+	for _, token := range r.s.refreshTokens {
+		if token.UserId == userId && token.FamilyId == familyId && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+		}
+	}
+
+	return nil
+}
+
+func (r *TokenMemory) RevokeAccessToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error {
+	const op = "repository.memory.token_memory.RevokeAccessToken"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.revokedTokens[jti]; ok {
+		return nil
+	}
+
+	if _, ok := r.s.users[userId]; !ok {
+		return fmt.Errorf("%s:%s: %w", op, revokedTable, errForeignKey)
+	}
+
+	r.s.revokedTokens[jti] = revokedRow{UserId: userId, ExpiresAt: expiresAt}
+
+	return nil
+}
+
+/*
+RevokeAllUserTokens revokes all refresh and personal access tokens
+of a user and marks all access tokens issued so far as revoked
+*/
+func (r *TokenMemory) RevokeAllUserTokens(ctx context.Context, userId int) error {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	revokedAt := now()
+
+	for _, token := range r.s.refreshTokens {
+		if token.UserId == userId && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+		}
+	}
+
+	for _, token := range r.s.personalTokens {
+		if token.UserId == userId && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+		}
+	}
+
+	if user, ok := r.s.users[userId]; ok {
+		// access tokens are issued with milliseconds, see IsAccessTokenRevoked
+		tokensRevokedAt := revokedAt.Truncate(time.Millisecond)
+		user.TokensRevokedAt = &tokensRevokedAt
+	}
+
+	return nil
+}
+
+/*
+IsAccessTokenRevoked checks both revocation sources: the token itself
+is revoked on logout, and all tokens of the user issued before
+the user revoked them all are revoked on "log out everywhere". Tokens are
+issued with milliseconds, so a token issued in the millisecond of the
+revocation stays valid
+*/
+func (r *TokenMemory) IsAccessTokenRevoked(ctx context.Context, jti string, userId int, issuedAt time.Time) (bool, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	if _, ok := r.s.revokedTokens[jti]; ok {
+		return true, nil
+	}
+
+	user, ok := r.s.users[userId]
+	if ok && user.TokensRevokedAt != nil && user.TokensRevokedAt.After(issuedAt) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+/*
+UseOneTimeToken marks a one-time token as used. It returns false
+if the token has been used before
+*/
+func (r *TokenMemory) UseOneTimeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if _, ok := r.s.usedTokens[jti]; ok {
+		return false, nil
+	}
+
+	r.s.usedTokens[jti] = expiresAt
+
+	return true, nil
+}
+
+/*
+PurgeExpiredTokens removes used one-time tokens, revoked access tokens
+and refresh tokens which expired before expiredBefore moment
+*/
+func (r *TokenMemory) PurgeExpiredTokens(ctx context.Context, expiredBefore time.Time) (int, error) {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	purged := 0
+
+	for jti, expiresAt := range r.s.usedTokens {
+		if expiresAt.Before(expiredBefore) {
+			delete(r.s.usedTokens, jti)
+			purged++
+		}
+	}
+
+	for jti, token := range r.s.revokedTokens {
+		if token.ExpiresAt.Before(expiredBefore) {
+			delete(r.s.revokedTokens, jti)
+			purged++
+		}
+	}
+
+	for id, token := range r.s.refreshTokens {
+		if token.ExpiresAt.Before(expiredBefore) {
+			delete(r.s.refreshTokens, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+/*
+a transaction holds the lock of the store until it ends, the repositories
+called with its context take no lock of their own. The tables are copied
+when a transaction begins and put back if it fails
+*/
+
+type txKey struct{}
+
+// inTx reports if ctx is in a transaction of the store
+func (s *store) inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) == s
+}
+
+func (s *store) lock(ctx context.Context) {
+	if !s.inTx(ctx) {
+		s.mu.Lock()
+	}
+}
+
+func (s *store) unlock(ctx context.Context) {
+	if !s.inTx(ctx) {
+		s.mu.Unlock()
+	}
+}
+
+func (s *store) rlock(ctx context.Context) {
+	if !s.inTx(ctx) {
+		s.mu.RLock()
+	}
+}
+
+func (s *store) runlock(ctx context.Context) {
+	if !s.inTx(ctx) {
+		s.mu.RUnlock()
+	}
+}
+
+// copyRows copies a table which rows are changed in place
+func copyRows[K comparable, V any](rows map[K]*V) map[K]*V {
+	copied := make(map[K]*V, len(rows))
+	for key, row := range rows {
+		row := *row
+		copied[key] = &row
+	}
+
+	return copied
+}
+
+// copyValues copies a table which rows are only replaced
+func copyValues[K comparable, V any](rows map[K]V) map[K]V {
+	copied := make(map[K]V, len(rows))
+	for key, row := range rows {
+		copied[key] = row
+	}
+
+	return copied
+}
+
+// tables returns a copy of the tables of the store
+func (s *store) tables() *store {
+	roles := copyRows(s.roles)
+	for _, role := range roles {
+		role.Permissions = append([]string(nil), role.Permissions...)
+	}
+
+	return &store{
+		lastIds:        copyValues(s.lastIds),
+		users:          copyRows(s.users),
+		roles:          roles,
+		permissions:    copyValues(s.permissions),
+		habits:         copyRows(s.habits),
+		trackers:       copyRows(s.trackers),
+		userHabits:     copyRows(s.userHabits),
+		rewards:        copyRows(s.rewards),
+		userRewards:    copyRows(s.userRewards),
+		audit:          append([]models.AuditEntry(nil), s.audit...),
+		refreshTokens:  copyRows(s.refreshTokens),
+		revokedTokens:  copyValues(s.revokedTokens),
+		usedTokens:     copyValues(s.usedTokens),
+		identities:     copyRows(s.identities),
+		linkCodes:      copyValues(s.linkCodes),
+		personalTokens: copyRows(s.personalTokens),
+		idempotency:    copyRows(s.idempotency),
+	}
+}
+
+// restore puts back the tables copied by tables
+func (s *store) restore(t *store) {
+	s.lastIds = t.lastIds
+	s.users = t.users
+	s.roles = t.roles
+	s.permissions = t.permissions
+	s.habits = t.habits
+	s.trackers = t.trackers
+	s.userHabits = t.userHabits
+	s.rewards = t.rewards
+	s.userRewards = t.userRewards
+	s.audit = t.audit
+	s.refreshTokens = t.refreshTokens
+	s.revokedTokens = t.revokedTokens
+	s.usedTokens = t.usedTokens
+	s.identities = t.identities
+	s.linkCodes = t.linkCodes
+	s.personalTokens = t.personalTokens
+	s.idempotency = t.idempotency
+}
+
+type TransactorMemory struct {
+	s *store
+}
+
+func NewTransactorMemory(s *store) repository.Transactor {
+	return &TransactorMemory{s: s}
+}
+
+func (r *TransactorMemory) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !r.s.inTx(ctx) {
+		r.s.mu.Lock()
+		defer r.s.mu.Unlock()
+
+		ctx = context.WithValue(ctx, txKey{}, r.s)
+	}
+
+	tables := r.s.tables()
+
+	if err := fn(ctx); err != nil {
+		r.s.restore(tables)
+		return err
+	}
+
+	return nil
+}
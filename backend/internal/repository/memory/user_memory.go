@@ -0,0 +1,337 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type UserMemory struct {
+	s *store
+}
+
+func NewUserMemory(s *store) repository.User {
+	return &UserMemory{s: s}
+}
+
+func toGetUser(row *userRow) models.GetUser {
+	return models.GetUser{
+		Id:         row.Id,
+		Username:   row.Username,
+		TgUsername: row.TgUsername,
+		TgUserId:   row.TgUserId,
+		FirstName:  row.FirstName,
+		LastName:   row.LastName,
+		Email:      row.Email,
+		Role:       row.Role,
+		DeletedAt:  copyTime(row.DeletedAt),
+	}
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	copied := *t
+
+	return &copied
+}
+
+/*
+checkUnique checks the unique keys of user_account for a user with
+the given id. Empty values are NULLs in the SQL schema, they never clash
+*/
+func (s *store) checkUnique(user *userRow) error {
+	for id, other := range s.users {
+		if id == user.Id {
+			continue
+		}
+
+		if (user.Username != "" && user.Username == other.Username) ||
+			(user.TgUsername != "" && user.TgUsername == other.TgUsername) ||
+			(user.TgUserId != 0 && user.TgUserId == other.TgUserId) ||
+			(user.Email != "" && user.Email == other.Email) {
+			return errUnique
+		}
+	}
+
+	return nil
+}
+
+// activeUser finds a user which is not deleted, nil if there is none
+func (s *store) activeUser(userId int) *userRow {
+	user, ok := s.users[userId]
+	if !ok || user.DeletedAt != nil {
+		return nil
+	}
+
+	return user
+}
+
+func (r *UserMemory) CreateUser(ctx context.Context, user models.User) (int, error) {
+	const (
+		op         = "repository.memory.CreateUser"
+		userExists = "such user already exists"
+	)
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	row := &userRow{User: user}
+	row.Role = models.UserGeneral
+	row.DeletedAt = nil
+
+	if err := r.s.checkUnique(row); err != nil {
+		return 0, fmt.Errorf("%s: %s: %w", op, userExists, dbErr("user", err)) // for unique_violation error
+	}
+
+	row.Id = r.s.nextId(userTable)
+	r.s.users[row.Id] = row
+
+	return row.Id, nil
+}
+
+// GetUserByUsername returns a user along with the password hash
+func (r *UserMemory) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	const op = "repository.memory.GetUserByUsername"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	for _, row := range r.s.users {
+		if username != "" && row.Username == username {
+			user := row.User
+			user.DeletedAt = copyTime(row.DeletedAt)
+
+			return user, nil
+		}
+	}
+
+	return models.User{}, fmt.Errorf("%s: %w", op, errNoRows)
+}
+
+func (r *UserMemory) GetPasswordHash(ctx context.Context, userId int) (string, error) {
+	const op = "repository.memory.GetPasswordHash"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	user := r.s.activeUser(userId)
+	if user == nil {
+		return "", fmt.Errorf("%s: %w", op, errNoRows)
+	}
+
+	return user.Password, nil
+}
+
+func (r *UserMemory) UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if user, ok := r.s.users[userId]; ok {
+		user.Password = passwordHash
+	}
+
+	return nil
+}
+
+func (r *UserMemory) GetAllUsers(ctx context.Context, params models.ListParams) ([]models.GetUser, int, error) {
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	users := make([]models.GetUser, 0, len(r.s.users))
+	for _, row := range r.s.users {
+		users = append(users, toGetUser(row))
+	}
+
+	page, total := listPage(users, params, userFields)
+
+	return page, total, nil
+}
+
+func (r *UserMemory) GetUserById(ctx context.Context, userId int) (models.GetUser, error) {
+	const op = "repository.memory.GetUserById"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	row, ok := r.s.users[userId]
+	if !ok {
+		return models.GetUser{}, fmt.Errorf("%s: %w", op, dbErr("user", errNoRows))
+	}
+
+	return toGetUser(row), nil
+}
+
+// GetUserByEmail returns an active user with the email
+func (r *UserMemory) GetUserByEmail(ctx context.Context, email string) (models.GetUser, error) {
+	const op = "repository.memory.GetUserByEmail"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	for _, row := range r.s.users {
+		if email != "" && row.Email == email && row.DeletedAt == nil {
+			return toGetUser(row), nil
+		}
+	}
+
+	return models.GetUser{}, fmt.Errorf("%s: %w", op, errNoRows)
+}
+
+func (r *UserMemory) SetEmailVerified(ctx context.Context, userId int) error {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	if user, ok := r.s.users[userId]; ok && user.EmailVerifiedAt == nil {
+		verifiedAt := now()
+		user.EmailVerifiedAt = &verifiedAt
+	}
+
+	return nil
+}
+
+/*
+IsEmailVerified reports if a user has confirmed the email.
+Users without an email (telegram users) have nothing to confirm
+*/
+func (r *UserMemory) IsEmailVerified(ctx context.Context, userId int) (bool, error) {
+	const op = "repository.memory.IsEmailVerified"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	user, ok := r.s.users[userId]
+	if !ok {
+		return false, fmt.Errorf("%s: %w", op, errNoRows)
+	}
+
+	return user.Email == "" || user.EmailVerifiedAt != nil, nil
+}
+
+func (r *UserMemory) GetUserByTgUserId(ctx context.Context, tgUserId int64) (models.GetUser, error) {
+	const op = "repository.memory.GetUserByTgUserId"
+
+	r.s.rlock(ctx)
+	defer r.s.runlock(ctx)
+
+	for _, row := range r.s.users {
+		if tgUserId != 0 && row.TgUserId == tgUserId && row.DeletedAt == nil {
+			return toGetUser(row), nil
+		}
+	}
+
+	return models.GetUser{}, fmt.Errorf("%s: %w", op, dbErr("user", errNoRows))
+}
+
+/*
+ClaimTgUserId binds a telegram id to an account created by the bot before
+the ids were stored, found by the telegram user name. An account that
+already has an id is never changed. A user name can pass to another
+telegram user, so an account with web credentials is never claimed by it,
+such an account is linked by a link code only
+*/
+func (r *UserMemory) ClaimTgUserId(ctx context.Context, tgUsername string, tgUserId int64) (int, error) {
+	const op = "repository.memory.ClaimTgUserId"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	for _, row := range r.s.users {
+		if tgUsername == "" || row.TgUsername != tgUsername || row.TgUserId != 0 || row.DeletedAt != nil {
+			continue
+		}
+
+		if row.Username != "" || row.Email != "" || row.Password != "" {
+			continue
+		}
+
+		claimed := *row
+		claimed.TgUserId = tgUserId
+
+		if err := r.s.checkUnique(&claimed); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, dbErr("user", err))
+		}
+
+		row.TgUserId = tgUserId
+
+		return row.Id, nil
+	}
+
+	return 0, fmt.Errorf("%s: %w", op, dbErr("user", errNoRows))
+}
+
+/*
+DeleteUser only marks a user as deleted. The user stays in the store
+during the grace period and can be restored, after that the account
+is removed by PurgeDeletedUsers
+*/
+func (r *UserMemory) DeleteUser(ctx context.Context, userId int) (int, error) {
+	const op = "repository.memory.DeleteUser"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	user := r.s.activeUser(userId)
+	if user == nil {
+		return 0, fmt.Errorf("%s: %w", op, dbErr("user", errNoRows))
+	}
+
+	deletedAt := now()
+	user.DeletedAt = &deletedAt
+
+	return user.Id, nil
+}
+
+// RestoreUser restores a user who was deleted after deletedAfter moment
+func (r *UserMemory) RestoreUser(ctx context.Context, userId int, deletedAfter time.Time) (int, error) {
+	const op = "repository.memory.RestoreUser"
+
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	user, ok := r.s.users[userId]
+	if !ok || user.DeletedAt == nil || !user.DeletedAt.After(deletedAfter) {
+		return 0, fmt.Errorf("%s: %w", op, dbErr("user", errNoRows))
+	}
+
+	user.DeletedAt = nil
+
+	return user.Id, nil
+}
+
+/*
+PurgeDeletedUsers permanently removes users deleted before deletedBefore
+moment along with their habits, trackers and rewards
+*/
+func (r *UserMemory) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]int, error) {
+	r.s.lock(ctx)
+	defer r.s.unlock(ctx)
+
+	userIds := []int{}
+
+	for id, user := range r.s.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(deletedBefore) {
+			userIds = append(userIds, id)
+		}
+	}
+
+	sort.Ints(userIds)
+
+	for _, userId := range userIds {
+		for _, link := range r.s.userHabits {
+			if link.UserId == userId {
+				r.s.deleteHabit(link)
+			}
+		}
+
+		r.s.deleteUser(userId)
+	}
+
+	return userIds, nil
+}
@@ -18,7 +18,7 @@ func NewAdminRewardPostgres(dbpool *pgxpool.Pool) repository.AdminReward {
 	return &AdminRewardPostgres{dbpool: dbpool}
 }
 
-func (r *AdminRewardPostgres) Create(reward models.Reward) (int, error) {
+func (r *AdminRewardPostgres) Create(ctx context.Context, reward models.Reward) (int, error) {
 	const op = "repository.postgres.admin_reward_postgres.Create"
 
 	var rewardId int
@@ -26,16 +26,16 @@ func (r *AdminRewardPostgres) Create(reward models.Reward) (int, error) {
 						reward (title, description) 
 						VALUES ($1, $2) 
 					RETURNING id`
-	// ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	row := r.dbpool.QueryRow(context.Background(), query, reward.Title, reward.Description)
+	// ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, reward.Title, reward.Description)
 	if err := row.Scan(&rewardId); err != nil {
-		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("reward", err))
 	}
 
 	return rewardId, nil
 }
 
-func (r *AdminRewardPostgres) GetById(rewardId int) (models.Reward, error) {
+func (r *AdminRewardPostgres) GetById(ctx context.Context, rewardId int) (models.Reward, error) {
 	const op = "repository.postgres.admin_reward_postgres.GetById"
 
 	var reward models.Reward
@@ -48,7 +48,7 @@ func (r *AdminRewardPostgres) GetById(rewardId int) (models.Reward, error) {
 					reward
 				WHERE id = $1`
 
-	rowReward, err := r.dbpool.Query(context.Background(), query, rewardId)
+	rowReward, err := conn(ctx, r.dbpool).Query(ctx, query, rewardId)
 	if err != nil {
 		return reward, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
@@ -57,43 +57,52 @@ func (r *AdminRewardPostgres) GetById(rewardId int) (models.Reward, error) {
 
 	reward, err = pgx.CollectOneRow(rowReward, pgx.RowToStructByName[models.Reward])
 	if err != nil {
-		return reward, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return reward, fmt.Errorf("%s:%s: %w", op, collectErr, dbErr("reward", err))
 	}
 
 	return reward, err
 }
 
-func (r *AdminRewardPostgres) GetAllRewards() ([]models.Reward, error) {
+func (r *AdminRewardPostgres) GetAllRewards(ctx context.Context, params models.ListParams) ([]models.Reward, int, error) {
 	const op = "repository.postgres.admin_reward_postgres.GetAllRewards"
 
 	var rewards []models.Reward
 
+	where, args := listWhere(nil, nil, params.Filters, rewardColumns)
+
+	total, err := countRows(ctx, r.dbpool, `SELECT count(*) FROM reward tl`+where, args)
+	if err != nil {
+		return rewards, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, rewardColumns, args)
+
 	query := `SELECT 
-					id, 
-					title, 
-					description 
+					tl.id, 
+					tl.title, 
+					tl.description 
 				FROM 
-					reward`
+					reward tl` + where + tail
 
-	rowsRewards, err := r.dbpool.Query(context.Background(), query)
+	rowsRewards, err := conn(ctx, r.dbpool).Query(ctx, query, args...)
 	if err != nil {
-		return rewards, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+		return rewards, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
 
 	defer rowsRewards.Close()
 
 	rewards, err = pgx.CollectRows(rowsRewards, pgx.RowToStructByName[models.Reward])
 	if err != nil {
-		return rewards, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return rewards, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
 	}
 
-	return rewards, err
+	return rewards, total, err
 }
 
-func (r *AdminRewardPostgres) Delete(rewardId int) error {
+func (r *AdminRewardPostgres) Delete(ctx context.Context, rewardId int) error {
 	const op = "repository.postgres.admin_reward_postgres.Delete"
 
-	tx, err := r.dbpool.Begin(context.Background())
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -105,17 +114,17 @@ func (r *AdminRewardPostgres) Delete(rewardId int) error {
 
 	var checkrewardId int
 
-	rowReward := tx.QueryRow(context.Background(), queryReward, rewardId)
+	rowReward := tx.QueryRow(ctx, queryReward, rewardId)
 	err = rowReward.Scan(&checkrewardId)
 	if err != nil {
-		tx.Rollback(context.Background())
-		return fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("reward", err))
 	}
 
-	return tx.Commit(context.Background())
+	return tx.Commit(ctx)
 }
 
-func (r *AdminRewardPostgres) UpdateReward(rewardId int, input models.UpdateRewardInput) error {
+func (r *AdminRewardPostgres) UpdateReward(ctx context.Context, rewardId int, input models.UpdateRewardInput) error {
 	const op = "repository.postgres.admin_reward_postgres.UpdateReward"
 
 	query := `UPDATE 
@@ -128,10 +137,10 @@ func (r *AdminRewardPostgres) UpdateReward(rewardId int, input models.UpdateRewa
 
 	var checkRewardId int
 
-	rowRewardd := r.dbpool.QueryRow(context.Background(), query, rewardId, input.Title, input.Description)
+	rowRewardd := conn(ctx, r.dbpool).QueryRow(ctx, query, rewardId, input.Title, input.Description)
 	err := rowRewardd.Scan(&checkRewardId)
 	if err != nil {
-		return fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		return fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("reward", err))
 	}
 
 	return err
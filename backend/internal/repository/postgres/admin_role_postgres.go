@@ -6,9 +6,18 @@ import (
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const roleSelect = `SELECT 
+						r.name,
+						COALESCE(r.description, '') AS description,
+						COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}') AS permissions 
+					FROM 
+						role r 
+					LEFT JOIN role_permission rp ON rp.role_name = r.name`
+
 type AdminRolePostgres struct {
 	dbpool *pgxpool.Pool
 }
@@ -17,7 +26,7 @@ func NewAdminRolePostgres(dbpool *pgxpool.Pool) repository.AdminRole {
 	return &AdminRolePostgres{dbpool: dbpool}
 }
 
-func (r *AdminRolePostgres) AssignRole(userId int, role models.UpdateRoleInput) (int, error) {
+func (r *AdminRolePostgres) AssignRole(ctx context.Context, userId int, role models.UpdateRoleInput) (int, error) {
 	const op = "repository.postgres.AssignRole"
 
 	var id int
@@ -29,10 +38,168 @@ func (r *AdminRolePostgres) AssignRole(userId int, role models.UpdateRoleInput)
 				WHERE id =$1
 				RETURNING id`
 
-	row := r.dbpool.QueryRow(context.Background(), query, userId, role.Role)
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, userId, role.Role)
 	if err := row.Scan(&id); err != nil {
-		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
 	}
 
 	return id, nil
 }
+
+func (r *AdminRolePostgres) GetAllRoles(ctx context.Context) ([]models.Role, error) {
+	const op = "repository.postgres.GetAllRoles"
+
+	query := roleSelect + ` GROUP BY r.name ORDER BY r.name`
+
+	rowsRoles, err := conn(ctx, r.dbpool).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	defer rowsRoles.Close()
+
+	roles, err := pgx.CollectRows(rowsRoles, pgx.RowToStructByName[models.Role])
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return roles, nil
+}
+
+func (r *AdminRolePostgres) GetRole(ctx context.Context, name string) (models.Role, error) {
+	const op = "repository.postgres.GetRole"
+
+	var role models.Role
+
+	query := roleSelect + ` WHERE r.name=$1 GROUP BY r.name`
+
+	rowRole, err := conn(ctx, r.dbpool).Query(ctx, query, name)
+	if err != nil {
+		return role, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	defer rowRole.Close()
+
+	role, err = pgx.CollectOneRow(rowRole, pgx.RowToStructByName[models.Role])
+	if err != nil {
+		return role, fmt.Errorf("%s:%s: %w", op, collectErr, dbErr("role", err))
+	}
+
+	return role, nil
+}
+
+func (r *AdminRolePostgres) CreateRole(ctx context.Context, role models.Role) error {
+	const op = "repository.postgres.CreateRole"
+
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `INSERT INTO 
+					role (name, description) 
+					VALUES ($1, NULLIF($2, ''))`
+
+	if _, err := tx.Exec(ctx, query, role.Name, role.Description); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, roleTable, dbErr("role", err))
+	}
+
+	if err := setRolePermissions(ctx, tx, role); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpdateRole replaces the description and the permissions of a role
+func (r *AdminRolePostgres) UpdateRole(ctx context.Context, role models.Role) error {
+	const op = "repository.postgres.UpdateRole"
+
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `UPDATE 
+					role 
+				SET 
+					description = NULLIF($2, '') 
+				WHERE name=$1`
+
+	if _, err := tx.Exec(ctx, query, role.Name, role.Description); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, roleTable, err)
+	}
+
+	queryClear := `DELETE FROM role_permission WHERE role_name=$1`
+
+	if _, err := tx.Exec(ctx, queryClear, role.Name); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, rolePermissionTable, err)
+	}
+
+	if err := setRolePermissions(ctx, tx, role); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func setRolePermissions(ctx context.Context, tx pgx.Tx, role models.Role) error {
+	query := `INSERT INTO 
+					role_permission (role_name, permission) 
+					SELECT $1, unnest($2::varchar[])`
+
+	if _, err := tx.Exec(ctx, query, role.Name, role.Permissions); err != nil {
+		return fmt.Errorf("%s: %w", rolePermissionTable, err)
+	}
+
+	return nil
+}
+
+/*
+DeleteRole removes a role that no user has. It returns false
+if the role is not found or is still in use
+*/
+func (r *AdminRolePostgres) DeleteRole(ctx context.Context, name string) (bool, error) {
+	const op = "repository.postgres.DeleteRole"
+
+	query := `DELETE FROM 
+					role 
+				WHERE name=$1 AND NOT EXISTS (SELECT 1 FROM user_account WHERE role=$1)`
+
+	tag, err := conn(ctx, r.dbpool).Exec(ctx, query, name)
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, roleTable, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *AdminRolePostgres) GetAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	const op = "repository.postgres.GetAllPermissions"
+
+	query := `SELECT 
+					name,
+					COALESCE(description, '') AS description 
+				FROM 
+					permission 
+				ORDER BY name`
+
+	rowsPermissions, err := conn(ctx, r.dbpool).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	defer rowsPermissions.Close()
+
+	permissions, err := pgx.CollectRows(rowsPermissions, pgx.RowToStructByName[models.Permission])
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return permissions, nil
+}
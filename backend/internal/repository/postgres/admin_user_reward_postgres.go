@@ -18,10 +18,10 @@ func NewAdminUserRewardPostgres(dbpool *pgxpool.Pool) repository.AdminUserReward
 	return &AdminUserRewardPostgres{dbpool: dbpool}
 }
 
-func (r *AdminUserRewardPostgres) AssignReward(userId, habitId, rewardId int) (int, error) {
+func (r *AdminUserRewardPostgres) AssignReward(ctx context.Context, userId, habitId, rewardId int) (int, error) {
 	const op = "repository.postgres.AssignReward"
 
-	tx, err := r.dbpool.Begin(context.Background())
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -42,20 +42,20 @@ func (r *AdminUserRewardPostgres) AssignReward(userId, habitId, rewardId int) (i
 
 							RETURNING id`
 
-	rowUserReward := tx.QueryRow(context.Background(), assignRewardQuery, userId, habitId, rewardId)
+	rowUserReward := tx.QueryRow(ctx, assignRewardQuery, userId, habitId, rewardId)
 	if err := rowUserReward.Scan(&userRewardId); err != nil {
-		tx.Rollback(context.Background())
-		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		tx.Rollback(ctx)
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user_reward", err))
 	}
 
-	return userRewardId, tx.Commit(context.Background())
+	return userRewardId, tx.Commit(ctx)
 }
 
 // Take away from user
-func (r *AdminUserRewardPostgres) RemoveFromUser(userId, habitId, rewardId int) error {
+func (r *AdminUserRewardPostgres) RemoveFromUser(ctx context.Context, userId, habitId, rewardId int) error {
 	const op = "repository.postgres.RemoveFromUser"
 
-	tx, err := r.dbpool.Begin(context.Background())
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -67,12 +67,12 @@ func (r *AdminUserRewardPostgres) RemoveFromUser(userId, habitId, rewardId int)
 							WHERE user_id = $1 AND habit_id = $2 AND reward_id=$3
 							RETURNING id`
 
-	rowUserReward := tx.QueryRow(context.Background(), queryUserReward, userId, habitId, rewardId)
+	rowUserReward := tx.QueryRow(ctx, queryUserReward, userId, habitId, rewardId)
 
 	err = rowUserReward.Scan(&checkUserRewardId)
 	if err != nil {
-		tx.Rollback(context.Background())
-		return fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user_reward", err))
 	}
 
 	// queryUserReward := `IF EXISTS (
@@ -93,20 +93,20 @@ func (r *AdminUserRewardPostgres) RemoveFromUser(userId, habitId, rewardId int)
 	// 						RAISERROR ('No rows found', 16, 1)
 	// 					END`
 
-	// _, err = tx.Exec(context.Background(), queryUserReward, userId, rewardId)
+	// _, err = tx.Exec(ctx, queryUserReward, userId, rewardId)
 	// if err != nil {
-	// 	tx.Rollback(context.Background())
+	// 	tx.Rollback(ctx)
 	// 	fmt.Printf("err: repository: admin_user_reward_postgres.go: RemoveFromUser: rowUserReward.Scan: user_reward doesn't exist: %v\n", err)
 	// 	return err
 	// }
 
-	return tx.Commit(context.Background())
+	return tx.Commit(ctx)
 }
 
-func (r *AdminUserRewardPostgres) UpdateUserReward(userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
+func (r *AdminUserRewardPostgres) UpdateUserReward(ctx context.Context, userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
 	const op = "repository.postgres.UpdateUserReward"
 
-	tx, err := r.dbpool.Begin(context.Background())
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -121,13 +121,13 @@ func (r *AdminUserRewardPostgres) UpdateUserReward(userId, habitId, rewardId int
 				WHERE user_id = $1 AND habit_id = $2 AND reward_id=$3
 				RETURNING id`
 
-	rowUserReward := r.dbpool.QueryRow(context.Background(), query, userId, habitId, rewardId, input.HabitId, input.RewardId)
+	rowUserReward := conn(ctx, r.dbpool).QueryRow(ctx, query, userId, habitId, rewardId, input.HabitId, input.RewardId)
 	err = rowUserReward.Scan(&userRewardId)
 
 	if err != nil {
-		tx.Rollback(context.Background())
-		return fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user_reward", err))
 	}
 
-	return tx.Commit(context.Background())
+	return tx.Commit(ctx)
 }
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditPostgres struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewAuditPostgres(dbpool *pgxpool.Pool) repository.Audit {
+	return &AuditPostgres{dbpool: dbpool}
+}
+
+func (r *AuditPostgres) Create(ctx context.Context, entry models.AuditEntry) (int, error) {
+	const op = "repository.postgres.audit_postgres.Create"
+
+	var id int
+	query := `INSERT INTO 
+						audit_log (actor_id, target_user_id, action, before, after, request_id) 
+						VALUES ($1, NULLIF($2, 0), $3, $4, $5, NULLIF($6, '')) 
+					RETURNING id`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, entry.ActorId, entry.TargetUserId, entry.Action, entry.Before, entry.After, entry.RequestId)
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, nil
+}
+
+func (r *AuditPostgres) GetAll(ctx context.Context, params models.ListParams) ([]models.AuditEntry, int, error) {
+	const op = "repository.postgres.audit_postgres.GetAll"
+
+	var entries []models.AuditEntry
+
+	where, args := listWhere(nil, nil, params.Filters, auditColumns)
+
+	total, err := countRows(ctx, r.dbpool, `SELECT count(*) FROM audit_log`+where, args)
+	if err != nil {
+		return entries, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, auditColumns, args)
+
+	query := `SELECT 
+					id,
+					actor_id,
+					COALESCE(target_user_id, 0) AS target_user_id,
+					action,
+					before,
+					after,
+					COALESCE(request_id, '') AS request_id,
+					created_at
+				FROM 
+					audit_log` + where + tail
+
+	rowsEntries, err := conn(ctx, r.dbpool).Query(ctx, query, args...)
+	if err != nil {
+		return entries, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	defer rowsEntries.Close()
+
+	entries, err = pgx.CollectRows(rowsEntries, pgx.RowToStructByName[models.AuditEntry])
+	if err != nil {
+		return entries, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return entries, total, err
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
@@ -19,10 +20,10 @@ func NewHabitPostgres(dbpool *pgxpool.Pool) repository.Habit {
 	return &HabitPostgres{dbpool: dbpool}
 }
 
-func (r *HabitPostgres) Create(userId int, habit models.Habit) (int, error) {
+func (r *HabitPostgres) Create(ctx context.Context, userId int, habit models.Habit) (int, error) {
 	const op = "repository.postgres.habit_postgres.Create"
 
-	tx, err := r.dbpool.Begin(context.Background())
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -34,9 +35,9 @@ func (r *HabitPostgres) Create(userId int, habit models.Habit) (int, error) {
 								VALUES ($1, $2) 
 							RETURNING id`
 
-	rowHabit := tx.QueryRow(context.Background(), createHabitQuery, habit.Title, habit.Description)
+	rowHabit := tx.QueryRow(ctx, createHabitQuery, habit.Title, habit.Description)
 	if err := rowHabit.Scan(&habitId); err != nil {
-		tx.Rollback(context.Background())
+		tx.Rollback(ctx)
 		return 0, fmt.Errorf("%s:%s: %w", op, habitTable, err)
 	}
 
@@ -47,10 +48,10 @@ func (r *HabitPostgres) Create(userId int, habit models.Habit) (int, error) {
 										VALUES ($1) 
 									RETURNING id`
 
-	rowTracker := tx.QueryRow(context.Background(), createHabitTrackerQuery, habitId)
+	rowTracker := tx.QueryRow(ctx, createHabitTrackerQuery, habitId)
 	err = rowTracker.Scan(&trackerId)
 	if err != nil {
-		tx.Rollback(context.Background())
+		tx.Rollback(ctx)
 		return 0, fmt.Errorf("%s:%s: %w", op, trackerTable, err)
 	}
 
@@ -59,43 +60,56 @@ func (r *HabitPostgres) Create(userId int, habit models.Habit) (int, error) {
 										user_habit (user_id, habit_id, habit_tracker_id) 
 										VALUES ($1, $2, $3)`
 
-	_, err = tx.Exec(context.Background(), createUsersHabitsQuery, userId, habitId, trackerId)
+	_, err = tx.Exec(ctx, createUsersHabitsQuery, userId, habitId, trackerId)
 	if err != nil {
-		tx.Rollback(context.Background())
+		tx.Rollback(ctx)
 		return 0, fmt.Errorf("%s:%s: %w", op, userHabitTable, err)
 	}
 
-	return habitId, tx.Commit(context.Background())
+	return habitId, tx.Commit(ctx)
 }
 
-func (r *HabitPostgres) GetAll(userId int) ([]models.Habit, error) {
+func (r *HabitPostgres) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.Habit, int, error) {
 	const op = "repository.postgres.habit_postgres.GetAll"
 
 	var habits []models.Habit
+
+	where, args := listWhere([]string{"ul.user_id = $1"}, []any{userId}, params.Filters, habitColumns)
+
+	total, err := countRows(ctx, r.dbpool, `SELECT 
+											count(*) 
+										FROM 
+											habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id`+where, args)
+	if err != nil {
+		return habits, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, habitColumns, args)
+
 	query := `SELECT 
 					tl.id, 
 					tl.title, 
-					tl.description 
+					tl.description,
+					tl.version 
 				FROM 
-					habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id 
-				WHERE ul.user_id = $1`
+					habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id` + where + tail
 
-	rowsHabits, err := r.dbpool.Query(context.Background(), query, userId)
+	rowsHabits, err := conn(ctx, r.dbpool).Query(ctx, query, args...)
 	if err != nil {
-		return habits, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+		return habits, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
 
 	defer rowsHabits.Close()
 
 	habits, err = pgx.CollectRows(rowsHabits, pgx.RowToStructByName[models.Habit])
 	if err != nil {
-		return habits, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return habits, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
 	}
 
-	return habits, err
+	return habits, total, err
 }
 
-func (r *HabitPostgres) GetById(userId, habitId int) (models.Habit, error) {
+func (r *HabitPostgres) GetById(ctx context.Context, userId, habitId int) (models.Habit, error) {
 	const op = "repository.postgres.habit_postgres.GetById"
 
 	var habit models.Habit
@@ -103,12 +117,13 @@ func (r *HabitPostgres) GetById(userId, habitId int) (models.Habit, error) {
 	query := `SELECT 
 					tl.id, 
 					tl.title, 
-					tl.description 
+					tl.description,
+					tl.version 
 				FROM 
 					habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id 
 				WHERE ul.user_id = $1 AND ul.habit_id = $2`
 
-	rowHabit, err := r.dbpool.Query(context.Background(), query, userId, habitId)
+	rowHabit, err := conn(ctx, r.dbpool).Query(ctx, query, userId, habitId)
 	if err != nil {
 		return habit, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
@@ -117,16 +132,16 @@ func (r *HabitPostgres) GetById(userId, habitId int) (models.Habit, error) {
 
 	habit, err = pgx.CollectOneRow(rowHabit, pgx.RowToStructByName[models.Habit])
 	if err != nil {
-		return habit, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return habit, fmt.Errorf("%s:%s: %w", op, collectErr, dbErr("habit", err))
 	}
 
 	return habit, err
 }
 
-func (r *HabitPostgres) Delete(userId, habitId int) error {
+func (r *HabitPostgres) Delete(ctx context.Context, userId, habitId int) error {
 	const op = "repository.postgres.habit_postgres.Delete"
 
-	tx, err := r.dbpool.Begin(context.Background())
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -138,11 +153,11 @@ func (r *HabitPostgres) Delete(userId, habitId int) error {
 
 	var checkTrackerId int
 
-	rowTracker := tx.QueryRow(context.Background(), queryTracker, userId, habitId)
+	rowTracker := tx.QueryRow(ctx, queryTracker, userId, habitId)
 	err = rowTracker.Scan(&checkTrackerId)
 	if err != nil {
-		tx.Rollback(context.Background())
-		return fmt.Errorf("%s:%s: %w", op, trackerTable, err)
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, trackerTable, dbErr("habit", err))
 	}
 
 	query := `DELETE FROM 
@@ -152,35 +167,46 @@ func (r *HabitPostgres) Delete(userId, habitId int) error {
 
 	var checkHabitId int
 
-	rowHabit := tx.QueryRow(context.Background(), query, userId, habitId)
+	rowHabit := tx.QueryRow(ctx, query, userId, habitId)
 	err = rowHabit.Scan(&checkHabitId)
 	if err != nil {
-		tx.Rollback(context.Background())
-		return fmt.Errorf("%s:%s: %w", op, habitTable, err)
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, habitTable, dbErr("habit", err))
 	}
 
-	return tx.Commit(context.Background())
+	return tx.Commit(ctx)
 }
 
-func (r *HabitPostgres) Update(userId, habitId int, input models.UpdateHabitInput) error {
+/*
+Update changes a habit and increments its version. If input.Versions is set,
+the habit is changed only if it still has one of those versions
+*/
+func (r *HabitPostgres) Update(ctx context.Context, userId, habitId int, input models.UpdateHabitInput) (int, error) {
 	const op = "repository.postgres.habit_postgres.Update"
 
 	query := `UPDATE 
 					habit tl 
 				SET 
 					title=COALESCE($3, title), 
-					description=COALESCE($4, description)
+					description=COALESCE($4, description),
+					version=tl.version + 1
 				FROM user_habit ul 
 					WHERE tl.id = ul.habit_id AND ul.user_id=$1 AND ul.habit_id=$2
-					RETURNING tl.id`
+						AND ($5::int[] IS NULL OR tl.version = ANY($5))
+					RETURNING tl.version`
 
-	var checkHabitId int
+	var version int
 
-	rowHabit := r.dbpool.QueryRow(context.Background(), query, userId, habitId, input.Title, input.Description)
-	err := rowHabit.Scan(&checkHabitId)
+	rowHabit := conn(ctx, r.dbpool).QueryRow(ctx, query, userId, habitId, input.Title, input.Description, input.Versions)
+	err := rowHabit.Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) && input.Versions != nil {
+		if _, getErr := r.GetById(ctx, userId, habitId); getErr == nil {
+			return 0, fmt.Errorf("%s: %w", op, repository.ErrVersionMismatch)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("habit", err))
 	}
 
-	return err
+	return version, nil
 }
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
@@ -18,7 +19,7 @@ func NewHabitTrackerPostgres(dbpool *pgxpool.Pool) repository.HabitTracker {
 	return &HabitTrackerPostgres{dbpool: dbpool}
 }
 
-func (r *HabitTrackerPostgres) GetById(userId, habitId int) (models.HabitTracker, error) {
+func (r *HabitTrackerPostgres) GetById(ctx context.Context, userId, habitId int) (models.HabitTracker, error) {
 	const op = "repository.postgres.habit_tracker_postgres.GetById"
 
 	var habitTracker models.HabitTracker
@@ -32,7 +33,8 @@ func (r *HabitTrackerPostgres) GetById(userId, habitId int) (models.HabitTracker
 					tl.start_date,
 					COALESCE(tl.end_date, CURRENT_DATE) as end_date,
 					COALESCE(tl.counter, 0) as counter,
-					tl.done 
+					tl.done,
+					tl.version 
 				FROM 
 					habit_tracker tl INNER JOIN user_habit ul on tl.id = ul.habit_tracker_id 
 				WHERE ul.user_id = $1 AND ul.habit_id = $2`
@@ -42,23 +44,36 @@ func (r *HabitTrackerPostgres) GetById(userId, habitId int) (models.HabitTracker
 		https://www.commandprompt.com/education/postgresql-dateadd-equivalent-how-to-add-interval-to-datetime/
 	*/
 
-	rowTracker, err := r.dbpool.Query(context.Background(), query, userId, habitId)
+	rowTracker, err := conn(ctx, r.dbpool).Query(ctx, query, userId, habitId)
 	if err != nil {
 		return habitTracker, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
 
 	habitTracker, err = pgx.CollectOneRow(rowTracker, pgx.RowToStructByName[models.HabitTracker])
 	if err != nil {
-		return habitTracker, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return habitTracker, fmt.Errorf("%s:%s: %w", op, collectErr, dbErr("habit_tracker", err))
 	}
 
 	return habitTracker, err
 }
 
-func (r *HabitTrackerPostgres) GetAll(userId int) ([]models.HabitTracker, error) {
+func (r *HabitTrackerPostgres) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.HabitTracker, int, error) {
 	const op = "repository.postgres.habit_tracker_postgres.GetAll"
 
 	var trackers []models.HabitTracker
+
+	where, args := listWhere([]string{"ul.user_id = $1"}, []any{userId}, params.Filters, trackerColumns)
+
+	total, err := countRows(ctx, r.dbpool, `SELECT 
+											count(*) 
+										FROM 
+											habit_tracker tl INNER JOIN user_habit ul on tl.id = ul.habit_tracker_id`+where, args)
+	if err != nil {
+		return trackers, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, trackerColumns, args)
+
 	query := `SELECT 
 					tl.id, 
 					tl.habit_id, 
@@ -68,28 +83,32 @@ func (r *HabitTrackerPostgres) GetAll(userId int) ([]models.HabitTracker, error)
 					tl.start_date,
 					COALESCE(tl.end_date, CURRENT_DATE) as end_date,
 					COALESCE(tl.counter, 0) as counter,
-					tl.done 
+					tl.done,
+					tl.version 
 				FROM 
-					habit_tracker tl INNER JOIN user_habit ul on tl.id = ul.habit_tracker_id 
-				WHERE 
-					ul.user_id = $1`
+					habit_tracker tl INNER JOIN user_habit ul on tl.id = ul.habit_tracker_id` + where + tail
 
-	rowsTrackers, err := r.dbpool.Query(context.Background(), query, userId)
+	rowsTrackers, err := conn(ctx, r.dbpool).Query(ctx, query, args...)
 	if err != nil {
-		return trackers, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+		return trackers, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
 
 	defer rowsTrackers.Close()
 
 	trackers, err = pgx.CollectRows(rowsTrackers, pgx.RowToStructByName[models.HabitTracker])
 	if err != nil {
-		return trackers, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return trackers, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
 	}
 
-	return trackers, err
+	return trackers, total, err
 }
 
-func (r *HabitTrackerPostgres) Update(userId, habitId int, input models.UpdateTrackerInput) error {
+/*
+Update changes a tracker and increments its version. If input.Versions is set,
+the tracker is changed only if it still has one of those versions, so an edit made
+from another client in the meantime is not overwritten
+*/
+func (r *HabitTrackerPostgres) Update(ctx context.Context, userId, habitId int, input models.UpdateTrackerInput) (int, error) {
 	const op = "repository.postgres.habit_tracker_postgres.Update"
 
 	query := `UPDATE 
@@ -101,20 +120,27 @@ func (r *HabitTrackerPostgres) Update(userId, habitId int, input models.UpdateTr
 					start_date=COALESCE($6, start_date),
 					end_date=COALESCE($7, end_date),
 					counter=COALESCE($8, counter),
-					done=COALESCE($9, done) 
+					done=COALESCE($9, done),
+					version=tl.version + 1 
 				FROM user_habit ul 
 					WHERE tl.id = ul.habit_tracker_id AND ul.habit_id=$2 AND ul.user_id=$1
-					RETURNING tl.id`
+						AND ($10::int[] IS NULL OR tl.version = ANY($10))
+					RETURNING tl.version`
 
-	var checkTrackerId int
+	var version int
 
-	rowTracker := r.dbpool.QueryRow(context.Background(), query, userId, habitId, input.UnitOfMessure, input.Goal, input.Frequency, input.StartDate, input.EndDate, input.Counter, input.Done)
-	err := rowTracker.Scan(&checkTrackerId)
+	rowTracker := conn(ctx, r.dbpool).QueryRow(ctx, query, userId, habitId, input.UnitOfMessure, input.Goal, input.Frequency, input.StartDate, input.EndDate, input.Counter, input.Done, input.Versions)
+	err := rowTracker.Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) && input.Versions != nil {
+		if _, getErr := r.GetById(ctx, userId, habitId); getErr == nil {
+			return 0, fmt.Errorf("%s: %w", op, repository.ErrVersionMismatch)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("habit_tracker", err))
 	}
 
-	return err
+	return version, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyPostgres struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewIdempotencyPostgres(dbpool *pgxpool.Pool) repository.Idempotency {
+	return &IdempotencyPostgres{dbpool: dbpool}
+}
+
+/*
+Reserve stores a key of a request in progress. It reports false if the
+key is taken by a record which is not expired. Expired keys of the user
+are removed on the way, so the table does not grow with old keys
+*/
+func (r *IdempotencyPostgres) Reserve(ctx context.Context, record models.IdempotencyRecord) (bool, error) {
+	const op = "repository.postgres.idempotency_postgres.Reserve"
+
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	queryExpired := `DELETE FROM
+						idempotency_key
+					WHERE user_id=$1 AND expires_at <= now()`
+
+	if _, err := tx.Exec(ctx, queryExpired, record.UserId); err != nil {
+		tx.Rollback(ctx)
+		return false, fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	queryReserve := `INSERT INTO
+						idempotency_key (user_id, idem_key, request_hash, expires_at)
+						VALUES ($1, $2, $3, $4)
+					ON CONFLICT (user_id, idem_key) DO NOTHING`
+
+	tag, err := tx.Exec(ctx, queryReserve, record.UserId, record.Key, record.RequestHash, record.ExpiresAt)
+	if err != nil {
+		tx.Rollback(ctx)
+		return false, fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *IdempotencyPostgres) Get(ctx context.Context, userId int, key string) (models.IdempotencyRecord, error) {
+	const op = "repository.postgres.idempotency_postgres.Get"
+
+	var record models.IdempotencyRecord
+	query := `SELECT
+					user_id,
+					idem_key,
+					request_hash,
+					status_code,
+					response_body,
+					response_headers,
+					expires_at
+				FROM
+					idempotency_key
+				WHERE user_id=$1 AND idem_key=$2`
+
+	rowRecord, err := conn(ctx, r.dbpool).Query(ctx, query, userId, key)
+	if err != nil {
+		return record, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	record, err = pgx.CollectOneRow(rowRecord, pgx.RowToStructByName[models.IdempotencyRecord])
+	if err != nil {
+		return record, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return record, nil
+}
+
+// Complete stores the response to the request the key was reserved for
+func (r *IdempotencyPostgres) Complete(ctx context.Context, userId int, key string, statusCode int, headers map[string]string, body []byte) error {
+	const op = "repository.postgres.idempotency_postgres.Complete"
+
+	query := `UPDATE
+					idempotency_key
+				SET
+					status_code = $3,
+					response_headers = $4,
+					response_body = $5
+				WHERE user_id=$1 AND idem_key=$2`
+
+	if _, err := conn(ctx, r.dbpool).Exec(ctx, query, userId, key, statusCode, headers, body); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	return nil
+}
+
+// Delete frees a key, so the request can be sent again with it
+func (r *IdempotencyPostgres) Delete(ctx context.Context, userId int, key string) error {
+	const op = "repository.postgres.idempotency_postgres.Delete"
+
+	query := `DELETE FROM
+					idempotency_key
+				WHERE user_id=$1 AND idem_key=$2`
+
+	if _, err := conn(ctx, r.dbpool).Exec(ctx, query, userId, key); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	return nil
+}
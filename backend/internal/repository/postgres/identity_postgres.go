@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdentityPostgres struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewIdentityPostgres(dbpool *pgxpool.Pool) repository.Identity {
+	return &IdentityPostgres{dbpool: dbpool}
+}
+
+func (r *IdentityPostgres) Create(ctx context.Context, identity models.Identity) (int, error) {
+	const op = "repository.postgres.identity_postgres.Create"
+
+	var id int
+	query := `INSERT INTO 
+						user_identity (user_id, provider, subject, email) 
+						VALUES ($1, $2, $3, NULLIF($4, '')) 
+					RETURNING id`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, identity.UserId, identity.Provider, identity.Subject, identity.Email)
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, nil
+}
+
+// GetUserId returns the id of a user linked to the identity
+func (r *IdentityPostgres) GetUserId(ctx context.Context, provider, subject string) (int, error) {
+	const op = "repository.postgres.identity_postgres.GetUserId"
+
+	var userId int
+	query := `SELECT 
+					user_id 
+				FROM 
+					user_identity 
+				WHERE provider=$1 AND subject=$2`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, provider, subject)
+	if err := row.Scan(&userId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return userId, nil
+}
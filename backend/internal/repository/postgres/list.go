@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+/*
+listColumns maps field names of models.ListFields to the table columns
+used in a query. Only mapped fields can get to sql, so user input
+never becomes a part of a query text
+*/
+type listColumns map[string]string
+
+var (
+	habitColumns = listColumns{
+		"id":    "tl.id",
+		"title": "tl.title",
+	}
+
+	trackerColumns = listColumns{
+		"id":              "tl.id",
+		"habit_id":        "tl.habit_id",
+		"start_date":      "tl.start_date",
+		"end_date":        "tl.end_date",
+		"counter":         "tl.counter",
+		"unit_of_messure": "tl.unit_of_messure",
+		"frequency":       "tl.frequency",
+		"done":            "tl.done",
+	}
+
+	rewardColumns = listColumns{
+		"id":    "tl.id",
+		"title": "tl.title",
+	}
+
+	userColumns = listColumns{
+		"id":           "id",
+		"user_name":    "user_name",
+		"tg_user_name": "tg_user_name",
+		"email":        "email",
+		"role":         "role",
+	}
+
+	auditColumns = listColumns{
+		"id":             "id",
+		"created_at":     "created_at",
+		"actor_id":       "actor_id",
+		"target_user_id": "target_user_id",
+		"action":         "action",
+		"request_id":     "request_id",
+	}
+)
+
+// likeEscaper makes the wildcards of LIKE in a filter value match themselves
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+/*
+listWhere joins base conditions of a query with the filters of
+list params. Filter values are passed as arguments placed after
+the arguments that the base conditions already use
+*/
+func listWhere(conditions []string, args []any, filters []models.Filter, columns listColumns) (string, []any) {
+	for _, filter := range filters {
+		column, ok := columns[filter.Field]
+		if !ok {
+			continue
+		}
+
+		// values are already validated by models.ParseListParams
+		var value any = filter.Value
+		switch filter.Kind {
+		case models.FilterContains:
+			value = likeEscaper.Replace(filter.Value)
+		case models.FilterBool:
+			value, _ = strconv.ParseBool(filter.Value)
+		case models.FilterInt:
+			value, _ = strconv.Atoi(filter.Value)
+		}
+
+		args = append(args, value)
+		placeholder := fmt.Sprintf("$%d", len(args))
+
+		if filter.Kind == models.FilterContains {
+			conditions = append(conditions, fmt.Sprintf(`%s ILIKE '%%' || %s || '%%' ESCAPE '\'`, column, placeholder))
+			continue
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s = %s", column, placeholder))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+/*
+listTail builds ORDER BY and LIMIT/OFFSET clauses. The id column
+is always the last sort key so pages stay stable between requests
+*/
+func listTail(params models.ListParams, columns listColumns, args []any) (string, []any) {
+	direction := "ASC"
+	if params.Desc {
+		direction = "DESC"
+	}
+
+	order := columns["id"] + " " + direction
+	if column, ok := columns[params.Sort]; ok && params.Sort != "id" {
+		order = fmt.Sprintf("%s %s, %s", column, direction, order)
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = models.DefaultListLimit
+	}
+
+	args = append(args, limit, params.Offset)
+
+	return fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", order, len(args)-1, len(args)), args
+}
+
+func countRows(ctx context.Context, dbpool *pgxpool.Pool, query string, args []any) (int, error) {
+	var total int
+
+	if err := dbpool.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
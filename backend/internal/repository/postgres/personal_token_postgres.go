@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+/*
+touchInterval limits how often the last used time of a token is written,
+a script polling the api does not cause a write on every request
+*/
+const touchInterval = "1 minute"
+
+type PersonalTokenPostgres struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPersonalTokenPostgres(dbpool *pgxpool.Pool) repository.PersonalToken {
+	return &PersonalTokenPostgres{dbpool: dbpool}
+}
+
+func (r *PersonalTokenPostgres) Create(ctx context.Context, token models.PersonalToken) (int, error) {
+	const op = "repository.postgres.personal_token_postgres.Create"
+
+	var id int
+	query := `INSERT INTO
+						personal_access_token (user_id, name, token_hash, scopes, expires_at)
+						VALUES ($1, $2, $3, $4, $5)
+					RETURNING id`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, token.UserId, token.Name, token.TokenHash, token.Scopes, token.ExpiresAt)
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, nil
+}
+
+// GetAll returns tokens of a user which are not revoked, expired ones included
+func (r *PersonalTokenPostgres) GetAll(ctx context.Context, userId int) ([]models.PersonalToken, error) {
+	const op = "repository.postgres.personal_token_postgres.GetAll"
+
+	query := `SELECT
+					id,
+					user_id,
+					name,
+					token_hash,
+					scopes,
+					expires_at,
+					last_used_at,
+					revoked_at,
+					created_at
+				FROM
+					personal_access_token
+				WHERE user_id=$1 AND revoked_at IS NULL
+				ORDER BY created_at DESC`
+
+	rowsTokens, err := conn(ctx, r.dbpool).Query(ctx, query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	tokens, err := pgx.CollectRows(rowsTokens, pgx.RowToStructByName[models.PersonalToken])
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return tokens, nil
+}
+
+func (r *PersonalTokenPostgres) GetByHash(ctx context.Context, tokenHash string) (models.PersonalToken, error) {
+	const op = "repository.postgres.personal_token_postgres.GetByHash"
+
+	var token models.PersonalToken
+	query := `SELECT
+					id,
+					user_id,
+					name,
+					token_hash,
+					scopes,
+					expires_at,
+					last_used_at,
+					revoked_at,
+					created_at
+				FROM
+					personal_access_token
+				WHERE token_hash=$1`
+
+	rowToken, err := conn(ctx, r.dbpool).Query(ctx, query, tokenHash)
+	if err != nil {
+		return token, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	token, err = pgx.CollectOneRow(rowToken, pgx.RowToStructByName[models.PersonalToken])
+	if err != nil {
+		return token, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return token, nil
+}
+
+// Revoke reports false if the user has no such token or it is revoked already
+func (r *PersonalTokenPostgres) Revoke(ctx context.Context, userId, tokenId int) (bool, error) {
+	const op = "repository.postgres.personal_token_postgres.Revoke"
+
+	query := `UPDATE
+					personal_access_token
+				SET
+					revoked_at = now()
+				WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL`
+
+	tag, err := conn(ctx, r.dbpool).Exec(ctx, query, tokenId, userId)
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, personalTokenTable, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Touch records that a token is used
+func (r *PersonalTokenPostgres) Touch(ctx context.Context, tokenId int) error {
+	const op = "repository.postgres.personal_token_postgres.Touch"
+
+	query := `UPDATE
+					personal_access_token
+				SET
+					last_used_at = now()
+				WHERE id=$1 AND (last_used_at IS NULL OR last_used_at < now() - $2::interval)`
+
+	if _, err := conn(ctx, r.dbpool).Exec(ctx, query, tokenId, touchInterval); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, personalTokenTable, err)
+	}
+
+	return nil
+}
@@ -2,10 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/config"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,24 +24,67 @@ const (
 	queryErr              = "queryRow failed"
 	collectErr            = "collectRow failed"
 	scanErr               = "row scan failed"
+	countErr              = "count rows failed"
 )
 
 const (
-	habitTable     = "habit-table"
-	trackerTable   = "habit-tracker-table"
-	userHabitTable = "user-habit-table"
+	habitTable          = "habit-table"
+	trackerTable        = "habit-tracker-table"
+	userHabitTable      = "user-habit-table"
+	rewardTable         = "reward-table"
+	refreshTable        = "refresh-token-table"
+	revokedTable        = "revoked-token-table"
+	usedTable           = "used-token-table"
+	personalTokenTable  = "personal-access-token-table"
+	idempotencyTable    = "idempotency-key-table"
+	linkCodeTable       = "telegram-link-code-table"
+	userTable           = "user-account-table"
+	userRewardTable     = "user-reward-table"
+	roleTable           = "role-table"
+	rolePermissionTable = "role-permission-table"
 )
 
-func NewPostgresDB(cfg *config.Config) (*pgxpool.Pool, error) {
+/*
+dbErr turns a missing row, a unique and a foreign key violation into typed
+errors of pkg/errs, so a client gets 404, 409 or 422 instead of an internal
+error. The entity makes the error code, e.g. "habit" gives "habit_not_found"
+*/
+func dbErr(entity string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errs.NotFound(entity+"_not_found", err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case nonUniqueValueCode:
+			return errs.Conflict(entity+"_exists", err)
+		case foreign_key_violation:
+			return errs.Validation(entity+"_invalid_reference", err)
+		}
+	}
+
+	return err
+}
+
+func NewPostgresDB(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
 	const op = "repository.postgres.NewPostgresDB"
 
-	dbpool, err := pgxpool.New(context.Background(), fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
+	poolConfig, err := pgxpool.ParseConfig(fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
 		cfg.DB.Username, cfg.DB.Password, cfg.DB.Host, cfg.DB.Port, cfg.DB.DBName))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	err = dbpool.Ping(context.Background())
+	// every query gets a span, it is a child of the span of the request
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	dbpool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = dbpool.Ping(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -47,13 +94,21 @@ func NewPostgresDB(cfg *config.Config) (*pgxpool.Pool, error) {
 
 func NewPostgresRepository(dbpool *pgxpool.Pool) *repository.Repository {
 	return &repository.Repository{
+		Transactor:      NewTransactorPostgres(dbpool),
 		AdminRole:       NewAdminRolePostgres(dbpool),
 		AdminReward:     NewAdminRewardPostgres(dbpool),
 		AdminUserReward: NewAdminUserRewardPostgres(dbpool),
 		Admin:           NewAdminPostgres(dbpool),
 		User:            NewUserPostgres(dbpool),
+		Token:           NewTokenPostgres(dbpool),
+		PersonalToken:   NewPersonalTokenPostgres(dbpool),
+		Idempotency:     NewIdempotencyPostgres(dbpool),
+		Identity:        NewIdentityPostgres(dbpool),
+		TelegramLink:    NewTelegramLinkPostgres(dbpool),
 		Habit:           NewHabitPostgres(dbpool),
 		HabitTracker:    NewHabitTrackerPostgres(dbpool),
 		Reward:          NewRewardPostgres(dbpool),
+		Search:          NewSearchPostgres(dbpool),
+		Audit:           NewAuditPostgres(dbpool),
 	}
 }
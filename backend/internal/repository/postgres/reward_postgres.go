@@ -19,7 +19,7 @@ func NewRewardPostgres(dbpool *pgxpool.Pool) repository.Reward {
 	return &RewardPostgres{dbpool: dbpool}
 }
 
-func (r *RewardPostgres) GetPersonalRewardsByHabitId(userId, habitId int) ([]models.Reward, error) {
+func (r *RewardPostgres) GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error) {
 	const op = "repository.postgres.reward_postgres.GetPersonalRewardsByHabitId"
 
 	var rewards []models.Reward
@@ -29,7 +29,7 @@ func (r *RewardPostgres) GetPersonalRewardsByHabitId(userId, habitId int) ([]mod
 				FROM reward tl INNER JOIN user_reward ul on tl.id = ul.reward_id
 				WHERE ul.user_id = $1 AND ul.habit_id = $2`
 
-	rowReward, err := r.dbpool.Query(context.Background(), query, userId, habitId)
+	rowReward, err := conn(ctx, r.dbpool).Query(ctx, query, userId, habitId)
 	if err != nil {
 		return rewards, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
@@ -44,27 +44,37 @@ func (r *RewardPostgres) GetPersonalRewardsByHabitId(userId, habitId int) ([]mod
 	return rewards, err
 }
 
-func (r *RewardPostgres) GetAllPersonalRewards(userId int) ([]models.Reward, error) {
+func (r *RewardPostgres) GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error) {
 	const op = "repository.postgres.reward_postgres.GetAllPersonalRewards"
 
 	var rewards []models.Reward
 
+	where, args := listWhere([]string{"ul.user_id = $1"}, []any{userId}, params.Filters, rewardColumns)
+
+	total, err := countRows(ctx, r.dbpool, `SELECT 
+											count(*) 
+										FROM reward tl INNER JOIN user_reward ul on tl.id = ul.reward_id`+where, args)
+	if err != nil {
+		return rewards, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, rewardColumns, args)
+
 	query := `SELECT 
 					tl.id, tl.title, tl.description 
-				FROM reward tl INNER JOIN user_reward ul on tl.id = ul.reward_id
-				WHERE ul.user_id = $1`
+				FROM reward tl INNER JOIN user_reward ul on tl.id = ul.reward_id` + where + tail
 
-	rowRewards, err := r.dbpool.Query(context.Background(), query, userId)
+	rowRewards, err := conn(ctx, r.dbpool).Query(ctx, query, args...)
 	if err != nil {
-		return rewards, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+		return rewards, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
 
 	defer rowRewards.Close()
 
 	rewards, err = pgx.CollectRows(rowRewards, pgx.RowToStructByName[models.Reward])
 	if err != nil {
-		return rewards, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return rewards, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
 	}
 
-	return rewards, err
+	return rewards, total, err
 }
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SearchPostgres struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewSearchPostgres(dbpool *pgxpool.Pool) repository.Search {
+	return &SearchPostgres{dbpool: dbpool}
+}
+
+/*
+Search looks for the query among habits and rewards of a certain user.
+websearch_to_tsquery is used, so a user can type a query the same
+way as in a search engine: quoted phrases, "or", -excluded words
+*/
+func (r *SearchPostgres) Search(ctx context.Context, userId int, input models.SearchInput) (models.SearchResult, error) {
+	const op = "repository.postgres.search_postgres.Search"
+
+	var result models.SearchResult
+
+	habitsQuery := `SELECT
+						tl.id,
+						ul.user_id,
+						tl.title,
+						COALESCE(tl.description, '') AS description,
+						ts_rank(tl.search_vector, q) AS rank
+					FROM
+						habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id,
+						websearch_to_tsquery('simple', $2) q
+					WHERE ul.user_id = $1 AND tl.search_vector @@ q
+					ORDER BY rank DESC, tl.id
+					LIMIT $3 OFFSET $4`
+
+	habits, err := r.collectHits(ctx, habitsQuery, userId, input.Query, input.Limit, input.Offset)
+	if err != nil {
+		return result, fmt.Errorf("%s:%s: %w", op, habitTable, err)
+	}
+
+	rewardsQuery := `SELECT
+						tl.id,
+						0 AS user_id,
+						tl.title,
+						COALESCE(tl.description, '') AS description,
+						ts_rank(tl.search_vector, q) AS rank
+					FROM
+						reward tl,
+						websearch_to_tsquery('simple', $2) q
+					WHERE tl.id IN (SELECT reward_id FROM user_reward WHERE user_id = $1)
+						AND tl.search_vector @@ q
+					ORDER BY rank DESC, tl.id
+					LIMIT $3 OFFSET $4`
+
+	rewards, err := r.collectHits(ctx, rewardsQuery, userId, input.Query, input.Limit, input.Offset)
+	if err != nil {
+		return result, fmt.Errorf("%s:%s: %w", op, rewardTable, err)
+	}
+
+	result.Habits = habits
+	result.Rewards = rewards
+
+	return result, nil
+}
+
+// SearchAll looks for the query among habits of all users and all rewards
+func (r *SearchPostgres) SearchAll(ctx context.Context, input models.SearchInput) (models.SearchResult, error) {
+	const op = "repository.postgres.search_postgres.SearchAll"
+
+	var result models.SearchResult
+
+	habitsQuery := `SELECT
+						tl.id,
+						ul.user_id,
+						tl.title,
+						COALESCE(tl.description, '') AS description,
+						ts_rank(tl.search_vector, q) AS rank
+					FROM
+						habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id,
+						websearch_to_tsquery('simple', $1) q
+					WHERE tl.search_vector @@ q
+					ORDER BY rank DESC, tl.id
+					LIMIT $2 OFFSET $3`
+
+	habits, err := r.collectHits(ctx, habitsQuery, input.Query, input.Limit, input.Offset)
+	if err != nil {
+		return result, fmt.Errorf("%s:%s: %w", op, habitTable, err)
+	}
+
+	rewardsQuery := `SELECT
+						tl.id,
+						0 AS user_id,
+						tl.title,
+						COALESCE(tl.description, '') AS description,
+						ts_rank(tl.search_vector, q) AS rank
+					FROM
+						reward tl,
+						websearch_to_tsquery('simple', $1) q
+					WHERE tl.search_vector @@ q
+					ORDER BY rank DESC, tl.id
+					LIMIT $2 OFFSET $3`
+
+	rewards, err := r.collectHits(ctx, rewardsQuery, input.Query, input.Limit, input.Offset)
+	if err != nil {
+		return result, fmt.Errorf("%s:%s: %w", op, rewardTable, err)
+	}
+
+	result.Habits = habits
+	result.Rewards = rewards
+
+	return result, nil
+}
+
+func (r *SearchPostgres) collectHits(ctx context.Context, query string, args ...any) ([]models.SearchHit, error) {
+	rowsHits, err := conn(ctx, r.dbpool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", queryErr, err)
+	}
+
+	defer rowsHits.Close()
+
+	hits, err := pgx.CollectRows(rowsHits, pgx.RowToStructByName[models.SearchHit])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", collectErr, err)
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,270 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TokenPostgres struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewTokenPostgres(dbpool *pgxpool.Pool) repository.Token {
+	return &TokenPostgres{dbpool: dbpool}
+}
+
+func (r *TokenPostgres) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (int, error) {
+	const op = "repository.postgres.token_postgres.CreateRefreshToken"
+
+	var id int
+	query := `INSERT INTO 
+						refresh_token (user_id, token_hash, family_id, expires_at) 
+						VALUES ($1, $2, $3, $4) 
+					RETURNING id`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, token.UserId, token.TokenHash, token.FamilyId, token.ExpiresAt)
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, nil
+}
+
+func (r *TokenPostgres) GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	const op = "repository.postgres.token_postgres.GetRefreshToken"
+
+	var token models.RefreshToken
+	query := `SELECT 
+					id,
+					user_id,
+					token_hash,
+					family_id,
+					expires_at,
+					revoked_at
+				FROM 
+					refresh_token 
+				WHERE token_hash=$1`
+
+	rowToken, err := conn(ctx, r.dbpool).Query(ctx, query, tokenHash)
+	if err != nil {
+		return token, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	token, err = pgx.CollectOneRow(rowToken, pgx.RowToStructByName[models.RefreshToken])
+	if err != nil {
+		return token, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return token, nil
+}
+
+/*
+RotateRefreshToken revokes the used token and stores the next one
+in a single transaction. If the used token has been revoked already
+(two refresh requests with the same token), nothing is stored
+*/
+func (r *TokenPostgres) RotateRefreshToken(ctx context.Context, usedTokenId int, next models.RefreshToken) (int, error) {
+	const op = "repository.postgres.token_postgres.RotateRefreshToken"
+
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	queryRevoke := `UPDATE 
+						refresh_token 
+					SET 
+						revoked_at = now() 
+					WHERE id=$1 AND revoked_at IS NULL`
+
+	tag, err := tx.Exec(ctx, queryRevoke, usedTokenId)
+	if err != nil {
+		tx.Rollback(ctx)
+		return 0, fmt.Errorf("%s:%s: %w", op, refreshTable, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		tx.Rollback(ctx)
+		return 0, fmt.Errorf("%s:%s: %w", op, refreshTable, errors.New("token is already used"))
+	}
+
+	var id int
+	queryCreate := `INSERT INTO 
+						refresh_token (user_id, token_hash, family_id, expires_at) 
+						VALUES ($1, $2, $3, $4) 
+					RETURNING id`
+
+	row := tx.QueryRow(ctx, queryCreate, next.UserId, next.TokenHash, next.FamilyId, next.ExpiresAt)
+	if err := row.Scan(&id); err != nil {
+		tx.Rollback(ctx)
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, tx.Commit(ctx)
+}
+
+func (r *TokenPostgres) RevokeTokenFamily(ctx context.Context, userId int, familyId string) error {
+	const op = "repository.postgres.token_postgres.RevokeTokenFamily"
+
+	query := `UPDATE 
+					refresh_token 
+				SET 
+					revoked_at = now() 
+				WHERE user_id=$1 AND family_id=$2 AND revoked_at IS NULL`
+
+	if _, err := conn(ctx, r.dbpool).Exec(ctx, query, userId, familyId); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, refreshTable, err)
+	}
+
+	return nil
+}
+
+func (r *TokenPostgres) RevokeAccessToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error {
+	const op = "repository.postgres.token_postgres.RevokeAccessToken"
+
+	query := `INSERT INTO 
+					revoked_token (jti, user_id, expires_at) 
+					VALUES ($1, $2, $3) 
+				ON CONFLICT (jti) DO NOTHING`
+
+	if _, err := conn(ctx, r.dbpool).Exec(ctx, query, jti, userId, expiresAt); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, revokedTable, err)
+	}
+
+	return nil
+}
+
+/*
+RevokeAllUserTokens revokes all refresh and personal access tokens
+of a user and marks all access tokens issued so far as revoked
+*/
+func (r *TokenPostgres) RevokeAllUserTokens(ctx context.Context, userId int) error {
+	const op = "repository.postgres.token_postgres.RevokeAllUserTokens"
+
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	queryRefresh := `UPDATE 
+						refresh_token 
+					SET 
+						revoked_at = now() 
+					WHERE user_id=$1 AND revoked_at IS NULL`
+
+	if _, err := tx.Exec(ctx, queryRefresh, userId); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, refreshTable, err)
+	}
+
+	queryPersonal := `UPDATE 
+						personal_access_token 
+					SET 
+						revoked_at = now() 
+					WHERE user_id=$1 AND revoked_at IS NULL`
+
+	if _, err := tx.Exec(ctx, queryPersonal, userId); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s:%s: %w", op, personalTokenTable, err)
+	}
+
+	queryUser := `UPDATE 
+					user_account 
+				SET 
+					tokens_revoked_at = date_trunc('milliseconds', now()) 
+				WHERE id=$1`
+
+	if _, err := tx.Exec(ctx, queryUser, userId); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+/*
+IsAccessTokenRevoked checks both revocation sources: the token itself
+is revoked on logout, and all tokens of the user issued before
+tokens_revoked_at are revoked on "log out everywhere". Tokens are issued
+with milliseconds, so tokens_revoked_at is kept in milliseconds and a token
+issued in the millisecond of the revocation stays valid
+*/
+func (r *TokenPostgres) IsAccessTokenRevoked(ctx context.Context, jti string, userId int, issuedAt time.Time) (bool, error) {
+	const op = "repository.postgres.token_postgres.IsAccessTokenRevoked"
+
+	var revoked bool
+	query := `SELECT 
+					EXISTS (SELECT 1 FROM revoked_token WHERE jti=$1)
+					OR EXISTS (SELECT 1 FROM user_account WHERE id=$2 AND tokens_revoked_at > $3)`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, jti, userId, issuedAt)
+	if err := row.Scan(&revoked); err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return revoked, nil
+}
+
+/*
+UseOneTimeToken marks a one-time token as used. It returns false
+if the token has been used before
+*/
+func (r *TokenPostgres) UseOneTimeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	const op = "repository.postgres.token_postgres.UseOneTimeToken"
+
+	query := `INSERT INTO 
+					used_token (jti, expires_at) 
+					VALUES ($1, $2) 
+				ON CONFLICT (jti) DO NOTHING`
+
+	tag, err := conn(ctx, r.dbpool).Exec(ctx, query, jti, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, usedTable, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+/*
+PurgeExpiredTokens removes used one-time tokens, revoked access tokens
+and refresh tokens which expired before expiredBefore moment. They are
+rejected as expired anyway, so the rows are no longer needed
+*/
+func (r *TokenPostgres) PurgeExpiredTokens(ctx context.Context, expiredBefore time.Time) (int, error) {
+	const op = "repository.postgres.token_postgres.PurgeExpiredTokens"
+
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tables := []struct {
+		name  string
+		query string
+	}{
+		{name: usedTable, query: `DELETE FROM used_token WHERE expires_at < $1`},
+		{name: revokedTable, query: `DELETE FROM revoked_token WHERE expires_at < $1`},
+		{name: refreshTable, query: `DELETE FROM refresh_token WHERE expires_at < $1`},
+	}
+
+	purged := 0
+
+	for _, table := range tables {
+		tag, err := tx.Exec(ctx, table.query, expiredBefore)
+		if err != nil {
+			tx.Rollback(ctx)
+			return 0, fmt.Errorf("%s:%s: %w", op, table.name, err)
+		}
+
+		purged += int(tag.RowsAffected())
+	}
+
+	return purged, tx.Commit(ctx)
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/*
+queryTracer starts a span for every query of the pool. The span holds the
+statement, but not the arguments, they can be passwords and tokens
+*/
+type queryTracer struct{}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracing.Tracer().Start(ctx, queryName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBStatement(data.SQL),
+		),
+	)
+
+	return ctx
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	tracing.End(trace.SpanFromContext(ctx), data.Err)
+}
+
+// queryName names a span by the first word of a statement, e.g. "db SELECT"
+func queryName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "db"
+	}
+
+	return "db " + strings.ToUpper(fields[0])
+}
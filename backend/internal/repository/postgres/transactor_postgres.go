@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type txKey struct{}
+
+// querier runs queries, it is either the pool or a transaction
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+/*
+conn returns the transaction ctx is in, the pool otherwise. A transaction
+begun on a transaction is a savepoint, so the repositories which change
+several tables keep their own transactions inside an outer one
+*/
+func conn(ctx context.Context, dbpool *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return dbpool
+}
+
+type TransactorPostgres struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewTransactorPostgres(dbpool *pgxpool.Pool) repository.Transactor {
+	return &TransactorPostgres{dbpool: dbpool}
+}
+
+func (r *TransactorPostgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "repository.postgres.WithinTx"
+
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
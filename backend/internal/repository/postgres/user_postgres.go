@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
@@ -21,7 +22,7 @@ func NewUserPostgres(dbpool *pgxpool.Pool) repository.User {
 	return &UserPostgres{dbpool: dbpool}
 }
 
-func (r *UserPostgres) CreateUser(user models.User) (int, error) {
+func (r *UserPostgres) CreateUser(ctx context.Context, user models.User) (int, error) {
 	const (
 		op         = "repository.postgres.CreateUser"
 		userExists = "such user already exists"
@@ -29,18 +30,19 @@ func (r *UserPostgres) CreateUser(user models.User) (int, error) {
 
 	var id int
 	query := `INSERT INTO 
-						user_account (user_name, tg_user_name, first_name, last_name, email, password_hash) 
+						user_account (user_name, tg_user_name, first_name, last_name, email, password_hash, tg_user_id) 
 						VALUES (
 							COALESCE(NULLIF($1, ''), NULL),
 							COALESCE(NULLIF($2, ''), NULL),
 							COALESCE(NULLIF($3, ''), NULL),
 							COALESCE(NULLIF($4, ''), NULL),
 							COALESCE(NULLIF($5, ''), NULL),
-							COALESCE(NULLIF($6, ''), NULL)
+							COALESCE(NULLIF($6, ''), NULL),
+							NULLIF($7::bigint, 0)
 							) 
 					RETURNING id`
 
-	row := r.dbpool.QueryRow(context.Background(), query, user.Username, user.TgUsername, user.FirstName, user.LastName, user.Email, user.Password)
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, user.Username, user.TgUsername, user.FirstName, user.LastName, user.Email, user.Password, user.TgUserId)
 	if err := row.Scan(&id); err != nil {
 
 		/*
@@ -49,36 +51,40 @@ func (r *UserPostgres) CreateUser(user models.User) (int, error) {
 			https://github.com/jackc/pgx/wiki/Error-Handling
 		*/
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == nonUniqueValueCode {
-				return 0, fmt.Errorf("%s: %s: %w", op, userExists, err) // for unique_violation error
-			}
-			return 0, fmt.Errorf("%s: %w", op, err) // for any other errors
+		if errors.As(err, &pgErr) && pgErr.Code == nonUniqueValueCode {
+			return 0, fmt.Errorf("%s: %s: %w", op, userExists, dbErr("user", err)) // for unique_violation error
 		}
-
+		return 0, fmt.Errorf("%s: %w", op, err) // for any other errors
 	}
 
 	return id, nil
 }
 
-func (r *UserPostgres) GetUser(username, password string) (models.User, error) {
-	const op = "repository.postgres.GetUser"
+/*
+GetUserByUsername returns a user along with the password hash.
+The hash is checked by the service, since argon2id hashes can't be
+compared inside a query
+*/
+func (r *UserPostgres) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	const op = "repository.postgres.GetUserByUsername"
 
 	var user models.User
 	query := `SELECT 
 					id,
 					COALESCE(user_name, '') AS user_name,
 					COALESCE(tg_user_name, '') AS tg_user_name,
+					COALESCE(tg_user_id, 0) AS tg_user_id,
 					COALESCE(first_name, '') AS first_name,
 					COALESCE(last_name, '') AS last_name,
 					COALESCE(email, '') AS email,
-					password_hash, 
-					role 
+					COALESCE(password_hash, '') AS password_hash, 
+					role,
+					deleted_at 
 				FROM 
 					user_account 
-				WHERE user_name=$1 AND password_hash=$2`
+				WHERE user_name=$1`
 
-	userHabit, err := r.dbpool.Query(context.Background(), query, username, password)
+	userHabit, err := conn(ctx, r.dbpool).Query(ctx, query, username)
 	if err != nil {
 		return user, fmt.Errorf("%s: %w", op, err)
 	}
@@ -92,37 +98,83 @@ func (r *UserPostgres) GetUser(username, password string) (models.User, error) {
 	return user, err
 }
 
-func (r *UserPostgres) GetAllUsers() ([]models.GetUser, error) {
+func (r *UserPostgres) GetPasswordHash(ctx context.Context, userId int) (string, error) {
+	const op = "repository.postgres.GetPasswordHash"
+
+	var passwordHash string
+	query := `SELECT 
+					COALESCE(password_hash, '') 
+				FROM 
+					user_account 
+				WHERE id=$1 AND deleted_at IS NULL`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, userId)
+	if err := row.Scan(&passwordHash); err != nil {
+		return "", fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return passwordHash, nil
+}
+
+func (r *UserPostgres) UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error {
+	const op = "repository.postgres.UpdatePasswordHash"
+
+	query := `UPDATE 
+					user_account 
+				SET 
+					password_hash = $2 
+				WHERE id=$1`
+
+	if _, err := conn(ctx, r.dbpool).Exec(ctx, query, userId, passwordHash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *UserPostgres) GetAllUsers(ctx context.Context, params models.ListParams) ([]models.GetUser, int, error) {
 	const op = "repository.postgres.GetAllUsers"
 
 	var users []models.GetUser
+
+	where, args := listWhere(nil, nil, params.Filters, userColumns)
+
+	total, err := countRows(ctx, r.dbpool, `SELECT count(*) FROM user_account`+where, args)
+	if err != nil {
+		return users, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, userColumns, args)
+
 	query := `SELECT 
 					id,
 					COALESCE(user_name, '') AS user_name,
 					COALESCE(tg_user_name, '') AS tg_user_name,
+					COALESCE(tg_user_id, 0) AS tg_user_id,
 					COALESCE(first_name, '') AS first_name,
 					COALESCE(last_name, '') AS last_name,
 					COALESCE(email, '') AS email,
-					role 
+					role,
+					deleted_at 
 				FROM 
-					user_account`
+					user_account` + where + tail
 
-	rowsUsers, err := r.dbpool.Query(context.Background(), query)
+	rowsUsers, err := conn(ctx, r.dbpool).Query(ctx, query, args...)
 	if err != nil {
-		return users, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+		return users, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
 
 	defer rowsUsers.Close()
 
 	users, err = pgx.CollectRows(rowsUsers, pgx.RowToStructByName[models.GetUser])
 	if err != nil {
-		return users, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return users, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
 	}
 
-	return users, err
+	return users, total, err
 }
 
-func (r *UserPostgres) GetUserById(userId int) (models.GetUser, error) {
+func (r *UserPostgres) GetUserById(ctx context.Context, userId int) (models.GetUser, error) {
 	const op = "repository.postgres.GetUserById"
 
 	var user models.GetUser
@@ -130,15 +182,17 @@ func (r *UserPostgres) GetUserById(userId int) (models.GetUser, error) {
 					id,
 					COALESCE(user_name, '') AS user_name,
 					COALESCE(tg_user_name, '') AS tg_user_name,
+					COALESCE(tg_user_id, 0) AS tg_user_id,
 					COALESCE(first_name, '') AS first_name,
 					COALESCE(last_name, '') AS last_name,
 					COALESCE(email, '') AS email,
-					role 
+					role,
+					deleted_at 
 				FROM 
 					user_account
 				WHERE id=$1`
 
-	rowUser, err := r.dbpool.Query(context.Background(), query, userId)
+	rowUser, err := conn(ctx, r.dbpool).Query(ctx, query, userId)
 	if err != nil {
 		return user, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
@@ -147,29 +201,32 @@ func (r *UserPostgres) GetUserById(userId int) (models.GetUser, error) {
 
 	user, err = pgx.CollectOneRow(rowUser, pgx.RowToStructByName[models.GetUser])
 	if err != nil {
-		return user, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+		return user, fmt.Errorf("%s:%s: %w", op, collectErr, dbErr("user", err))
 	}
 
 	return user, err
 }
 
-func (r *UserPostgres) GetUserByTgUsername(TGusername string) (models.GetUser, error) {
-	const op = "repository.postgres.GetUserByTgUsername"
+// GetUserByEmail returns an active user with the email
+func (r *UserPostgres) GetUserByEmail(ctx context.Context, email string) (models.GetUser, error) {
+	const op = "repository.postgres.GetUserByEmail"
 
 	var user models.GetUser
 	query := `SELECT 
 					id,
 					COALESCE(user_name, '') AS user_name,
 					COALESCE(tg_user_name, '') AS tg_user_name,
+					COALESCE(tg_user_id, 0) AS tg_user_id,
 					COALESCE(first_name, '') AS first_name,
 					COALESCE(last_name, '') AS last_name,
 					COALESCE(email, '') AS email,
-					role 
+					role,
+					deleted_at 
 				FROM 
 					user_account
-				WHERE tg_user_name=$1`
+				WHERE email=$1 AND deleted_at IS NULL`
 
-	rowUser, err := r.dbpool.Query(context.Background(), query, TGusername)
+	rowUser, err := conn(ctx, r.dbpool).Query(ctx, query, email)
 	if err != nil {
 		return user, fmt.Errorf("%s:%s: %w", op, queryErr, err)
 	}
@@ -184,27 +241,200 @@ func (r *UserPostgres) GetUserByTgUsername(TGusername string) (models.GetUser, e
 	return user, err
 }
 
-func (r *UserPostgres) DeleteUser(userId int) (int, error) {
-	const op = "repository.postgres.DeleteUser"
+func (r *UserPostgres) SetEmailVerified(ctx context.Context, userId int) error {
+	const op = "repository.postgres.SetEmailVerified"
+
+	query := `UPDATE 
+					user_account 
+				SET 
+					email_verified_at = now() 
+				WHERE id=$1 AND email_verified_at IS NULL`
+
+	if _, err := conn(ctx, r.dbpool).Exec(ctx, query, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+/*
+IsEmailVerified reports if a user has confirmed the email.
+Users without an email (telegram users) have nothing to confirm
+*/
+func (r *UserPostgres) IsEmailVerified(ctx context.Context, userId int) (bool, error) {
+	const op = "repository.postgres.IsEmailVerified"
+
+	var verified bool
+	query := `SELECT 
+					email IS NULL OR email_verified_at IS NOT NULL 
+				FROM 
+					user_account 
+				WHERE id=$1`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, userId)
+	if err := row.Scan(&verified); err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return verified, nil
+}
+
+func (r *UserPostgres) GetUserByTgUserId(ctx context.Context, tgUserId int64) (models.GetUser, error) {
+	const op = "repository.postgres.GetUserByTgUserId"
+
+	var user models.GetUser
+	query := `SELECT 
+					id,
+					COALESCE(user_name, '') AS user_name,
+					COALESCE(tg_user_name, '') AS tg_user_name,
+					COALESCE(tg_user_id, 0) AS tg_user_id,
+					COALESCE(first_name, '') AS first_name,
+					COALESCE(last_name, '') AS last_name,
+					COALESCE(email, '') AS email,
+					role,
+					deleted_at 
+				FROM 
+					user_account
+				WHERE tg_user_id=$1 AND deleted_at IS NULL`
+
+	rowUser, err := conn(ctx, r.dbpool).Query(ctx, query, tgUserId)
+	if err != nil {
+		return user, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	defer rowUser.Close()
 
-	tx, err := r.dbpool.Begin(context.Background())
+	user, err = pgx.CollectOneRow(rowUser, pgx.RowToStructByName[models.GetUser])
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return user, fmt.Errorf("%s:%s: %w", op, collectErr, dbErr("user", err))
+	}
+
+	return user, err
+}
+
+/*
+ClaimTgUserId binds a telegram id to an account created by the bot before
+the ids were stored, found by the telegram user name. An account that
+already has an id is never changed. A user name can pass to another
+telegram user, so an account with web credentials is never claimed by it,
+such an account is linked by a link code only
+*/
+func (r *UserPostgres) ClaimTgUserId(ctx context.Context, tgUsername string, tgUserId int64) (int, error) {
+	const op = "repository.postgres.ClaimTgUserId"
+
+	var userId int
+	query := `UPDATE 
+					user_account 
+				SET 
+					tg_user_id = $2 
+				WHERE tg_user_name=$1 AND tg_user_id IS NULL AND deleted_at IS NULL 
+					AND user_name IS NULL AND email IS NULL AND password_hash IS NULL 
+				RETURNING id`
+
+	row := conn(ctx, r.dbpool).QueryRow(ctx, query, tgUsername, tgUserId)
+	if err := row.Scan(&userId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
+	}
+
+	return userId, nil
+}
+
+/*
+DeleteUser only marks a user as deleted. The user stays in the table
+during the grace period and can be restored, after that the account
+is removed by PurgeDeletedUsers
+*/
+func (r *UserPostgres) DeleteUser(ctx context.Context, userId int) (int, error) {
+	const op = "repository.postgres.DeleteUser"
+
+	var checkUserId int
+
+	query := `UPDATE 
+					user_account
+				SET 
+					deleted_at = now()
+				WHERE id=$1 AND deleted_at IS NULL
+				RETURNING id`
+
+	rowUser := conn(ctx, r.dbpool).QueryRow(ctx, query, userId)
+	if err := rowUser.Scan(&checkUserId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
 	}
 
+	return checkUserId, nil
+}
+
+// RestoreUser restores a user who was deleted after deletedAfter moment
+func (r *UserPostgres) RestoreUser(ctx context.Context, userId int, deletedAfter time.Time) (int, error) {
+	const op = "repository.postgres.RestoreUser"
+
 	var checkUserId int
 
-	query := `DELETE FROM
+	query := `UPDATE 
+					user_account
+				SET 
+					deleted_at = NULL
+				WHERE id=$1 AND deleted_at IS NOT NULL AND deleted_at > $2
+				RETURNING id`
+
+	rowUser := conn(ctx, r.dbpool).QueryRow(ctx, query, userId, deletedAfter)
+	if err := rowUser.Scan(&checkUserId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
+	}
+
+	return checkUserId, nil
+}
+
+/*
+PurgeDeletedUsers permanently removes users deleted before deletedBefore
+moment. All habits and rewards of the users are removed by cascade
+*/
+func (r *UserPostgres) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]int, error) {
+	const op = "repository.postgres.PurgeDeletedUsers"
+
+	tx, err := conn(ctx, r.dbpool).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	/*
+		habits and trackers are not linked to user_account by foreign keys,
+		so they are deleted before the users, while user_habit still exists
+	*/
+	queryTrackers := `DELETE FROM 
+							habit_tracker tl USING user_habit ul, user_account ua 
+						WHERE tl.id = ul.habit_tracker_id AND ul.user_id = ua.id AND ua.deleted_at < $1`
+
+	if _, err := tx.Exec(ctx, queryTrackers, deletedBefore); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("%s:%s: %w", op, trackerTable, err)
+	}
+
+	queryHabits := `DELETE FROM 
+							habit tl USING user_habit ul, user_account ua 
+						WHERE tl.id = ul.habit_id AND ul.user_id = ua.id AND ua.deleted_at < $1`
+
+	if _, err := tx.Exec(ctx, queryHabits, deletedBefore); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("%s:%s: %w", op, habitTable, err)
+	}
+
+	queryUsers := `DELETE FROM
 						user_account
-					WHERE id=$1
+					WHERE deleted_at < $1
 					RETURNING id`
 
-	rowUser := tx.QueryRow(context.Background(), query, userId)
-	err = rowUser.Scan(&checkUserId)
+	rowsUsers, err := tx.Query(ctx, queryUsers, deletedBefore)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	userIds, err := pgx.CollectRows(rowsUsers, pgx.RowTo[int])
 	if err != nil {
-		tx.Rollback(context.Background())
-		return 0, fmt.Errorf("%s: %w", op, err)
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
 	}
 
-	return checkUserId, tx.Commit(context.Background())
+	return userIds, tx.Commit(ctx)
 }
@@ -1,27 +1,50 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 
 	_ "github.com/jackc/pgx/v5"
 )
 
+// ErrVersionMismatch is returned by an update made with a version the record no longer has
+var ErrVersionMismatch = errors.New("version does not match")
+
+/*
+Transactor runs fn in a transaction. Repositories called with the context
+fn gets take part in it, so either all the changes fn makes are kept or,
+if fn returns an error, none of them. A transaction run inside another one
+is rolled back on its own error only, the way a savepoint is
+*/
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type AdminRole interface {
-	AssignRole(userId int, role models.UpdateRoleInput) (int, error)
+	AssignRole(ctx context.Context, userId int, role models.UpdateRoleInput) (int, error)
+	GetAllRoles(ctx context.Context) ([]models.Role, error)
+	GetRole(ctx context.Context, name string) (models.Role, error)
+	CreateRole(ctx context.Context, role models.Role) error
+	UpdateRole(ctx context.Context, role models.Role) error
+	DeleteRole(ctx context.Context, name string) (bool, error)
+	GetAllPermissions(ctx context.Context) ([]models.Permission, error)
 }
 
 type AdminReward interface {
-	Create(reward models.Reward) (int, error)
-	GetById(rewardId int) (models.Reward, error)
-	GetAllRewards() ([]models.Reward, error)
-	Delete(rewardId int) error
-	UpdateReward(rewardId int, input models.UpdateRewardInput) error
+	Create(ctx context.Context, reward models.Reward) (int, error)
+	GetById(ctx context.Context, rewardId int) (models.Reward, error)
+	GetAllRewards(ctx context.Context, params models.ListParams) ([]models.Reward, int, error)
+	Delete(ctx context.Context, rewardId int) error
+	UpdateReward(ctx context.Context, rewardId int, input models.UpdateRewardInput) error
 }
 
 type AdminUserReward interface {
-	AssignReward(userId, habitId, rewardId int) (int, error)
-	RemoveFromUser(userId, habitId, rewardId int) error
-	UpdateUserReward(userId, habitId, rewardId int, input models.UpdateUserRewardInput) error
+	AssignReward(ctx context.Context, userId, habitId, rewardId int) (int, error)
+	RemoveFromUser(ctx context.Context, userId, habitId, rewardId int) error
+	UpdateUserReward(ctx context.Context, userId, habitId, rewardId int, input models.UpdateUserRewardInput) error
 	Reward
 }
 
@@ -32,42 +55,106 @@ type Admin interface {
 }
 
 type User interface {
-	CreateUser(user models.User) (int, error)
-	GetUser(username, password string) (models.User, error)
-	GetUserByTgUsername(TGusername string) (models.GetUser, error)
-	GetUserById(userId int) (models.GetUser, error)
-	GetAllUsers() ([]models.GetUser, error)
-	DeleteUser(userId int) (int, error)
+	CreateUser(ctx context.Context, user models.User) (int, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	GetPasswordHash(ctx context.Context, userId int) (string, error)
+	UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error
+	GetUserByTgUserId(ctx context.Context, tgUserId int64) (models.GetUser, error)
+	ClaimTgUserId(ctx context.Context, tgUsername string, tgUserId int64) (int, error)
+	GetUserById(ctx context.Context, userId int) (models.GetUser, error)
+	GetUserByEmail(ctx context.Context, email string) (models.GetUser, error)
+	SetEmailVerified(ctx context.Context, userId int) error
+	IsEmailVerified(ctx context.Context, userId int) (bool, error)
+	GetAllUsers(ctx context.Context, params models.ListParams) ([]models.GetUser, int, error)
+	DeleteUser(ctx context.Context, userId int) (int, error)
+	RestoreUser(ctx context.Context, userId int, deletedAfter time.Time) (int, error)
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]int, error)
+}
+
+type Token interface {
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) (int, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, usedTokenId int, next models.RefreshToken) (int, error)
+	RevokeTokenFamily(ctx context.Context, userId int, familyId string) error
+	RevokeAccessToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error
+	RevokeAllUserTokens(ctx context.Context, userId int) error
+	IsAccessTokenRevoked(ctx context.Context, jti string, userId int, issuedAt time.Time) (bool, error)
+	UseOneTimeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error)
+	PurgeExpiredTokens(ctx context.Context, expiredBefore time.Time) (int, error)
+}
+
+type PersonalToken interface {
+	Create(ctx context.Context, token models.PersonalToken) (int, error)
+	GetAll(ctx context.Context, userId int) ([]models.PersonalToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (models.PersonalToken, error)
+	Revoke(ctx context.Context, userId, tokenId int) (bool, error)
+	Touch(ctx context.Context, tokenId int) error
+}
+
+type Idempotency interface {
+	Reserve(ctx context.Context, record models.IdempotencyRecord) (bool, error)
+	Get(ctx context.Context, userId int, key string) (models.IdempotencyRecord, error)
+	Complete(ctx context.Context, userId int, key string, statusCode int, headers map[string]string, body []byte) error
+	Delete(ctx context.Context, userId int, key string) error
+}
+
+type Identity interface {
+	Create(ctx context.Context, identity models.Identity) (int, error)
+	GetUserId(ctx context.Context, provider, subject string) (int, error)
+}
+
+type TelegramLink interface {
+	CreateCode(ctx context.Context, userId int, codeHash string, expiresAt time.Time) error
+	UseCode(ctx context.Context, codeHash string) (int, error)
+	Link(ctx context.Context, userId, mergeUserId int, tgUserId int64, tgUsername string) error
 }
 
 type Habit interface {
-	Create(userId int, habit models.Habit) (int, error)
-	GetAll(userId int) ([]models.Habit, error)
-	GetById(userId, habitId int) (models.Habit, error)
-	Delete(userId, habitId int) error
-	Update(userId, habitId int, input models.UpdateHabitInput) error
+	Create(ctx context.Context, userId int, habit models.Habit) (int, error)
+	GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.Habit, int, error)
+	GetById(ctx context.Context, userId, habitId int) (models.Habit, error)
+	Delete(ctx context.Context, userId, habitId int) error
+	Update(ctx context.Context, userId, habitId int, input models.UpdateHabitInput) (int, error)
 }
 
 type HabitTracker interface {
 	// Create(userHabitId int, tracker habit.HabitTracker) (int, error) // temporarily disabled
-	GetAll(userId int) ([]models.HabitTracker, error)
-	GetById(userId, habitId int) (models.HabitTracker, error)
+	GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.HabitTracker, int, error)
+	GetById(ctx context.Context, userId, habitId int) (models.HabitTracker, error)
 	// Delete(userId, habitId int) error // temporarily disabled
-	Update(userId, habitId int, input models.UpdateTrackerInput) error
+	Update(ctx context.Context, userId, habitId int, input models.UpdateTrackerInput) (int, error)
 }
 
 type Reward interface {
-	GetPersonalRewardsByHabitId(userId, habitId int) ([]models.Reward, error)
-	GetAllPersonalRewards(userId int) ([]models.Reward, error)
+	GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error)
+	GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error)
+}
+
+type Search interface {
+	Search(ctx context.Context, userId int, input models.SearchInput) (models.SearchResult, error)
+	SearchAll(ctx context.Context, input models.SearchInput) (models.SearchResult, error)
+}
+
+type Audit interface {
+	Create(ctx context.Context, entry models.AuditEntry) (int, error)
+	GetAll(ctx context.Context, params models.ListParams) ([]models.AuditEntry, int, error)
 }
 
 type Repository struct {
+	Transactor
 	AdminRole
 	AdminReward
 	AdminUserReward
 	Admin
 	User
+	Token
+	PersonalToken
+	Idempotency
+	Identity
+	TelegramLink
 	Habit
 	HabitTracker
 	Reward
+	Search
+	Audit
 }
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type AdminRewardSQLite struct {
+	db *sql.DB
+}
+
+func NewAdminRewardSQLite(db *sql.DB) repository.AdminReward {
+	return &AdminRewardSQLite{db: db}
+}
+
+func scanReward(row scanner) (models.Reward, error) {
+	var reward models.Reward
+
+	err := row.Scan(&reward.Id, &reward.Title, &reward.Description)
+
+	return reward, err
+}
+
+func (r *AdminRewardSQLite) Create(ctx context.Context, reward models.Reward) (int, error) {
+	const op = "repository.sqlite.admin_reward_sqlite.Create"
+
+	var rewardId int
+	query := `INSERT INTO 
+						reward (title, description) 
+						VALUES (?1, ?2) 
+					RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, reward.Title, reward.Description)
+	if err := row.Scan(&rewardId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("reward", err))
+	}
+
+	return rewardId, nil
+}
+
+func (r *AdminRewardSQLite) GetById(ctx context.Context, rewardId int) (models.Reward, error) {
+	const op = "repository.sqlite.admin_reward_sqlite.GetById"
+
+	query := `SELECT 
+					id, 
+					title, 
+					COALESCE(description, '') 
+				FROM 
+					reward
+				WHERE id = ?1`
+
+	reward, err := scanReward(conn(ctx, r.db).QueryRowContext(ctx, query, rewardId))
+	if err != nil {
+		return reward, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("reward", err))
+	}
+
+	return reward, nil
+}
+
+func (r *AdminRewardSQLite) GetAllRewards(ctx context.Context, params models.ListParams) ([]models.Reward, int, error) {
+	const op = "repository.sqlite.admin_reward_sqlite.GetAllRewards"
+
+	where, args := listWhere(nil, nil, params.Filters, rewardColumns)
+
+	total, err := countRows(ctx, r.db, `SELECT count(*) FROM reward tl`+where, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, rewardColumns, args)
+
+	query := `SELECT 
+					tl.id, 
+					tl.title, 
+					COALESCE(tl.description, '') 
+				FROM 
+					reward tl` + where + tail
+
+	rowsRewards, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	rewards, err := collectRows(rowsRewards, scanReward)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return rewards, total, nil
+}
+
+func (r *AdminRewardSQLite) Delete(ctx context.Context, rewardId int) error {
+	const op = "repository.sqlite.admin_reward_sqlite.Delete"
+
+	query := `DELETE FROM 
+					reward 
+				WHERE id = ?1
+				RETURNING id`
+
+	var checkRewardId int
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, rewardId).Scan(&checkRewardId); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("reward", err))
+	}
+
+	return nil
+}
+
+func (r *AdminRewardSQLite) UpdateReward(ctx context.Context, rewardId int, input models.UpdateRewardInput) error {
+	const op = "repository.sqlite.admin_reward_sqlite.UpdateReward"
+
+	query := `UPDATE 
+					reward 
+				SET 
+					title=COALESCE(?2, title), 
+					description=COALESCE(?3, description)
+				WHERE id = ?1
+				RETURNING id`
+
+	var checkRewardId int
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, rewardId, input.Title, input.Description)
+	if err := row.Scan(&checkRewardId); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("reward", err))
+	}
+
+	return nil
+}
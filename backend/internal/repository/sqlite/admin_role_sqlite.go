@@ -0,0 +1,225 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+// SQLite has no arrays, permissions of a role are joined by group_concat and split back
+const roleSelect = `SELECT 
+						r.name,
+						COALESCE(r.description, ''),
+						group_concat(rp.permission) 
+					FROM 
+						role r 
+					LEFT JOIN role_permission rp ON rp.role_name = r.name`
+
+type AdminRoleSQLite struct {
+	db *sql.DB
+}
+
+func NewAdminRoleSQLite(db *sql.DB) repository.AdminRole {
+	return &AdminRoleSQLite{db: db}
+}
+
+func scanRole(row scanner) (models.Role, error) {
+	var (
+		role        models.Role
+		permissions sql.NullString
+	)
+
+	if err := row.Scan(&role.Name, &role.Description, &permissions); err != nil {
+		return role, err
+	}
+
+	role.Permissions = []string{}
+	if permissions.Valid {
+		role.Permissions = strings.Split(permissions.String, ",")
+		sort.Strings(role.Permissions)
+	}
+
+	return role, nil
+}
+
+func (r *AdminRoleSQLite) AssignRole(ctx context.Context, userId int, role models.UpdateRoleInput) (int, error) {
+	const op = "repository.sqlite.AssignRole"
+
+	var id int
+
+	query := `UPDATE 
+					user_account
+				SET 
+					role = ?2
+				WHERE id = ?1
+				RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, userId, role.Role)
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
+	}
+
+	return id, nil
+}
+
+func (r *AdminRoleSQLite) GetAllRoles(ctx context.Context) ([]models.Role, error) {
+	const op = "repository.sqlite.GetAllRoles"
+
+	query := roleSelect + ` GROUP BY r.name ORDER BY r.name`
+
+	rowsRoles, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	roles, err := collectRows(rowsRoles, scanRole)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return roles, nil
+}
+
+func (r *AdminRoleSQLite) GetRole(ctx context.Context, name string) (models.Role, error) {
+	const op = "repository.sqlite.GetRole"
+
+	query := roleSelect + ` WHERE r.name = ?1 GROUP BY r.name`
+
+	role, err := scanRole(conn(ctx, r.db).QueryRowContext(ctx, query, name))
+	if err != nil {
+		return role, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("role", err))
+	}
+
+	return role, nil
+}
+
+func (r *AdminRoleSQLite) CreateRole(ctx context.Context, role models.Role) error {
+	const op = "repository.sqlite.CreateRole"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `INSERT INTO 
+					role (name, description) 
+					VALUES (?1, NULLIF(?2, ''))`
+
+	if _, err := tx.ExecContext(ctx, query, role.Name, role.Description); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, roleTable, dbErr("role", err))
+	}
+
+	if err := setRolePermissions(ctx, tx, role); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateRole replaces the description and the permissions of a role
+func (r *AdminRoleSQLite) UpdateRole(ctx context.Context, role models.Role) error {
+	const op = "repository.sqlite.UpdateRole"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `UPDATE 
+					role 
+				SET 
+					description = NULLIF(?2, '') 
+				WHERE name = ?1`
+
+	if _, err := tx.ExecContext(ctx, query, role.Name, role.Description); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, roleTable, err)
+	}
+
+	queryClear := `DELETE FROM role_permission WHERE role_name = ?1`
+
+	if _, err := tx.ExecContext(ctx, queryClear, role.Name); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, rolePermissionTable, err)
+	}
+
+	if err := setRolePermissions(ctx, tx, role); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tx.Commit()
+}
+
+func setRolePermissions(ctx context.Context, tx dbTx, role models.Role) error {
+	query := `INSERT INTO 
+					role_permission (role_name, permission) 
+					VALUES (?1, ?2)`
+
+	for _, permission := range role.Permissions {
+		if _, err := tx.ExecContext(ctx, query, role.Name, permission); err != nil {
+			return fmt.Errorf("%s: %w", rolePermissionTable, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+DeleteRole removes a role that no user has. It returns false
+if the role is not found or is still in use
+*/
+func (r *AdminRoleSQLite) DeleteRole(ctx context.Context, name string) (bool, error) {
+	const op = "repository.sqlite.DeleteRole"
+
+	query := `DELETE FROM 
+					role 
+				WHERE name = ?1 AND NOT EXISTS (SELECT 1 FROM user_account WHERE role = ?1)`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, name)
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, roleTable, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, roleTable, err)
+	}
+
+	return affected == 1, nil
+}
+
+func (r *AdminRoleSQLite) GetAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	const op = "repository.sqlite.GetAllPermissions"
+
+	query := `SELECT 
+					name,
+					COALESCE(description, '') 
+				FROM 
+					permission 
+				ORDER BY name`
+
+	rowsPermissions, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	permissions, err := collectRows(rowsPermissions, func(row scanner) (models.Permission, error) {
+		var permission models.Permission
+		err := row.Scan(&permission.Name, &permission.Description)
+
+		return permission, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return permissions, nil
+}
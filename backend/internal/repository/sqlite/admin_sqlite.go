@@ -0,0 +1,18 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type AdminSQLite struct {
+	db *sql.DB
+	repository.AdminRole
+	repository.AdminReward
+	repository.AdminUserReward
+}
+
+func NewAdminSQLite(db *sql.DB) repository.Admin {
+	return &AdminSQLite{db: db}
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type AdminUserRewardSQLite struct {
+	db *sql.DB
+	repository.Reward
+}
+
+func NewAdminUserRewardSQLite(db *sql.DB) repository.AdminUserReward {
+	return &AdminUserRewardSQLite{db: db, Reward: NewRewardSQLite(db)}
+}
+
+func (r *AdminUserRewardSQLite) AssignReward(ctx context.Context, userId, habitId, rewardId int) (int, error) {
+	const op = "repository.sqlite.AssignReward"
+
+	var userRewardId int
+
+	query := `INSERT INTO
+					user_reward (user_id, habit_id, reward_id)
+				SELECT 
+					ht.user_id, ht.habit_id, rt.id
+				FROM user_habit AS ht, reward AS rt
+				WHERE
+					ht.user_id = ?1
+				AND  
+					ht.habit_id = ?2
+				AND  
+					rt.id = ?3
+				RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, userId, habitId, rewardId)
+	if err := row.Scan(&userRewardId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user_reward", err))
+	}
+
+	return userRewardId, nil
+}
+
+// Take away from user
+func (r *AdminUserRewardSQLite) RemoveFromUser(ctx context.Context, userId, habitId, rewardId int) error {
+	const op = "repository.sqlite.RemoveFromUser"
+
+	var checkUserRewardId int
+
+	query := `DELETE FROM
+					user_reward
+				WHERE user_id = ?1 AND habit_id = ?2 AND reward_id = ?3
+				RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, userId, habitId, rewardId)
+	if err := row.Scan(&checkUserRewardId); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user_reward", err))
+	}
+
+	return nil
+}
+
+func (r *AdminUserRewardSQLite) UpdateUserReward(ctx context.Context, userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
+	const op = "repository.sqlite.UpdateUserReward"
+
+	var userRewardId int
+
+	query := `UPDATE 
+					user_reward
+				SET 
+					habit_id = COALESCE(?4, habit_id),
+					reward_id = COALESCE(?5, reward_id) 
+				WHERE user_id = ?1 AND habit_id = ?2 AND reward_id = ?3
+				RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, userId, habitId, rewardId, input.HabitId, input.RewardId)
+	if err := row.Scan(&userRewardId); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user_reward", err))
+	}
+
+	return nil
+}
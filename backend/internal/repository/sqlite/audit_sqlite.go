@@ -0,0 +1,106 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type AuditSQLite struct {
+	db *sql.DB
+}
+
+func NewAuditSQLite(db *sql.DB) repository.Audit {
+	return &AuditSQLite{db: db}
+}
+
+// jsonArg stores a json document as text, an empty one as NULL
+func jsonArg(doc json.RawMessage) any {
+	if len(doc) == 0 {
+		return nil
+	}
+
+	return string(doc)
+}
+
+func scanAuditEntry(row scanner) (models.AuditEntry, error) {
+	var (
+		entry         models.AuditEntry
+		before, after sql.NullString
+	)
+
+	err := row.Scan(&entry.Id, &entry.ActorId, &entry.TargetUserId, &entry.Action,
+		&before, &after, &entry.RequestId, &entry.CreatedAt)
+	if err != nil {
+		return entry, err
+	}
+
+	if before.Valid {
+		entry.Before = json.RawMessage(before.String)
+	}
+
+	if after.Valid {
+		entry.After = json.RawMessage(after.String)
+	}
+
+	return entry, nil
+}
+
+func (r *AuditSQLite) Create(ctx context.Context, entry models.AuditEntry) (int, error) {
+	const op = "repository.sqlite.audit_sqlite.Create"
+
+	var id int
+	query := `INSERT INTO 
+						audit_log (actor_id, target_user_id, action, before, after, request_id, created_at) 
+						VALUES (?1, NULLIF(?2, 0), ?3, ?4, ?5, NULLIF(?6, ''), ?7) 
+					RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, entry.ActorId, entry.TargetUserId, entry.Action,
+		jsonArg(entry.Before), jsonArg(entry.After), entry.RequestId, now())
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, nil
+}
+
+func (r *AuditSQLite) GetAll(ctx context.Context, params models.ListParams) ([]models.AuditEntry, int, error) {
+	const op = "repository.sqlite.audit_sqlite.GetAll"
+
+	where, args := listWhere(nil, nil, params.Filters, auditColumns)
+
+	total, err := countRows(ctx, r.db, `SELECT count(*) FROM audit_log`+where, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, auditColumns, args)
+
+	query := `SELECT 
+					id,
+					actor_id,
+					COALESCE(target_user_id, 0),
+					action,
+					before,
+					after,
+					COALESCE(request_id, ''),
+					created_at
+				FROM 
+					audit_log` + where + tail
+
+	rowsEntries, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	entries, err := collectRows(rowsEntries, scanAuditEntry)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return entries, total, nil
+}
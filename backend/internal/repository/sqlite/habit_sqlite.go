@@ -0,0 +1,198 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type HabitSQLite struct {
+	db *sql.DB
+}
+
+func NewHabitSQLite(db *sql.DB) repository.Habit {
+	return &HabitSQLite{db: db}
+}
+
+func scanHabit(row scanner) (models.Habit, error) {
+	var habit models.Habit
+
+	err := row.Scan(&habit.Id, &habit.Title, &habit.Description, &habit.Version)
+
+	return habit, err
+}
+
+func (r *HabitSQLite) Create(ctx context.Context, userId int, habit models.Habit) (int, error) {
+	const op = "repository.sqlite.habit_sqlite.Create"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var habitId int
+	// create a habit
+	createHabitQuery := `INSERT INTO 
+								habit (title, description) 
+								VALUES (?1, ?2) 
+							RETURNING id`
+
+	if err := tx.QueryRowContext(ctx, createHabitQuery, habit.Title, habit.Description).Scan(&habitId); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("%s:%s: %w", op, habitTable, err)
+	}
+
+	// create an empty tracker for a habit
+	var trackerId int
+	createHabitTrackerQuery := `INSERT INTO 
+										habit_tracker (habit_id) 
+										VALUES (?1) 
+									RETURNING id`
+
+	if err := tx.QueryRowContext(ctx, createHabitTrackerQuery, habitId).Scan(&trackerId); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("%s:%s: %w", op, trackerTable, err)
+	}
+
+	// link habit to a user and a tracker to a habit
+	createUsersHabitsQuery := `INSERT INTO 
+										user_habit (user_id, habit_id, habit_tracker_id) 
+										VALUES (?1, ?2, ?3)`
+
+	if _, err := tx.ExecContext(ctx, createUsersHabitsQuery, userId, habitId, trackerId); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("%s:%s: %w", op, userHabitTable, err)
+	}
+
+	return habitId, tx.Commit()
+}
+
+func (r *HabitSQLite) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.Habit, int, error) {
+	const op = "repository.sqlite.habit_sqlite.GetAll"
+
+	where, args := listWhere([]string{"ul.user_id = ?1"}, []any{userId}, params.Filters, habitColumns)
+
+	total, err := countRows(ctx, r.db, `SELECT 
+											count(*) 
+										FROM 
+											habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id`+where, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, habitColumns, args)
+
+	query := `SELECT 
+					tl.id, 
+					tl.title, 
+					COALESCE(tl.description, ''),
+					tl.version 
+				FROM 
+					habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id` + where + tail
+
+	rowsHabits, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	habits, err := collectRows(rowsHabits, scanHabit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return habits, total, nil
+}
+
+func (r *HabitSQLite) GetById(ctx context.Context, userId, habitId int) (models.Habit, error) {
+	const op = "repository.sqlite.habit_sqlite.GetById"
+
+	query := `SELECT 
+					tl.id, 
+					tl.title, 
+					COALESCE(tl.description, ''),
+					tl.version 
+				FROM 
+					habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id 
+				WHERE ul.user_id = ?1 AND ul.habit_id = ?2`
+
+	habit, err := scanHabit(conn(ctx, r.db).QueryRowContext(ctx, query, userId, habitId))
+	if err != nil {
+		return habit, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("habit", err))
+	}
+
+	return habit, nil
+}
+
+func (r *HabitSQLite) Delete(ctx context.Context, userId, habitId int) error {
+	const op = "repository.sqlite.habit_sqlite.Delete"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// SQLite has no DELETE ... USING, the rows of the user are found by subqueries
+	queryTracker := `DELETE FROM 
+							habit_tracker 
+						WHERE id = (SELECT habit_tracker_id FROM user_habit WHERE user_id = ?1 AND habit_id = ?2)
+						RETURNING id`
+
+	var checkTrackerId int
+
+	if err := tx.QueryRowContext(ctx, queryTracker, userId, habitId).Scan(&checkTrackerId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, trackerTable, dbErr("habit", err))
+	}
+
+	query := `DELETE FROM 
+					habit 
+				WHERE id = (SELECT habit_id FROM user_habit WHERE user_id = ?1 AND habit_id = ?2)
+				RETURNING id`
+
+	var checkHabitId int
+
+	if err := tx.QueryRowContext(ctx, query, userId, habitId).Scan(&checkHabitId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, habitTable, dbErr("habit", err))
+	}
+
+	return tx.Commit()
+}
+
+/*
+Update changes a habit and increments its version. If input.Versions is set,
+the habit is changed only if it still has one of those versions
+*/
+func (r *HabitSQLite) Update(ctx context.Context, userId, habitId int, input models.UpdateHabitInput) (int, error) {
+	const op = "repository.sqlite.habit_sqlite.Update"
+
+	query := `UPDATE 
+					habit AS tl 
+				SET 
+					title=COALESCE(?3, title), 
+					description=COALESCE(?4, description),
+					version=tl.version + 1
+				FROM user_habit ul 
+					WHERE tl.id = ul.habit_id AND ul.user_id = ?1 AND ul.habit_id = ?2
+						AND (?5 IS NULL OR tl.version IN (SELECT value FROM json_each(?5)))
+					RETURNING version`
+
+	var version int
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, userId, habitId, input.Title, input.Description, versionList(input.Versions))
+	err := row.Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) && input.Versions != nil {
+		if _, getErr := r.GetById(ctx, userId, habitId); getErr == nil {
+			return 0, fmt.Errorf("%s: %w", op, repository.ErrVersionMismatch)
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("habit", err))
+	}
+
+	return version, nil
+}
@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+/*
+trackerSelect reads end_date as it is: COALESCE would drop the declared
+type of the column and the driver would return text instead of a time.
+An empty end date is replaced with the current date by scanTracker
+*/
+const trackerSelect = `SELECT 
+							tl.id, 
+							tl.habit_id, 
+							COALESCE(tl.unit_of_messure, '-'), 
+							COALESCE(tl.goal, '-'),
+							COALESCE(tl.frequency, '-'),
+							tl.start_date,
+							tl.end_date,
+							COALESCE(tl.counter, 0),
+							tl.done,
+							tl.version 
+						FROM 
+							habit_tracker tl INNER JOIN user_habit ul on tl.id = ul.habit_tracker_id`
+
+type HabitTrackerSQLite struct {
+	db *sql.DB
+}
+
+func NewHabitTrackerSQLite(db *sql.DB) repository.HabitTracker {
+	return &HabitTrackerSQLite{db: db}
+}
+
+func scanTracker(row scanner) (models.HabitTracker, error) {
+	var (
+		tracker models.HabitTracker
+		endDate sql.NullTime
+	)
+
+	err := row.Scan(&tracker.Id, &tracker.HabitId, &tracker.UnitOfMessure, &tracker.Goal, &tracker.Frequency,
+		&tracker.StartDate, &endDate, &tracker.Counter, &tracker.Done, &tracker.Version)
+	if err != nil {
+		return tracker, err
+	}
+
+	tracker.EndDate = endDate.Time
+	if !endDate.Valid {
+		tracker.EndDate = now().Truncate(24 * time.Hour)
+	}
+
+	return tracker, nil
+}
+
+func (r *HabitTrackerSQLite) GetById(ctx context.Context, userId, habitId int) (models.HabitTracker, error) {
+	const op = "repository.sqlite.habit_tracker_sqlite.GetById"
+
+	query := trackerSelect + ` WHERE ul.user_id = ?1 AND ul.habit_id = ?2`
+
+	tracker, err := scanTracker(conn(ctx, r.db).QueryRowContext(ctx, query, userId, habitId))
+	if err != nil {
+		return tracker, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("habit_tracker", err))
+	}
+
+	return tracker, nil
+}
+
+func (r *HabitTrackerSQLite) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.HabitTracker, int, error) {
+	const op = "repository.sqlite.habit_tracker_sqlite.GetAll"
+
+	where, args := listWhere([]string{"ul.user_id = ?1"}, []any{userId}, params.Filters, trackerColumns)
+
+	total, err := countRows(ctx, r.db, `SELECT 
+											count(*) 
+										FROM 
+											habit_tracker tl INNER JOIN user_habit ul on tl.id = ul.habit_tracker_id`+where, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, trackerColumns, args)
+
+	rowsTrackers, err := conn(ctx, r.db).QueryContext(ctx, trackerSelect+where+tail, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	trackers, err := collectRows(rowsTrackers, scanTracker)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return trackers, total, nil
+}
+
+/*
+Update changes a tracker and increments its version. If input.Versions is set,
+the tracker is changed only if it still has one of those versions, so an edit made
+from another client in the meantime is not overwritten
+*/
+func (r *HabitTrackerSQLite) Update(ctx context.Context, userId, habitId int, input models.UpdateTrackerInput) (int, error) {
+	const op = "repository.sqlite.habit_tracker_sqlite.Update"
+
+	query := `UPDATE 
+					habit_tracker AS tl 
+				SET 
+					unit_of_messure=COALESCE(?3, unit_of_messure),
+					goal=COALESCE(?4, goal),
+					frequency=COALESCE(?5, frequency),
+					start_date=COALESCE(?6, start_date),
+					end_date=COALESCE(?7, end_date),
+					counter=COALESCE(?8, counter),
+					done=COALESCE(?9, done),
+					version=tl.version + 1 
+				FROM user_habit ul 
+					WHERE tl.id = ul.habit_tracker_id AND ul.habit_id = ?2 AND ul.user_id = ?1
+						AND (?10 IS NULL OR tl.version IN (SELECT value FROM json_each(?10)))
+					RETURNING version`
+
+	var version int
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, userId, habitId, input.UnitOfMessure, input.Goal, input.Frequency,
+		utcTime(input.StartDate), utcTime(input.EndDate), input.Counter, input.Done, versionList(input.Versions))
+	err := row.Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) && input.Versions != nil {
+		if _, getErr := r.GetById(ctx, userId, habitId); getErr == nil {
+			return 0, fmt.Errorf("%s: %w", op, repository.ErrVersionMismatch)
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("habit_tracker", err))
+	}
+
+	return version, nil
+}
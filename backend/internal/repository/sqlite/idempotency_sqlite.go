@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type IdempotencySQLite struct {
+	db *sql.DB
+}
+
+func NewIdempotencySQLite(db *sql.DB) repository.Idempotency {
+	return &IdempotencySQLite{db: db}
+}
+
+/*
+Reserve stores a key of a request in progress. It reports false if the
+key is taken by a record which is not expired. Expired keys of the user
+are removed on the way, so the table does not grow with old keys
+*/
+func (r *IdempotencySQLite) Reserve(ctx context.Context, record models.IdempotencyRecord) (bool, error) {
+	const op = "repository.sqlite.idempotency_sqlite.Reserve"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	queryExpired := `DELETE FROM
+						idempotency_key
+					WHERE user_id = ?1 AND expires_at <= ?2`
+
+	if _, err := tx.ExecContext(ctx, queryExpired, record.UserId, now()); err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	queryReserve := `INSERT INTO
+						idempotency_key (user_id, idem_key, request_hash, created_at, expires_at)
+						VALUES (?1, ?2, ?3, ?4, ?5)
+					ON CONFLICT (user_id, idem_key) DO NOTHING`
+
+	result, err := tx.ExecContext(ctx, queryReserve, record.UserId, record.Key, record.RequestHash, now(), record.ExpiresAt.UTC())
+	if err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reserved == 1, nil
+}
+
+func (r *IdempotencySQLite) Get(ctx context.Context, userId int, key string) (models.IdempotencyRecord, error) {
+	const op = "repository.sqlite.idempotency_sqlite.Get"
+
+	var (
+		record  models.IdempotencyRecord
+		headers sql.NullString
+	)
+	// headers are kept as a json object
+	query := `SELECT
+					user_id,
+					idem_key,
+					request_hash,
+					status_code,
+					response_body,
+					response_headers,
+					expires_at
+				FROM
+					idempotency_key
+				WHERE user_id = ?1 AND idem_key = ?2`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, userId, key)
+	err := row.Scan(&record.UserId, &record.Key, &record.RequestHash, &record.StatusCode, &record.ResponseBody, &headers, &record.ExpiresAt)
+	if err != nil {
+		return record, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	if headers.Valid {
+		if err := json.Unmarshal([]byte(headers.String), &record.ResponseHeaders); err != nil {
+			return record, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+		}
+	}
+
+	return record, nil
+}
+
+// Complete stores the response to the request the key was reserved for
+func (r *IdempotencySQLite) Complete(ctx context.Context, userId int, key string, statusCode int, headers map[string]string, body []byte) error {
+	const op = "repository.sqlite.idempotency_sqlite.Complete"
+
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `UPDATE
+					idempotency_key
+				SET
+					status_code = ?3,
+					response_headers = ?4,
+					response_body = ?5
+				WHERE user_id = ?1 AND idem_key = ?2`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userId, key, statusCode, string(encodedHeaders), body); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	return nil
+}
+
+// Delete frees a key, so the request can be sent again with it
+func (r *IdempotencySQLite) Delete(ctx context.Context, userId int, key string) error {
+	const op = "repository.sqlite.idempotency_sqlite.Delete"
+
+	query := `DELETE FROM
+					idempotency_key
+				WHERE user_id = ?1 AND idem_key = ?2`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userId, key); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, idempotencyTable, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type IdentitySQLite struct {
+	db *sql.DB
+}
+
+func NewIdentitySQLite(db *sql.DB) repository.Identity {
+	return &IdentitySQLite{db: db}
+}
+
+func (r *IdentitySQLite) Create(ctx context.Context, identity models.Identity) (int, error) {
+	const op = "repository.sqlite.identity_sqlite.Create"
+
+	var id int
+	query := `INSERT INTO 
+						user_identity (user_id, provider, subject, email, created_at) 
+						VALUES (?1, ?2, ?3, NULLIF(?4, ''), ?5) 
+					RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, identity.UserId, identity.Provider, identity.Subject, identity.Email, now())
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, nil
+}
+
+// GetUserId returns the id of a user linked to the identity
+func (r *IdentitySQLite) GetUserId(ctx context.Context, provider, subject string) (int, error) {
+	const op = "repository.sqlite.identity_sqlite.GetUserId"
+
+	var userId int
+	query := `SELECT 
+					user_id 
+				FROM 
+					user_identity 
+				WHERE provider = ?1 AND subject = ?2`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, provider, subject).Scan(&userId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return userId, nil
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+/*
+touchInterval limits how often the last used time of a token is written,
+a script polling the api does not cause a write on every request
+*/
+const touchInterval = time.Minute
+
+// personalTokenSelect reads scopes as a json array, SQLite has no arrays
+const personalTokenSelect = `SELECT
+								id,
+								user_id,
+								name,
+								token_hash,
+								scopes,
+								expires_at,
+								last_used_at,
+								revoked_at,
+								created_at
+							FROM
+								personal_access_token`
+
+type PersonalTokenSQLite struct {
+	db *sql.DB
+}
+
+func NewPersonalTokenSQLite(db *sql.DB) repository.PersonalToken {
+	return &PersonalTokenSQLite{db: db}
+}
+
+func scanPersonalToken(row scanner) (models.PersonalToken, error) {
+	var (
+		token  models.PersonalToken
+		scopes string
+	)
+
+	err := row.Scan(&token.Id, &token.UserId, &token.Name, &token.TokenHash, &scopes,
+		&token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt, &token.CreatedAt)
+	if err != nil {
+		return token, err
+	}
+
+	if err := json.Unmarshal([]byte(scopes), &token.Scopes); err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+func (r *PersonalTokenSQLite) Create(ctx context.Context, token models.PersonalToken) (int, error) {
+	const op = "repository.sqlite.personal_token_sqlite.Create"
+
+	scopes, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var id int
+	query := `INSERT INTO
+						personal_access_token (user_id, name, token_hash, scopes, expires_at, created_at)
+						VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+					RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, token.UserId, token.Name, token.TokenHash, string(scopes), token.ExpiresAt.UTC(), now())
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, nil
+}
+
+// GetAll returns tokens of a user which are not revoked, expired ones included
+func (r *PersonalTokenSQLite) GetAll(ctx context.Context, userId int) ([]models.PersonalToken, error) {
+	const op = "repository.sqlite.personal_token_sqlite.GetAll"
+
+	query := personalTokenSelect + ` WHERE user_id = ?1 AND revoked_at IS NULL ORDER BY created_at DESC, id DESC`
+
+	rowsTokens, err := conn(ctx, r.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	tokens, err := collectRows(rowsTokens, scanPersonalToken)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return tokens, nil
+}
+
+func (r *PersonalTokenSQLite) GetByHash(ctx context.Context, tokenHash string) (models.PersonalToken, error) {
+	const op = "repository.sqlite.personal_token_sqlite.GetByHash"
+
+	query := personalTokenSelect + ` WHERE token_hash = ?1`
+
+	token, err := scanPersonalToken(conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		return token, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return token, nil
+}
+
+// Revoke reports false if the user has no such token or it is revoked already
+func (r *PersonalTokenSQLite) Revoke(ctx context.Context, userId, tokenId int) (bool, error) {
+	const op = "repository.sqlite.personal_token_sqlite.Revoke"
+
+	query := `UPDATE
+					personal_access_token
+				SET
+					revoked_at = ?3
+				WHERE id = ?1 AND user_id = ?2 AND revoked_at IS NULL`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, tokenId, userId, now())
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, personalTokenTable, err)
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, personalTokenTable, err)
+	}
+
+	return revoked == 1, nil
+}
+
+// Touch records that a token is used
+func (r *PersonalTokenSQLite) Touch(ctx context.Context, tokenId int) error {
+	const op = "repository.sqlite.personal_token_sqlite.Touch"
+
+	query := `UPDATE
+					personal_access_token
+				SET
+					last_used_at = ?2
+				WHERE id = ?1 AND (last_used_at IS NULL OR last_used_at < ?3)`
+
+	usedAt := now()
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, tokenId, usedAt, usedAt.Add(-touchInterval)); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, personalTokenTable, err)
+	}
+
+	return nil
+}
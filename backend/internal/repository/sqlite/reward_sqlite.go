@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type RewardSQLite struct {
+	db *sql.DB
+}
+
+func NewRewardSQLite(db *sql.DB) repository.Reward {
+	return &RewardSQLite{db: db}
+}
+
+func (r *RewardSQLite) GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error) {
+	const op = "repository.sqlite.reward_sqlite.GetPersonalRewardsByHabitId"
+
+	query := `SELECT 
+					tl.id, tl.title, COALESCE(tl.description, '') 
+				FROM reward tl INNER JOIN user_reward ul on tl.id = ul.reward_id
+				WHERE ul.user_id = ?1 AND ul.habit_id = ?2`
+
+	rowsRewards, err := conn(ctx, r.db).QueryContext(ctx, query, userId, habitId)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	rewards, err := collectRows(rowsRewards, scanReward)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return rewards, nil
+}
+
+func (r *RewardSQLite) GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error) {
+	const op = "repository.sqlite.reward_sqlite.GetAllPersonalRewards"
+
+	where, args := listWhere([]string{"ul.user_id = ?1"}, []any{userId}, params.Filters, rewardColumns)
+
+	total, err := countRows(ctx, r.db, `SELECT 
+											count(*) 
+										FROM reward tl INNER JOIN user_reward ul on tl.id = ul.reward_id`+where, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, rewardColumns, args)
+
+	query := `SELECT 
+					tl.id, tl.title, COALESCE(tl.description, '') 
+				FROM reward tl INNER JOIN user_reward ul on tl.id = ul.reward_id` + where + tail
+
+	rowsRewards, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	rewards, err := collectRows(rowsRewards, scanReward)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return rewards, total, nil
+}
@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type SearchSQLite struct {
+	db *sql.DB
+}
+
+func NewSearchSQLite(db *sql.DB) repository.Search {
+	return &SearchSQLite{db: db}
+}
+
+/*
+searchMatch builds the condition and the rank of a search query. SQLite
+has no full text search in the default build, so every word of the query
+has to be found in the title or the description. A word found in the
+title weighs more, as the weights of the postgres search vector do.
+The arguments of the words are placed after the first args arguments
+*/
+func searchMatch(query string, args []any) (string, string, []any) {
+	var conditions, ranks []string
+
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `"`, "")
+
+	for _, word := range strings.Fields(query) {
+		word = replacer.Replace(word)
+		if word == "" {
+			continue
+		}
+
+		args = append(args, "%"+word+"%")
+		placeholder := fmt.Sprintf("?%d", len(args))
+
+		title := fmt.Sprintf(`tl.title LIKE %s ESCAPE '\'`, placeholder)
+		description := fmt.Sprintf(`COALESCE(tl.description, '') LIKE %s ESCAPE '\'`, placeholder)
+
+		conditions = append(conditions, fmt.Sprintf("(%s OR %s)", title, description))
+		ranks = append(ranks, fmt.Sprintf("(%s) * 1.0 + (%s) * 0.4", title, description))
+	}
+
+	if len(conditions) == 0 {
+		return "0", "0", args
+	}
+
+	return strings.Join(conditions, " AND "), strings.Join(ranks, " + "), args
+}
+
+/*
+Search looks for the query among habits and rewards of a certain user.
+Words of the query are matched case insensitively as parts of words
+*/
+func (r *SearchSQLite) Search(ctx context.Context, userId int, input models.SearchInput) (models.SearchResult, error) {
+	const op = "repository.sqlite.search_sqlite.Search"
+
+	var result models.SearchResult
+
+	match, rank, args := searchMatch(input.Query, []any{userId, input.Limit, input.Offset})
+
+	habitsQuery := fmt.Sprintf(`SELECT
+						tl.id,
+						ul.user_id,
+						tl.title,
+						COALESCE(tl.description, ''),
+						%s AS rank
+					FROM
+						habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id
+					WHERE ul.user_id = ?1 AND %s
+					ORDER BY rank DESC, tl.id
+					LIMIT ?2 OFFSET ?3`, rank, match)
+
+	habits, err := r.collectHits(ctx, habitsQuery, args...)
+	if err != nil {
+		return result, fmt.Errorf("%s:%s: %w", op, habitTable, err)
+	}
+
+	rewardsQuery := fmt.Sprintf(`SELECT
+						tl.id,
+						0 AS user_id,
+						tl.title,
+						COALESCE(tl.description, ''),
+						%s AS rank
+					FROM
+						reward tl
+					WHERE tl.id IN (SELECT reward_id FROM user_reward WHERE user_id = ?1)
+						AND %s
+					ORDER BY rank DESC, tl.id
+					LIMIT ?2 OFFSET ?3`, rank, match)
+
+	rewards, err := r.collectHits(ctx, rewardsQuery, args...)
+	if err != nil {
+		return result, fmt.Errorf("%s:%s: %w", op, rewardTable, err)
+	}
+
+	result.Habits = habits
+	result.Rewards = rewards
+
+	return result, nil
+}
+
+// SearchAll looks for the query among habits of all users and all rewards
+func (r *SearchSQLite) SearchAll(ctx context.Context, input models.SearchInput) (models.SearchResult, error) {
+	const op = "repository.sqlite.search_sqlite.SearchAll"
+
+	var result models.SearchResult
+
+	match, rank, args := searchMatch(input.Query, []any{input.Limit, input.Offset})
+
+	habitsQuery := fmt.Sprintf(`SELECT
+						tl.id,
+						ul.user_id,
+						tl.title,
+						COALESCE(tl.description, ''),
+						%s AS rank
+					FROM
+						habit tl INNER JOIN user_habit ul on tl.id = ul.habit_id
+					WHERE %s
+					ORDER BY rank DESC, tl.id
+					LIMIT ?1 OFFSET ?2`, rank, match)
+
+	habits, err := r.collectHits(ctx, habitsQuery, args...)
+	if err != nil {
+		return result, fmt.Errorf("%s:%s: %w", op, habitTable, err)
+	}
+
+	rewardsQuery := fmt.Sprintf(`SELECT
+						tl.id,
+						0 AS user_id,
+						tl.title,
+						COALESCE(tl.description, ''),
+						%s AS rank
+					FROM
+						reward tl
+					WHERE %s
+					ORDER BY rank DESC, tl.id
+					LIMIT ?1 OFFSET ?2`, rank, match)
+
+	rewards, err := r.collectHits(ctx, rewardsQuery, args...)
+	if err != nil {
+		return result, fmt.Errorf("%s:%s: %w", op, rewardTable, err)
+	}
+
+	result.Habits = habits
+	result.Rewards = rewards
+
+	return result, nil
+}
+
+func (r *SearchSQLite) collectHits(ctx context.Context, query string, args ...any) ([]models.SearchHit, error) {
+	rowsHits, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", queryErr, err)
+	}
+
+	hits, err := collectRows(rowsHits, func(row scanner) (models.SearchHit, error) {
+		var hit models.SearchHit
+		err := row.Scan(&hit.Id, &hit.UserId, &hit.Title, &hit.Description, &hit.Rank)
+
+		return hit, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", collectErr, err)
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,205 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/migrate"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/backend/migrations"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+	"github.com/mattn/go-sqlite3"
+)
+
+/*
+the SQLite repository runs the app without a postgres server: locally,
+in demos and in end-to-end tests. Queries use ?N placeholders, since
+$N is a named parameter for SQLite, and times are stored in UTC, so
+the stored text compares in the same order as the times do
+*/
+
+const (
+	queryErr   = "queryRow failed"
+	collectErr = "collectRow failed"
+	scanErr    = "row scan failed"
+	countErr   = "count rows failed"
+)
+
+const (
+	habitTable          = "habit-table"
+	trackerTable        = "habit-tracker-table"
+	userHabitTable      = "user-habit-table"
+	rewardTable         = "reward-table"
+	refreshTable        = "refresh-token-table"
+	revokedTable        = "revoked-token-table"
+	usedTable           = "used-token-table"
+	personalTokenTable  = "personal-access-token-table"
+	idempotencyTable    = "idempotency-key-table"
+	linkCodeTable       = "telegram-link-code-table"
+	userTable           = "user-account-table"
+	userRewardTable     = "user-reward-table"
+	roleTable           = "role-table"
+	rolePermissionTable = "role-permission-table"
+)
+
+// scanner is either *sql.Row or *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+/*
+dbErr turns a missing row, a unique and a foreign key violation into
+typed errors of pkg/errs, the same way the postgres repository does
+*/
+func dbErr(entity string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return errs.NotFound(entity+"_not_found", err)
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return errs.Conflict(entity+"_exists", err)
+		case sqlite3.ErrConstraintForeignKey:
+			return errs.Validation(entity+"_invalid_reference", err)
+		}
+	}
+
+	return err
+}
+
+// now is the current time in UTC, the only zone times are stored in
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// utcTime converts an optional time argument to UTC, a nil one stays NULL
+func utcTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return t.UTC()
+}
+
+// versionList passes the versions of If-Match as a json array, SQLite has no arrays. Nil stays NULL
+func versionList(versions []int) any {
+	if versions == nil {
+		return nil
+	}
+
+	list := make([]string, len(versions))
+	for i, version := range versions {
+		list[i] = strconv.Itoa(version)
+	}
+
+	return "[" + strings.Join(list, ",") + "]"
+}
+
+/*
+NewSQLiteDB opens the database file of the config, creates it if there is
+none and applies the migrations of the SQLite schema. The version of the
+schema is kept in the user_version pragma
+*/
+func NewSQLiteDB(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	const op = "repository.sqlite.NewSQLiteDB"
+
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	params.Set("_busy_timeout", "5000")
+	params.Set("_journal_mode", "WAL")
+	// a write transaction takes the lock at once, so two of them never deadlock
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?%s", cfg.DB.Path, params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := migrateSchema(ctx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return db, nil
+}
+
+func migrateSchema(ctx context.Context, db *sql.DB) error {
+	fsys, err := fs.Sub(migrations.SQLiteFS, "sqlite")
+	if err != nil {
+		return err
+	}
+
+	list, err := migrate.Load(fsys)
+	if err != nil {
+		return err
+	}
+
+	var version int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for _, migration := range list {
+		if migration.Version <= version {
+			continue
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		// pragmas take no arguments, the version is a number from the file name
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, migration.Version)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func NewSQLiteRepository(db *sql.DB) *repository.Repository {
+	return &repository.Repository{
+		Transactor:      NewTransactorSQLite(db),
+		AdminRole:       NewAdminRoleSQLite(db),
+		AdminReward:     NewAdminRewardSQLite(db),
+		AdminUserReward: NewAdminUserRewardSQLite(db),
+		Admin:           NewAdminSQLite(db),
+		User:            NewUserSQLite(db),
+		Token:           NewTokenSQLite(db),
+		PersonalToken:   NewPersonalTokenSQLite(db),
+		Idempotency:     NewIdempotencySQLite(db),
+		Identity:        NewIdentitySQLite(db),
+		TelegramLink:    NewTelegramLinkSQLite(db),
+		Habit:           NewHabitSQLite(db),
+		HabitTracker:    NewHabitTrackerSQLite(db),
+		Reward:          NewRewardSQLite(db),
+		Search:          NewSearchSQLite(db),
+		Audit:           NewAuditSQLite(db),
+	}
+}
@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type TelegramLinkSQLite struct {
+	db *sql.DB
+}
+
+func NewTelegramLinkSQLite(db *sql.DB) repository.TelegramLink {
+	return &TelegramLinkSQLite{db: db}
+}
+
+// CreateCode stores a new link code of a user, a previous code stops working
+func (r *TelegramLinkSQLite) CreateCode(ctx context.Context, userId int, codeHash string, expiresAt time.Time) error {
+	const op = "repository.sqlite.telegram_link_sqlite.CreateCode"
+
+	query := `INSERT INTO
+					telegram_link_code (code_hash, user_id, expires_at)
+					VALUES (?1, ?2, ?3)
+				ON CONFLICT (user_id) DO UPDATE
+					SET code_hash = excluded.code_hash, expires_at = excluded.expires_at`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, codeHash, userId, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, linkCodeTable, err)
+	}
+
+	return nil
+}
+
+// UseCode removes a code that is not expired and returns the user it belongs to
+func (r *TelegramLinkSQLite) UseCode(ctx context.Context, codeHash string) (int, error) {
+	const op = "repository.sqlite.telegram_link_sqlite.UseCode"
+
+	var userId int
+	query := `DELETE FROM
+					telegram_link_code
+				WHERE code_hash = ?1 AND expires_at > ?2
+				RETURNING user_id`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, codeHash, now()).Scan(&userId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return userId, nil
+}
+
+/*
+Link binds a telegram account to a user. If mergeUserId is set, habits
+and rewards of that telegram-only account are moved to the user and the
+account is removed, so the telegram id becomes free before it is bound.
+A deleted account releases the telegram id too, it is not linked back
+if the account is restored.
+Habits belong to a single user, so moving them can't break unique keys
+*/
+func (r *TelegramLinkSQLite) Link(ctx context.Context, userId, mergeUserId int, tgUserId int64, tgUsername string) error {
+	const op = "repository.sqlite.telegram_link_sqlite.Link"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if mergeUserId != 0 {
+		queryHabits := `UPDATE
+							user_habit
+						SET
+							user_id = ?1
+						WHERE user_id = ?2`
+
+		if _, err := tx.ExecContext(ctx, queryHabits, userId, mergeUserId); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s:%s: %w", op, userHabitTable, err)
+		}
+
+		queryRewards := `UPDATE
+							user_reward
+						SET
+							user_id = ?1
+						WHERE user_id = ?2`
+
+		if _, err := tx.ExecContext(ctx, queryRewards, userId, mergeUserId); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s:%s: %w", op, userRewardTable, err)
+		}
+
+		// only an account without web credentials is merged
+		queryMerged := `DELETE FROM
+							user_account
+						WHERE id = ?1 AND tg_user_id = ?2 AND user_name IS NULL AND password_hash IS NULL`
+
+		result, err := tx.ExecContext(ctx, queryMerged, mergeUserId, tgUserId)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s:%s: %w", op, userTable, err)
+		}
+
+		if merged, err := result.RowsAffected(); err != nil || merged != 1 {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", op, errors.New("merged account is not a telegram-only account"))
+		}
+	}
+
+	queryDeleted := `UPDATE
+						user_account
+					SET
+						tg_user_id = NULL
+					WHERE tg_user_id = ?1 AND id <> ?2 AND deleted_at IS NOT NULL`
+
+	if _, err := tx.ExecContext(ctx, queryDeleted, tgUserId, userId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, userTable, err)
+	}
+
+	// the user name is kept only if no other account holds it
+	queryUser := `UPDATE
+					user_account
+				SET
+					tg_user_id = ?2,
+					tg_user_name = CASE
+						WHEN EXISTS (SELECT 1 FROM user_account WHERE tg_user_name = ?3 AND id <> ?1) THEN tg_user_name
+						ELSE COALESCE(NULLIF(?3, ''), tg_user_name)
+					END
+				WHERE id = ?1 AND (tg_user_id IS NULL OR tg_user_id = ?2) AND deleted_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, queryUser, userId, tgUserId, tgUsername)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, userTable, err)
+	}
+
+	if linked, err := result.RowsAffected(); err != nil || linked != 1 {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", op, errors.New("user is not found or linked to another telegram account"))
+	}
+
+	return tx.Commit()
+}
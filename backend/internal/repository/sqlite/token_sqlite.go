@@ -0,0 +1,278 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type TokenSQLite struct {
+	db *sql.DB
+}
+
+func NewTokenSQLite(db *sql.DB) repository.Token {
+	return &TokenSQLite{db: db}
+}
+
+func (r *TokenSQLite) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (int, error) {
+	const op = "repository.sqlite.token_sqlite.CreateRefreshToken"
+
+	var id int
+	query := `INSERT INTO 
+						refresh_token (user_id, token_hash, family_id, expires_at, created_at) 
+						VALUES (?1, ?2, ?3, ?4, ?5) 
+					RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, token.UserId, token.TokenHash, token.FamilyId, token.ExpiresAt.UTC(), now())
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, nil
+}
+
+func (r *TokenSQLite) GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	const op = "repository.sqlite.token_sqlite.GetRefreshToken"
+
+	var token models.RefreshToken
+	query := `SELECT 
+					id,
+					user_id,
+					token_hash,
+					family_id,
+					expires_at,
+					revoked_at
+				FROM 
+					refresh_token 
+				WHERE token_hash = ?1`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash)
+	err := row.Scan(&token.Id, &token.UserId, &token.TokenHash, &token.FamilyId, &token.ExpiresAt, &token.RevokedAt)
+	if err != nil {
+		return token, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return token, nil
+}
+
+/*
+RotateRefreshToken revokes the used token and stores the next one
+in a single transaction. If the used token has been revoked already
+(two refresh requests with the same token), nothing is stored
+*/
+func (r *TokenSQLite) RotateRefreshToken(ctx context.Context, usedTokenId int, next models.RefreshToken) (int, error) {
+	const op = "repository.sqlite.token_sqlite.RotateRefreshToken"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	queryRevoke := `UPDATE 
+						refresh_token 
+					SET 
+						revoked_at = ?2 
+					WHERE id = ?1 AND revoked_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, queryRevoke, usedTokenId, now())
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("%s:%s: %w", op, refreshTable, err)
+	}
+
+	if revoked, err := result.RowsAffected(); err != nil || revoked == 0 {
+		tx.Rollback()
+		return 0, fmt.Errorf("%s:%s: %w", op, refreshTable, errors.New("token is already used"))
+	}
+
+	var id int
+	queryCreate := `INSERT INTO 
+						refresh_token (user_id, token_hash, family_id, expires_at, created_at) 
+						VALUES (?1, ?2, ?3, ?4, ?5) 
+					RETURNING id`
+
+	row := tx.QueryRowContext(ctx, queryCreate, next.UserId, next.TokenHash, next.FamilyId, next.ExpiresAt.UTC(), now())
+	if err := row.Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *TokenSQLite) RevokeTokenFamily(ctx context.Context, userId int, familyId string) error {
+	const op = "repository.sqlite.token_sqlite.RevokeTokenFamily"
+
+	query := `UPDATE 
+					refresh_token 
+				SET 
+					revoked_at = ?3 
+				WHERE user_id = ?1 AND family_id = ?2 AND revoked_at IS NULL`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userId, familyId, now()); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, refreshTable, err)
+	}
+
+	return nil
+}
+
+func (r *TokenSQLite) RevokeAccessToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error {
+	const op = "repository.sqlite.token_sqlite.RevokeAccessToken"
+
+	query := `INSERT INTO 
+					revoked_token (jti, user_id, expires_at) 
+					VALUES (?1, ?2, ?3) 
+				ON CONFLICT (jti) DO NOTHING`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, jti, userId, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("%s:%s: %w", op, revokedTable, err)
+	}
+
+	return nil
+}
+
+/*
+RevokeAllUserTokens revokes all refresh and personal access tokens
+of a user and marks all access tokens issued so far as revoked
+*/
+func (r *TokenSQLite) RevokeAllUserTokens(ctx context.Context, userId int) error {
+	const op = "repository.sqlite.token_sqlite.RevokeAllUserTokens"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	revokedAt := now()
+
+	queryRefresh := `UPDATE 
+						refresh_token 
+					SET 
+						revoked_at = ?2 
+					WHERE user_id = ?1 AND revoked_at IS NULL`
+
+	if _, err := tx.ExecContext(ctx, queryRefresh, userId, revokedAt); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, refreshTable, err)
+	}
+
+	queryPersonal := `UPDATE 
+						personal_access_token 
+					SET 
+						revoked_at = ?2 
+					WHERE user_id = ?1 AND revoked_at IS NULL`
+
+	if _, err := tx.ExecContext(ctx, queryPersonal, userId, revokedAt); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s:%s: %w", op, personalTokenTable, err)
+	}
+
+	queryUser := `UPDATE 
+					user_account 
+				SET 
+					tokens_revoked_at = ?2 
+				WHERE id = ?1`
+
+	// access tokens are issued with milliseconds, see IsAccessTokenRevoked
+	if _, err := tx.ExecContext(ctx, queryUser, userId, revokedAt.Truncate(time.Millisecond)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tx.Commit()
+}
+
+/*
+IsAccessTokenRevoked checks both revocation sources: the token itself
+is revoked on logout, and all tokens of the user issued before
+tokens_revoked_at are revoked on "log out everywhere". Tokens are issued
+with milliseconds, so tokens_revoked_at is kept in milliseconds and a token
+issued in the millisecond of the revocation stays valid
+*/
+func (r *TokenSQLite) IsAccessTokenRevoked(ctx context.Context, jti string, userId int, issuedAt time.Time) (bool, error) {
+	const op = "repository.sqlite.token_sqlite.IsAccessTokenRevoked"
+
+	var revoked bool
+	query := `SELECT 
+					EXISTS (SELECT 1 FROM revoked_token WHERE jti = ?1)
+					OR EXISTS (SELECT 1 FROM user_account WHERE id = ?2 AND tokens_revoked_at > ?3)`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, jti, userId, issuedAt.UTC()).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return revoked, nil
+}
+
+/*
+UseOneTimeToken marks a one-time token as used. It returns false
+if the token has been used before
+*/
+func (r *TokenSQLite) UseOneTimeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	const op = "repository.sqlite.token_sqlite.UseOneTimeToken"
+
+	query := `INSERT INTO 
+					used_token (jti, expires_at) 
+					VALUES (?1, ?2) 
+				ON CONFLICT (jti) DO NOTHING`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, jti, expiresAt.UTC())
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, usedTable, err)
+	}
+
+	used, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, usedTable, err)
+	}
+
+	return used == 1, nil
+}
+
+/*
+PurgeExpiredTokens removes used one-time tokens, revoked access tokens
+and refresh tokens which expired before expiredBefore moment. They are
+rejected as expired anyway, so the rows are no longer needed
+*/
+func (r *TokenSQLite) PurgeExpiredTokens(ctx context.Context, expiredBefore time.Time) (int, error) {
+	const op = "repository.sqlite.token_sqlite.PurgeExpiredTokens"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tables := []struct {
+		name  string
+		query string
+	}{
+		{name: usedTable, query: `DELETE FROM used_token WHERE expires_at < ?1`},
+		{name: revokedTable, query: `DELETE FROM revoked_token WHERE expires_at < ?1`},
+		{name: refreshTable, query: `DELETE FROM refresh_token WHERE expires_at < ?1`},
+	}
+
+	purged := 0
+
+	for _, table := range tables {
+		result, err := tx.ExecContext(ctx, table.query, expiredBefore.UTC())
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("%s:%s: %w", op, table.name, err)
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("%s:%s: %w", op, table.name, err)
+		}
+
+		purged += int(count)
+	}
+
+	return purged, tx.Commit()
+}
@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+type txKey struct{}
+
+// querier runs queries, it is either the database or a transaction
+type querier interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (dbTx, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// dbTx is a transaction or a savepoint of one
+type dbTx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Commit() error
+	Rollback() error
+}
+
+/*
+conn returns the transaction ctx is in, the database otherwise. A transaction
+begun on a transaction is a savepoint, so the repositories which change
+several tables keep their own transactions inside an outer one
+*/
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return txConn{Tx: tx}
+	}
+
+	return dbConn{DB: db}
+}
+
+type dbConn struct {
+	*sql.DB
+}
+
+func (c dbConn) BeginTx(ctx context.Context, opts *sql.TxOptions) (dbTx, error) {
+	tx, err := c.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+type txConn struct {
+	*sql.Tx
+}
+
+// savepointSeq makes names of savepoints unique
+var savepointSeq atomic.Int64
+
+func (c txConn) BeginTx(ctx context.Context, opts *sql.TxOptions) (dbTx, error) {
+	name := fmt.Sprintf("sp_%d", savepointSeq.Add(1))
+
+	if _, err := c.Tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+
+	return &savepoint{Tx: c.Tx, name: name}, nil
+}
+
+// savepoint commits and rolls back a part of a transaction
+type savepoint struct {
+	*sql.Tx
+	name string
+}
+
+func (s *savepoint) Commit() error {
+	_, err := s.Tx.Exec("RELEASE " + s.name)
+
+	return err
+}
+
+func (s *savepoint) Rollback() error {
+	if _, err := s.Tx.Exec("ROLLBACK TO " + s.name); err != nil {
+		return err
+	}
+
+	return s.Commit()
+}
+
+type TransactorSQLite struct {
+	db *sql.DB
+}
+
+func NewTransactorSQLite(db *sql.DB) repository.Transactor {
+	return &TransactorSQLite{db: db}
+}
+
+func (r *TransactorSQLite) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "repository.sqlite.WithinTx"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// a savepoint runs in the transaction ctx is in already
+	txCtx := ctx
+	if sqlTx, ok := tx.(*sql.Tx); ok {
+		txCtx = context.WithValue(ctx, txKey{}, sqlTx)
+	}
+
+	if err := fn(txCtx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
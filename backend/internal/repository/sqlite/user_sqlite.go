@@ -0,0 +1,373 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+)
+
+const userSelect = `SELECT 
+						id,
+						COALESCE(user_name, ''),
+						COALESCE(tg_user_name, ''),
+						COALESCE(tg_user_id, 0),
+						COALESCE(first_name, ''),
+						COALESCE(last_name, ''),
+						COALESCE(email, ''),
+						role,
+						deleted_at 
+					FROM 
+						user_account`
+
+type UserSQLite struct {
+	db *sql.DB
+}
+
+func NewUserSQLite(db *sql.DB) repository.User {
+	return &UserSQLite{db: db}
+}
+
+func scanUser(row scanner) (models.GetUser, error) {
+	var user models.GetUser
+
+	err := row.Scan(&user.Id, &user.Username, &user.TgUsername, &user.TgUserId, &user.FirstName,
+		&user.LastName, &user.Email, &user.Role, &user.DeletedAt)
+
+	return user, err
+}
+
+func (r *UserSQLite) CreateUser(ctx context.Context, user models.User) (int, error) {
+	const (
+		op         = "repository.sqlite.CreateUser"
+		userExists = "such user already exists"
+	)
+
+	var id int
+	query := `INSERT INTO 
+						user_account (user_name, tg_user_name, first_name, last_name, email, password_hash, tg_user_id) 
+						VALUES (
+							NULLIF(?1, ''),
+							NULLIF(?2, ''),
+							NULLIF(?3, ''),
+							NULLIF(?4, ''),
+							NULLIF(?5, ''),
+							NULLIF(?6, ''),
+							NULLIF(?7, 0)
+							) 
+					RETURNING id`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, user.Username, user.TgUsername, user.FirstName, user.LastName, user.Email, user.Password, user.TgUserId)
+	if err := row.Scan(&id); err != nil {
+		err = dbErr("user", err)
+		if errors.Is(err, errs.ErrConflict) {
+			return 0, fmt.Errorf("%s: %s: %w", op, userExists, err) // for unique_violation error
+		}
+		return 0, fmt.Errorf("%s: %w", op, err) // for any other errors
+	}
+
+	return id, nil
+}
+
+/*
+GetUserByUsername returns a user along with the password hash.
+The hash is checked by the service, since argon2id hashes can't be
+compared inside a query
+*/
+func (r *UserSQLite) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	const op = "repository.sqlite.GetUserByUsername"
+
+	var user models.User
+	query := `SELECT 
+					id,
+					COALESCE(user_name, ''),
+					COALESCE(tg_user_name, ''),
+					COALESCE(tg_user_id, 0),
+					COALESCE(first_name, ''),
+					COALESCE(last_name, ''),
+					COALESCE(email, ''),
+					COALESCE(password_hash, ''), 
+					role,
+					deleted_at 
+				FROM 
+					user_account 
+				WHERE user_name = ?1`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, username)
+	err := row.Scan(&user.Id, &user.Username, &user.TgUsername, &user.TgUserId, &user.FirstName,
+		&user.LastName, &user.Email, &user.Password, &user.Role, &user.DeletedAt)
+	if err != nil {
+		return user, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+func (r *UserSQLite) GetPasswordHash(ctx context.Context, userId int) (string, error) {
+	const op = "repository.sqlite.GetPasswordHash"
+
+	var passwordHash string
+	query := `SELECT 
+					COALESCE(password_hash, '') 
+				FROM 
+					user_account 
+				WHERE id = ?1 AND deleted_at IS NULL`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userId).Scan(&passwordHash); err != nil {
+		return "", fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return passwordHash, nil
+}
+
+func (r *UserSQLite) UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error {
+	const op = "repository.sqlite.UpdatePasswordHash"
+
+	query := `UPDATE 
+					user_account 
+				SET 
+					password_hash = ?2 
+				WHERE id = ?1`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userId, passwordHash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *UserSQLite) GetAllUsers(ctx context.Context, params models.ListParams) ([]models.GetUser, int, error) {
+	const op = "repository.sqlite.GetAllUsers"
+
+	where, args := listWhere(nil, nil, params.Filters, userColumns)
+
+	total, err := countRows(ctx, r.db, `SELECT count(*) FROM user_account`+where, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, countErr, err)
+	}
+
+	tail, args := listTail(params, userColumns, args)
+
+	rowsUsers, err := conn(ctx, r.db).QueryContext(ctx, userSelect+where+tail, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	users, err := collectRows(rowsUsers, scanUser)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return users, total, nil
+}
+
+func (r *UserSQLite) GetUserById(ctx context.Context, userId int) (models.GetUser, error) {
+	const op = "repository.sqlite.GetUserById"
+
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, userSelect+` WHERE id = ?1`, userId))
+	if err != nil {
+		return user, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail returns an active user with the email
+func (r *UserSQLite) GetUserByEmail(ctx context.Context, email string) (models.GetUser, error) {
+	const op = "repository.sqlite.GetUserByEmail"
+
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, userSelect+` WHERE email = ?1 AND deleted_at IS NULL`, email))
+	if err != nil {
+		return user, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return user, nil
+}
+
+func (r *UserSQLite) SetEmailVerified(ctx context.Context, userId int) error {
+	const op = "repository.sqlite.SetEmailVerified"
+
+	query := `UPDATE 
+					user_account 
+				SET 
+					email_verified_at = ?2 
+				WHERE id = ?1 AND email_verified_at IS NULL`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userId, now()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+/*
+IsEmailVerified reports if a user has confirmed the email.
+Users without an email (telegram users) have nothing to confirm
+*/
+func (r *UserSQLite) IsEmailVerified(ctx context.Context, userId int) (bool, error) {
+	const op = "repository.sqlite.IsEmailVerified"
+
+	var verified bool
+	query := `SELECT 
+					email IS NULL OR email_verified_at IS NOT NULL 
+				FROM 
+					user_account 
+				WHERE id = ?1`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userId).Scan(&verified); err != nil {
+		return false, fmt.Errorf("%s:%s: %w", op, scanErr, err)
+	}
+
+	return verified, nil
+}
+
+func (r *UserSQLite) GetUserByTgUserId(ctx context.Context, tgUserId int64) (models.GetUser, error) {
+	const op = "repository.sqlite.GetUserByTgUserId"
+
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, userSelect+` WHERE tg_user_id = ?1 AND deleted_at IS NULL`, tgUserId))
+	if err != nil {
+		return user, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
+	}
+
+	return user, nil
+}
+
+/*
+ClaimTgUserId binds a telegram id to an account created by the bot before
+the ids were stored, found by the telegram user name. An account that
+already has an id is never changed. A user name can pass to another
+telegram user, so an account with web credentials is never claimed by it,
+such an account is linked by a link code only
+*/
+func (r *UserSQLite) ClaimTgUserId(ctx context.Context, tgUsername string, tgUserId int64) (int, error) {
+	const op = "repository.sqlite.ClaimTgUserId"
+
+	var userId int
+	query := `UPDATE 
+					user_account 
+				SET 
+					tg_user_id = ?2 
+				WHERE tg_user_name = ?1 AND tg_user_id IS NULL AND deleted_at IS NULL 
+					AND user_name IS NULL AND email IS NULL AND password_hash IS NULL 
+				RETURNING id`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, tgUsername, tgUserId).Scan(&userId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
+	}
+
+	return userId, nil
+}
+
+/*
+DeleteUser only marks a user as deleted. The user stays in the table
+during the grace period and can be restored, after that the account
+is removed by PurgeDeletedUsers
+*/
+func (r *UserSQLite) DeleteUser(ctx context.Context, userId int) (int, error) {
+	const op = "repository.sqlite.DeleteUser"
+
+	var checkUserId int
+
+	query := `UPDATE 
+					user_account
+				SET 
+					deleted_at = ?2
+				WHERE id = ?1 AND deleted_at IS NULL
+				RETURNING id`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userId, now()).Scan(&checkUserId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
+	}
+
+	return checkUserId, nil
+}
+
+// RestoreUser restores a user who was deleted after deletedAfter moment
+func (r *UserSQLite) RestoreUser(ctx context.Context, userId int, deletedAfter time.Time) (int, error) {
+	const op = "repository.sqlite.RestoreUser"
+
+	var checkUserId int
+
+	query := `UPDATE 
+					user_account
+				SET 
+					deleted_at = NULL
+				WHERE id = ?1 AND deleted_at IS NOT NULL AND deleted_at > ?2
+				RETURNING id`
+
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userId, deletedAfter.UTC()).Scan(&checkUserId); err != nil {
+		return 0, fmt.Errorf("%s:%s: %w", op, scanErr, dbErr("user", err))
+	}
+
+	return checkUserId, nil
+}
+
+/*
+PurgeDeletedUsers permanently removes users deleted before deletedBefore
+moment. All habits and rewards of the users are removed by cascade
+*/
+func (r *UserSQLite) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]int, error) {
+	const op = "repository.sqlite.PurgeDeletedUsers"
+
+	tx, err := conn(ctx, r.db).BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deletedBefore = deletedBefore.UTC()
+
+	/*
+		habits and trackers are not linked to user_account by foreign keys,
+		so they are deleted before the users, while user_habit still exists
+	*/
+	queryTrackers := `DELETE FROM 
+							habit_tracker 
+						WHERE id IN (SELECT ul.habit_tracker_id 
+										FROM user_habit ul INNER JOIN user_account ua ON ul.user_id = ua.id 
+										WHERE ua.deleted_at < ?1)`
+
+	if _, err := tx.ExecContext(ctx, queryTrackers, deletedBefore); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("%s:%s: %w", op, trackerTable, err)
+	}
+
+	queryHabits := `DELETE FROM 
+							habit 
+						WHERE id IN (SELECT ul.habit_id 
+										FROM user_habit ul INNER JOIN user_account ua ON ul.user_id = ua.id 
+										WHERE ua.deleted_at < ?1)`
+
+	if _, err := tx.ExecContext(ctx, queryHabits, deletedBefore); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("%s:%s: %w", op, habitTable, err)
+	}
+
+	queryUsers := `DELETE FROM
+						user_account
+					WHERE deleted_at < ?1
+					RETURNING id`
+
+	rowsUsers, err := tx.QueryContext(ctx, queryUsers, deletedBefore)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("%s:%s: %w", op, queryErr, err)
+	}
+
+	userIds, err := collectRows(rowsUsers, func(row scanner) (int, error) {
+		var id int
+		err := row.Scan(&id)
+
+		return id, err
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("%s:%s: %w", op, collectErr, err)
+	}
+
+	return userIds, tx.Commit()
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"net"
 	"net/http"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/config"
@@ -13,7 +14,11 @@ type Server struct {
 	httpServer *http.Server
 }
 
-func (s *Server) Run(cfg *config.Config, log *slog.Logger, handler http.Handler) error {
+/*
+Run serves requests until the server is shut down. Contexts of the
+requests are derived from ctx, so cancelling it cancels all of them
+*/
+func (s *Server) Run(ctx context.Context, cfg *config.Config, log *slog.Logger, handler http.Handler) error {
 	s.log = log
 
 	s.httpServer = &http.Server{
@@ -23,6 +28,9 @@ func (s *Server) Run(cfg *config.Config, log *slog.Logger, handler http.Handler)
 		ReadTimeout:    cfg.HTTPServer.Timeout,
 		WriteTimeout:   cfg.HTTPServer.Timeout,
 		IdleTimeout:    cfg.HTTPServer.IdleTimeout,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
 	}
 
 	s.log.Info("backend server started and listening", slog.String("port", cfg.HTTPServer.Port))
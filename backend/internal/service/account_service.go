@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/mailer"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/exp/slog"
+)
+
+// purposes of one-time tokens, a token of one purpose can't be used for another
+const (
+	purposeVerifyEmail   = "verify_email"
+	purposeResetPassword = "reset_password"
+)
+
+var ErrOneTimeTokenInvalid = errors.New("token is invalid, expired or already used")
+
+type AccountService struct {
+	repo      repository.User
+	tokenRepo repository.Token
+	mailer    mailer.Mailer
+	authCfg   config.Auth
+	appURL    string
+}
+
+func NewAccountService(repo repository.User, tokenRepo repository.Token, mailer mailer.Mailer, authCfg config.Auth, appURL string) Account {
+	return &AccountService{
+		repo:      repo,
+		tokenRepo: tokenRepo,
+		mailer:    mailer,
+		authCfg:   authCfg,
+		appURL:    appURL,
+	}
+}
+
+// SendVerification emails a user a link to confirm the email
+func (s *AccountService) SendVerification(ctx context.Context, userId int) error {
+	const op = "service.account_service.SendVerification"
+
+	user, err := s.repo.GetUserById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.Email == "" {
+		return nil
+	}
+
+	token, err := s.newOneTimeToken(purposeVerifyEmail, user.Id, s.authCfg.VerifyTokenTTL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nplease confirm your email by following the link:\r\n%s\r\n",
+			user.Username, s.link("/verify", token)),
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: verification email sent", op), slog.Int("user_id", user.Id))
+
+	return nil
+}
+
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	const op = "service.account_service.VerifyEmail"
+
+	userId, err := s.useOneTimeToken(ctx, purposeVerifyEmail, token)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.repo.SetEmailVerified(ctx, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+/*
+ForgotPassword emails a password reset link. Nothing is reported
+if there is no user with the email, so the endpoint can't be used
+to find out which emails are registered
+*/
+func (s *AccountService) ForgotPassword(ctx context.Context, email string) error {
+	const op = "service.account_service.ForgotPassword"
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+
+	token, err := s.newOneTimeToken(purposeResetPassword, user.Id, s.authCfg.ResetTokenTTL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nto set a new password follow the link:\r\n%s\r\n\r\n"+
+			"The link expires in %s. If you did not ask to reset the password, just ignore this email.\r\n",
+			user.Username, s.link("/reset", token), s.authCfg.ResetTokenTTL),
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: password reset email sent", op), slog.Int("user_id", user.Id))
+
+	return nil
+}
+
+/*
+ResetPassword sets a new password by a reset token. All tokens of
+the user are revoked, so every device has to sign in again
+*/
+func (s *AccountService) ResetPassword(ctx context.Context, input models.ResetPasswordInput) error {
+	const op = "service.account_service.ResetPassword"
+
+	if err := models.ValidatePassword(input.NewPassword); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	userId, err := s.useOneTimeToken(ctx, purposeResetPassword, input.Token)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	passwordHash, err := hashPassword(input.NewPassword)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.repo.UpdatePasswordHash(ctx, userId, passwordHash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// a reset link proves the ownership of the email as well
+	if err := s.repo.SetEmailVerified(ctx, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.tokenRepo.RevokeAllUserTokens(ctx, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *AccountService) IsEmailVerified(ctx context.Context, userId int) (bool, error) {
+	const op = "service.account_service.IsEmailVerified"
+
+	verified, err := s.repo.IsEmailVerified(ctx, userId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return verified, nil
+}
+
+/*
+newOneTimeToken issues a signed token for a link sent by email.
+It is signed with the same keys as access tokens, but has no "data"
+claim, so it can't be used as an access token and vice versa
+*/
+func (s *AccountService) newOneTimeToken(purpose string, userId int, ttl time.Duration) (string, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"jti":     jti,
+		"sub":     strconv.Itoa(userId),
+		"purpose": purpose,
+		"iat":     now.Unix(),
+		"exp":     now.Add(ttl).Unix(),
+	}
+
+	return signToken(s.authCfg, claims)
+}
+
+// useOneTimeToken checks a token and marks it as used, so it can't be used again
+func (s *AccountService) useOneTimeToken(ctx context.Context, purpose, tokenString string) (int, error) {
+	token, err := jwt.Parse(tokenString, signingKeyFunc(s.authCfg))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrOneTimeTokenInvalid, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != purpose {
+		return 0, ErrOneTimeTokenInvalid
+	}
+
+	jti, _ := claims["jti"].(string)
+	sub, _ := claims["sub"].(string)
+	expiresAt, _ := claims["exp"].(float64)
+
+	userId, err := strconv.Atoi(sub)
+	if err != nil || jti == "" {
+		return 0, ErrOneTimeTokenInvalid
+	}
+
+	fresh, err := s.tokenRepo.UseOneTimeToken(ctx, jti, time.Unix(int64(expiresAt), 0))
+	if err != nil {
+		return 0, err
+	}
+
+	if !fresh {
+		return 0, ErrOneTimeTokenInvalid
+	}
+
+	return userId, nil
+}
+
+func (s *AccountService) link(path, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -1,42 +1,109 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
 )
 
 type AdminRewardService struct {
-	repo repository.AdminReward
+	tx    repository.Transactor
+	repo  repository.AdminReward
+	audit repository.Audit
 }
 
-func NewAdminRewardService(repo repository.AdminReward) AdminReward {
-	return &AdminRewardService{repo: repo}
+func NewAdminRewardService(tx repository.Transactor, repo repository.AdminReward, audit repository.Audit) AdminReward {
+	return &AdminRewardService{
+		tx:    tx,
+		repo:  repo,
+		audit: audit,
+	}
 }
 
-func (s *AdminRewardService) Create(reward models.Reward) (int, error) {
-	return s.repo.Create(reward)
+func (s *AdminRewardService) Create(ctx context.Context, meta models.AuditMeta, reward models.Reward) (int, error) {
+	const op = "service.admin_reward_service.Create"
+
+	var id int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		id, err = s.repo.Create(ctx, reward)
+		if err != nil {
+			return err
+		}
+
+		reward.Id = id
+
+		return recordAudit(ctx, s.audit, meta, models.AuditRewardCreate, 0, nil, reward)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
 }
 
-func (s *AdminRewardService) GetById(rewardId int) (models.Reward, error) {
-	return s.repo.GetById(rewardId)
+func (s *AdminRewardService) GetById(ctx context.Context, rewardId int) (models.Reward, error) {
+	return s.repo.GetById(ctx, rewardId)
 }
 
-func (s *AdminRewardService) GetAllRewards() ([]models.Reward, error) {
-	return s.repo.GetAllRewards()
+func (s *AdminRewardService) GetAllRewards(ctx context.Context, params models.ListParams) ([]models.Reward, int, error) {
+	return s.repo.GetAllRewards(ctx, params)
 }
 
-func (s *AdminRewardService) Delete(rewardId int) error {
-	return s.repo.Delete(rewardId)
+func (s *AdminRewardService) Delete(ctx context.Context, meta models.AuditMeta, rewardId int) error {
+	const op = "service.admin_reward_service.Delete"
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetById(ctx, rewardId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.Delete(ctx, rewardId); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.audit, meta, models.AuditRewardDelete, 0, before, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
 }
 
-func (s *AdminRewardService) UpdateReward(rewardId int, input models.UpdateRewardInput) error {
+func (s *AdminRewardService) UpdateReward(ctx context.Context, meta models.AuditMeta, rewardId int, input models.UpdateRewardInput) error {
 	const op = "service.admin_reward_service.UpdateReward"
 
 	if err := input.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, errs.Validation("invalid_reward", err))
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetById(ctx, rewardId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.UpdateReward(ctx, rewardId, input); err != nil {
+			return err
+		}
+
+		after, err := s.repo.GetById(ctx, rewardId)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.audit, meta, models.AuditRewardUpdate, 0, before, after)
+	})
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return s.repo.UpdateReward(rewardId, input)
+	return nil
 }
@@ -1,26 +1,333 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+)
+
+/*
+permissionCacheTTL is how long permissions of roles and roles of users
+are kept in memory. Changes made through this instance are seen at once,
+changes made through other instances within the TTL
+*/
+const permissionCacheTTL = time.Minute
+
+var (
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrUnknownRole       = errors.New("role does not exist")
+	ErrUnknownPermission = errors.New("permission does not exist")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleInUse         = errors.New("role is assigned to users")
+	// ErrBuiltInRole is returned on an attempt to change a role the app relies on
+	ErrBuiltInRole = errors.New("built-in role can't be changed")
 )
 
 type AdminRoleService struct {
-	repo repository.AdminRole
+	tx       repository.Transactor
+	repo     repository.AdminRole
+	userRepo repository.User
+	audit    repository.Audit
+
+	mu          sync.RWMutex
+	permissions map[string]map[string]bool
+	loadedAt    time.Time
+	userRoles   map[int]userRole
 }
 
-func NewAdminRoleService(repo repository.AdminRole) AdminRole {
-	return &AdminRoleService{repo: repo}
+// userRole is a cached role of a user
+type userRole struct {
+	name     string
+	loadedAt time.Time
+}
+
+func NewAdminRoleService(tx repository.Transactor, repo repository.AdminRole, userRepo repository.User, audit repository.Audit) AdminRole {
+	return &AdminRoleService{
+		tx:       tx,
+		repo:     repo,
+		userRepo: userRepo,
+		audit:    audit,
+	}
 }
 
-func (r *AdminRoleService) AssignRole(userId int, role models.UpdateRoleInput) (int, error) {
+func (r *AdminRoleService) AssignRole(ctx context.Context, meta models.AuditMeta, userId int, role models.UpdateRoleInput) (int, error) {
 	const op = "service.admin_role_service.AssignRole"
 
 	if err := role.Validate(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, errs.Validation("invalid_role", err))
+	}
+
+	if _, err := r.repo.GetRole(ctx, *role.Role); err != nil {
+		return 0, fmt.Errorf("%s: %w: %v", op, ErrUnknownRole, err)
+	}
+
+	var id int
+
+	err := r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.userRepo.GetUserById(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		id, err = r.repo.AssignRole(ctx, userId, role)
+		if err != nil {
+			return err
+		}
+
+		after := before
+		after.Role = *role.Role
+
+		return recordAudit(ctx, r.audit, meta, models.AuditRoleAssign, userId, before, after)
+	})
+	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return r.repo.AssignRole(userId, role)
+	r.invalidate()
+
+	return id, nil
+}
+
+func (r *AdminRoleService) GetAllRoles(ctx context.Context) ([]models.Role, error) {
+	const op = "service.admin_role_service.GetAllRoles"
+
+	roles, err := r.repo.GetAllRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, nil
+}
+
+func (r *AdminRoleService) GetAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	const op = "service.admin_role_service.GetAllPermissions"
+
+	permissions, err := r.repo.GetAllPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return permissions, nil
+}
+
+func (r *AdminRoleService) CreateRole(ctx context.Context, meta models.AuditMeta, input models.RoleInput) error {
+	const op = "service.admin_role_service.CreateRole"
+
+	if err := input.Validate(); err != nil {
+		return fmt.Errorf("%s: %w: %v", op, ErrInvalidRole, err)
+	}
+
+	if _, err := r.repo.GetRole(ctx, input.Name); err == nil {
+		return fmt.Errorf("%s: %w", op, ErrRoleExists)
+	}
+
+	role := models.Role{Name: input.Name, Permissions: []string{}}
+	if input.Description != nil {
+		role.Description = *input.Description
+	}
+	if input.Permissions != nil {
+		role.Permissions = *input.Permissions
+	}
+
+	if err := r.checkPermissions(ctx, role.Permissions); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.repo.CreateRole(ctx, role); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, r.audit, meta, models.AuditRoleCreate, 0, nil, role)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.invalidate()
+
+	return nil
+}
+
+/*
+UpdateRole changes the description and the permissions of a role.
+The admin role is not changed, so there is always a role able to
+manage roles
+*/
+func (r *AdminRoleService) UpdateRole(ctx context.Context, meta models.AuditMeta, name string, input models.RoleInput) error {
+	const op = "service.admin_role_service.UpdateRole"
+
+	if name == models.Administrator {
+		return fmt.Errorf("%s: %w", op, ErrBuiltInRole)
+	}
+
+	before, err := r.repo.GetRole(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %v", op, ErrUnknownRole, err)
+	}
+
+	after := before
+	if input.Description != nil {
+		after.Description = *input.Description
+	}
+	if input.Permissions != nil {
+		after.Permissions = *input.Permissions
+	}
+
+	if err := r.checkPermissions(ctx, after.Permissions); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.repo.UpdateRole(ctx, after); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, r.audit, meta, models.AuditRoleUpdate, 0, before, after)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.invalidate()
+
+	return nil
+}
+
+// DeleteRole removes a role no user has. Built-in roles are never removed
+func (r *AdminRoleService) DeleteRole(ctx context.Context, meta models.AuditMeta, name string) error {
+	const op = "service.admin_role_service.DeleteRole"
+
+	if name == models.Administrator || name == models.UserGeneral {
+		return fmt.Errorf("%s: %w", op, ErrBuiltInRole)
+	}
+
+	before, err := r.repo.GetRole(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %v", op, ErrUnknownRole, err)
+	}
+
+	err = r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		deleted, err := r.repo.DeleteRole(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		if !deleted {
+			return ErrRoleInUse
+		}
+
+		return recordAudit(ctx, r.audit, meta, models.AuditRoleDelete, 0, before, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.invalidate()
+
+	return nil
+}
+
+/*
+HasPermission reports if a role has a permission. Permissions of all
+roles are loaded at once and kept for permissionCacheTTL, so the check
+does not go to the database on every request
+*/
+func (r *AdminRoleService) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	const op = "service.admin_role_service.HasPermission"
+
+	r.mu.RLock()
+	fresh := r.permissions != nil && time.Since(r.loadedAt) < permissionCacheTTL
+	allowed := r.permissions[role][permission]
+	r.mu.RUnlock()
+
+	if fresh {
+		return allowed, nil
+	}
+
+	roles, err := r.repo.GetAllRoles(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	permissions := make(map[string]map[string]bool, len(roles))
+	for _, role := range roles {
+		permissions[role.Name] = make(map[string]bool, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions[role.Name][permission] = true
+		}
+	}
+
+	r.mu.Lock()
+	r.permissions = permissions
+	r.loadedAt = time.Now()
+	// roles of users are dropped along, so the cache does not grow
+	r.userRoles = nil
+	r.mu.Unlock()
+
+	return permissions[role][permission], nil
+}
+
+/*
+UserRole returns the current role of a user. The role in a token is the
+one the user had on sign in, so permissions are checked against this one.
+A role is kept for permissionCacheTTL
+*/
+func (r *AdminRoleService) UserRole(ctx context.Context, userId int) (string, error) {
+	const op = "service.admin_role_service.UserRole"
+
+	r.mu.RLock()
+	cached, ok := r.userRoles[userId]
+	r.mu.RUnlock()
+
+	if ok && time.Since(cached.loadedAt) < permissionCacheTTL {
+		return cached.name, nil
+	}
+
+	user, err := r.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.mu.Lock()
+	if r.userRoles == nil {
+		r.userRoles = make(map[int]userRole)
+	}
+	r.userRoles[userId] = userRole{name: user.Role, loadedAt: time.Now()}
+	r.mu.Unlock()
+
+	return user.Role, nil
+}
+
+func (r *AdminRoleService) invalidate() {
+	r.mu.Lock()
+	r.permissions = nil
+	r.userRoles = nil
+	r.mu.Unlock()
+}
+
+func (r *AdminRoleService) checkPermissions(ctx context.Context, permissions []string) error {
+	known, err := r.repo.GetAllPermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(known))
+	for _, permission := range known {
+		names[permission.Name] = true
+	}
+
+	for _, permission := range permissions {
+		if !names[permission] {
+			return fmt.Errorf("%w: %q", ErrUnknownPermission, permission)
+		}
+	}
+
+	return nil
 }
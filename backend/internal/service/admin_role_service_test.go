@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+)
+
+func Test_AdminRoleService_UserRole(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			roles := NewAdminRoleService(repos.Transactor, repos.AdminRole, repos.User, repos.Audit)
+
+			userId := createTestUser(t, repos, "runner")
+
+			role, err := roles.UserRole(ctx, userId)
+			if err != nil || role != models.UserGeneral {
+				t.Fatalf("Expected role %s but got %s: %v", models.UserGeneral, role, err)
+			}
+
+			moderator := "moderator"
+			if _, err := roles.AssignRole(ctx, models.AuditMeta{ActorId: userId}, userId, models.UpdateRoleInput{Role: &moderator}); err != nil {
+				t.Fatalf("failed to assign role: %v", err)
+			}
+
+			// the cached role is dropped by the assignment
+			role, err = roles.UserRole(ctx, userId)
+			if err != nil || role != moderator {
+				t.Errorf("Expected role %s but got %s: %v", moderator, role, err)
+			}
+		})
+	}
+}
@@ -1,35 +1,106 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
 )
 
 type AdminUserRewardService struct {
-	repo repository.AdminUserReward
+	tx    repository.Transactor
+	repo  repository.AdminUserReward
+	audit repository.Audit
 	Reward
 }
 
-func NewAdminUserRewardService(repo repository.AdminUserReward) AdminUserReward {
-	return &AdminUserRewardService{repo: repo}
+func NewAdminUserRewardService(tx repository.Transactor, repo repository.AdminUserReward, audit repository.Audit) AdminUserReward {
+	return &AdminUserRewardService{
+		tx:    tx,
+		repo:  repo,
+		audit: audit,
+	}
 }
 
-func (s *AdminUserRewardService) AssignReward(userId, habitId, rewardId int) (int, error) {
-	return s.repo.AssignReward(userId, habitId, rewardId)
+// userRewardState is the state of a user reward written to the audit log
+type userRewardState struct {
+	UserRewardId int `json:"userRewardId,omitempty"`
+	HabitId      int `json:"habitId"`
+	RewardId     int `json:"rewardId"`
 }
 
-func (s *AdminUserRewardService) RemoveFromUser(userId, habitId, rewardId int) error {
-	return s.repo.RemoveFromUser(userId, habitId, rewardId)
+func (s *AdminUserRewardService) AssignReward(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int) (int, error) {
+	const op = "service.admin_user_reward_service.AssignReward"
+
+	var id int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		id, err = s.repo.AssignReward(ctx, userId, habitId, rewardId)
+		if err != nil {
+			return err
+		}
+
+		after := userRewardState{UserRewardId: id, HabitId: habitId, RewardId: rewardId}
+
+		return recordAudit(ctx, s.audit, meta, models.AuditUserRewardAssign, userId, nil, after)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
 }
 
-func (s *AdminUserRewardService) UpdateUserReward(userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
+func (s *AdminUserRewardService) RemoveFromUser(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int) error {
+	const op = "service.admin_user_reward_service.RemoveFromUser"
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.RemoveFromUser(ctx, userId, habitId, rewardId); err != nil {
+			return err
+		}
+
+		before := userRewardState{HabitId: habitId, RewardId: rewardId}
+
+		return recordAudit(ctx, s.audit, meta, models.AuditUserRewardRemove, userId, before, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *AdminUserRewardService) UpdateUserReward(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
 	const op = "service.admin_user_reward_service.UpdateUserReward"
 
 	if err := input.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, errs.Validation("invalid_user_reward", err))
+	}
+
+	before := userRewardState{HabitId: habitId, RewardId: rewardId}
+
+	after := before
+	if input.HabitId != nil {
+		after.HabitId = *input.HabitId
+	}
+	if input.RewardId != nil {
+		after.RewardId = *input.RewardId
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateUserReward(ctx, userId, habitId, rewardId, input); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.audit, meta, models.AuditUserRewardUpdate, userId, before, after)
+	})
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return s.repo.UpdateUserReward(userId, habitId, rewardId, input)
+	return nil
 }
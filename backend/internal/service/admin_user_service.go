@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/metrics"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+)
+
+/*
+AdminUserService does the same changes of user habits and accounts as
+HabitService, HabitTrackerService and UserService do, but on behalf of
+an admin, so every change is recorded in the audit log
+*/
+type AdminUserService struct {
+	tx          repository.Transactor
+	habitRepo   repository.Habit
+	trackerRepo repository.HabitTracker
+	userRepo    repository.User
+	tokenRepo   repository.Token
+	audit       repository.Audit
+	gracePeriod time.Duration
+}
+
+func NewAdminUserService(tx repository.Transactor, habitRepo repository.Habit, trackerRepo repository.HabitTracker, userRepo repository.User, tokenRepo repository.Token, audit repository.Audit, gracePeriod time.Duration) AdminUser {
+	return &AdminUserService{
+		tx:          tx,
+		habitRepo:   habitRepo,
+		trackerRepo: trackerRepo,
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		audit:       audit,
+		gracePeriod: gracePeriod,
+	}
+}
+
+func (s *AdminUserService) CreateHabit(ctx context.Context, meta models.AuditMeta, userId int, habit models.Habit) (int, error) {
+	const op = "service.admin_user_service.CreateHabit"
+
+	var habitId int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		habitId, err = s.habitRepo.Create(ctx, userId, habit)
+		if err != nil {
+			return err
+		}
+
+		habit.Id = habitId
+
+		return recordAudit(ctx, s.audit, meta, models.AuditHabitCreate, userId, nil, habit)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	metrics.HabitsCreated.Inc()
+
+	return habitId, nil
+}
+
+func (s *AdminUserService) UpdateHabit(ctx context.Context, meta models.AuditMeta, userId, habitId int, input models.UpdateHabitInput) (int, error) {
+	const op = "service.admin_user_service.UpdateHabit"
+
+	if err := input.Validate(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, errs.Validation("invalid_habit", err))
+	}
+
+	var version int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.habitRepo.GetById(ctx, userId, habitId)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.habitRepo.Update(ctx, userId, habitId, input); err != nil {
+			return versionErr(err)
+		}
+
+		after, err := s.habitRepo.GetById(ctx, userId, habitId)
+		if err != nil {
+			return err
+		}
+		version = after.Version
+
+		return recordAudit(ctx, s.audit, meta, models.AuditHabitUpdate, userId, before, after)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, nil
+}
+
+func (s *AdminUserService) DeleteHabit(ctx context.Context, meta models.AuditMeta, userId, habitId int) error {
+	const op = "service.admin_user_service.DeleteHabit"
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.habitRepo.GetById(ctx, userId, habitId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.habitRepo.Delete(ctx, userId, habitId); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.audit, meta, models.AuditHabitDelete, userId, before, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *AdminUserService) UpdateTracker(ctx context.Context, meta models.AuditMeta, userId, habitId int, input models.UpdateTrackerInput) (int, error) {
+	const op = "service.admin_user_service.UpdateTracker"
+
+	if err := input.Validate(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, errs.Validation("invalid_habit_tracker", err))
+	}
+
+	var version int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.trackerRepo.GetById(ctx, userId, habitId)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.trackerRepo.Update(ctx, userId, habitId, input); err != nil {
+			return versionErr(err)
+		}
+
+		after, err := s.trackerRepo.GetById(ctx, userId, habitId)
+		if err != nil {
+			return err
+		}
+		version = after.Version
+
+		return recordAudit(ctx, s.audit, meta, models.AuditTrackerUpdate, userId, before, after)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	metrics.CheckInsRecorded.Inc()
+
+	return version, nil
+}
+
+func (s *AdminUserService) DeleteUser(ctx context.Context, meta models.AuditMeta, userId int) (int, error) {
+	const op = "service.admin_user_service.DeleteUser"
+
+	var deletedUserId int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.userRepo.GetUserById(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		deletedUserId, err = s.userRepo.DeleteUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.tokenRepo.RevokeAllUserTokens(ctx, userId); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.audit, meta, models.AuditUserDelete, userId, before, nil)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deletedUserId, nil
+}
+
+// RestoreUser restores a deleted account if its grace period is not over yet
+func (s *AdminUserService) RestoreUser(ctx context.Context, meta models.AuditMeta, userId int) (int, error) {
+	const op = "service.admin_user_service.RestoreUser"
+
+	var restoredUserId int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.userRepo.GetUserById(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		restoredUserId, err = s.userRepo.RestoreUser(ctx, userId, time.Now().Add(-s.gracePeriod))
+		if err != nil {
+			return fmt.Errorf("account is not deleted or its grace period is over: %w", err)
+		}
+
+		after := before
+		after.DeletedAt = nil
+
+		return recordAudit(ctx, s.audit, meta, models.AuditUserRestore, userId, before, after)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return restoredUserId, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/metrics"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// a change made by an admin counts in the domain metrics as the same change made by the user
+func Test_AdminUserService_metrics(t *testing.T) {
+	ctx := context.Background()
+	meta := models.AuditMeta{ActorId: 1}
+
+	testTable := []struct {
+		name    string
+		counter prometheus.Counter
+		change  func(t *testing.T, admin AdminUser, userId, habitId int) error
+	}{
+		{
+			name:    "Habit Create",
+			counter: metrics.HabitsCreated,
+			change: func(t *testing.T, admin AdminUser, userId, habitId int) error {
+				_, err := admin.CreateHabit(ctx, meta, userId, models.Habit{Title: "reading"})
+				return err
+			},
+		},
+		{
+			name:    "Tracker Update",
+			counter: metrics.CheckInsRecorded,
+			change: func(t *testing.T, admin AdminUser, userId, habitId int) error {
+				unit, counter := "km", 1
+				_, err := admin.UpdateTracker(ctx, meta, userId, habitId, models.UpdateTrackerInput{UnitOfMessure: &unit, Counter: &counter})
+				return err
+			},
+		},
+	}
+
+	for _, testRepository := range testRepositories {
+		for _, testCase := range testTable {
+			t.Run(testRepository.name+" "+testCase.name, func(t *testing.T) {
+				repos := testRepository.new(t)
+
+				userId := createTestUser(t, repos, "runner")
+				habitId, err := repos.Habit.Create(ctx, userId, models.Habit{Title: "running"})
+				if err != nil {
+					t.Fatalf("failed to create habit: %v", err)
+				}
+
+				admin := newTestAdminUserService(repos)
+
+				before := testutil.ToFloat64(testCase.counter)
+
+				if err := testCase.change(t, admin, userId, habitId); err != nil {
+					t.Fatalf("failed to make the change: %v", err)
+				}
+
+				if after := testutil.ToFloat64(testCase.counter); after != before+1 {
+					t.Errorf("Expected the counter to grow by 1 but it went from %v to %v", before, after)
+				}
+			})
+		}
+	}
+}
+
+func newTestAdminUserService(repos *repository.Repository) AdminUser {
+	return NewAdminUserService(repos.Transactor, repos.Habit, repos.HabitTracker, repos.User, repos.Token, repos.Audit, 0)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"golang.org/x/exp/slog"
+)
+
+type AuditService struct {
+	repo repository.Audit
+}
+
+func NewAuditService(repo repository.Audit) Audit {
+	return &AuditService{repo: repo}
+}
+
+func (s *AuditService) GetAll(ctx context.Context, params models.ListParams) ([]models.AuditEntry, int, error) {
+	return s.repo.GetAll(ctx, params)
+}
+
+/*
+recordAudit writes an entry to the audit log. before and after are
+states of the changed object, nil means that the object didn't
+exist before (creation) or doesn't exist after (deletion).
+It is called in the transaction of the change, so a change
+the log can't record is rolled back
+*/
+func recordAudit(ctx context.Context, repo repository.Audit, meta models.AuditMeta, action string, targetUserId int, before, after any) error {
+	const op = "service.audit_service.recordAudit"
+
+	beforeJSON, err := auditState(before)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	afterJSON, err := auditState(after)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = repo.Create(ctx, models.AuditEntry{
+		ActorId:      meta.ActorId,
+		TargetUserId: targetUserId,
+		Action:       action,
+		Before:       beforeJSON,
+		After:        afterJSON,
+		RequestId:    meta.RequestId,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: failed to record action %s: %w", op, action, err)
+	}
+
+	loggs.FromContext(ctx).Info(
+		fmt.Sprintf("%s: audit entry recorded", op),
+		slog.String("action", action),
+		slog.Int("actor_id", meta.ActorId),
+		slog.Int("target_user_id", targetUserId),
+	)
+
+	return nil
+}
+
+func auditState(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(state)
+}
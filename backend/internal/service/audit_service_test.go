@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/migrate"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository/memory"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository/postgres"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository/sqlite"
+	"github.com/aidos-dev/habit-tracker/backend/migrations"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// failingAudit is an audit log which refuses to record anything
+type failingAudit struct {
+	repository.Audit
+}
+
+func (a failingAudit) Create(ctx context.Context, entry models.AuditEntry) (int, error) {
+	return 0, errors.New("audit log is unavailable")
+}
+
+/*
+testRepositories are the repositories the services are tested over.
+The postgres one is skipped unless TEST_POSTGRES_URL is set
+*/
+var testRepositories = []struct {
+	name string
+	new  func(t *testing.T) *repository.Repository
+}{
+	{
+		name: "Memory",
+		new: func(t *testing.T) *repository.Repository {
+			return memory.NewMemoryRepository()
+		},
+	},
+	{
+		name: "SQLite",
+		new: func(t *testing.T) *repository.Repository {
+			cfg := &config.Config{}
+			cfg.DB.Path = filepath.Join(t.TempDir(), "habit-tracker.db")
+
+			db, err := sqlite.NewSQLiteDB(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("failed to open sqlite database: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+
+			return sqlite.NewSQLiteRepository(db)
+		},
+	},
+	{
+		name: "Postgres",
+		new:  newTestPostgresRepository,
+	},
+}
+
+/*
+newTestPostgresRepository migrates a new schema of the database at
+TEST_POSTGRES_URL, so every test starts with empty tables
+*/
+func newTestPostgresRepository(t *testing.T) *repository.Repository {
+	databaseURL := os.Getenv("TEST_POSTGRES_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+
+	admin, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	t.Cleanup(admin.Close)
+
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE") })
+
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("invalid TEST_POSTGRES_URL: %v", err)
+	}
+	poolConfig.ConnConfig.RuntimeParams["search_path"] = schema
+
+	dbpool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	t.Cleanup(dbpool.Close)
+
+	migrator, err := migrate.NewMigrator(slogdiscard.NewDiscardLogger(), dbpool, migrations.FS)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	return postgres.NewPostgresRepository(dbpool)
+}
+
+func createTestUser(t *testing.T, repos *repository.Repository, userName string) int {
+	t.Helper()
+
+	userId, err := repos.User.CreateUser(context.Background(), models.User{
+		Username: userName,
+		Email:    userName + "@habit-tracker.test",
+		Password: "password-hash",
+		Role:     models.UserGeneral,
+	})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return userId
+}
+
+func Test_AuditService_GetAll(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewMemoryRepository()
+
+	meta := models.AuditMeta{ActorId: 5, RequestId: "req-1"}
+	rewards := NewAdminRewardService(repos.Transactor, repos.AdminReward, repos.Audit)
+
+	rewardId, err := rewards.Create(ctx, meta, models.Reward{Title: "gold"})
+	if err != nil {
+		t.Fatalf("failed to create reward: %v", err)
+	}
+
+	title := "silver"
+	if err := rewards.UpdateReward(ctx, models.AuditMeta{ActorId: 6, RequestId: "req-2"}, rewardId, models.UpdateRewardInput{Title: &title}); err != nil {
+		t.Fatalf("failed to update reward: %v", err)
+	}
+
+	audit := NewAuditService(repos.Audit)
+
+	testTable := []struct {
+		name            string
+		params          models.ListParams
+		expectedTotal   int
+		expectedActions []string
+	}{
+		{
+			name:            "All",
+			params:          models.ListParams{Limit: 10},
+			expectedTotal:   2,
+			expectedActions: []string{models.AuditRewardCreate, models.AuditRewardUpdate},
+		},
+		{
+			name:            "Newest First",
+			params:          models.ListParams{Limit: 1, Sort: "id", Desc: true},
+			expectedTotal:   2,
+			expectedActions: []string{models.AuditRewardUpdate},
+		},
+		{
+			name: "By Request",
+			params: models.ListParams{Limit: 10, Filters: []models.Filter{
+				{Field: "request_id", Kind: models.FilterExact, Value: "req-1"},
+			}},
+			expectedTotal:   1,
+			expectedActions: []string{models.AuditRewardCreate},
+		},
+		{
+			name: "By Actor",
+			params: models.ListParams{Limit: 10, Filters: []models.Filter{
+				{Field: "actor_id", Kind: models.FilterInt, Value: "7"},
+			}},
+			expectedTotal:   0,
+			expectedActions: []string{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			entries, total, err := audit.GetAll(ctx, testCase.params)
+			if err != nil {
+				t.Fatalf("failed to get audit log: %v", err)
+			}
+
+			if total != testCase.expectedTotal {
+				t.Errorf("Expected total %d but got %d", testCase.expectedTotal, total)
+			}
+
+			actions := []string{}
+			for _, entry := range entries {
+				actions = append(actions, entry.Action)
+			}
+
+			if len(actions) != len(testCase.expectedActions) {
+				t.Fatalf("Expected actions %v but got %v", testCase.expectedActions, actions)
+			}
+
+			for i := range actions {
+				if actions[i] != testCase.expectedActions[i] {
+					t.Errorf("Expected actions %v but got %v", testCase.expectedActions, actions)
+				}
+			}
+		})
+	}
+
+	entries, _, err := audit.GetAll(ctx, models.ListParams{Limit: 10, Filters: []models.Filter{
+		{Field: "action", Kind: models.FilterExact, Value: models.AuditRewardUpdate},
+	}})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected a single update entry, got %v: %v", entries, err)
+	}
+
+	entry := entries[0]
+	if entry.ActorId != 6 || entry.RequestId != "req-2" || entry.TargetUserId != 0 {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+
+	expectedBefore := `{"rewardId":1,"title":"gold","description":""}`
+	expectedAfter := `{"rewardId":1,"title":"silver","description":""}`
+	if string(entry.Before) != expectedBefore || string(entry.After) != expectedAfter {
+		t.Errorf("Expected states '%s' -> '%s' but got '%s' -> '%s'", expectedBefore, expectedAfter, entry.Before, entry.After)
+	}
+}
+
+/*
+Test_recordAudit_rollsBack checks that a change is not kept when its
+audit entry can't be written
+*/
+func Test_recordAudit_rollsBack(t *testing.T) {
+	ctx := context.Background()
+	meta := models.AuditMeta{ActorId: 1}
+
+	testTable := []struct {
+		name string
+		// change makes a change with an audit log which fails
+		change func(t *testing.T, repos *repository.Repository, audit repository.Audit) error
+		// check fails the test if the change is kept
+		check func(t *testing.T, repos *repository.Repository)
+	}{
+		{
+			name: "Reward Create",
+			change: func(t *testing.T, repos *repository.Repository, audit repository.Audit) error {
+				_, err := NewAdminRewardService(repos.Transactor, repos.AdminReward, audit).Create(ctx, meta, models.Reward{Title: "gold"})
+				return err
+			},
+			check: func(t *testing.T, repos *repository.Repository) {
+				if _, total, _ := repos.AdminReward.GetAllRewards(ctx, models.ListParams{Limit: 10}); total != 0 {
+					t.Errorf("Expected no rewards but got %d", total)
+				}
+			},
+		},
+		{
+			name: "Role Assign",
+			change: func(t *testing.T, repos *repository.Repository, audit repository.Audit) error {
+				userId := createTestUser(t, repos, "runner")
+				role := "moderator"
+				_, err := NewAdminRoleService(repos.Transactor, repos.AdminRole, repos.User, audit).AssignRole(ctx, meta, userId, models.UpdateRoleInput{Role: &role})
+				return err
+			},
+			check: func(t *testing.T, repos *repository.Repository) {
+				user, err := repos.User.GetUserByUsername(ctx, "runner")
+				if err != nil || user.Role != models.UserGeneral {
+					t.Errorf("Expected role %s but got %s: %v", models.UserGeneral, user.Role, err)
+				}
+			},
+		},
+		{
+			name: "Habit Delete",
+			change: func(t *testing.T, repos *repository.Repository, audit repository.Audit) error {
+				userId := createTestUser(t, repos, "runner")
+				habitId, err := repos.Habit.Create(ctx, userId, models.Habit{Title: "running"})
+				if err != nil {
+					t.Fatalf("failed to create habit: %v", err)
+				}
+				return NewAdminUserService(repos.Transactor, repos.Habit, repos.HabitTracker, repos.User, repos.Token, audit, 0).DeleteHabit(ctx, meta, userId, habitId)
+			},
+			check: func(t *testing.T, repos *repository.Repository) {
+				user, _ := repos.User.GetUserByUsername(ctx, "runner")
+				if _, total, _ := repos.Habit.GetAll(ctx, user.Id, models.ListParams{Limit: 10}); total != 1 {
+					t.Errorf("Expected the habit to be kept, got %d habits", total)
+				}
+			},
+		},
+		{
+			name: "User Purge",
+			change: func(t *testing.T, repos *repository.Repository, audit repository.Audit) error {
+				userId := createTestUser(t, repos, "runner")
+				if _, err := repos.User.DeleteUser(ctx, userId); err != nil {
+					t.Fatalf("failed to delete user: %v", err)
+				}
+				_, err := NewUserService(repos.Transactor, repos.User, repos.Token, audit, 0).PurgeDeletedUsers(ctx)
+				return err
+			},
+			check: func(t *testing.T, repos *repository.Repository) {
+				if _, err := repos.User.GetUserByUsername(ctx, "runner"); err != nil {
+					t.Errorf("Expected the deleted user to be kept until the purge is recorded: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, testRepository := range testRepositories {
+		for _, testCase := range testTable {
+			t.Run(testRepository.name+" "+testCase.name, func(t *testing.T) {
+				repos := testRepository.new(t)
+
+				if err := testCase.change(t, repos, failingAudit{Audit: repos.Audit}); err == nil {
+					t.Fatalf("Expected an error when the audit log fails")
+				}
+
+				testCase.check(t, repos)
+
+				if _, total, _ := repos.Audit.GetAll(ctx, models.ListParams{Limit: 10}); total != 0 {
+					t.Errorf("Expected no audit entries but got %d", total)
+				}
+			})
+		}
+	}
+}
@@ -1,19 +1,62 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
 )
 
-func (s *AuthService) FindTgUser(tgUsername string) (models.GetUser, error) {
+func (s *AuthService) FindTgUser(ctx context.Context, tgUserId int64) (models.GetUser, error) {
 	const op = "service.auth_TG_service.FindTgUser"
 
 	var user models.GetUser
-	user, err := s.repo.GetUserByTgUsername(tgUsername)
+	user, err := s.repo.GetUserByTgUserId(ctx, tgUserId)
 	if err != nil {
 		return user, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return user, err
 }
+
+/*
+SignUpTelegram returns the account of a telegram user and creates one
+on the first start of the bot. Accounts created before telegram ids were
+stored are found by the user name once and get the id bound to them
+*/
+func (s *AuthService) SignUpTelegram(ctx context.Context, user models.User) (int, error) {
+	const op = "service.auth_TG_service.SignUpTelegram"
+
+	existing, err := s.repo.GetUserByTgUserId(ctx, user.TgUserId)
+	if err == nil {
+		return existing.Id, nil
+	}
+	// an account is created only if there is none, not if it can't be read
+	if !errors.Is(err, errs.ErrNotFound) {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.TgUsername != "" {
+		userId, err := s.repo.ClaimTgUserId(ctx, user.TgUsername, user.TgUserId)
+		if err == nil {
+			return userId, nil
+		}
+		if !errors.Is(err, errs.ErrNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	userId, err := s.repo.CreateUser(ctx, user)
+	if err != nil && user.TgUsername != "" {
+		// the user name can still belong to the account of its previous owner
+		user.TgUsername = ""
+		userId, err = s.repo.CreateUser(ctx, user)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userId, nil
+}
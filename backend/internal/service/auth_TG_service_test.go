@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+)
+
+func Test_AuthService_SignUpTelegram(t *testing.T) {
+	ctx := context.Background()
+
+	const tgUserId = 1001
+
+	testTable := []struct {
+		name string
+		// prepare creates an account and returns its id
+		prepare func(t *testing.T, repos *repository.Repository) int
+		// expectedClaim is set if the telegram user is expected to get the prepared account
+		expectedClaim bool
+	}{
+		{
+			name: "Known Telegram Id",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				userId, err := repos.User.CreateUser(ctx, models.User{TgUsername: "tg_runner", TgUserId: tgUserId})
+				if err != nil {
+					t.Fatalf("failed to create user: %v", err)
+				}
+				return userId
+			},
+			expectedClaim: true,
+		},
+		{
+			name: "Legacy Bot Account",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				userId, err := repos.User.CreateUser(ctx, models.User{TgUsername: "tg_runner"})
+				if err != nil {
+					t.Fatalf("failed to create user: %v", err)
+				}
+				return userId
+			},
+			expectedClaim: true,
+		},
+		{
+			name: "Web Account With The User Name",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				userId, err := repos.User.CreateUser(ctx, models.User{
+					Username:   "runner",
+					TgUsername: "tg_runner",
+					Email:      "runner@habit-tracker.test",
+					Password:   "password-hash",
+				})
+				if err != nil {
+					t.Fatalf("failed to create user: %v", err)
+				}
+				return userId
+			},
+		},
+		{
+			name: "Deleted Legacy Bot Account",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				userId, err := repos.User.CreateUser(ctx, models.User{TgUsername: "tg_runner"})
+				if err != nil {
+					t.Fatalf("failed to create user: %v", err)
+				}
+				if _, err := repos.User.DeleteUser(ctx, userId); err != nil {
+					t.Fatalf("failed to delete user: %v", err)
+				}
+				return userId
+			},
+		},
+	}
+
+	for _, testRepository := range testRepositories {
+		for _, testCase := range testTable {
+			t.Run(testRepository.name+" "+testCase.name, func(t *testing.T) {
+				repos := testRepository.new(t)
+				auth := newTestAuthService(repos)
+
+				preparedUserId := testCase.prepare(t, repos)
+
+				userId, err := auth.SignUpTelegram(ctx, models.User{TgUsername: "tg_runner", TgUserId: tgUserId})
+				if err != nil {
+					t.Fatalf("failed to sign up: %v", err)
+				}
+
+				if testCase.expectedClaim != (userId == preparedUserId) {
+					t.Fatalf("Expected the prepared account %d to be claimed: %t, but got account %d", preparedUserId, testCase.expectedClaim, userId)
+				}
+
+				user, err := repos.User.GetUserByTgUserId(ctx, tgUserId)
+				if err != nil || user.Id != userId {
+					t.Errorf("Expected the telegram id to belong to account %d but got %d: %v", userId, user.Id, err)
+				}
+
+				if !testCase.expectedClaim {
+					prepared, err := repos.User.GetUserById(ctx, preparedUserId)
+					if err != nil || prepared.TgUserId != 0 {
+						t.Errorf("Expected the prepared account to keep no telegram id, got %+v: %v", prepared, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// failingTgLookup is a user repository which can't read users by the telegram id
+type failingTgLookup struct {
+	repository.User
+}
+
+func (r failingTgLookup) GetUserByTgUserId(ctx context.Context, tgUserId int64) (models.GetUser, error) {
+	return models.GetUser{}, errors.New("users are unavailable")
+}
+
+// Test_AuthService_SignUpTelegram_lookupFailure checks that no account is created when the lookup fails
+func Test_AuthService_SignUpTelegram_lookupFailure(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			auth := NewAuthService(repos.Transactor, failingTgLookup{User: repos.User}, repos.Token, repos.Audit, time.Hour, testAuthConfig)
+
+			if _, err := auth.SignUpTelegram(ctx, models.User{TgUsername: "tg_runner", TgUserId: 1001}); err == nil {
+				t.Fatalf("Expected an error when the lookup fails")
+			}
+
+			if _, err := repos.User.GetUserByTgUserId(ctx, 1001); !errors.Is(err, errs.ErrNotFound) {
+				t.Errorf("Expected no account to be created but got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	// ErrRefreshTokenReused means a rotated token was used again, so it might be stolen
+	ErrRefreshTokenReused = errors.New("refresh token is reused")
+)
+
+/*
+Refresh exchanges a refresh token for a new pair of tokens. The used
+refresh token is revoked. If a revoked token comes again, the whole
+family is revoked, so both the owner and a possible thief have to sign in
+*/
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (models.Tokens, error) {
+	const op = "service.auth_token_service.Refresh"
+
+	var tokens models.Tokens
+
+	used, err := s.tokenRepo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w: %v", op, ErrRefreshTokenInvalid, err)
+	}
+
+	if used.RevokedAt != nil {
+		if err := s.tokenRepo.RevokeTokenFamily(ctx, used.UserId, used.FamilyId); err != nil {
+			return tokens, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return tokens, fmt.Errorf("%s: %w", op, ErrRefreshTokenReused)
+	}
+
+	if time.Now().After(used.ExpiresAt) {
+		return tokens, fmt.Errorf("%s: %w", op, ErrRefreshTokenInvalid)
+	}
+
+	user, err := s.repo.GetUserById(ctx, used.UserId)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.DeletedAt != nil {
+		return tokens, fmt.Errorf("%s: %w", op, ErrAccountDeleted)
+	}
+
+	rawToken, next, err := s.newRefreshToken(user.Id, used.FamilyId)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := s.tokenRepo.RotateRefreshToken(ctx, used.Id, next); err != nil {
+		return tokens, fmt.Errorf("%s: %w: %v", op, ErrRefreshTokenInvalid, err)
+	}
+
+	accessToken, err := s.newAccessToken(user.Id, user.Role)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tokens.AccessToken = accessToken
+	tokens.RefreshToken = rawToken
+
+	return tokens, nil
+}
+
+/*
+Logout revokes the access token of the current request and the family
+of the refresh token, so only the current device is logged out.
+The refresh token is optional
+*/
+func (s *AuthService) Logout(ctx context.Context, userId int, accessToken, refreshToken string) error {
+	const op = "service.auth_token_service.Logout"
+
+	claims, err := s.parseClaims(accessToken)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.tokenRepo.RevokeAccessToken(ctx, claims.jti, userId, claims.expiresAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.tokenRepo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %v", op, ErrRefreshTokenInvalid, err)
+	}
+
+	if err := s.tokenRepo.RevokeTokenFamily(ctx, userId, stored.FamilyId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// LogoutAll revokes all access and refresh tokens of a user
+func (s *AuthService) LogoutAll(ctx context.Context, userId int) error {
+	const op = "service.auth_token_service.LogoutAll"
+
+	if err := s.tokenRepo.RevokeAllUserTokens(ctx, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+/*
+PurgeExpiredTokens removes expired tokens kept to reject their reuse and
+returns the number of removed tokens. Expired tokens are rejected anyway
+*/
+func (s *AuthService) PurgeExpiredTokens(ctx context.Context) (int, error) {
+	const op = "service.auth_token_service.PurgeExpiredTokens"
+
+	purged, err := s.tokenRepo.PurgeExpiredTokens(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return purged, nil
+}
+
+/*
+tokenIssuer issues access and refresh tokens. It is shared by all
+the ways to sign in, so they all end up with the same tokens
+*/
+type tokenIssuer struct {
+	tokenRepo repository.Token
+	cfg       config.Auth
+}
+
+// newTokens issues an access token and starts a new refresh token family
+func (s *tokenIssuer) newTokens(ctx context.Context, userId int, userRole string) (models.Tokens, error) {
+	var tokens models.Tokens
+
+	familyId, err := randomHex(16)
+	if err != nil {
+		return tokens, err
+	}
+
+	rawToken, refreshToken, err := s.newRefreshToken(userId, familyId)
+	if err != nil {
+		return tokens, err
+	}
+
+	if _, err := s.tokenRepo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return tokens, err
+	}
+
+	accessToken, err := s.newAccessToken(userId, userRole)
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.AccessToken = accessToken
+	tokens.RefreshToken = rawToken
+
+	return tokens, nil
+}
+
+/*
+newRefreshToken returns a raw token for the client and its record
+to be stored. Only the hash of the token is kept in the database
+*/
+func (s *tokenIssuer) newRefreshToken(userId int, familyId string) (string, models.RefreshToken, error) {
+	rawToken, err := randomHex(32)
+	if err != nil {
+		return "", models.RefreshToken{}, err
+	}
+
+	token := models.RefreshToken{
+		UserId:    userId,
+		TokenHash: hashToken(rawToken),
+		FamilyId:  familyId,
+		ExpiresAt: time.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+
+	return rawToken, token, nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+)
+
+func Test_AuthService_PurgeExpiredTokens(t *testing.T) {
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			auth := newTestAuthService(repos)
+
+			userId := createTestUser(t, repos, "runner")
+
+			for jti, expiresAt := range map[string]time.Time{"used-expired": past, "used-live": future} {
+				if _, err := repos.Token.UseOneTimeToken(ctx, jti, expiresAt); err != nil {
+					t.Fatalf("failed to use one-time token: %v", err)
+				}
+			}
+
+			for jti, expiresAt := range map[string]time.Time{"revoked-expired": past, "revoked-live": future} {
+				if err := repos.Token.RevokeAccessToken(ctx, jti, userId, expiresAt); err != nil {
+					t.Fatalf("failed to revoke access token: %v", err)
+				}
+			}
+
+			for hash, expiresAt := range map[string]time.Time{"refresh-expired": past, "refresh-live": future} {
+				token := models.RefreshToken{UserId: userId, TokenHash: hash, FamilyId: hash, ExpiresAt: expiresAt}
+				if _, err := repos.Token.CreateRefreshToken(ctx, token); err != nil {
+					t.Fatalf("failed to create refresh token: %v", err)
+				}
+			}
+
+			purged, err := auth.PurgeExpiredTokens(ctx)
+			if err != nil {
+				t.Fatalf("failed to purge tokens: %v", err)
+			}
+
+			if purged != 3 {
+				t.Errorf("Expected 3 purged tokens but got %d", purged)
+			}
+
+			// a used token which is not expired yet still can't be used again
+			if fresh, _ := repos.Token.UseOneTimeToken(ctx, "used-live", future); fresh {
+				t.Errorf("Expected the live one-time token to be kept")
+			}
+
+			if fresh, _ := repos.Token.UseOneTimeToken(ctx, "used-expired", future); !fresh {
+				t.Errorf("Expected the expired one-time token to be purged")
+			}
+
+			if revoked, _ := repos.Token.IsAccessTokenRevoked(ctx, "revoked-live", userId, time.Now()); !revoked {
+				t.Errorf("Expected the live revoked token to be kept")
+			}
+
+			if revoked, _ := repos.Token.IsAccessTokenRevoked(ctx, "revoked-expired", userId, time.Now()); revoked {
+				t.Errorf("Expected the expired revoked token to be purged")
+			}
+
+			if _, err := repos.Token.GetRefreshToken(ctx, "refresh-live"); err != nil {
+				t.Errorf("Expected the live refresh token to be kept: %v", err)
+			}
+
+			if _, err := repos.Token.GetRefreshToken(ctx, "refresh-expired"); err == nil {
+				t.Errorf("Expected the expired refresh token to be purged")
+			}
+		})
+	}
+}
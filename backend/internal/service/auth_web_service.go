@@ -1,88 +1,330 @@
 package service
 
 import (
-	"crypto/sha1"
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/golang-jwt/jwt"
+	"golang.org/x/exp/slog"
 )
 
-const (
-	salt       = "lk6vm9vkf47#b@7kdn4nv"
-	signingKey = "436k@5*6lklj4t6^k4$4#(*&$"
-	tokenTTL   = 12 * time.Hour
+var (
+	// ErrAccountDeleted is returned on sign in to an account deleted by its owner
+	ErrAccountDeleted = errors.New("account is deleted")
+	ErrTokenRevoked   = errors.New("token is revoked")
+	// ErrInvalidCredentials is returned when a user name or a password is wrong
+	ErrInvalidCredentials = errors.New("invalid user name or password")
 )
 
 type AuthService struct {
-	repo repository.User
+	tokenIssuer
+	tx          repository.Transactor
+	repo        repository.User
+	audit       repository.Audit
+	gracePeriod time.Duration
 }
 
-func NewAuthService(repo repository.User) Authorization {
-	return &AuthService{repo: repo}
+func NewAuthService(tx repository.Transactor, repo repository.User, tokenRepo repository.Token, audit repository.Audit, gracePeriod time.Duration, cfg config.Auth) Authorization {
+	return &AuthService{
+		tokenIssuer: tokenIssuer{tokenRepo: tokenRepo, cfg: cfg},
+		tx:          tx,
+		repo:        repo,
+		audit:       audit,
+		gracePeriod: gracePeriod,
+	}
 }
 
-func (s *AuthService) GenerateToken(username, password string) (string, error) {
+func (s *AuthService) GenerateToken(ctx context.Context, username, password string) (models.Tokens, error) {
 	const op = "service.auth_web_service.GenerateToken"
 
-	user, err := s.repo.GetUser(username, generatePasswordHash(password))
+	var tokens models.Tokens
+
+	user, err := s.authenticate(ctx, username, password)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		return tokens, fmt.Errorf("%s: %w", op, err)
 	}
 
-	claims := &jwt.MapClaims{
-		"iss":       "issuer",
-		"issuedAt":  time.Now().Unix(),
-		"expiresAt": time.Now().Add(tokenTTL).Unix(),
-		"data": map[string]any{
-			"userId":   user.Id,
-			"userRole": user.Role,
-		},
+	if user.DeletedAt != nil {
+		return tokens, fmt.Errorf("%s: %w", op, ErrAccountDeleted)
 	}
 
-	token := jwt.NewWithClaims(
-		jwt.SigningMethodHS256,
-		claims,
-	)
+	tokens, err = s.newTokens(ctx, user.Id, user.Role)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+/*
+Reactivate restores an account deleted by its owner and signs the owner in.
+It works only until the grace period is over, after that the account
+is purged. The owner is recorded in the audit log as the actor
+*/
+func (s *AuthService) Reactivate(ctx context.Context, username, password string) (models.Tokens, error) {
+	const op = "service.auth_web_service.Reactivate"
+
+	var tokens models.Tokens
 
-	tokenString, err := token.SignedString([]byte(signingKey))
+	user, err := s.authenticate(ctx, username, password)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		return tokens, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return tokenString, err
+	if user.DeletedAt != nil {
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			before, err := s.repo.GetUserById(ctx, user.Id)
+			if err != nil {
+				return err
+			}
+
+			if _, err := s.repo.RestoreUser(ctx, user.Id, time.Now().Add(-s.gracePeriod)); err != nil {
+				return fmt.Errorf("grace period is over: %w", err)
+			}
+
+			after := before
+			after.DeletedAt = nil
+
+			meta := models.AuditMeta{ActorId: user.Id, RequestId: loggs.RequestId(ctx)}
+
+			return recordAudit(ctx, s.audit, meta, models.AuditUserRestore, user.Id, before, after)
+		})
+		if err != nil {
+			return tokens, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	tokens, err = s.newTokens(ctx, user.Id, user.Role)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
 }
 
-func (s *AuthService) ParseToken(accessToken string) (int, string, error) {
-	const op = "service.auth_web_service.ParseToken"
+/*
+authenticate checks the credentials of a user. A legacy SHA-1 hash
+is replaced with an argon2id one once the password is known to be right.
+The password of an unknown user is hashed too, so the time of the
+answer does not tell if a user name exists
+*/
+func (s *AuthService) authenticate(ctx context.Context, username, password string) (models.User, error) {
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		verifyPassword(password, dummyPasswordHash())
 
-	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+		return user, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	match, needsRehash, err := verifyPassword(password, user.Password)
+	if err != nil {
+		return user, err
+	}
+
+	if !match {
+		return user, ErrInvalidCredentials
+	}
+
+	if needsRehash {
+		if passwordHash, err := hashPassword(password); err == nil {
+			// if the upgrade fails, it is done on the next sign in
+			if err := s.repo.UpdatePasswordHash(ctx, user.Id, passwordHash); err != nil {
+				loggs.FromContext(ctx).Warn("service.auth_web_service.authenticate: failed to upgrade the password hash", slog.Int("user_id", user.Id), sl.Err(err))
+			}
+		}
+	}
+
+	return user, nil
+}
+
+/*
+ChangePassword sets a new password if the old one is right.
+All tokens of the user are revoked, so every device has to sign in again
+*/
+func (s *AuthService) ChangePassword(ctx context.Context, userId int, input models.ChangePasswordInput) error {
+	const op = "service.auth_web_service.ChangePassword"
+
+	if err := input.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, errs.Validation("invalid_password", err))
+	}
+
+	oldHash, err := s.repo.GetPasswordHash(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	match, _, err := verifyPassword(input.OldPassword, oldHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !match {
+		return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	newHash, err := hashPassword(input.NewPassword)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// the password is not changed if the sessions made with the old one stay valid
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdatePasswordHash(ctx, userId, newHash); err != nil {
+			return err
 		}
 
-		return []byte(signingKey), nil
+		return s.tokenRepo.RevokeAllUserTokens(ctx, userId)
 	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+/*
+ParseToken checks the signature of an access token with the key
+named in its "kid" header and makes sure the token is not revoked
+and its owner is not deleted
+*/
+func (s *AuthService) ParseToken(ctx context.Context, accessToken string) (int, string, error) {
+	const op = "service.auth_web_service.ParseToken"
+
+	claims, err := s.parseClaims(accessToken)
+	if err != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(ctx, claims.jti, claims.userId, claims.issuedAt)
+	if err != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if revoked {
+		return 0, "", fmt.Errorf("%s: %w", op, ErrTokenRevoked)
+	}
+
+	user, err := s.repo.GetUserById(ctx, claims.userId)
 	if err != nil {
 		return 0, "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
+	if user.DeletedAt != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, ErrAccountDeleted)
+	}
+
+	return claims.userId, claims.userRole, nil
+}
+
+// accessClaims are the claims of an access token the service relies on
+type accessClaims struct {
+	jti       string
+	userId    int
+	userRole  string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+func (s *tokenIssuer) newAccessToken(userId int, userRole string) (string, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
 
-	data := claims["data"].(map[string]any)
+	now := time.Now()
 
-	userIdfloat := data["userId"].(float64) // we can  convert interface only to float64
-	userId := int(userIdfloat)              // after that it is easy to convert float64 to int
-	userRole := data["userRole"].(string)
+	claims := jwt.MapClaims{
+		"iss": "issuer",
+		"jti": jti,
+		"iat": now.Unix(),
+		// iat has whole seconds, revocation is checked against the millisecond
+		"iat_ms": now.UnixMilli(),
+		"exp":    now.Add(s.cfg.AccessTokenTTL).Unix(),
+		"data": map[string]any{
+			"userId":   userId,
+			"userRole": userRole,
+		},
+	}
 
-	return userId, userRole, err
+	return signToken(s.cfg, claims)
 }
 
-func generatePasswordHash(password string) string {
-	hash := sha1.New()
-	hash.Write([]byte(password))
+func (s *tokenIssuer) parseClaims(accessToken string) (accessClaims, error) {
+	var claims accessClaims
 
-	return fmt.Sprintf("%x", hash.Sum([]byte(salt)))
+	token, err := jwt.Parse(accessToken, signingKeyFunc(s.cfg))
+	if err != nil {
+		return claims, err
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return claims, errors.New("invalid token claims")
+	}
+
+	data, ok := mapClaims["data"].(map[string]any)
+	if !ok {
+		return claims, errors.New("invalid token claims")
+	}
+
+	userIdfloat, _ := data["userId"].(float64) // we can  convert interface only to float64
+	issuedAt, _ := mapClaims["iat"].(float64)
+	issuedAtMs, _ := mapClaims["iat_ms"].(float64)
+	expiresAt, _ := mapClaims["exp"].(float64)
+
+	claims.jti, _ = mapClaims["jti"].(string)
+	claims.userId = int(userIdfloat)
+	claims.userRole, _ = data["userRole"].(string)
+	claims.issuedAt = time.Unix(int64(issuedAt), 0)
+	if issuedAtMs > 0 {
+		claims.issuedAt = time.UnixMilli(int64(issuedAtMs))
+	}
+	claims.expiresAt = time.Unix(int64(expiresAt), 0)
+
+	if claims.jti == "" || claims.userId == 0 {
+		return claims, errors.New("invalid token claims")
+	}
+
+	return claims, nil
+}
+
+// signToken signs claims with the active key and puts its id to the "kid" header
+func signToken(cfg config.Auth, claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(
+		jwt.SigningMethodHS256,
+		claims,
+	)
+	token.Header["kid"] = cfg.ActiveKeyId
+
+	return token.SignedString([]byte(cfg.SigningKeys[cfg.ActiveKeyId]))
+}
+
+/*
+signingKeyFunc finds the key a token was signed with by its "kid" header.
+Keys that were rotated out but are still in the config keep
+validating tokens issued before the rotation
+*/
+func signingKeyFunc(cfg config.Auth) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := cfg.SigningKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+
+		return []byte(key), nil
+	}
 }
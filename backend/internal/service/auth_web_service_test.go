@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+var testAuthConfig = config.Auth{
+	AccessTokenTTL:  15 * time.Minute,
+	RefreshTokenTTL: time.Hour,
+	SigningKeys:     map[string]string{"k1": "secret"},
+	ActiveKeyId:     "k1",
+}
+
+func newTestAuthService(repos *repository.Repository) *AuthService {
+	return NewAuthService(repos.Transactor, repos.User, repos.Token, repos.Audit, time.Hour, testAuthConfig).(*AuthService)
+}
+
+// signUpTestUser creates a user with a password and signs it in
+func signUpTestUser(t *testing.T, repos *repository.Repository, auth *AuthService) (int, models.Tokens) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	users := NewUserService(repos.Transactor, repos.User, repos.Token, repos.Audit, time.Hour)
+
+	userId, err := users.CreateUser(ctx, models.User{
+		Username: "runner",
+		Email:    "runner@habit-tracker.test",
+		Password: "qwerty123",
+		Role:     models.UserGeneral,
+	})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	tokens, err := auth.GenerateToken(ctx, "runner", "qwerty123")
+	if err != nil {
+		t.Fatalf("failed to sign in: %v", err)
+	}
+
+	return userId, tokens
+}
+
+func Test_UserService_DeleteUser_revokesTokens(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			auth := newTestAuthService(repos)
+
+			userId, tokens := signUpTestUser(t, repos, auth)
+
+			users := NewUserService(repos.Transactor, repos.User, repos.Token, repos.Audit, time.Hour)
+			if _, err := users.DeleteUser(ctx, userId); err != nil {
+				t.Fatalf("failed to delete user: %v", err)
+			}
+
+			if _, _, err := auth.ParseToken(ctx, tokens.AccessToken); err == nil {
+				t.Errorf("Expected the access token of a deleted account to be rejected")
+			}
+
+			if _, err := auth.Refresh(ctx, tokens.RefreshToken); err == nil {
+				t.Errorf("Expected the refresh token of a deleted account to be rejected")
+			}
+		})
+	}
+}
+
+func Test_AuthService_ParseToken_deletedAccount(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			auth := newTestAuthService(repos)
+
+			userId, tokens := signUpTestUser(t, repos, auth)
+
+			// the account is deleted without revoking its tokens
+			if _, err := repos.User.DeleteUser(ctx, userId); err != nil {
+				t.Fatalf("failed to delete user: %v", err)
+			}
+
+			if _, _, err := auth.ParseToken(ctx, tokens.AccessToken); !errors.Is(err, ErrAccountDeleted) {
+				t.Errorf("Expected error %v but got %v", ErrAccountDeleted, err)
+			}
+
+			if _, err := auth.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrAccountDeleted) {
+				t.Errorf("Expected error %v but got %v", ErrAccountDeleted, err)
+			}
+		})
+	}
+}
+
+func Test_AuthService_Reactivate(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			auth := newTestAuthService(repos)
+
+			userId, _ := signUpTestUser(t, repos, auth)
+
+			if _, err := repos.User.DeleteUser(ctx, userId); err != nil {
+				t.Fatalf("failed to delete user: %v", err)
+			}
+
+			if _, err := auth.Reactivate(ctx, "runner", "qwerty123"); err != nil {
+				t.Fatalf("failed to reactivate: %v", err)
+			}
+
+			user, err := repos.User.GetUserById(ctx, userId)
+			if err != nil || user.DeletedAt != nil {
+				t.Errorf("Expected the account to be restored: %+v: %v", user, err)
+			}
+
+			entries, total, err := repos.Audit.GetAll(ctx, models.ListParams{Limit: 10})
+			if err != nil || total != 1 {
+				t.Fatalf("Expected a single audit entry but got %d: %v", total, err)
+			}
+
+			entry := entries[0]
+			if entry.Action != models.AuditUserRestore || entry.ActorId != userId || entry.TargetUserId != userId {
+				t.Errorf("unexpected audit entry: %+v", entry)
+			}
+		})
+	}
+}
+
+// Test_AuthService_Reactivate_rollsBack checks that an account stays deleted when its restore can't be recorded
+func Test_AuthService_Reactivate_rollsBack(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+
+			userId, _ := signUpTestUser(t, repos, newTestAuthService(repos))
+
+			if _, err := repos.User.DeleteUser(ctx, userId); err != nil {
+				t.Fatalf("failed to delete user: %v", err)
+			}
+
+			auth := NewAuthService(repos.Transactor, repos.User, repos.Token, failingAudit{Audit: repos.Audit}, time.Hour, testAuthConfig)
+			if _, err := auth.Reactivate(ctx, "runner", "qwerty123"); err == nil {
+				t.Fatalf("Expected an error when the audit log fails")
+			}
+
+			user, err := repos.User.GetUserById(ctx, userId)
+			if err != nil || user.DeletedAt == nil {
+				t.Errorf("Expected the account to stay deleted: %+v: %v", user, err)
+			}
+		})
+	}
+}
+
+// Test_AuthService_ParseToken_afterRevokeAll checks that a token issued right after the revocation is valid
+func Test_AuthService_ParseToken_afterRevokeAll(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			auth := newTestAuthService(repos)
+
+			userId, revokedTokens := signUpTestUser(t, repos, auth)
+
+			// tokens issued in the millisecond of the revocation stay valid
+			time.Sleep(2 * time.Millisecond)
+
+			if err := repos.Token.RevokeAllUserTokens(ctx, userId); err != nil {
+				t.Fatalf("failed to revoke tokens: %v", err)
+			}
+
+			tokens, err := auth.GenerateToken(ctx, "runner", "qwerty123")
+			if err != nil {
+				t.Fatalf("failed to sign in: %v", err)
+			}
+
+			if _, _, err := auth.ParseToken(ctx, tokens.AccessToken); err != nil {
+				t.Errorf("Expected the new access token to be valid but got %v", err)
+			}
+
+			if _, _, err := auth.ParseToken(ctx, revokedTokens.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+				t.Errorf("Expected error %v but got %v", ErrTokenRevoked, err)
+			}
+		})
+	}
+}
+
+// failingToken is a token repository which can't revoke the sessions of a user
+type failingToken struct {
+	repository.Token
+}
+
+func (r failingToken) RevokeAllUserTokens(ctx context.Context, userId int) error {
+	return errors.New("tokens are unavailable")
+}
+
+// Test_AuthService_ChangePassword_rollsBack checks that a password stays the same when the sessions can't be revoked
+func Test_AuthService_ChangePassword_rollsBack(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+
+			userId, _ := signUpTestUser(t, repos, newTestAuthService(repos))
+
+			auth := NewAuthService(repos.Transactor, repos.User, failingToken{Token: repos.Token}, repos.Audit, time.Hour, testAuthConfig)
+
+			input := models.ChangePasswordInput{OldPassword: "qwerty123", NewPassword: "asdfgh456"}
+			if err := auth.ChangePassword(ctx, userId, input); err == nil {
+				t.Fatalf("Expected an error when the sessions can't be revoked")
+			}
+
+			if _, err := auth.GenerateToken(ctx, "runner", "qwerty123"); err != nil {
+				t.Errorf("Expected the old password to stay valid but got %v", err)
+			}
+		})
+	}
+}
+
+func Test_AuthService_GenerateToken_unknownUser(t *testing.T) {
+	ctx := context.Background()
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			auth := newTestAuthService(testRepository.new(t))
+
+			if _, err := auth.GenerateToken(ctx, "nobody", "qwerty123"); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("Expected error %v but got %v", ErrInvalidCredentials, err)
+			}
+		})
+	}
+}
+
+// the dummy hash has the parameters of new hashes, so it costs the same to check
+func Test_dummyPasswordHash(t *testing.T) {
+	match, needsRehash, err := verifyPassword("qwerty123", dummyPasswordHash())
+	if err != nil || match || needsRehash {
+		t.Errorf("Expected a current argon2id hash of another password, match: %v, needs rehash: %v: %v", match, needsRehash, err)
+	}
+}
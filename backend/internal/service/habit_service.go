@@ -1,12 +1,19 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
+	"github.com/aidos-dev/habit-tracker/backend/internal/metrics"
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
 )
 
+// ErrVersionMismatch means a habit or a tracker was changed after the client read it
+var ErrVersionMismatch = errors.New("version does not match: the resource has been changed, read it again")
+
 type HabitService struct {
 	repo repository.Habit
 }
@@ -15,28 +22,49 @@ func NewHabitService(repo repository.Habit) Habit {
 	return &HabitService{repo: repo}
 }
 
-func (s *HabitService) Create(userId int, habit models.Habit) (int, error) {
-	return s.repo.Create(userId, habit)
+func (s *HabitService) Create(ctx context.Context, userId int, habit models.Habit) (int, error) {
+	habitId, err := s.repo.Create(ctx, userId, habit)
+	if err != nil {
+		return 0, err
+	}
+
+	metrics.HabitsCreated.Inc()
+
+	return habitId, nil
 }
 
-func (s *HabitService) GetAll(userId int) ([]models.Habit, error) {
-	return s.repo.GetAll(userId)
+func (s *HabitService) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.Habit, int, error) {
+	return s.repo.GetAll(ctx, userId, params)
 }
 
-func (s *HabitService) GetById(userId, habitId int) (models.Habit, error) {
-	return s.repo.GetById(userId, habitId)
+func (s *HabitService) GetById(ctx context.Context, userId, habitId int) (models.Habit, error) {
+	return s.repo.GetById(ctx, userId, habitId)
 }
 
-func (s *HabitService) Delete(userId, habitId int) error {
-	return s.repo.Delete(userId, habitId)
+func (s *HabitService) Delete(ctx context.Context, userId, habitId int) error {
+	return s.repo.Delete(ctx, userId, habitId)
 }
 
-func (s *HabitService) Update(userId, habitId int, input models.UpdateHabitInput) error {
+func (s *HabitService) Update(ctx context.Context, userId, habitId int, input models.UpdateHabitInput) (int, error) {
 	const op = "service.habit_service.Update"
 
 	if err := input.Validate(); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return 0, fmt.Errorf("%s: %w", op, errs.Validation("invalid_habit", err))
+	}
+
+	version, err := s.repo.Update(ctx, userId, habitId, input)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, versionErr(err))
+	}
+
+	return version, nil
+}
+
+// versionErr turns a version conflict of the repository into ErrVersionMismatch
+func versionErr(err error) error {
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		return fmt.Errorf("%w: %v", ErrVersionMismatch, err)
 	}
 
-	return s.repo.Update(userId, habitId, input)
+	return err
 }
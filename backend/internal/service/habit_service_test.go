@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+)
+
+// Test_HabitService_GetAll_filterWildcards checks that wildcards of LIKE in a filter match only themselves
+func Test_HabitService_GetAll_filterWildcards(t *testing.T) {
+	ctx := context.Background()
+
+	testTable := []struct {
+		name           string
+		filter         string
+		expectedTitles []string
+	}{
+		{name: "Underscore", filter: "_", expectedTitles: []string{"read_books"}},
+		{name: "Percent", filter: "%", expectedTitles: []string{"100% water"}},
+		{name: "Backslash", filter: `\`, expectedTitles: []string{`c:\gym`}},
+		{name: "Letters", filter: "RUN", expectedTitles: []string{"running"}},
+	}
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			habits := NewHabitService(repos.Habit)
+
+			userId := createTestUser(t, repos, "runner")
+
+			for _, title := range []string{"read_books", "100% water", `c:\gym`, "running"} {
+				if _, err := habits.Create(ctx, userId, models.Habit{Title: title}); err != nil {
+					t.Fatalf("failed to create habit: %v", err)
+				}
+			}
+
+			for _, testCase := range testTable {
+				t.Run(testCase.name, func(t *testing.T) {
+					params, err := models.ParseListParams(map[string][]string{"title": {testCase.filter}}, models.HabitListFields)
+					if err != nil {
+						t.Fatalf("failed to parse params: %v", err)
+					}
+
+					list, _, err := habits.GetAll(ctx, userId, params)
+					if err != nil {
+						t.Fatalf("failed to get habits: %v", err)
+					}
+
+					titles := make([]string, 0, len(list))
+					for _, habit := range list {
+						titles = append(titles, habit.Title)
+					}
+
+					if len(titles) != len(testCase.expectedTitles) || titles[0] != testCase.expectedTitles[0] {
+						t.Errorf("Expected habits %v but got %v", testCase.expectedTitles, titles)
+					}
+				})
+			}
+		})
+	}
+}
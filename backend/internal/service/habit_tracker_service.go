@@ -1,10 +1,13 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/aidos-dev/habit-tracker/backend/internal/metrics"
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
 )
 
 type HabitTrackerService struct {
@@ -15,22 +18,29 @@ func NewHabitTrackerService(repo repository.HabitTracker) HabitTracker {
 	return &HabitTrackerService{repo: repo}
 }
 
-func (s *HabitTrackerService) GetAll(userId int) ([]models.HabitTracker, error) {
-	return s.repo.GetAll(userId)
+func (s *HabitTrackerService) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.HabitTracker, int, error) {
+	return s.repo.GetAll(ctx, userId, params)
 }
 
-func (s *HabitTrackerService) GetById(userId, habitId int) (models.HabitTracker, error) {
-	return s.repo.GetById(userId, habitId)
+func (s *HabitTrackerService) GetById(ctx context.Context, userId, habitId int) (models.HabitTracker, error) {
+	return s.repo.GetById(ctx, userId, habitId)
 }
 
-func (s *HabitTrackerService) Update(userId, habitId int, input models.UpdateTrackerInput) error {
+func (s *HabitTrackerService) Update(ctx context.Context, userId, habitId int, input models.UpdateTrackerInput) (int, error) {
 	const op = "service.habit_tracker_service.Update"
 
 	if err := input.Validate(); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return 0, fmt.Errorf("%s: %w", op, errs.Validation("invalid_habit_tracker", err))
 	}
 
-	return s.repo.Update(userId, habitId, input)
+	version, err := s.repo.Update(ctx, userId, habitId, input)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, versionErr(err))
+	}
+
+	metrics.CheckInsRecorded.Inc()
+
+	return version, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -41,7 +51,7 @@ temporarily disabled
 */
 
 // func (s *HabitTrackerService) Create(userHabitId int, tracker habit.HabitTracker) (int, error) {
-// 	return s.repo.Create(userHabitId, tracker)
+// 	return s.repo.Create(ctx, userHabitId, tracker)
 // }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -52,5 +62,5 @@ temporarily disabled
 */
 
 // func (s *HabitTrackerService) Delete(userId, habitId int) error {
-// 	return s.repo.Delete(userId, habitId)
+// 	return s.repo.Delete(ctx, userId, habitId)
 // }
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+const maxIdempotencyKeyLength = 255
+
+var (
+	ErrIdempotencyKeyInvalid = errors.New("idempotency key must be 1 to 255 characters long")
+	// ErrIdempotencyKeyReused means the key was sent before with another request
+	ErrIdempotencyKeyReused = errors.New("idempotency key is already used for another request")
+	// ErrIdempotencyInProgress means the first request with the key has not finished yet
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+type IdempotencyService struct {
+	repo repository.Idempotency
+	cfg  config.Idempotency
+}
+
+func NewIdempotencyService(repo repository.Idempotency, cfg config.Idempotency) Idempotency {
+	return &IdempotencyService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+/*
+Begin reserves the key for the request. If the key was used before with
+the same request, the stored record is returned with true, so the response
+is replayed instead of doing the request again
+*/
+func (s *IdempotencyService) Begin(ctx context.Context, userId int, key string, req models.IdempotentRequest) (models.IdempotencyRecord, bool, error) {
+	const op = "service.idempotency_service.Begin"
+
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return models.IdempotencyRecord{}, false, fmt.Errorf("%s: %w", op, ErrIdempotencyKeyInvalid)
+	}
+
+	record := models.IdempotencyRecord{
+		UserId:      userId,
+		Key:         key,
+		RequestHash: requestHash(req),
+		ExpiresAt:   time.Now().Add(s.cfg.TTL),
+	}
+
+	reserved, err := s.repo.Reserve(ctx, record)
+	if err != nil {
+		return record, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if reserved {
+		return record, false, nil
+	}
+
+	stored, err := s.repo.Get(ctx, userId, key)
+	if err != nil {
+		return record, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if stored.RequestHash != record.RequestHash {
+		return stored, false, fmt.Errorf("%s: %w", op, ErrIdempotencyKeyReused)
+	}
+
+	if stored.StatusCode == nil {
+		return stored, false, fmt.Errorf("%s: %w", op, ErrIdempotencyInProgress)
+	}
+
+	return stored, true, nil
+}
+
+// Complete stores the response and its headers to replay them to repeated requests
+func (s *IdempotencyService) Complete(ctx context.Context, userId int, key string, statusCode int, headers map[string]string, body []byte) error {
+	const op = "service.idempotency_service.Complete"
+
+	if err := s.repo.Complete(ctx, userId, key, statusCode, headers, body); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Abandon frees the key of a request which failed, so it can be retried
+func (s *IdempotencyService) Abandon(ctx context.Context, userId int, key string) error {
+	const op = "service.idempotency_service.Abandon"
+
+	if err := s.repo.Delete(ctx, userId, key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func requestHash(req models.IdempotentRequest) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.RequestURI + "\n"))
+	hash.Write(req.Body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
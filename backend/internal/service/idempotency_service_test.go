@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+)
+
+func Test_IdempotencyService_replay(t *testing.T) {
+	ctx := context.Background()
+
+	req := models.IdempotentRequest{Method: "PUT", RequestURI: "/web/api/habits/1", Body: []byte(`{"title":"jogging"}`)}
+	headers := map[string]string{"Content-Type": "application/json; charset=utf-8", "ETag": `"2"`}
+
+	for _, testRepository := range testRepositories {
+		t.Run(testRepository.name, func(t *testing.T) {
+			repos := testRepository.new(t)
+			idempotency := NewIdempotencyService(repos.Idempotency, config.Idempotency{TTL: time.Hour})
+
+			userId := createTestUser(t, repos, "runner")
+
+			if _, replay, err := idempotency.Begin(ctx, userId, "key-1", req); err != nil || replay {
+				t.Fatalf("Expected the key to be reserved, replay: %v: %v", replay, err)
+			}
+
+			if err := idempotency.Complete(ctx, userId, "key-1", 200, headers, []byte(`{"status":"ok"}`)); err != nil {
+				t.Fatalf("failed to complete request: %v", err)
+			}
+
+			record, replay, err := idempotency.Begin(ctx, userId, "key-1", req)
+			if err != nil || !replay {
+				t.Fatalf("Expected the response to be replayed, replay: %v: %v", replay, err)
+			}
+
+			if *record.StatusCode != 200 || string(record.ResponseBody) != `{"status":"ok"}` {
+				t.Errorf("unexpected response: %d %s", *record.StatusCode, record.ResponseBody)
+			}
+
+			if !reflect.DeepEqual(record.ResponseHeaders, headers) {
+				t.Errorf("Expected headers %v but got %v", headers, record.ResponseHeaders)
+			}
+		})
+	}
+}
@@ -5,7 +5,9 @@
 package mock_service
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/aidos-dev/habit-tracker/backend/internal/models"
 	gomock "go.uber.org/mock/gomock"
@@ -34,40 +36,82 @@ func (m *MockAuthorization) EXPECT() *MockAuthorizationMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockAuthorization) ChangePassword(ctx context.Context, userId int, input models.ChangePasswordInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userId, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthorizationMockRecorder) ChangePassword(ctx, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthorization)(nil).ChangePassword), ctx, userId, input)
+}
+
 // FindTgUser mocks base method.
-func (m *MockAuthorization) FindTgUser(tgUsername string) (models.GetUser, error) {
+func (m *MockAuthorization) FindTgUser(ctx context.Context, tgUserId int64) (models.GetUser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindTgUser", tgUsername)
+	ret := m.ctrl.Call(m, "FindTgUser", ctx, tgUserId)
 	ret0, _ := ret[0].(models.GetUser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindTgUser indicates an expected call of FindTgUser.
-func (mr *MockAuthorizationMockRecorder) FindTgUser(tgUsername interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) FindTgUser(ctx, tgUserId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTgUser", reflect.TypeOf((*MockAuthorization)(nil).FindTgUser), tgUsername)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTgUser", reflect.TypeOf((*MockAuthorization)(nil).FindTgUser), ctx, tgUserId)
 }
 
 // GenerateToken mocks base method.
-func (m *MockAuthorization) GenerateToken(username, password string) (string, error) {
+func (m *MockAuthorization) GenerateToken(ctx context.Context, username, password string) (models.Tokens, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", username, password)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GenerateToken", ctx, username, password)
+	ret0, _ := ret[0].(models.Tokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockAuthorizationMockRecorder) GenerateToken(username, password interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) GenerateToken(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuthorization)(nil).GenerateToken), ctx, username, password)
+}
+
+// Logout mocks base method.
+func (m *MockAuthorization) Logout(ctx context.Context, userId int, accessToken, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, userId, accessToken, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthorizationMockRecorder) Logout(ctx, userId, accessToken, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthorization)(nil).Logout), ctx, userId, accessToken, refreshToken)
+}
+
+// LogoutAll mocks base method.
+func (m *MockAuthorization) LogoutAll(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockAuthorizationMockRecorder) LogoutAll(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuthorization)(nil).GenerateToken), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockAuthorization)(nil).LogoutAll), ctx, userId)
 }
 
 // ParseToken mocks base method.
-func (m *MockAuthorization) ParseToken(token string) (int, string, error) {
+func (m *MockAuthorization) ParseToken(ctx context.Context, token string) (int, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ParseToken", token)
+	ret := m.ctrl.Call(m, "ParseToken", ctx, token)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
@@ -75,9 +119,550 @@ func (m *MockAuthorization) ParseToken(token string) (int, string, error) {
 }
 
 // ParseToken indicates an expected call of ParseToken.
-func (mr *MockAuthorizationMockRecorder) ParseToken(token interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) ParseToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuthorization)(nil).ParseToken), ctx, token)
+}
+
+// PurgeExpiredTokens mocks base method.
+func (m *MockAuthorization) PurgeExpiredTokens(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredTokens", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredTokens indicates an expected call of PurgeExpiredTokens.
+func (mr *MockAuthorizationMockRecorder) PurgeExpiredTokens(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredTokens", reflect.TypeOf((*MockAuthorization)(nil).PurgeExpiredTokens), ctx)
+}
+
+// Reactivate mocks base method.
+func (m *MockAuthorization) Reactivate(ctx context.Context, username, password string) (models.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reactivate", ctx, username, password)
+	ret0, _ := ret[0].(models.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reactivate indicates an expected call of Reactivate.
+func (mr *MockAuthorizationMockRecorder) Reactivate(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reactivate", reflect.TypeOf((*MockAuthorization)(nil).Reactivate), ctx, username, password)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthorization) Refresh(ctx context.Context, refreshToken string) (models.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+	ret0, _ := ret[0].(models.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthorizationMockRecorder) Refresh(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthorization)(nil).Refresh), ctx, refreshToken)
+}
+
+// SignUpTelegram mocks base method.
+func (m *MockAuthorization) SignUpTelegram(ctx context.Context, user models.User) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignUpTelegram", ctx, user)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignUpTelegram indicates an expected call of SignUpTelegram.
+func (mr *MockAuthorizationMockRecorder) SignUpTelegram(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpTelegram", reflect.TypeOf((*MockAuthorization)(nil).SignUpTelegram), ctx, user)
+}
+
+// MockServiceAuth is a mock of ServiceAuth interface.
+type MockServiceAuth struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceAuthMockRecorder
+}
+
+// MockServiceAuthMockRecorder is the mock recorder for MockServiceAuth.
+type MockServiceAuthMockRecorder struct {
+	mock *MockServiceAuth
+}
+
+// NewMockServiceAuth creates a new mock instance.
+func NewMockServiceAuth(ctrl *gomock.Controller) *MockServiceAuth {
+	mock := &MockServiceAuth{ctrl: ctrl}
+	mock.recorder = &MockServiceAuthMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceAuth) EXPECT() *MockServiceAuthMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockServiceAuth) Authenticate(ctx context.Context, req models.SignedRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockServiceAuthMockRecorder) Authenticate(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockServiceAuth)(nil).Authenticate), ctx, req)
+}
+
+// MockPersonalToken is a mock of PersonalToken interface.
+type MockPersonalToken struct {
+	ctrl     *gomock.Controller
+	recorder *MockPersonalTokenMockRecorder
+}
+
+// MockPersonalTokenMockRecorder is the mock recorder for MockPersonalToken.
+type MockPersonalTokenMockRecorder struct {
+	mock *MockPersonalToken
+}
+
+// NewMockPersonalToken creates a new mock instance.
+func NewMockPersonalToken(ctrl *gomock.Controller) *MockPersonalToken {
+	mock := &MockPersonalToken{ctrl: ctrl}
+	mock.recorder = &MockPersonalTokenMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPersonalToken) EXPECT() *MockPersonalTokenMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockPersonalToken) Authenticate(ctx context.Context, token string) (models.PersonalToken, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, token)
+	ret0, _ := ret[0].(models.PersonalToken)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockPersonalTokenMockRecorder) Authenticate(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockPersonalToken)(nil).Authenticate), ctx, token)
+}
+
+// Create mocks base method.
+func (m *MockPersonalToken) Create(ctx context.Context, userId int, input models.PersonalTokenInput) (models.NewPersonalToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, input)
+	ret0, _ := ret[0].(models.NewPersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPersonalTokenMockRecorder) Create(ctx, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPersonalToken)(nil).Create), ctx, userId, input)
+}
+
+// GetAll mocks base method.
+func (m *MockPersonalToken) GetAll(ctx context.Context, userId int) ([]models.PersonalToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
+	ret0, _ := ret[0].([]models.PersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockPersonalTokenMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockPersonalToken)(nil).GetAll), ctx, userId)
+}
+
+// Revoke mocks base method.
+func (m *MockPersonalToken) Revoke(ctx context.Context, userId, tokenId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, userId, tokenId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockPersonalTokenMockRecorder) Revoke(ctx, userId, tokenId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockPersonalToken)(nil).Revoke), ctx, userId, tokenId)
+}
+
+// MockTelegramLink is a mock of TelegramLink interface.
+type MockTelegramLink struct {
+	ctrl     *gomock.Controller
+	recorder *MockTelegramLinkMockRecorder
+}
+
+// MockTelegramLinkMockRecorder is the mock recorder for MockTelegramLink.
+type MockTelegramLinkMockRecorder struct {
+	mock *MockTelegramLink
+}
+
+// NewMockTelegramLink creates a new mock instance.
+func NewMockTelegramLink(ctrl *gomock.Controller) *MockTelegramLink {
+	mock := &MockTelegramLink{ctrl: ctrl}
+	mock.recorder = &MockTelegramLinkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTelegramLink) EXPECT() *MockTelegramLinkMockRecorder {
+	return m.recorder
+}
+
+// CreateLinkCode mocks base method.
+func (m *MockTelegramLink) CreateLinkCode(ctx context.Context, userId int) (models.LinkCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLinkCode", ctx, userId)
+	ret0, _ := ret[0].(models.LinkCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLinkCode indicates an expected call of CreateLinkCode.
+func (mr *MockTelegramLinkMockRecorder) CreateLinkCode(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLinkCode", reflect.TypeOf((*MockTelegramLink)(nil).CreateLinkCode), ctx, userId)
+}
+
+// Link mocks base method.
+func (m *MockTelegramLink) Link(ctx context.Context, input models.TelegramLinkInput) (models.TelegramLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Link", ctx, input)
+	ret0, _ := ret[0].(models.TelegramLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Link indicates an expected call of Link.
+func (mr *MockTelegramLinkMockRecorder) Link(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Link", reflect.TypeOf((*MockTelegramLink)(nil).Link), ctx, input)
+}
+
+// MockRateLimit is a mock of RateLimit interface.
+type MockRateLimit struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitMockRecorder
+}
+
+// MockRateLimitMockRecorder is the mock recorder for MockRateLimit.
+type MockRateLimitMockRecorder struct {
+	mock *MockRateLimit
+}
+
+// NewMockRateLimit creates a new mock instance.
+func NewMockRateLimit(ctrl *gomock.Controller) *MockRateLimit {
+	mock := &MockRateLimit{ctrl: ctrl}
+	mock.recorder = &MockRateLimitMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimit) EXPECT() *MockRateLimitMockRecorder {
+	return m.recorder
+}
+
+// Allow mocks base method.
+func (m *MockRateLimit) Allow(route, key string) (bool, time.Duration) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", route, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(time.Duration)
+	return ret0, ret1
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockRateLimitMockRecorder) Allow(route, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimit)(nil).Allow), route, key)
+}
+
+// AllowAddress mocks base method.
+func (m *MockRateLimit) AllowAddress(ip string) (bool, time.Duration) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowAddress", ip)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(time.Duration)
+	return ret0, ret1
+}
+
+// AllowAddress indicates an expected call of AllowAddress.
+func (mr *MockRateLimitMockRecorder) AllowAddress(ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowAddress", reflect.TypeOf((*MockRateLimit)(nil).AllowAddress), ip)
+}
+
+// SignInFailed mocks base method.
+func (m *MockRateLimit) SignInFailed(userName, ip string) time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInFailed", userName, ip)
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// SignInFailed indicates an expected call of SignInFailed.
+func (mr *MockRateLimitMockRecorder) SignInFailed(userName, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInFailed", reflect.TypeOf((*MockRateLimit)(nil).SignInFailed), userName, ip)
+}
+
+// SignInLocked mocks base method.
+func (m *MockRateLimit) SignInLocked(userName, ip string) time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInLocked", userName, ip)
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// SignInLocked indicates an expected call of SignInLocked.
+func (mr *MockRateLimitMockRecorder) SignInLocked(userName, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInLocked", reflect.TypeOf((*MockRateLimit)(nil).SignInLocked), userName, ip)
+}
+
+// SignInSucceeded mocks base method.
+func (m *MockRateLimit) SignInSucceeded(userName, ip string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SignInSucceeded", userName, ip)
+}
+
+// SignInSucceeded indicates an expected call of SignInSucceeded.
+func (mr *MockRateLimitMockRecorder) SignInSucceeded(userName, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInSucceeded", reflect.TypeOf((*MockRateLimit)(nil).SignInSucceeded), userName, ip)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// Abandon mocks base method.
+func (m *MockIdempotency) Abandon(ctx context.Context, userId int, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Abandon", ctx, userId, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Abandon indicates an expected call of Abandon.
+func (mr *MockIdempotencyMockRecorder) Abandon(ctx, userId, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Abandon", reflect.TypeOf((*MockIdempotency)(nil).Abandon), ctx, userId, key)
+}
+
+// Begin mocks base method.
+func (m *MockIdempotency) Begin(ctx context.Context, userId int, key string, req models.IdempotentRequest) (models.IdempotencyRecord, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx, userId, key, req)
+	ret0, _ := ret[0].(models.IdempotencyRecord)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyMockRecorder) Begin(ctx, userId, key, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotency)(nil).Begin), ctx, userId, key, req)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotency) Complete(ctx context.Context, userId int, key string, statusCode int, headers map[string]string, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, userId, key, statusCode, headers, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyMockRecorder) Complete(ctx, userId, key, statusCode, headers, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotency)(nil).Complete), ctx, userId, key, statusCode, headers, body)
+}
+
+// MockOIDC is a mock of OIDC interface.
+type MockOIDC struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCMockRecorder
+}
+
+// MockOIDCMockRecorder is the mock recorder for MockOIDC.
+type MockOIDCMockRecorder struct {
+	mock *MockOIDC
+}
+
+// NewMockOIDC creates a new mock instance.
+func NewMockOIDC(ctrl *gomock.Controller) *MockOIDC {
+	mock := &MockOIDC{ctrl: ctrl}
+	mock.recorder = &MockOIDCMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDC) EXPECT() *MockOIDCMockRecorder {
+	return m.recorder
+}
+
+// AuthURL mocks base method.
+func (m *MockOIDC) AuthURL(ctx context.Context, provider string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthURL", ctx, provider)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuthURL indicates an expected call of AuthURL.
+func (mr *MockOIDCMockRecorder) AuthURL(ctx, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthURL", reflect.TypeOf((*MockOIDC)(nil).AuthURL), ctx, provider)
+}
+
+// Callback mocks base method.
+func (m *MockOIDC) Callback(ctx context.Context, provider, code, state, flowState string) (models.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Callback", ctx, provider, code, state, flowState)
+	ret0, _ := ret[0].(models.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Callback indicates an expected call of Callback.
+func (mr *MockOIDCMockRecorder) Callback(ctx, provider, code, state, flowState interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Callback", reflect.TypeOf((*MockOIDC)(nil).Callback), ctx, provider, code, state, flowState)
+}
+
+// MockAccount is a mock of Account interface.
+type MockAccount struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountMockRecorder
+}
+
+// MockAccountMockRecorder is the mock recorder for MockAccount.
+type MockAccountMockRecorder struct {
+	mock *MockAccount
+}
+
+// NewMockAccount creates a new mock instance.
+func NewMockAccount(ctrl *gomock.Controller) *MockAccount {
+	mock := &MockAccount{ctrl: ctrl}
+	mock.recorder = &MockAccountMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccount) EXPECT() *MockAccountMockRecorder {
+	return m.recorder
+}
+
+// ForgotPassword mocks base method.
+func (m *MockAccount) ForgotPassword(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockAccountMockRecorder) ForgotPassword(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockAccount)(nil).ForgotPassword), ctx, email)
+}
+
+// IsEmailVerified mocks base method.
+func (m *MockAccount) IsEmailVerified(ctx context.Context, userId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEmailVerified", ctx, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEmailVerified indicates an expected call of IsEmailVerified.
+func (mr *MockAccountMockRecorder) IsEmailVerified(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmailVerified", reflect.TypeOf((*MockAccount)(nil).IsEmailVerified), ctx, userId)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAccount) ResetPassword(ctx context.Context, input models.ResetPasswordInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAccountMockRecorder) ResetPassword(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAccount)(nil).ResetPassword), ctx, input)
+}
+
+// SendVerification mocks base method.
+func (m *MockAccount) SendVerification(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVerification", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendVerification indicates an expected call of SendVerification.
+func (mr *MockAccountMockRecorder) SendVerification(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerification", reflect.TypeOf((*MockAccount)(nil).SendVerification), ctx, userId)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAccount) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAccountMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuthorization)(nil).ParseToken), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAccount)(nil).VerifyEmail), ctx, token)
 }
 
 // MockAdminRole is a mock of AdminRole interface.
@@ -86,36 +671,138 @@ type MockAdminRole struct {
 	recorder *MockAdminRoleMockRecorder
 }
 
-// MockAdminRoleMockRecorder is the mock recorder for MockAdminRole.
-type MockAdminRoleMockRecorder struct {
-	mock *MockAdminRole
+// MockAdminRoleMockRecorder is the mock recorder for MockAdminRole.
+type MockAdminRoleMockRecorder struct {
+	mock *MockAdminRole
+}
+
+// NewMockAdminRole creates a new mock instance.
+func NewMockAdminRole(ctrl *gomock.Controller) *MockAdminRole {
+	mock := &MockAdminRole{ctrl: ctrl}
+	mock.recorder = &MockAdminRoleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminRole) EXPECT() *MockAdminRoleMockRecorder {
+	return m.recorder
+}
+
+// AssignRole mocks base method.
+func (m *MockAdminRole) AssignRole(ctx context.Context, meta models.AuditMeta, userId int, role models.UpdateRoleInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignRole", ctx, meta, userId, role)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssignRole indicates an expected call of AssignRole.
+func (mr *MockAdminRoleMockRecorder) AssignRole(ctx, meta, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockAdminRole)(nil).AssignRole), ctx, meta, userId, role)
+}
+
+// CreateRole mocks base method.
+func (m *MockAdminRole) CreateRole(ctx context.Context, meta models.AuditMeta, input models.RoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", ctx, meta, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockAdminRoleMockRecorder) CreateRole(ctx, meta, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockAdminRole)(nil).CreateRole), ctx, meta, input)
+}
+
+// DeleteRole mocks base method.
+func (m *MockAdminRole) DeleteRole(ctx context.Context, meta models.AuditMeta, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, meta, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockAdminRoleMockRecorder) DeleteRole(ctx, meta, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockAdminRole)(nil).DeleteRole), ctx, meta, name)
+}
+
+// GetAllPermissions mocks base method.
+func (m *MockAdminRole) GetAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllPermissions", ctx)
+	ret0, _ := ret[0].([]models.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllPermissions indicates an expected call of GetAllPermissions.
+func (mr *MockAdminRoleMockRecorder) GetAllPermissions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPermissions", reflect.TypeOf((*MockAdminRole)(nil).GetAllPermissions), ctx)
+}
+
+// GetAllRoles mocks base method.
+func (m *MockAdminRole) GetAllRoles(ctx context.Context) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllRoles", ctx)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllRoles indicates an expected call of GetAllRoles.
+func (mr *MockAdminRoleMockRecorder) GetAllRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRoles", reflect.TypeOf((*MockAdminRole)(nil).GetAllRoles), ctx)
+}
+
+// HasPermission mocks base method.
+func (m *MockAdminRole) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPermission", ctx, role, permission)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// NewMockAdminRole creates a new mock instance.
-func NewMockAdminRole(ctrl *gomock.Controller) *MockAdminRole {
-	mock := &MockAdminRole{ctrl: ctrl}
-	mock.recorder = &MockAdminRoleMockRecorder{mock}
-	return mock
+// HasPermission indicates an expected call of HasPermission.
+func (mr *MockAdminRoleMockRecorder) HasPermission(ctx, role, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPermission", reflect.TypeOf((*MockAdminRole)(nil).HasPermission), ctx, role, permission)
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockAdminRole) EXPECT() *MockAdminRoleMockRecorder {
-	return m.recorder
+// UpdateRole mocks base method.
+func (m *MockAdminRole) UpdateRole(ctx context.Context, meta models.AuditMeta, name string, input models.RoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, meta, name, input)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// AssignRole mocks base method.
-func (m *MockAdminRole) AssignRole(userId int, role models.UpdateRoleInput) (int, error) {
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockAdminRoleMockRecorder) UpdateRole(ctx, meta, name, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockAdminRole)(nil).UpdateRole), ctx, meta, name, input)
+}
+
+// UserRole mocks base method.
+func (m *MockAdminRole) UserRole(ctx context.Context, userId int) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AssignRole", userId, role)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "UserRole", ctx, userId)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AssignRole indicates an expected call of AssignRole.
-func (mr *MockAdminRoleMockRecorder) AssignRole(userId, role interface{}) *gomock.Call {
+// UserRole indicates an expected call of UserRole.
+func (mr *MockAdminRoleMockRecorder) UserRole(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockAdminRole)(nil).AssignRole), userId, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRole", reflect.TypeOf((*MockAdminRole)(nil).UserRole), ctx, userId)
 }
 
 // MockAdminReward is a mock of AdminReward interface.
@@ -142,76 +829,77 @@ func (m *MockAdminReward) EXPECT() *MockAdminRewardMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockAdminReward) Create(reward models.Reward) (int, error) {
+func (m *MockAdminReward) Create(ctx context.Context, meta models.AuditMeta, reward models.Reward) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", reward)
+	ret := m.ctrl.Call(m, "Create", ctx, meta, reward)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockAdminRewardMockRecorder) Create(reward interface{}) *gomock.Call {
+func (mr *MockAdminRewardMockRecorder) Create(ctx, meta, reward interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAdminReward)(nil).Create), reward)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAdminReward)(nil).Create), ctx, meta, reward)
 }
 
 // Delete mocks base method.
-func (m *MockAdminReward) Delete(rewardId int) error {
+func (m *MockAdminReward) Delete(ctx context.Context, meta models.AuditMeta, rewardId int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", rewardId)
+	ret := m.ctrl.Call(m, "Delete", ctx, meta, rewardId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockAdminRewardMockRecorder) Delete(rewardId interface{}) *gomock.Call {
+func (mr *MockAdminRewardMockRecorder) Delete(ctx, meta, rewardId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAdminReward)(nil).Delete), rewardId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAdminReward)(nil).Delete), ctx, meta, rewardId)
 }
 
 // GetAllRewards mocks base method.
-func (m *MockAdminReward) GetAllRewards() ([]models.Reward, error) {
+func (m *MockAdminReward) GetAllRewards(ctx context.Context, params models.ListParams) ([]models.Reward, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllRewards")
+	ret := m.ctrl.Call(m, "GetAllRewards", ctx, params)
 	ret0, _ := ret[0].([]models.Reward)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetAllRewards indicates an expected call of GetAllRewards.
-func (mr *MockAdminRewardMockRecorder) GetAllRewards() *gomock.Call {
+func (mr *MockAdminRewardMockRecorder) GetAllRewards(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRewards", reflect.TypeOf((*MockAdminReward)(nil).GetAllRewards))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRewards", reflect.TypeOf((*MockAdminReward)(nil).GetAllRewards), ctx, params)
 }
 
 // GetById mocks base method.
-func (m *MockAdminReward) GetById(rewardId int) (models.Reward, error) {
+func (m *MockAdminReward) GetById(ctx context.Context, rewardId int) (models.Reward, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", rewardId)
+	ret := m.ctrl.Call(m, "GetById", ctx, rewardId)
 	ret0, _ := ret[0].(models.Reward)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockAdminRewardMockRecorder) GetById(rewardId interface{}) *gomock.Call {
+func (mr *MockAdminRewardMockRecorder) GetById(ctx, rewardId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockAdminReward)(nil).GetById), rewardId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockAdminReward)(nil).GetById), ctx, rewardId)
 }
 
 // UpdateReward mocks base method.
-func (m *MockAdminReward) UpdateReward(rewardId int, input models.UpdateRewardInput) error {
+func (m *MockAdminReward) UpdateReward(ctx context.Context, meta models.AuditMeta, rewardId int, input models.UpdateRewardInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateReward", rewardId, input)
+	ret := m.ctrl.Call(m, "UpdateReward", ctx, meta, rewardId, input)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateReward indicates an expected call of UpdateReward.
-func (mr *MockAdminRewardMockRecorder) UpdateReward(rewardId, input interface{}) *gomock.Call {
+func (mr *MockAdminRewardMockRecorder) UpdateReward(ctx, meta, rewardId, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReward", reflect.TypeOf((*MockAdminReward)(nil).UpdateReward), rewardId, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReward", reflect.TypeOf((*MockAdminReward)(nil).UpdateReward), ctx, meta, rewardId, input)
 }
 
 // MockAdminUserReward is a mock of AdminUserReward interface.
@@ -238,76 +926,189 @@ func (m *MockAdminUserReward) EXPECT() *MockAdminUserRewardMockRecorder {
 }
 
 // AssignReward mocks base method.
-func (m *MockAdminUserReward) AssignReward(userId, habitId, rewardId int) (int, error) {
+func (m *MockAdminUserReward) AssignReward(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AssignReward", userId, habitId, rewardId)
+	ret := m.ctrl.Call(m, "AssignReward", ctx, meta, userId, habitId, rewardId)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AssignReward indicates an expected call of AssignReward.
-func (mr *MockAdminUserRewardMockRecorder) AssignReward(userId, habitId, rewardId interface{}) *gomock.Call {
+func (mr *MockAdminUserRewardMockRecorder) AssignReward(ctx, meta, userId, habitId, rewardId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignReward", reflect.TypeOf((*MockAdminUserReward)(nil).AssignReward), userId, habitId, rewardId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignReward", reflect.TypeOf((*MockAdminUserReward)(nil).AssignReward), ctx, meta, userId, habitId, rewardId)
 }
 
 // GetAllPersonalRewards mocks base method.
-func (m *MockAdminUserReward) GetAllPersonalRewards(userId int) ([]models.Reward, error) {
+func (m *MockAdminUserReward) GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllPersonalRewards", userId)
+	ret := m.ctrl.Call(m, "GetAllPersonalRewards", ctx, userId, params)
 	ret0, _ := ret[0].([]models.Reward)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetAllPersonalRewards indicates an expected call of GetAllPersonalRewards.
-func (mr *MockAdminUserRewardMockRecorder) GetAllPersonalRewards(userId interface{}) *gomock.Call {
+func (mr *MockAdminUserRewardMockRecorder) GetAllPersonalRewards(ctx, userId, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPersonalRewards", reflect.TypeOf((*MockAdminUserReward)(nil).GetAllPersonalRewards), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPersonalRewards", reflect.TypeOf((*MockAdminUserReward)(nil).GetAllPersonalRewards), ctx, userId, params)
 }
 
 // GetPersonalRewardsByHabitId mocks base method.
-func (m *MockAdminUserReward) GetPersonalRewardsByHabitId(userId, habitId int) ([]models.Reward, error) {
+func (m *MockAdminUserReward) GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPersonalRewardsByHabitId", userId, habitId)
+	ret := m.ctrl.Call(m, "GetPersonalRewardsByHabitId", ctx, userId, habitId)
 	ret0, _ := ret[0].([]models.Reward)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPersonalRewardsByHabitId indicates an expected call of GetPersonalRewardsByHabitId.
-func (mr *MockAdminUserRewardMockRecorder) GetPersonalRewardsByHabitId(userId, habitId interface{}) *gomock.Call {
+func (mr *MockAdminUserRewardMockRecorder) GetPersonalRewardsByHabitId(ctx, userId, habitId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalRewardsByHabitId", reflect.TypeOf((*MockAdminUserReward)(nil).GetPersonalRewardsByHabitId), userId, habitId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalRewardsByHabitId", reflect.TypeOf((*MockAdminUserReward)(nil).GetPersonalRewardsByHabitId), ctx, userId, habitId)
 }
 
 // RemoveFromUser mocks base method.
-func (m *MockAdminUserReward) RemoveFromUser(userId, habitId, rewardId int) error {
+func (m *MockAdminUserReward) RemoveFromUser(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveFromUser", userId, habitId, rewardId)
+	ret := m.ctrl.Call(m, "RemoveFromUser", ctx, meta, userId, habitId, rewardId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RemoveFromUser indicates an expected call of RemoveFromUser.
-func (mr *MockAdminUserRewardMockRecorder) RemoveFromUser(userId, habitId, rewardId interface{}) *gomock.Call {
+func (mr *MockAdminUserRewardMockRecorder) RemoveFromUser(ctx, meta, userId, habitId, rewardId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromUser", reflect.TypeOf((*MockAdminUserReward)(nil).RemoveFromUser), userId, habitId, rewardId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromUser", reflect.TypeOf((*MockAdminUserReward)(nil).RemoveFromUser), ctx, meta, userId, habitId, rewardId)
 }
 
 // UpdateUserReward mocks base method.
-func (m *MockAdminUserReward) UpdateUserReward(userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
+func (m *MockAdminUserReward) UpdateUserReward(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserReward", userId, habitId, rewardId, input)
+	ret := m.ctrl.Call(m, "UpdateUserReward", ctx, meta, userId, habitId, rewardId, input)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUserReward indicates an expected call of UpdateUserReward.
-func (mr *MockAdminUserRewardMockRecorder) UpdateUserReward(userId, habitId, rewardId, input interface{}) *gomock.Call {
+func (mr *MockAdminUserRewardMockRecorder) UpdateUserReward(ctx, meta, userId, habitId, rewardId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserReward", reflect.TypeOf((*MockAdminUserReward)(nil).UpdateUserReward), ctx, meta, userId, habitId, rewardId, input)
+}
+
+// MockAdminUser is a mock of AdminUser interface.
+type MockAdminUser struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminUserMockRecorder
+}
+
+// MockAdminUserMockRecorder is the mock recorder for MockAdminUser.
+type MockAdminUserMockRecorder struct {
+	mock *MockAdminUser
+}
+
+// NewMockAdminUser creates a new mock instance.
+func NewMockAdminUser(ctrl *gomock.Controller) *MockAdminUser {
+	mock := &MockAdminUser{ctrl: ctrl}
+	mock.recorder = &MockAdminUserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminUser) EXPECT() *MockAdminUserMockRecorder {
+	return m.recorder
+}
+
+// CreateHabit mocks base method.
+func (m *MockAdminUser) CreateHabit(ctx context.Context, meta models.AuditMeta, userId int, habit models.Habit) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHabit", ctx, meta, userId, habit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHabit indicates an expected call of CreateHabit.
+func (mr *MockAdminUserMockRecorder) CreateHabit(ctx, meta, userId, habit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHabit", reflect.TypeOf((*MockAdminUser)(nil).CreateHabit), ctx, meta, userId, habit)
+}
+
+// DeleteHabit mocks base method.
+func (m *MockAdminUser) DeleteHabit(ctx context.Context, meta models.AuditMeta, userId, habitId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHabit", ctx, meta, userId, habitId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHabit indicates an expected call of DeleteHabit.
+func (mr *MockAdminUserMockRecorder) DeleteHabit(ctx, meta, userId, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHabit", reflect.TypeOf((*MockAdminUser)(nil).DeleteHabit), ctx, meta, userId, habitId)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAdminUser) DeleteUser(ctx context.Context, meta models.AuditMeta, userId int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, meta, userId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAdminUserMockRecorder) DeleteUser(ctx, meta, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAdminUser)(nil).DeleteUser), ctx, meta, userId)
+}
+
+// RestoreUser mocks base method.
+func (m *MockAdminUser) RestoreUser(ctx context.Context, meta models.AuditMeta, userId int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", ctx, meta, userId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockAdminUserMockRecorder) RestoreUser(ctx, meta, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockAdminUser)(nil).RestoreUser), ctx, meta, userId)
+}
+
+// UpdateHabit mocks base method.
+func (m *MockAdminUser) UpdateHabit(ctx context.Context, meta models.AuditMeta, userId, habitId int, input models.UpdateHabitInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHabit", ctx, meta, userId, habitId, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHabit indicates an expected call of UpdateHabit.
+func (mr *MockAdminUserMockRecorder) UpdateHabit(ctx, meta, userId, habitId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHabit", reflect.TypeOf((*MockAdminUser)(nil).UpdateHabit), ctx, meta, userId, habitId, input)
+}
+
+// UpdateTracker mocks base method.
+func (m *MockAdminUser) UpdateTracker(ctx context.Context, meta models.AuditMeta, userId, habitId int, input models.UpdateTrackerInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTracker", ctx, meta, userId, habitId, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTracker indicates an expected call of UpdateTracker.
+func (mr *MockAdminUserMockRecorder) UpdateTracker(ctx, meta, userId, habitId, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserReward", reflect.TypeOf((*MockAdminUserReward)(nil).UpdateUserReward), userId, habitId, rewardId, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTracker", reflect.TypeOf((*MockAdminUser)(nil).UpdateTracker), ctx, meta, userId, habitId, input)
 }
 
 // MockAdmin is a mock of Admin interface.
@@ -334,164 +1135,268 @@ func (m *MockAdmin) EXPECT() *MockAdminMockRecorder {
 }
 
 // AssignReward mocks base method.
-func (m *MockAdmin) AssignReward(userId, habitId, rewardId int) (int, error) {
+func (m *MockAdmin) AssignReward(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AssignReward", userId, habitId, rewardId)
+	ret := m.ctrl.Call(m, "AssignReward", ctx, meta, userId, habitId, rewardId)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AssignReward indicates an expected call of AssignReward.
-func (mr *MockAdminMockRecorder) AssignReward(userId, habitId, rewardId interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) AssignReward(ctx, meta, userId, habitId, rewardId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignReward", reflect.TypeOf((*MockAdmin)(nil).AssignReward), userId, habitId, rewardId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignReward", reflect.TypeOf((*MockAdmin)(nil).AssignReward), ctx, meta, userId, habitId, rewardId)
 }
 
 // AssignRole mocks base method.
-func (m *MockAdmin) AssignRole(userId int, role models.UpdateRoleInput) (int, error) {
+func (m *MockAdmin) AssignRole(ctx context.Context, meta models.AuditMeta, userId int, role models.UpdateRoleInput) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AssignRole", userId, role)
+	ret := m.ctrl.Call(m, "AssignRole", ctx, meta, userId, role)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AssignRole indicates an expected call of AssignRole.
-func (mr *MockAdminMockRecorder) AssignRole(userId, role interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) AssignRole(ctx, meta, userId, role interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockAdmin)(nil).AssignRole), userId, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockAdmin)(nil).AssignRole), ctx, meta, userId, role)
 }
 
 // Create mocks base method.
-func (m *MockAdmin) Create(reward models.Reward) (int, error) {
+func (m *MockAdmin) Create(ctx context.Context, meta models.AuditMeta, reward models.Reward) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", reward)
+	ret := m.ctrl.Call(m, "Create", ctx, meta, reward)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockAdminMockRecorder) Create(reward interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) Create(ctx, meta, reward interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAdmin)(nil).Create), ctx, meta, reward)
+}
+
+// CreateRole mocks base method.
+func (m *MockAdmin) CreateRole(ctx context.Context, meta models.AuditMeta, input models.RoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", ctx, meta, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockAdminMockRecorder) CreateRole(ctx, meta, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAdmin)(nil).Create), reward)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockAdmin)(nil).CreateRole), ctx, meta, input)
 }
 
 // Delete mocks base method.
-func (m *MockAdmin) Delete(rewardId int) error {
+func (m *MockAdmin) Delete(ctx context.Context, meta models.AuditMeta, rewardId int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", rewardId)
+	ret := m.ctrl.Call(m, "Delete", ctx, meta, rewardId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockAdminMockRecorder) Delete(rewardId interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) Delete(ctx, meta, rewardId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAdmin)(nil).Delete), rewardId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAdmin)(nil).Delete), ctx, meta, rewardId)
 }
 
-// GetAllPersonalRewards mocks base method.
-func (m *MockAdmin) GetAllPersonalRewards(userId int) ([]models.Reward, error) {
+// DeleteRole mocks base method.
+func (m *MockAdmin) DeleteRole(ctx context.Context, meta models.AuditMeta, name string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllPersonalRewards", userId)
-	ret0, _ := ret[0].([]models.Reward)
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, meta, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockAdminMockRecorder) DeleteRole(ctx, meta, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockAdmin)(nil).DeleteRole), ctx, meta, name)
+}
+
+// GetAllPermissions mocks base method.
+func (m *MockAdmin) GetAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllPermissions", ctx)
+	ret0, _ := ret[0].([]models.Permission)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// GetAllPermissions indicates an expected call of GetAllPermissions.
+func (mr *MockAdminMockRecorder) GetAllPermissions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPermissions", reflect.TypeOf((*MockAdmin)(nil).GetAllPermissions), ctx)
+}
+
+// GetAllPersonalRewards mocks base method.
+func (m *MockAdmin) GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllPersonalRewards", ctx, userId, params)
+	ret0, _ := ret[0].([]models.Reward)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // GetAllPersonalRewards indicates an expected call of GetAllPersonalRewards.
-func (mr *MockAdminMockRecorder) GetAllPersonalRewards(userId interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) GetAllPersonalRewards(ctx, userId, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPersonalRewards", reflect.TypeOf((*MockAdmin)(nil).GetAllPersonalRewards), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPersonalRewards", reflect.TypeOf((*MockAdmin)(nil).GetAllPersonalRewards), ctx, userId, params)
 }
 
 // GetAllRewards mocks base method.
-func (m *MockAdmin) GetAllRewards() ([]models.Reward, error) {
+func (m *MockAdmin) GetAllRewards(ctx context.Context, params models.ListParams) ([]models.Reward, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllRewards")
+	ret := m.ctrl.Call(m, "GetAllRewards", ctx, params)
 	ret0, _ := ret[0].([]models.Reward)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAllRewards indicates an expected call of GetAllRewards.
+func (mr *MockAdminMockRecorder) GetAllRewards(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRewards", reflect.TypeOf((*MockAdmin)(nil).GetAllRewards), ctx, params)
+}
+
+// GetAllRoles mocks base method.
+func (m *MockAdmin) GetAllRoles(ctx context.Context) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllRoles", ctx)
+	ret0, _ := ret[0].([]models.Role)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllRewards indicates an expected call of GetAllRewards.
-func (mr *MockAdminMockRecorder) GetAllRewards() *gomock.Call {
+// GetAllRoles indicates an expected call of GetAllRoles.
+func (mr *MockAdminMockRecorder) GetAllRoles(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRewards", reflect.TypeOf((*MockAdmin)(nil).GetAllRewards))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRoles", reflect.TypeOf((*MockAdmin)(nil).GetAllRoles), ctx)
 }
 
 // GetById mocks base method.
-func (m *MockAdmin) GetById(rewardId int) (models.Reward, error) {
+func (m *MockAdmin) GetById(ctx context.Context, rewardId int) (models.Reward, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", rewardId)
+	ret := m.ctrl.Call(m, "GetById", ctx, rewardId)
 	ret0, _ := ret[0].(models.Reward)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockAdminMockRecorder) GetById(rewardId interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) GetById(ctx, rewardId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockAdmin)(nil).GetById), rewardId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockAdmin)(nil).GetById), ctx, rewardId)
 }
 
 // GetPersonalRewardsByHabitId mocks base method.
-func (m *MockAdmin) GetPersonalRewardsByHabitId(userId, habitId int) ([]models.Reward, error) {
+func (m *MockAdmin) GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPersonalRewardsByHabitId", userId, habitId)
+	ret := m.ctrl.Call(m, "GetPersonalRewardsByHabitId", ctx, userId, habitId)
 	ret0, _ := ret[0].([]models.Reward)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPersonalRewardsByHabitId indicates an expected call of GetPersonalRewardsByHabitId.
-func (mr *MockAdminMockRecorder) GetPersonalRewardsByHabitId(userId, habitId interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) GetPersonalRewardsByHabitId(ctx, userId, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalRewardsByHabitId", reflect.TypeOf((*MockAdmin)(nil).GetPersonalRewardsByHabitId), ctx, userId, habitId)
+}
+
+// HasPermission mocks base method.
+func (m *MockAdmin) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPermission", ctx, role, permission)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPermission indicates an expected call of HasPermission.
+func (mr *MockAdminMockRecorder) HasPermission(ctx, role, permission interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalRewardsByHabitId", reflect.TypeOf((*MockAdmin)(nil).GetPersonalRewardsByHabitId), userId, habitId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPermission", reflect.TypeOf((*MockAdmin)(nil).HasPermission), ctx, role, permission)
 }
 
 // RemoveFromUser mocks base method.
-func (m *MockAdmin) RemoveFromUser(userId, habitId, rewardId int) error {
+func (m *MockAdmin) RemoveFromUser(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveFromUser", userId, habitId, rewardId)
+	ret := m.ctrl.Call(m, "RemoveFromUser", ctx, meta, userId, habitId, rewardId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RemoveFromUser indicates an expected call of RemoveFromUser.
-func (mr *MockAdminMockRecorder) RemoveFromUser(userId, habitId, rewardId interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) RemoveFromUser(ctx, meta, userId, habitId, rewardId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromUser", reflect.TypeOf((*MockAdmin)(nil).RemoveFromUser), userId, habitId, rewardId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromUser", reflect.TypeOf((*MockAdmin)(nil).RemoveFromUser), ctx, meta, userId, habitId, rewardId)
 }
 
 // UpdateReward mocks base method.
-func (m *MockAdmin) UpdateReward(rewardId int, input models.UpdateRewardInput) error {
+func (m *MockAdmin) UpdateReward(ctx context.Context, meta models.AuditMeta, rewardId int, input models.UpdateRewardInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateReward", rewardId, input)
+	ret := m.ctrl.Call(m, "UpdateReward", ctx, meta, rewardId, input)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateReward indicates an expected call of UpdateReward.
-func (mr *MockAdminMockRecorder) UpdateReward(rewardId, input interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) UpdateReward(ctx, meta, rewardId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReward", reflect.TypeOf((*MockAdmin)(nil).UpdateReward), ctx, meta, rewardId, input)
+}
+
+// UpdateRole mocks base method.
+func (m *MockAdmin) UpdateRole(ctx context.Context, meta models.AuditMeta, name string, input models.RoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, meta, name, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockAdminMockRecorder) UpdateRole(ctx, meta, name, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReward", reflect.TypeOf((*MockAdmin)(nil).UpdateReward), rewardId, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockAdmin)(nil).UpdateRole), ctx, meta, name, input)
 }
 
 // UpdateUserReward mocks base method.
-func (m *MockAdmin) UpdateUserReward(userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
+func (m *MockAdmin) UpdateUserReward(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int, input models.UpdateUserRewardInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserReward", userId, habitId, rewardId, input)
+	ret := m.ctrl.Call(m, "UpdateUserReward", ctx, meta, userId, habitId, rewardId, input)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUserReward indicates an expected call of UpdateUserReward.
-func (mr *MockAdminMockRecorder) UpdateUserReward(userId, habitId, rewardId, input interface{}) *gomock.Call {
+func (mr *MockAdminMockRecorder) UpdateUserReward(ctx, meta, userId, habitId, rewardId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserReward", reflect.TypeOf((*MockAdmin)(nil).UpdateUserReward), ctx, meta, userId, habitId, rewardId, input)
+}
+
+// UserRole mocks base method.
+func (m *MockAdmin) UserRole(ctx context.Context, userId int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserRole", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserRole indicates an expected call of UserRole.
+func (mr *MockAdminMockRecorder) UserRole(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserReward", reflect.TypeOf((*MockAdmin)(nil).UpdateUserReward), userId, habitId, rewardId, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRole", reflect.TypeOf((*MockAdmin)(nil).UserRole), ctx, userId)
 }
 
 // MockUser is a mock of User interface.
@@ -518,93 +1423,109 @@ func (m *MockUser) EXPECT() *MockUserMockRecorder {
 }
 
 // CreateUser mocks base method.
-func (m *MockUser) CreateUser(user models.User) (int, error) {
+func (m *MockUser) CreateUser(ctx context.Context, user models.User) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", user)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockUserMockRecorder) CreateUser(user interface{}) *gomock.Call {
+func (mr *MockUserMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUser)(nil).CreateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUser)(nil).CreateUser), ctx, user)
 }
 
 // DeleteUser mocks base method.
-func (m *MockUser) DeleteUser(userId int) (int, error) {
+func (m *MockUser) DeleteUser(ctx context.Context, userId int) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", userId)
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userId)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockUserMockRecorder) DeleteUser(userId interface{}) *gomock.Call {
+func (mr *MockUserMockRecorder) DeleteUser(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUser)(nil).DeleteUser), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUser)(nil).DeleteUser), ctx, userId)
 }
 
 // GetAllUsers mocks base method.
-func (m *MockUser) GetAllUsers() ([]models.GetUser, error) {
+func (m *MockUser) GetAllUsers(ctx context.Context, params models.ListParams) ([]models.GetUser, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUsers")
+	ret := m.ctrl.Call(m, "GetAllUsers", ctx, params)
 	ret0, _ := ret[0].([]models.GetUser)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetAllUsers indicates an expected call of GetAllUsers.
-func (mr *MockUserMockRecorder) GetAllUsers() *gomock.Call {
+func (mr *MockUserMockRecorder) GetAllUsers(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockUser)(nil).GetAllUsers))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockUser)(nil).GetAllUsers), ctx, params)
 }
 
-// GetUser mocks base method.
-func (m *MockUser) GetUser(username, password string) (models.User, error) {
+// GetUserById mocks base method.
+func (m *MockUser) GetUserById(ctx context.Context, userId int) (models.GetUser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", username, password)
-	ret0, _ := ret[0].(models.User)
+	ret := m.ctrl.Call(m, "GetUserById", ctx, userId)
+	ret0, _ := ret[0].(models.GetUser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockUserMockRecorder) GetUser(username, password interface{}) *gomock.Call {
+// GetUserById indicates an expected call of GetUserById.
+func (mr *MockUserMockRecorder) GetUserById(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUser)(nil).GetUser), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserById", reflect.TypeOf((*MockUser)(nil).GetUserById), ctx, userId)
 }
 
-// GetUserById mocks base method.
-func (m *MockUser) GetUserById(userId int) (models.GetUser, error) {
+// GetUserByTgUserId mocks base method.
+func (m *MockUser) GetUserByTgUserId(ctx context.Context, tgUserId int64) (models.GetUser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserById", userId)
+	ret := m.ctrl.Call(m, "GetUserByTgUserId", ctx, tgUserId)
 	ret0, _ := ret[0].(models.GetUser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserById indicates an expected call of GetUserById.
-func (mr *MockUserMockRecorder) GetUserById(userId interface{}) *gomock.Call {
+// GetUserByTgUserId indicates an expected call of GetUserByTgUserId.
+func (mr *MockUserMockRecorder) GetUserByTgUserId(ctx, tgUserId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserById", reflect.TypeOf((*MockUser)(nil).GetUserById), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByTgUserId", reflect.TypeOf((*MockUser)(nil).GetUserByTgUserId), ctx, tgUserId)
 }
 
-// GetUserByTgUsername mocks base method.
-func (m *MockUser) GetUserByTgUsername(TGusername string) (models.GetUser, error) {
+// GetUserByUsername mocks base method.
+func (m *MockUser) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByTgUsername", TGusername)
-	ret0, _ := ret[0].(models.GetUser)
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockUserMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUser)(nil).GetUserByUsername), ctx, username)
+}
+
+// PurgeDeletedUsers mocks base method.
+func (m *MockUser) PurgeDeletedUsers(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedUsers", ctx)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByTgUsername indicates an expected call of GetUserByTgUsername.
-func (mr *MockUserMockRecorder) GetUserByTgUsername(TGusername interface{}) *gomock.Call {
+// PurgeDeletedUsers indicates an expected call of PurgeDeletedUsers.
+func (mr *MockUserMockRecorder) PurgeDeletedUsers(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByTgUsername", reflect.TypeOf((*MockUser)(nil).GetUserByTgUsername), TGusername)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedUsers", reflect.TypeOf((*MockUser)(nil).PurgeDeletedUsers), ctx)
 }
 
 // MockHabit is a mock of Habit interface.
@@ -631,76 +1552,78 @@ func (m *MockHabit) EXPECT() *MockHabitMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockHabit) Create(userId int, habit models.Habit) (int, error) {
+func (m *MockHabit) Create(ctx context.Context, userId int, habit models.Habit) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", userId, habit)
+	ret := m.ctrl.Call(m, "Create", ctx, userId, habit)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockHabitMockRecorder) Create(userId, habit interface{}) *gomock.Call {
+func (mr *MockHabitMockRecorder) Create(ctx, userId, habit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHabit)(nil).Create), userId, habit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHabit)(nil).Create), ctx, userId, habit)
 }
 
 // Delete mocks base method.
-func (m *MockHabit) Delete(userId, habitId int) error {
+func (m *MockHabit) Delete(ctx context.Context, userId, habitId int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", userId, habitId)
+	ret := m.ctrl.Call(m, "Delete", ctx, userId, habitId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockHabitMockRecorder) Delete(userId, habitId interface{}) *gomock.Call {
+func (mr *MockHabitMockRecorder) Delete(ctx, userId, habitId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHabit)(nil).Delete), userId, habitId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHabit)(nil).Delete), ctx, userId, habitId)
 }
 
 // GetAll mocks base method.
-func (m *MockHabit) GetAll(userId int) ([]models.Habit, error) {
+func (m *MockHabit) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.Habit, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", userId)
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId, params)
 	ret0, _ := ret[0].([]models.Habit)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockHabitMockRecorder) GetAll(userId interface{}) *gomock.Call {
+func (mr *MockHabitMockRecorder) GetAll(ctx, userId, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockHabit)(nil).GetAll), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockHabit)(nil).GetAll), ctx, userId, params)
 }
 
 // GetById mocks base method.
-func (m *MockHabit) GetById(userId, habitId int) (models.Habit, error) {
+func (m *MockHabit) GetById(ctx context.Context, userId, habitId int) (models.Habit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", userId, habitId)
+	ret := m.ctrl.Call(m, "GetById", ctx, userId, habitId)
 	ret0, _ := ret[0].(models.Habit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockHabitMockRecorder) GetById(userId, habitId interface{}) *gomock.Call {
+func (mr *MockHabitMockRecorder) GetById(ctx, userId, habitId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockHabit)(nil).GetById), userId, habitId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockHabit)(nil).GetById), ctx, userId, habitId)
 }
 
 // Update mocks base method.
-func (m *MockHabit) Update(userId, habitId int, input models.UpdateHabitInput) error {
+func (m *MockHabit) Update(ctx context.Context, userId, habitId int, input models.UpdateHabitInput) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", userId, habitId, input)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Update", ctx, userId, habitId, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockHabitMockRecorder) Update(userId, habitId, input interface{}) *gomock.Call {
+func (mr *MockHabitMockRecorder) Update(ctx, userId, habitId, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockHabit)(nil).Update), userId, habitId, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockHabit)(nil).Update), ctx, userId, habitId, input)
 }
 
 // MockHabitTracker is a mock of HabitTracker interface.
@@ -727,47 +1650,49 @@ func (m *MockHabitTracker) EXPECT() *MockHabitTrackerMockRecorder {
 }
 
 // GetAll mocks base method.
-func (m *MockHabitTracker) GetAll(userId int) ([]models.HabitTracker, error) {
+func (m *MockHabitTracker) GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.HabitTracker, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", userId)
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId, params)
 	ret0, _ := ret[0].([]models.HabitTracker)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockHabitTrackerMockRecorder) GetAll(userId interface{}) *gomock.Call {
+func (mr *MockHabitTrackerMockRecorder) GetAll(ctx, userId, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockHabitTracker)(nil).GetAll), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockHabitTracker)(nil).GetAll), ctx, userId, params)
 }
 
 // GetById mocks base method.
-func (m *MockHabitTracker) GetById(userId, habitId int) (models.HabitTracker, error) {
+func (m *MockHabitTracker) GetById(ctx context.Context, userId, habitId int) (models.HabitTracker, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", userId, habitId)
+	ret := m.ctrl.Call(m, "GetById", ctx, userId, habitId)
 	ret0, _ := ret[0].(models.HabitTracker)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockHabitTrackerMockRecorder) GetById(userId, habitId interface{}) *gomock.Call {
+func (mr *MockHabitTrackerMockRecorder) GetById(ctx, userId, habitId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockHabitTracker)(nil).GetById), userId, habitId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockHabitTracker)(nil).GetById), ctx, userId, habitId)
 }
 
 // Update mocks base method.
-func (m *MockHabitTracker) Update(userId, habitId int, input models.UpdateTrackerInput) error {
+func (m *MockHabitTracker) Update(ctx context.Context, userId, habitId int, input models.UpdateTrackerInput) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", userId, habitId, input)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Update", ctx, userId, habitId, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockHabitTrackerMockRecorder) Update(userId, habitId, input interface{}) *gomock.Call {
+func (mr *MockHabitTrackerMockRecorder) Update(ctx, userId, habitId, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockHabitTracker)(nil).Update), userId, habitId, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockHabitTracker)(nil).Update), ctx, userId, habitId, input)
 }
 
 // MockReward is a mock of Reward interface.
@@ -794,31 +1719,124 @@ func (m *MockReward) EXPECT() *MockRewardMockRecorder {
 }
 
 // GetAllPersonalRewards mocks base method.
-func (m *MockReward) GetAllPersonalRewards(userId int) ([]models.Reward, error) {
+func (m *MockReward) GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllPersonalRewards", userId)
+	ret := m.ctrl.Call(m, "GetAllPersonalRewards", ctx, userId, params)
 	ret0, _ := ret[0].([]models.Reward)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetAllPersonalRewards indicates an expected call of GetAllPersonalRewards.
-func (mr *MockRewardMockRecorder) GetAllPersonalRewards(userId interface{}) *gomock.Call {
+func (mr *MockRewardMockRecorder) GetAllPersonalRewards(ctx, userId, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPersonalRewards", reflect.TypeOf((*MockReward)(nil).GetAllPersonalRewards), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPersonalRewards", reflect.TypeOf((*MockReward)(nil).GetAllPersonalRewards), ctx, userId, params)
 }
 
 // GetPersonalRewardsByHabitId mocks base method.
-func (m *MockReward) GetPersonalRewardsByHabitId(userId, habitId int) ([]models.Reward, error) {
+func (m *MockReward) GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPersonalRewardsByHabitId", userId, habitId)
+	ret := m.ctrl.Call(m, "GetPersonalRewardsByHabitId", ctx, userId, habitId)
 	ret0, _ := ret[0].([]models.Reward)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPersonalRewardsByHabitId indicates an expected call of GetPersonalRewardsByHabitId.
-func (mr *MockRewardMockRecorder) GetPersonalRewardsByHabitId(userId, habitId interface{}) *gomock.Call {
+func (mr *MockRewardMockRecorder) GetPersonalRewardsByHabitId(ctx, userId, habitId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalRewardsByHabitId", reflect.TypeOf((*MockReward)(nil).GetPersonalRewardsByHabitId), ctx, userId, habitId)
+}
+
+// MockSearch is a mock of Search interface.
+type MockSearch struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchMockRecorder
+}
+
+// MockSearchMockRecorder is the mock recorder for MockSearch.
+type MockSearchMockRecorder struct {
+	mock *MockSearch
+}
+
+// NewMockSearch creates a new mock instance.
+func NewMockSearch(ctrl *gomock.Controller) *MockSearch {
+	mock := &MockSearch{ctrl: ctrl}
+	mock.recorder = &MockSearchMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearch) EXPECT() *MockSearchMockRecorder {
+	return m.recorder
+}
+
+// Search mocks base method.
+func (m *MockSearch) Search(ctx context.Context, userId int, input models.SearchInput) (models.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, userId, input)
+	ret0, _ := ret[0].(models.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockSearchMockRecorder) Search(ctx, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockSearch)(nil).Search), ctx, userId, input)
+}
+
+// SearchAll mocks base method.
+func (m *MockSearch) SearchAll(ctx context.Context, input models.SearchInput) (models.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAll", ctx, input)
+	ret0, _ := ret[0].(models.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAll indicates an expected call of SearchAll.
+func (mr *MockSearchMockRecorder) SearchAll(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAll", reflect.TypeOf((*MockSearch)(nil).SearchAll), ctx, input)
+}
+
+// MockAudit is a mock of Audit interface.
+type MockAudit struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditMockRecorder
+}
+
+// MockAuditMockRecorder is the mock recorder for MockAudit.
+type MockAuditMockRecorder struct {
+	mock *MockAudit
+}
+
+// NewMockAudit creates a new mock instance.
+func NewMockAudit(ctrl *gomock.Controller) *MockAudit {
+	mock := &MockAudit{ctrl: ctrl}
+	mock.recorder = &MockAuditMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAudit) EXPECT() *MockAuditMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method.
+func (m *MockAudit) GetAll(ctx context.Context, params models.ListParams) ([]models.AuditEntry, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, params)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockAuditMockRecorder) GetAll(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalRewardsByHabitId", reflect.TypeOf((*MockReward)(nil).GetPersonalRewardsByHabitId), userId, habitId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAudit)(nil).GetAll), ctx, params)
 }
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	purposeOIDCFlow = "oidc_flow"
+	oidcFlowTTL     = 10 * time.Minute
+	oidcTimeout     = 10 * time.Second
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	// ErrOIDCFlowInvalid means the callback does not belong to a sign in started by the client
+	ErrOIDCFlowInvalid = errors.New("sign in flow is invalid or expired")
+)
+
+// oidcClient is a discovered identity provider
+type oidcClient struct {
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// oidcClaims are the claims of an ID token used to create a user
+type oidcClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+}
+
+type OIDCService struct {
+	tokenIssuer
+	users      repository.User
+	identities repository.Identity
+	providers  map[string]config.OIDCProvider
+
+	mu      sync.Mutex
+	clients map[string]*oidcClient
+}
+
+func NewOIDCService(users repository.User, identities repository.Identity, tokenRepo repository.Token, authCfg config.Auth, cfg config.OIDC) OIDC {
+	return &OIDCService{
+		tokenIssuer: tokenIssuer{tokenRepo: tokenRepo, cfg: authCfg},
+		users:       users,
+		identities:  identities,
+		providers:   cfg.Providers,
+		clients:     make(map[string]*oidcClient),
+	}
+}
+
+/*
+AuthURL starts the authorization code flow with PKCE. It returns the
+address of the provider to redirect a client to and the flow state.
+The state is signed and has to come back along with the callback,
+it keeps the PKCE verifier and the nonce on the client side
+*/
+func (s *OIDCService) AuthURL(ctx context.Context, provider string) (string, string, error) {
+	const op = "service.oidc_service.AuthURL"
+
+	client, err := s.client(ctx, provider)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	state, err := randomHex(16)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	nonce, err := randomHex(16)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	codeVerifier, err := randomHex(32)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+
+	flowState, err := signToken(s.cfg, jwt.MapClaims{
+		"purpose":  purposeOIDCFlow,
+		"provider": provider,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": codeVerifier,
+		"iat":      now.Unix(),
+		"exp":      now.Add(oidcFlowTTL).Unix(),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	authURL := client.oauth.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	return authURL, flowState, nil
+}
+
+/*
+Callback finishes the flow: exchanges the code, verifies the ID token and
+signs in the user linked to the identity. An identity seen for the first
+time is linked to a user with the same verified email or to a new user
+*/
+func (s *OIDCService) Callback(ctx context.Context, provider, code, state, flowState string) (models.Tokens, error) {
+	const op = "service.oidc_service.Callback"
+
+	var tokens models.Tokens
+
+	client, err := s.client(ctx, provider)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	flow, err := s.parseFlowState(flowState)
+	if err != nil || flow["provider"] != provider || flow["state"] != state {
+		return tokens, fmt.Errorf("%s: %w", op, ErrOIDCFlowInvalid)
+	}
+
+	codeVerifier, _ := flow["verifier"].(string)
+
+	ctx, cancel := context.WithTimeout(ctx, oidcTimeout)
+	defer cancel()
+
+	oauthToken, err := client.oauth.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	if err != nil {
+		return tokens, fmt.Errorf("%s: failed to exchange code: %w", op, err)
+	}
+
+	rawIDToken, ok := oauthToken.Extra("id_token").(string)
+	if !ok {
+		return tokens, fmt.Errorf("%s: %w", op, errors.New("no id_token in token response"))
+	}
+
+	idToken, err := client.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if idToken.Nonce != flow["nonce"] {
+		return tokens, fmt.Errorf("%s: %w", op, ErrOIDCFlowInvalid)
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	userId, err := s.identityUser(ctx, provider, idToken.Subject, claims)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := s.users.GetUserById(ctx, userId)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.DeletedAt != nil {
+		return tokens, fmt.Errorf("%s: %w", op, ErrAccountDeleted)
+	}
+
+	tokens, err = s.newTokens(ctx, user.Id, user.Role)
+	if err != nil {
+		return tokens, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+/*
+identityUser returns the user linked to an identity and links one if
+there is none. An existing user is linked only if both the provider and
+the user have confirmed the email, otherwise anyone could take over
+an account by registering its email at some provider
+*/
+func (s *OIDCService) identityUser(ctx context.Context, provider, subject string, claims oidcClaims) (int, error) {
+	if userId, err := s.identities.GetUserId(ctx, provider, subject); err == nil {
+		return userId, nil
+	}
+
+	userId := 0
+
+	if claims.Email != "" && claims.EmailVerified {
+		if user, err := s.users.GetUserByEmail(ctx, claims.Email); err == nil {
+			if verified, err := s.users.IsEmailVerified(ctx, user.Id); err == nil && verified {
+				userId = user.Id
+			}
+		}
+	}
+
+	if userId == 0 {
+		var err error
+		userId, err = s.createUser(ctx, provider, subject, claims)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	identity := models.Identity{
+		UserId:   userId,
+		Provider: provider,
+		Subject:  subject,
+		Email:    claims.Email,
+	}
+
+	if _, err := s.identities.Create(ctx, identity); err != nil {
+		return 0, err
+	}
+
+	return userId, nil
+}
+
+/*
+createUser creates a user without a password for an identity. If the
+preferred user name or the email is taken, a user name with a random
+suffix and no email is used
+*/
+func (s *OIDCService) createUser(ctx context.Context, provider, subject string, claims oidcClaims) (int, error) {
+	username := claims.PreferredUsername
+	if username == "" {
+		username, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if username == "" {
+		username = provider + "_" + subject
+	}
+
+	user := models.User{
+		Username:  username,
+		FirstName: claims.GivenName,
+		LastName:  claims.FamilyName,
+		Email:     claims.Email,
+	}
+
+	userId, err := s.users.CreateUser(ctx, user)
+	if err != nil {
+		suffix, err := randomHex(3)
+		if err != nil {
+			return 0, err
+		}
+
+		user.Username = username + "_" + suffix
+		user.Email = ""
+
+		userId, err = s.users.CreateUser(ctx, user)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if user.Email != "" && claims.EmailVerified {
+		if err := s.users.SetEmailVerified(ctx, userId); err != nil {
+			return 0, err
+		}
+	}
+
+	return userId, nil
+}
+
+func (s *OIDCService) parseFlowState(flowState string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(flowState, signingKeyFunc(s.cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != purposeOIDCFlow {
+		return nil, ErrOIDCFlowInvalid
+	}
+
+	return claims, nil
+}
+
+/*
+client returns a provider client. Discovery is done on the first use,
+so the app starts even if a provider is not available
+*/
+func (s *OIDCService) client(ctx context.Context, provider string) (*oidcClient, error) {
+	providerCfg, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client, ok := s.clients[provider]; ok {
+		return client, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, oidcTimeout)
+	defer cancel()
+
+	discovered, err := oidc.NewProvider(ctx, providerCfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", provider, err)
+	}
+
+	client := &oidcClient{
+		oauth: oauth2.Config{
+			ClientID:     providerCfg.ClientID,
+			ClientSecret: providerCfg.ClientSecret,
+			Endpoint:     discovered.Endpoint(),
+			RedirectURL:  providerCfg.RedirectURL,
+			Scopes:       append([]string{oidc.ScopeOpenID}, providerCfg.Scopes...),
+		},
+		verifier: discovered.Verifier(&oidc.Config{ClientID: providerCfg.ClientID}),
+	}
+
+	s.clients[provider] = client
+
+	return client, nil
+}
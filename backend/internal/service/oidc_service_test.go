@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	testOIDCProvider = "test"
+	testOIDCClientId = "habit-tracker"
+	testOIDCCode     = "auth-code"
+)
+
+/*
+testIdentityProvider serves the discovery document, the keys and the token
+endpoint of an OpenID provider. The token endpoint checks the PKCE verifier
+against the challenge of the last authorization and returns an ID token
+with the claims set by a test
+*/
+type testIdentityProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// challenge and nonce are taken from the authorization URL
+	challenge string
+	nonce     string
+
+	subject string
+	claims  oidcClaims
+}
+
+func newTestIdentityProvider(t *testing.T) *testIdentityProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	p := &testIdentityProvider{t: t, key: key, subject: "subject-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/keys", p.keys)
+	mux.HandleFunc("/token", p.token)
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *testIdentityProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                p.server.URL,
+		"authorization_endpoint":                p.server.URL + "/auth",
+		"token_endpoint":                        p.server.URL + "/token",
+		"jwks_uri":                              p.server.URL + "/keys",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *testIdentityProvider) keys(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *testIdentityProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != testOIDCCode {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != p.challenge {
+		http.Error(w, `{"error":"invalid_grant","error_description":"code verifier does not match"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                p.server.URL,
+		"aud":                testOIDCClientId,
+		"sub":                p.subject,
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Hour).Unix(),
+		"nonce":              p.nonce,
+		"email":              p.claims.Email,
+		"email_verified":     p.claims.EmailVerified,
+		"preferred_username": p.claims.PreferredUsername,
+	})
+	idToken.Header["kid"] = "key-1"
+
+	rawIDToken, err := idToken.SignedString(p.key)
+	if err != nil {
+		p.t.Errorf("failed to sign id token: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "provider-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     rawIDToken,
+	})
+}
+
+// authorize starts a flow and remembers its challenge and nonce as the provider does
+func (p *testIdentityProvider) authorize(s OIDC) (state, flowState string) {
+	p.t.Helper()
+
+	authURL, flowState, err := s.AuthURL(context.Background(), testOIDCProvider)
+	if err != nil {
+		p.t.Fatalf("failed to start the flow: %v", err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatalf("invalid auth url: %v", err)
+	}
+
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		p.t.Fatalf("expected the S256 code challenge, got %q", query.Get("code_challenge_method"))
+	}
+
+	p.challenge = query.Get("code_challenge")
+	p.nonce = query.Get("nonce")
+
+	return query.Get("state"), flowState
+}
+
+func newTestOIDCService(p *testIdentityProvider, repos *repository.Repository) OIDC {
+	cfg := config.OIDC{Providers: map[string]config.OIDCProvider{
+		testOIDCProvider: {
+			IssuerURL:   p.server.URL,
+			ClientID:    testOIDCClientId,
+			RedirectURL: "http://habit-tracker.test/callback",
+		},
+	}}
+
+	return NewOIDCService(repos.User, repos.Identity, repos.Token, testAuthConfig, cfg)
+}
+
+// createVerifiedUser creates a user with a confirmed email
+func createVerifiedUser(t *testing.T, repos *repository.Repository, userName string) int {
+	t.Helper()
+
+	userId := createTestUser(t, repos, userName)
+	if err := repos.User.SetEmailVerified(context.Background(), userId); err != nil {
+		t.Fatalf("failed to verify email: %v", err)
+	}
+
+	return userId
+}
+
+func Test_OIDCService_Callback(t *testing.T) {
+	ctx := context.Background()
+
+	testTable := []struct {
+		name string
+		// prepare sets the claims of the provider and returns the id of the user expected to be signed in
+		prepare func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int
+		// callback runs the callback of the flow, the provider sends the code to the state
+		callback func(s OIDC, p *testIdentityProvider) (models.Tokens, error)
+		// fails is set if the flow fails with an error which is not one of the service
+		fails         bool
+		expectedError error
+		// check runs on the repositories after a successful callback
+		check func(t *testing.T, repos *repository.Repository, userId int)
+	}{
+		{
+			name: "New User",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				p.claims = oidcClaims{Email: "runner@habit-tracker.test", EmailVerified: true, PreferredUsername: "runner"}
+				return 0
+			},
+			check: func(t *testing.T, repos *repository.Repository, userId int) {
+				user, err := repos.User.GetUserById(ctx, userId)
+				if err != nil || user.Username != "runner" || user.Email != "runner@habit-tracker.test" {
+					t.Errorf("unexpected user: %+v: %v", user, err)
+				}
+
+				if verified, _ := repos.User.IsEmailVerified(ctx, userId); !verified {
+					t.Errorf("Expected the email confirmed by the provider to be verified")
+				}
+			},
+		},
+		{
+			name: "Linked Identity",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				userId := createTestUser(t, repos, "runner")
+				if _, err := repos.Identity.Create(ctx, models.Identity{UserId: userId, Provider: testOIDCProvider, Subject: p.subject}); err != nil {
+					t.Fatalf("failed to link identity: %v", err)
+				}
+				p.claims = oidcClaims{Email: "other@habit-tracker.test", EmailVerified: true}
+				return userId
+			},
+		},
+		{
+			name: "Verified Email Is Linked",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				userId := createVerifiedUser(t, repos, "runner")
+				p.claims = oidcClaims{Email: "runner@habit-tracker.test", EmailVerified: true, PreferredUsername: "runner"}
+				return userId
+			},
+		},
+		{
+			name: "Unverified Email At Provider Is Not Linked",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				createVerifiedUser(t, repos, "runner")
+				p.claims = oidcClaims{Email: "runner@habit-tracker.test", EmailVerified: false, PreferredUsername: "runner"}
+				return 0
+			},
+			check: func(t *testing.T, repos *repository.Repository, userId int) {
+				user, err := repos.User.GetUserById(ctx, userId)
+				if err != nil || user.Username == "runner" || user.Email != "" {
+					t.Errorf("Expected a new user with a suffixed name and no email, got %+v: %v", user, err)
+				}
+			},
+		},
+		{
+			name: "Unverified Email Of User Is Not Linked",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				createTestUser(t, repos, "runner")
+				p.claims = oidcClaims{Email: "runner@habit-tracker.test", EmailVerified: true, PreferredUsername: "runner"}
+				return 0
+			},
+			check: func(t *testing.T, repos *repository.Repository, userId int) {
+				user, err := repos.User.GetUserById(ctx, userId)
+				if err != nil || user.Username == "runner" || user.Email != "" {
+					t.Errorf("Expected a new user with a suffixed name and no email, got %+v: %v", user, err)
+				}
+			},
+		},
+		{
+			name: "Deleted Account Is Not Linked",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				userId := createVerifiedUser(t, repos, "runner")
+				if _, err := repos.User.DeleteUser(ctx, userId); err != nil {
+					t.Fatalf("failed to delete user: %v", err)
+				}
+				p.claims = oidcClaims{Email: "runner@habit-tracker.test", EmailVerified: true, PreferredUsername: "runner"}
+				return 0
+			},
+			check: func(t *testing.T, repos *repository.Repository, userId int) {
+				deleted, _ := repos.User.GetUserByUsername(ctx, "runner")
+				if userId == deleted.Id {
+					t.Errorf("Expected the identity not to be linked to the deleted account")
+				}
+			},
+		},
+		{
+			name: "Deleted Account Of Linked Identity",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				userId := createVerifiedUser(t, repos, "runner")
+				if _, err := repos.Identity.Create(ctx, models.Identity{UserId: userId, Provider: testOIDCProvider, Subject: p.subject}); err != nil {
+					t.Fatalf("failed to link identity: %v", err)
+				}
+				if _, err := repos.User.DeleteUser(ctx, userId); err != nil {
+					t.Fatalf("failed to delete user: %v", err)
+				}
+				return 0
+			},
+			expectedError: ErrAccountDeleted,
+		},
+		{
+			name: "State Mismatch",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				return 0
+			},
+			callback: func(s OIDC, p *testIdentityProvider) (models.Tokens, error) {
+				_, flowState := p.authorize(s)
+				return s.Callback(ctx, testOIDCProvider, testOIDCCode, "forged-state", flowState)
+			},
+			expectedError: ErrOIDCFlowInvalid,
+		},
+		{
+			name: "Provider Mismatch",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				return 0
+			},
+			callback: func(s OIDC, p *testIdentityProvider) (models.Tokens, error) {
+				state, _ := p.authorize(s)
+				return s.Callback(ctx, testOIDCProvider, testOIDCCode, state, "forged-flow-state")
+			},
+			expectedError: ErrOIDCFlowInvalid,
+		},
+		{
+			name: "Nonce Mismatch",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				return 0
+			},
+			callback: func(s OIDC, p *testIdentityProvider) (models.Tokens, error) {
+				state, flowState := p.authorize(s)
+				p.nonce = "replayed-nonce"
+				return s.Callback(ctx, testOIDCProvider, testOIDCCode, state, flowState)
+			},
+			expectedError: ErrOIDCFlowInvalid,
+		},
+		{
+			name: "Wrong PKCE Verifier",
+			prepare: func(t *testing.T, repos *repository.Repository, p *testIdentityProvider) int {
+				return 0
+			},
+			callback: func(s OIDC, p *testIdentityProvider) (models.Tokens, error) {
+				// the code is sent with the verifier of another flow
+				state, flowState := p.authorize(s)
+				p.authorize(s)
+				return s.Callback(ctx, testOIDCProvider, testOIDCCode, state, flowState)
+			},
+			fails: true,
+		},
+	}
+
+	for _, testRepository := range testRepositories {
+		for _, testCase := range testTable {
+			t.Run(testRepository.name+" "+testCase.name, func(t *testing.T) {
+				repos := testRepository.new(t)
+				p := newTestIdentityProvider(t)
+				s := newTestOIDCService(p, repos)
+
+				expectedUserId := testCase.prepare(t, repos, p)
+
+				callback := testCase.callback
+				if callback == nil {
+					callback = func(s OIDC, p *testIdentityProvider) (models.Tokens, error) {
+						state, flowState := p.authorize(s)
+						return s.Callback(ctx, testOIDCProvider, testOIDCCode, state, flowState)
+					}
+				}
+
+				expectFailure := testCase.fails || testCase.expectedError != nil
+
+				tokens, err := callback(s, p)
+				if expectFailure {
+					if err == nil {
+						t.Fatalf("Expected an error but the user is signed in")
+					}
+					if testCase.expectedError != nil && !errors.Is(err, testCase.expectedError) {
+						t.Fatalf("Expected error %v but got %v", testCase.expectedError, err)
+					}
+					if tokens.AccessToken != "" {
+						t.Errorf("Expected no tokens but got some")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("failed to sign in: %v", err)
+				}
+
+				auth := newTestAuthService(repos)
+				userId, _, err := auth.ParseToken(ctx, tokens.AccessToken)
+				if err != nil {
+					t.Fatalf("failed to parse access token: %v", err)
+				}
+
+				if expectedUserId != 0 && userId != expectedUserId {
+					t.Errorf("Expected user %d to be signed in but got %d", expectedUserId, userId)
+				}
+
+				linkedUserId, err := repos.Identity.GetUserId(ctx, testOIDCProvider, p.subject)
+				if err != nil || linkedUserId != userId {
+					t.Errorf("Expected the identity to be linked to user %d but got %d: %v", userId, linkedUserId, err)
+				}
+
+				if testCase.check != nil {
+					testCase.check(t, repos, userId)
+				}
+			})
+		}
+	}
+}
+
+func Test_OIDCService_unknownProvider(t *testing.T) {
+	s := NewOIDCService(nil, nil, nil, testAuthConfig, config.OIDC{})
+
+	if _, _, err := s.AuthURL(context.Background(), "unknown"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Expected error %v but got %v", ErrUnknownProvider, err)
+	}
+
+	if _, err := s.Callback(context.Background(), "unknown", testOIDCCode, "state", "flow"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Expected error %v but got %v", ErrUnknownProvider, err)
+	}
+}
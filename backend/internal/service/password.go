@@ -0,0 +1,133 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2id parameters of newly created hashes
+const (
+	argonTime    = 1
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+const (
+	argonPrefix = "$argon2id$"
+	salt        = "lk6vm9vkf47#b@7kdn4nv" // salt of legacy SHA-1 hashes
+)
+
+var errInvalidHash = errors.New("invalid password hash format")
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+/*
+dummyPasswordHash is an argon2id hash of a random password. A password of
+an unknown user is checked against it, so a sign in takes as long
+as for a known user and does not tell which user names exist
+*/
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		password := make([]byte, argonSaltLen)
+		rand.Read(password)
+
+		dummyHash, _ = hashPassword(string(password))
+	})
+
+	return dummyHash
+}
+
+/*
+hashPassword hashes a password with argon2id and a random salt.
+The result is encoded in the PHC string format, so the parameters
+are stored along with the hash and can be changed later:
+$argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>
+*/
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argonPrefix, argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+/*
+verifyPassword compares a password with a stored hash. needsRehash
+is true if the password matches, but the hash is a legacy salted SHA-1
+one or was made with other argon2id parameters
+*/
+func verifyPassword(password, encodedHash string) (match bool, needsRehash bool, err error) {
+	if !strings.HasPrefix(encodedHash, argonPrefix) {
+		legacyHash := generatePasswordHash(password)
+		match = subtle.ConstantTimeCompare([]byte(legacyHash), []byte(encodedHash)) == 1
+
+		return match, match, nil
+	}
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 {
+		return false, false, errInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, false, errInvalidHash
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, false, errInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, errInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, errInvalidHash
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+
+	match = subtle.ConstantTimeCompare(key, otherKey) == 1
+	needsRehash = version != argon2.Version ||
+		memory != argonMemory || time != argonTime || threads != argonThreads ||
+		len(key) != argonKeyLen || len(salt) != argonSaltLen
+
+	return match, match && needsRehash, nil
+}
+
+/*
+generatePasswordHash is the legacy salted SHA-1 hash. It is kept
+only to verify passwords of users who have not signed in since
+the move to argon2id
+*/
+func generatePasswordHash(password string) string {
+	hash := sha1.New()
+	hash.Write([]byte(password))
+
+	return fmt.Sprintf("%x", hash.Sum([]byte(salt)))
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+var (
+	ErrInvalidPersonalToken  = errors.New("invalid personal access token")
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+	// ErrPersonalTokenRejected is returned when a token is unknown, expired or revoked
+	ErrPersonalTokenRejected = errors.New("personal access token is invalid, expired or revoked")
+)
+
+type PersonalTokenService struct {
+	repo  repository.PersonalToken
+	users repository.User
+	cfg   config.Auth
+}
+
+func NewPersonalTokenService(repo repository.PersonalToken, users repository.User, cfg config.Auth) PersonalToken {
+	return &PersonalTokenService{
+		repo:  repo,
+		users: users,
+		cfg:   cfg,
+	}
+}
+
+// Create issues a token, the returned value is the only place the token is shown
+func (s *PersonalTokenService) Create(ctx context.Context, userId int, input models.PersonalTokenInput) (models.NewPersonalToken, error) {
+	const op = "service.personal_token_service.Create"
+
+	var newToken models.NewPersonalToken
+
+	if err := input.Validate(); err != nil {
+		return newToken, fmt.Errorf("%s: %w: %v", op, ErrInvalidPersonalToken, err)
+	}
+
+	ttl := s.cfg.PersonalTokenTTL
+	if input.ExpiresInDays > 0 {
+		ttl = time.Duration(input.ExpiresInDays) * 24 * time.Hour
+	}
+
+	if ttl > s.cfg.PersonalTokenMaxTTL {
+		return newToken, fmt.Errorf("%s: %w: lifetime is longer than %v", op, ErrInvalidPersonalToken, s.cfg.PersonalTokenMaxTTL)
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return newToken, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rawToken := models.PersonalTokenPrefix + secret
+
+	token := models.PersonalToken{
+		UserId:    userId,
+		Name:      input.Name,
+		TokenHash: hashToken(rawToken),
+		Scopes:    input.Scopes,
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: time.Now(),
+	}
+
+	token.Id, err = s.repo.Create(ctx, token)
+	if err != nil {
+		return newToken, fmt.Errorf("%s: %w", op, err)
+	}
+
+	newToken.Token = rawToken
+	newToken.PersonalToken = token
+
+	return newToken, nil
+}
+
+func (s *PersonalTokenService) GetAll(ctx context.Context, userId int) ([]models.PersonalToken, error) {
+	const op = "service.personal_token_service.GetAll"
+
+	tokens, err := s.repo.GetAll(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+func (s *PersonalTokenService) Revoke(ctx context.Context, userId, tokenId int) error {
+	const op = "service.personal_token_service.Revoke"
+
+	revoked, err := s.repo.Revoke(ctx, userId, tokenId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !revoked {
+		return fmt.Errorf("%s: %w", op, ErrPersonalTokenNotFound)
+	}
+
+	return nil
+}
+
+/*
+Authenticate finds the token and the role of its owner. The time
+the token is used is recorded, see repository.PersonalToken.Touch
+*/
+func (s *PersonalTokenService) Authenticate(ctx context.Context, rawToken string) (models.PersonalToken, string, error) {
+	const op = "service.personal_token_service.Authenticate"
+
+	token, err := s.repo.GetByHash(ctx, hashToken(rawToken))
+	if err != nil {
+		return token, "", fmt.Errorf("%s: %w: %v", op, ErrPersonalTokenRejected, err)
+	}
+
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return token, "", fmt.Errorf("%s: %w", op, ErrPersonalTokenRejected)
+	}
+
+	user, err := s.users.GetUserById(ctx, token.UserId)
+	if err != nil {
+		return token, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.DeletedAt != nil {
+		return token, "", fmt.Errorf("%s: %w", op, ErrAccountDeleted)
+	}
+
+	if err := s.repo.Touch(ctx, token.Id); err != nil {
+		return token, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, user.Role, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/ratelimit"
+)
+
+const (
+	// signInKeyPrefix keeps keys of failed sign in attempts apart from route buckets
+	signInKeyPrefix = "sign-in|"
+	// addressRoute is the bucket of an address over all the api routes
+	addressRoute = "address"
+)
+
+type RateLimitService struct {
+	store ratelimit.Store
+	cfg   config.RateLimit
+}
+
+func NewRateLimitService(store ratelimit.Store, cfg config.RateLimit) RateLimit {
+	return &RateLimitService{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+/*
+Allow takes a request from the budget of the route for the key.
+If the budget is spent, it returns false and the time to wait
+*/
+func (s *RateLimitService) Allow(route, key string) (bool, time.Duration) {
+	if !s.cfg.Enabled {
+		return true, 0
+	}
+
+	rule, ok := s.cfg.Routes[route]
+	if !ok {
+		rule = s.cfg.Default
+	}
+
+	return s.take(route+"|"+key, rule)
+}
+
+/*
+AllowAddress takes a request from the budget of an address over all the
+api routes. It is taken before a token is checked, so a flood of invalid
+tokens is stopped before every token costs a lookup
+*/
+func (s *RateLimitService) AllowAddress(ip string) (bool, time.Duration) {
+	if !s.cfg.Enabled {
+		return true, 0
+	}
+
+	return s.take(addressRoute+"|ip:"+ip, s.cfg.Address)
+}
+
+func (s *RateLimitService) take(key string, rule config.RateLimitRule) (bool, time.Duration) {
+	if rule.Requests <= 0 || rule.Per <= 0 || rule.Burst <= 0 {
+		return true, 0
+	}
+
+	rate := float64(rule.Requests) / rule.Per.Seconds()
+
+	return s.store.Take(key, rate, rule.Burst)
+}
+
+/*
+signInKeys are the keys failed sign ins are counted by. The failures of
+a user name from an address lock it soon. The failures of a user name
+from all addresses lock it after more of them, so a password is not
+guessed from many addresses
+*/
+func (s *RateLimitService) signInKeys(userName, ip string) (string, string) {
+	userName = strings.ToLower(userName)
+
+	return signInKeyPrefix + userName + "|" + ip, signInKeyPrefix + "user:" + userName
+}
+
+// SignInLocked returns how long sign in is locked for the user name from the address, 0 if it is not
+func (s *RateLimitService) SignInLocked(userName, ip string) time.Duration {
+	if !s.cfg.Enabled {
+		return 0
+	}
+
+	addressKey, userKey := s.signInKeys(userName, ip)
+
+	lockedFor := s.store.Locked(addressKey)
+	if s.cfg.SignInLockout.UserThreshold > 0 {
+		lockedFor = maxDuration(lockedFor, s.store.Locked(userKey))
+	}
+
+	return lockedFor
+}
+
+// SignInFailed counts a failed sign in and returns how long the user name is locked for
+func (s *RateLimitService) SignInFailed(userName, ip string) time.Duration {
+	if !s.cfg.Enabled {
+		return 0
+	}
+
+	lockout := s.cfg.SignInLockout
+	addressKey, userKey := s.signInKeys(userName, ip)
+
+	lockedFor := s.store.Fail(addressKey, lockout.Threshold, lockout.BaseDelay, lockout.MaxDelay)
+	if lockout.UserThreshold > 0 {
+		lockedFor = maxDuration(lockedFor, s.store.Fail(userKey, lockout.UserThreshold, lockout.BaseDelay, lockout.MaxDelay))
+	}
+
+	return lockedFor
+}
+
+// SignInSucceeded forgets the failures of the user name, the password is known to the one signing in
+func (s *RateLimitService) SignInSucceeded(userName, ip string) {
+	addressKey, userKey := s.signInKeys(userName, ip)
+
+	s.store.Reset(addressKey)
+	s.store.Reset(userKey)
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+
+	return b
+}
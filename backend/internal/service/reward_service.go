@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
 )
@@ -13,10 +14,10 @@ func NewRewardService(repo repository.Reward) Reward {
 	return &RewardService{repo: repo}
 }
 
-func (r *RewardService) GetPersonalRewardsByHabitId(userId, habitId int) ([]models.Reward, error) {
-	return r.repo.GetPersonalRewardsByHabitId(userId, habitId)
+func (r *RewardService) GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error) {
+	return r.repo.GetPersonalRewardsByHabitId(ctx, userId, habitId)
 }
 
-func (r *RewardService) GetAllPersonalRewards(userId int) ([]models.Reward, error) {
-	return r.repo.GetAllPersonalRewards(userId)
+func (r *RewardService) GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error) {
+	return r.repo.GetAllPersonalRewards(ctx, userId, params)
 }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/errs"
+)
+
+type SearchService struct {
+	repo repository.Search
+}
+
+func NewSearchService(repo repository.Search) Search {
+	return &SearchService{repo: repo}
+}
+
+func (s *SearchService) Search(ctx context.Context, userId int, input models.SearchInput) (models.SearchResult, error) {
+	const op = "service.search_service.Search"
+
+	if err := input.Validate(); err != nil {
+		return models.SearchResult{}, fmt.Errorf("%s: %w", op, errs.Validation("invalid_search", err))
+	}
+
+	return s.repo.Search(ctx, userId, input)
+}
+
+func (s *SearchService) SearchAll(ctx context.Context, input models.SearchInput) (models.SearchResult, error) {
+	const op = "service.search_service.SearchAll"
+
+	if err := input.Validate(); err != nil {
+		return models.SearchResult{}, fmt.Errorf("%s: %w", op, errs.Validation("invalid_search", err))
+	}
+
+	return s.repo.SearchAll(ctx, input)
+}
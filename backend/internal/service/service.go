@@ -1,37 +1,122 @@
 package service
 
 import (
+	"context"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/mailer"
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/ratelimit"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/mock.go
 
 type Authorization interface {
-	GenerateToken(username, password string) (string, error)
-	ParseToken(token string) (int, string, error)
-	FindTgUser(tgUsername string) (models.GetUser, error)
+	GenerateToken(ctx context.Context, username, password string) (models.Tokens, error)
+	ParseToken(ctx context.Context, token string) (int, string, error)
+	FindTgUser(ctx context.Context, tgUserId int64) (models.GetUser, error)
+	SignUpTelegram(ctx context.Context, user models.User) (int, error)
+	Reactivate(ctx context.Context, username, password string) (models.Tokens, error)
+	Refresh(ctx context.Context, refreshToken string) (models.Tokens, error)
+	Logout(ctx context.Context, userId int, accessToken, refreshToken string) error
+	LogoutAll(ctx context.Context, userId int) error
+	ChangePassword(ctx context.Context, userId int, input models.ChangePasswordInput) error
+	PurgeExpiredTokens(ctx context.Context) (int, error)
+}
+
+// ServiceAuth checks requests other services of the app send on behalf of users
+type ServiceAuth interface {
+	Authenticate(ctx context.Context, req models.SignedRequest) error
+}
+
+// PersonalToken manages long-lived tokens users create for scripts
+type PersonalToken interface {
+	Create(ctx context.Context, userId int, input models.PersonalTokenInput) (models.NewPersonalToken, error)
+	GetAll(ctx context.Context, userId int) ([]models.PersonalToken, error)
+	Revoke(ctx context.Context, userId, tokenId int) error
+	Authenticate(ctx context.Context, token string) (models.PersonalToken, string, error)
+}
+
+// TelegramLink links a telegram chat to a web account
+type TelegramLink interface {
+	CreateLinkCode(ctx context.Context, userId int) (models.LinkCode, error)
+	Link(ctx context.Context, input models.TelegramLinkInput) (models.TelegramLink, error)
+}
+
+// RateLimit throttles clients and locks sign in after repeated failures. It works in memory, so it takes no context
+type RateLimit interface {
+	Allow(route, key string) (bool, time.Duration)
+	AllowAddress(ip string) (bool, time.Duration)
+	SignInLocked(userName, ip string) time.Duration
+	SignInFailed(userName, ip string) time.Duration
+	SignInSucceeded(userName, ip string)
+}
+
+// Idempotency makes mutating requests safe to retry with the same key
+type Idempotency interface {
+	Begin(ctx context.Context, userId int, key string, req models.IdempotentRequest) (models.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, userId int, key string, statusCode int, headers map[string]string, body []byte) error
+	Abandon(ctx context.Context, userId int, key string) error
 }
 
+// OIDC signs web users in with external identity providers
+type OIDC interface {
+	AuthURL(ctx context.Context, provider string) (authURL string, flowState string, err error)
+	Callback(ctx context.Context, provider, code, state, flowState string) (models.Tokens, error)
+}
+
+// Account covers the flows that confirm a user owns the email
+type Account interface {
+	SendVerification(ctx context.Context, userId int) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, input models.ResetPasswordInput) error
+	IsEmailVerified(ctx context.Context, userId int) (bool, error)
+}
+
+/*
+every mutating method of admin services takes models.AuditMeta
+and leaves a record in the audit log
+*/
+
 type AdminRole interface {
-	AssignRole(userId int, role models.UpdateRoleInput) (int, error)
+	AssignRole(ctx context.Context, meta models.AuditMeta, userId int, role models.UpdateRoleInput) (int, error)
+	GetAllRoles(ctx context.Context) ([]models.Role, error)
+	GetAllPermissions(ctx context.Context) ([]models.Permission, error)
+	CreateRole(ctx context.Context, meta models.AuditMeta, input models.RoleInput) error
+	UpdateRole(ctx context.Context, meta models.AuditMeta, name string, input models.RoleInput) error
+	DeleteRole(ctx context.Context, meta models.AuditMeta, name string) error
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+	UserRole(ctx context.Context, userId int) (string, error)
 }
 
 type AdminReward interface {
-	Create(reward models.Reward) (int, error)
-	GetById(rewardId int) (models.Reward, error)
-	GetAllRewards() ([]models.Reward, error)
-	Delete(rewardId int) error
-	UpdateReward(rewardId int, input models.UpdateRewardInput) error
+	Create(ctx context.Context, meta models.AuditMeta, reward models.Reward) (int, error)
+	GetById(ctx context.Context, rewardId int) (models.Reward, error)
+	GetAllRewards(ctx context.Context, params models.ListParams) ([]models.Reward, int, error)
+	Delete(ctx context.Context, meta models.AuditMeta, rewardId int) error
+	UpdateReward(ctx context.Context, meta models.AuditMeta, rewardId int, input models.UpdateRewardInput) error
 }
 
 type AdminUserReward interface {
-	AssignReward(userId, habitId, rewardId int) (int, error)
-	RemoveFromUser(userId, habitId, rewardId int) error
-	UpdateUserReward(userId, habitId, rewardId int, input models.UpdateUserRewardInput) error
+	AssignReward(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int) (int, error)
+	RemoveFromUser(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int) error
+	UpdateUserReward(ctx context.Context, meta models.AuditMeta, userId, habitId, rewardId int, input models.UpdateUserRewardInput) error
 	Reward
 }
 
+// AdminUser is used when an admin manages habits and the account of a certain user
+type AdminUser interface {
+	CreateHabit(ctx context.Context, meta models.AuditMeta, userId int, habit models.Habit) (int, error)
+	UpdateHabit(ctx context.Context, meta models.AuditMeta, userId, habitId int, input models.UpdateHabitInput) (int, error)
+	DeleteHabit(ctx context.Context, meta models.AuditMeta, userId, habitId int) error
+	UpdateTracker(ctx context.Context, meta models.AuditMeta, userId, habitId int, input models.UpdateTrackerInput) (int, error)
+	DeleteUser(ctx context.Context, meta models.AuditMeta, userId int) (int, error)
+	RestoreUser(ctx context.Context, meta models.AuditMeta, userId int) (int, error)
+}
+
 type Admin interface {
 	AdminRole
 	AdminReward
@@ -39,57 +124,87 @@ type Admin interface {
 }
 
 type User interface {
-	CreateUser(user models.User) (int, error)
-	GetUser(username, password string) (models.User, error)
-	GetUserByTgUsername(TGusername string) (models.GetUser, error)
-	GetUserById(userId int) (models.GetUser, error)
-	GetAllUsers() ([]models.GetUser, error)
-	DeleteUser(userId int) (int, error)
+	CreateUser(ctx context.Context, user models.User) (int, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	GetUserByTgUserId(ctx context.Context, tgUserId int64) (models.GetUser, error)
+	GetUserById(ctx context.Context, userId int) (models.GetUser, error)
+	GetAllUsers(ctx context.Context, params models.ListParams) ([]models.GetUser, int, error)
+	DeleteUser(ctx context.Context, userId int) (int, error)
+	PurgeDeletedUsers(ctx context.Context) (int, error)
 }
 
 type Habit interface {
-	Create(userId int, habit models.Habit) (int, error)
-	GetAll(userId int) ([]models.Habit, error)
-	GetById(userId, habitId int) (models.Habit, error)
-	Delete(userId, habitId int) error
-	Update(userId, habitId int, input models.UpdateHabitInput) error
+	Create(ctx context.Context, userId int, habit models.Habit) (int, error)
+	GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.Habit, int, error)
+	GetById(ctx context.Context, userId, habitId int) (models.Habit, error)
+	Delete(ctx context.Context, userId, habitId int) error
+	Update(ctx context.Context, userId, habitId int, input models.UpdateHabitInput) (int, error)
 }
 
 type HabitTracker interface {
 	// Create(userHabitId int, tracker habit.HabitTracker) (int, error) // temporarily disabled
-	GetAll(userId int) ([]models.HabitTracker, error)
-	GetById(userId, habitId int) (models.HabitTracker, error)
+	GetAll(ctx context.Context, userId int, params models.ListParams) ([]models.HabitTracker, int, error)
+	GetById(ctx context.Context, userId, habitId int) (models.HabitTracker, error)
 	// Delete(userId, habitId int) error // temporarily disabled
-	Update(userId, habitId int, input models.UpdateTrackerInput) error
+	Update(ctx context.Context, userId, habitId int, input models.UpdateTrackerInput) (int, error)
 }
 
 type Reward interface {
-	GetPersonalRewardsByHabitId(userId, habitId int) ([]models.Reward, error)
-	GetAllPersonalRewards(userId int) ([]models.Reward, error)
+	GetPersonalRewardsByHabitId(ctx context.Context, userId, habitId int) ([]models.Reward, error)
+	GetAllPersonalRewards(ctx context.Context, userId int, params models.ListParams) ([]models.Reward, int, error)
+}
+
+type Search interface {
+	Search(ctx context.Context, userId int, input models.SearchInput) (models.SearchResult, error)
+	SearchAll(ctx context.Context, input models.SearchInput) (models.SearchResult, error)
+}
+
+type Audit interface {
+	GetAll(ctx context.Context, params models.ListParams) ([]models.AuditEntry, int, error)
 }
 
 type Service struct {
 	Authorization
+	ServiceAuth
+	PersonalToken
+	RateLimit
+	Idempotency
+	TelegramLink
+	OIDC
+	Account
 	AdminRole
 	AdminReward
 	AdminUserReward
+	AdminUser
 	Admin
 	User
 	Habit
 	HabitTracker
 	Reward
+	Search
+	Audit
 }
 
-func NewService(repos *repository.Repository) *Service {
+func NewService(repos *repository.Repository, cfg *config.Config, mailer mailer.Mailer, limits ratelimit.Store) *Service {
 	return &Service{
-		Authorization:   NewAuthService(repos.User),
-		AdminRole:       NewAdminRoleService(repos.AdminRole),
-		AdminReward:     NewAdminRewardService(repos.AdminReward),
-		AdminUserReward: NewAdminUserRewardService(repos.AdminUserReward),
+		Authorization:   NewAuthService(repos.Transactor, repos.User, repos.Token, repos.Audit, cfg.Account.DeletionGracePeriod, cfg.Auth),
+		ServiceAuth:     NewServiceAuthService(repos.Token, cfg.ServiceAuth),
+		PersonalToken:   NewPersonalTokenService(repos.PersonalToken, repos.User, cfg.Auth),
+		RateLimit:       NewRateLimitService(limits, cfg.RateLimit),
+		Idempotency:     NewIdempotencyService(repos.Idempotency, cfg.Idempotency),
+		TelegramLink:    NewTelegramLinkService(repos.Transactor, repos.TelegramLink, repos.User, repos.Audit, cfg.Telegram),
+		OIDC:            NewOIDCService(repos.User, repos.Identity, repos.Token, cfg.Auth, cfg.OIDC),
+		Account:         NewAccountService(repos.User, repos.Token, mailer, cfg.Auth, cfg.Mail.AppURL),
+		AdminRole:       NewAdminRoleService(repos.Transactor, repos.AdminRole, repos.User, repos.Audit),
+		AdminReward:     NewAdminRewardService(repos.Transactor, repos.AdminReward, repos.Audit),
+		AdminUserReward: NewAdminUserRewardService(repos.Transactor, repos.AdminUserReward, repos.Audit),
+		AdminUser:       NewAdminUserService(repos.Transactor, repos.Habit, repos.HabitTracker, repos.User, repos.Token, repos.Audit, cfg.Account.DeletionGracePeriod),
 		Admin:           NewAdminService(repos.Admin),
-		User:            NewUserService(repos.User),
+		User:            NewUserService(repos.Transactor, repos.User, repos.Token, repos.Audit, cfg.Account.DeletionGracePeriod),
 		Habit:           NewHabitService(repos.Habit),
 		HabitTracker:    NewHabitTrackerService(repos.HabitTracker),
 		Reward:          NewRewardService(repos.Reward),
+		Search:          NewSearchService(repos.Search),
+		Audit:           NewAuditService(repos.Audit),
 	}
 }
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/svcauth"
+)
+
+const maxNonceLength = 64
+
+// ErrServiceUnauthorized is returned when a request is not signed by a known service
+var ErrServiceUnauthorized = errors.New("service request is not authorized")
+
+type ServiceAuthService struct {
+	tokenRepo repository.Token
+	cfg       config.ServiceAuth
+}
+
+func NewServiceAuthService(tokenRepo repository.Token, cfg config.ServiceAuth) ServiceAuth {
+	return &ServiceAuthService{
+		tokenRepo: tokenRepo,
+		cfg:       cfg,
+	}
+}
+
+/*
+Authenticate checks the signature of a request made by another service.
+The timestamp has to be close to the server time and the nonce is
+remembered until the timestamp is too old, so a captured request
+can't be sent again
+*/
+func (s *ServiceAuthService) Authenticate(ctx context.Context, req models.SignedRequest) error {
+	const op = "service.service_auth_service.Authenticate"
+
+	secret, ok := s.cfg.Keys[req.ServiceId]
+	if !ok || secret == "" {
+		return fmt.Errorf("%s: %w: unknown service %q", op, ErrServiceUnauthorized, req.ServiceId)
+	}
+
+	unixTime, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: %w: invalid timestamp", op, ErrServiceUnauthorized)
+	}
+
+	signedAt := time.Unix(unixTime, 0)
+
+	if skew := time.Since(signedAt); skew > s.cfg.MaxClockSkew || skew < -s.cfg.MaxClockSkew {
+		return fmt.Errorf("%s: %w: timestamp is out of range", op, ErrServiceUnauthorized)
+	}
+
+	if req.Nonce == "" || len(req.Nonce) > maxNonceLength {
+		return fmt.Errorf("%s: %w: invalid nonce", op, ErrServiceUnauthorized)
+	}
+
+	canonical := svcauth.Canonical(req.Method, req.RequestURI, req.Timestamp, req.Nonce, req.Body)
+
+	if !svcauth.Verify(secret, canonical, req.Signature) {
+		return fmt.Errorf("%s: %w: invalid signature", op, ErrServiceUnauthorized)
+	}
+
+	// nonces are kept along with used one-time tokens, the prefix keeps them apart
+	fresh, err := s.tokenRepo.UseOneTimeToken(ctx, "nonce:"+req.ServiceId+":"+req.Nonce, signedAt.Add(s.cfg.MaxClockSkew))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !fresh {
+		return fmt.Errorf("%s: %w: nonce is already used", op, ErrServiceUnauthorized)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+)
+
+const (
+	// linkCodeAlphabet has no characters that are easy to mix up, like 0 and O
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	linkCodeLength   = 8
+	// linkPayloadPrefix marks a /start payload of a deep link as a link code
+	linkPayloadPrefix = "link_"
+)
+
+var (
+	ErrLinkCodeInvalid = errors.New("link code is invalid or expired")
+	// ErrTelegramAlreadyLinked means the user has another telegram account linked
+	ErrTelegramAlreadyLinked = errors.New("another telegram account is already linked to the user")
+	// ErrTelegramLinkedElsewhere means the telegram account belongs to another web account
+	ErrTelegramLinkedElsewhere = errors.New("telegram account is linked to another user")
+)
+
+type TelegramLinkService struct {
+	tx    repository.Transactor
+	repo  repository.TelegramLink
+	users repository.User
+	audit repository.Audit
+	cfg   config.Telegram
+}
+
+func NewTelegramLinkService(tx repository.Transactor, repo repository.TelegramLink, users repository.User, audit repository.Audit, cfg config.Telegram) TelegramLink {
+	return &TelegramLinkService{
+		tx:    tx,
+		repo:  repo,
+		users: users,
+		audit: audit,
+		cfg:   cfg,
+	}
+}
+
+/*
+CreateLinkCode issues a code a web user sends to the bot. Only the hash
+of the code is stored and a new code replaces the previous one
+*/
+func (s *TelegramLinkService) CreateLinkCode(ctx context.Context, userId int) (models.LinkCode, error) {
+	const op = "service.telegram_link_service.CreateLinkCode"
+
+	var linkCode models.LinkCode
+
+	code, err := randomCode(linkCodeLength)
+	if err != nil {
+		return linkCode, fmt.Errorf("%s: %w", op, err)
+	}
+
+	expiresAt := time.Now().Add(s.cfg.LinkCodeTTL)
+
+	if err := s.repo.CreateCode(ctx, userId, hashToken(code), expiresAt); err != nil {
+		return linkCode, fmt.Errorf("%s: %w", op, err)
+	}
+
+	linkCode.Code = code
+	linkCode.ExpiresAt = expiresAt
+
+	if s.cfg.BotName != "" {
+		linkCode.DeepLink = "https://t.me/" + s.cfg.BotName + "?start=" + linkPayloadPrefix + code
+	}
+
+	return linkCode, nil
+}
+
+/*
+Link binds the telegram account of the bot user to the web account
+the code was issued to. If the telegram user already has an account
+made by the bot, its habits are merged into the web account.
+The code is used in the transaction of the link, so it keeps working
+if the link fails
+*/
+func (s *TelegramLinkService) Link(ctx context.Context, input models.TelegramLinkInput) (models.TelegramLink, error) {
+	const op = "service.telegram_link_service.Link"
+
+	var link models.TelegramLink
+
+	code := strings.ToUpper(strings.TrimSpace(input.Code))
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		userId, err := s.repo.UseCode(ctx, hashToken(code))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrLinkCodeInvalid, err)
+		}
+
+		user, err := s.users.GetUserById(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		if user.DeletedAt != nil {
+			return ErrAccountDeleted
+		}
+
+		if user.TgUserId != 0 && user.TgUserId != input.TgUserId {
+			return ErrTelegramAlreadyLinked
+		}
+
+		mergeUserId := 0
+
+		if tgUser, err := s.users.GetUserByTgUserId(ctx, input.TgUserId); err == nil && tgUser.Id != userId {
+			// an account with web credentials is never merged, it has an owner of its own
+			if tgUser.Username != "" || tgUser.Email != "" {
+				return ErrTelegramLinkedElsewhere
+			}
+
+			mergeUserId = tgUser.Id
+		}
+
+		if err := s.repo.Link(ctx, userId, mergeUserId, input.TgUserId, input.TgUsername); err != nil {
+			return err
+		}
+
+		link.UserId = userId
+		link.MergedUserId = mergeUserId
+
+		meta := models.AuditMeta{ActorId: userId, RequestId: loggs.RequestId(ctx)}
+		before := map[string]any{"tgUserId": user.TgUserId}
+		after := map[string]any{"tgUserId": input.TgUserId, "mergedUserId": mergeUserId}
+
+		return recordAudit(ctx, s.audit, meta, models.AuditTelegramLink, userId, before, after)
+	})
+	if err != nil {
+		return models.TelegramLink{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return link, nil
+}
+
+func randomCode(length int) (string, error) {
+	max := big.NewInt(int64(len(linkCodeAlphabet)))
+
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		code[i] = linkCodeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
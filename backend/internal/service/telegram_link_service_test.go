@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aidos-dev/habit-tracker/backend/internal/config"
+	"github.com/aidos-dev/habit-tracker/backend/internal/models"
+	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
+)
+
+func newTestTelegramLinkService(repos *repository.Repository, audit repository.Audit) TelegramLink {
+	return NewTelegramLinkService(repos.Transactor, repos.TelegramLink, repos.User, audit, config.Telegram{LinkCodeTTL: time.Minute})
+}
+
+func Test_TelegramLinkService_Link(t *testing.T) {
+	ctx := context.Background()
+
+	const tgUserId = 42
+
+	testTable := []struct {
+		name string
+		// prepare creates the accounts, the code is issued to the returned user
+		prepare func(t *testing.T, repos *repository.Repository) int
+		// afterCode changes the accounts once the code is issued
+		afterCode     func(t *testing.T, repos *repository.Repository, userId int)
+		audit         func(repos *repository.Repository) repository.Audit
+		expectedError error
+		// check runs after the link, userId is the user the code is issued to
+		check func(t *testing.T, repos *repository.Repository, userId int)
+	}{
+		{
+			name: "OK",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				return createTestUser(t, repos, "runner")
+			},
+			check: func(t *testing.T, repos *repository.Repository, userId int) {
+				if _, total, _ := repos.Audit.GetAll(ctx, models.ListParams{Limit: 10}); total != 1 {
+					t.Errorf("Expected a single audit entry but got %d", total)
+				}
+			},
+		},
+		{
+			name: "Telegram Id Of Deleted Account",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				deletedId, err := repos.User.CreateUser(ctx, models.User{
+					Username: "reader",
+					Email:    "reader@habit-tracker.test",
+					Password: "password-hash",
+					TgUserId: tgUserId,
+				})
+				if err != nil {
+					t.Fatalf("failed to create user: %v", err)
+				}
+				if _, err := repos.User.DeleteUser(ctx, deletedId); err != nil {
+					t.Fatalf("failed to delete user: %v", err)
+				}
+				return createTestUser(t, repos, "runner")
+			},
+			check: func(t *testing.T, repos *repository.Repository, userId int) {
+				deleted, err := repos.User.GetUserByUsername(ctx, "reader")
+				if err != nil || deleted.TgUserId != 0 {
+					t.Errorf("Expected the deleted account to release the telegram id, got %d: %v", deleted.TgUserId, err)
+				}
+			},
+		},
+		{
+			name: "Deleted Account",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				return createTestUser(t, repos, "runner")
+			},
+			afterCode: func(t *testing.T, repos *repository.Repository, userId int) {
+				if _, err := repos.User.DeleteUser(ctx, userId); err != nil {
+					t.Fatalf("failed to delete user: %v", err)
+				}
+			},
+			expectedError: ErrAccountDeleted,
+		},
+		{
+			name: "Another Telegram Account Linked",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				userId, err := repos.User.CreateUser(ctx, models.User{
+					Username: "runner",
+					Email:    "runner@habit-tracker.test",
+					Password: "password-hash",
+					TgUserId: 7,
+				})
+				if err != nil {
+					t.Fatalf("failed to create user: %v", err)
+				}
+				return userId
+			},
+			expectedError: ErrTelegramAlreadyLinked,
+		},
+		{
+			name: "Audit Log Fails",
+			prepare: func(t *testing.T, repos *repository.Repository) int {
+				return createTestUser(t, repos, "runner")
+			},
+			audit: func(repos *repository.Repository) repository.Audit {
+				return failingAudit{Audit: repos.Audit}
+			},
+		},
+	}
+
+	for _, testRepository := range testRepositories {
+		for _, testCase := range testTable {
+			t.Run(testRepository.name+" "+testCase.name, func(t *testing.T) {
+				repos := testRepository.new(t)
+
+				audit := repos.Audit
+				if testCase.audit != nil {
+					audit = testCase.audit(repos)
+				}
+
+				s := newTestTelegramLinkService(repos, audit)
+
+				userId := testCase.prepare(t, repos)
+
+				code, err := s.CreateLinkCode(ctx, userId)
+				if err != nil {
+					t.Fatalf("failed to create link code: %v", err)
+				}
+
+				if testCase.afterCode != nil {
+					testCase.afterCode(t, repos, userId)
+				}
+
+				input := models.TelegramLinkInput{Code: code.Code, TgUserId: tgUserId, TgUsername: "tg_runner"}
+				fails := testCase.expectedError != nil || testCase.audit != nil
+
+				link, err := s.Link(ctx, input)
+				if !fails {
+					if err != nil {
+						t.Fatalf("failed to link: %v", err)
+					}
+
+					user, err := repos.User.GetUserByTgUserId(ctx, tgUserId)
+					if err != nil || user.Id != userId || link.UserId != userId {
+						t.Errorf("Expected the telegram id to be linked to user %d but got %d: %v", userId, user.Id, err)
+					}
+
+					if testCase.check != nil {
+						testCase.check(t, repos, userId)
+					}
+
+					// a used code does not work again
+					if _, err := s.Link(ctx, input); !errors.Is(err, ErrLinkCodeInvalid) {
+						t.Errorf("Expected error %v but got %v", ErrLinkCodeInvalid, err)
+					}
+					return
+				}
+
+				if err == nil {
+					t.Fatalf("Expected an error but the account is linked")
+				}
+				if testCase.expectedError != nil && !errors.Is(err, testCase.expectedError) {
+					t.Fatalf("Expected error %v but got %v", testCase.expectedError, err)
+				}
+
+				if _, err := repos.User.GetUserByTgUserId(ctx, tgUserId); err == nil {
+					t.Errorf("Expected the telegram id to stay unlinked")
+				}
+
+				// the code is used in the transaction of the link, so it still works
+				if _, err := s.Link(ctx, input); errors.Is(err, ErrLinkCodeInvalid) {
+					t.Errorf("Expected the code to be kept after a failed link: %v", err)
+				}
+			})
+		}
+	}
+}
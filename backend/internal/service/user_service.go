@@ -1,46 +1,123 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/aidos-dev/habit-tracker/backend/internal/models"
 	"github.com/aidos-dev/habit-tracker/backend/internal/repository"
 )
 
 type UserService struct {
-	repo repository.User
+	tx          repository.Transactor
+	repo        repository.User
+	tokenRepo   repository.Token
+	audit       repository.Audit
+	gracePeriod time.Duration
 }
 
-func NewUserService(repo repository.User) User {
-	return &UserService{repo: repo}
+func NewUserService(tx repository.Transactor, repo repository.User, tokenRepo repository.Token, audit repository.Audit, gracePeriod time.Duration) User {
+	return &UserService{
+		tx:          tx,
+		repo:        repo,
+		tokenRepo:   tokenRepo,
+		audit:       audit,
+		gracePeriod: gracePeriod,
+	}
 }
 
-func (s *UserService) CreateUser(user models.User) (int, error) {
-	// const op = "service.user_service.CreateUser"
+func (s *UserService) CreateUser(ctx context.Context, user models.User) (int, error) {
+	const op = "service.user_service.CreateUser"
 
-	user.Password = generatePasswordHash(user.Password)
+	if user.Password != "" {
+		passwordHash, err := hashPassword(user.Password)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		user.Password = passwordHash
+	}
 
 	// if err := user.Validate(); err != nil {
 	// 	return 0, fmt.Errorf("%s: %w", op, err)
 	// }
 
-	return s.repo.CreateUser(user)
+	return s.repo.CreateUser(ctx, user)
+}
+
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	return s.repo.GetUserByUsername(ctx, username)
 }
 
-func (s *UserService) GetUser(username, password string) (models.User, error) {
-	return s.repo.GetUser(username, password)
+func (r *UserService) GetUserByTgUserId(ctx context.Context, tgUserId int64) (models.GetUser, error) {
+	return r.repo.GetUserByTgUserId(ctx, tgUserId)
 }
 
-func (r *UserService) GetUserByTgUsername(TGusername string) (models.GetUser, error) {
-	return r.repo.GetUserByTgUsername(TGusername)
+func (r *UserService) GetUserById(ctx context.Context, userId int) (models.GetUser, error) {
+	return r.repo.GetUserById(ctx, userId)
 }
 
-func (r *UserService) GetUserById(userId int) (models.GetUser, error) {
-	return r.repo.GetUserById(userId)
+func (r *UserService) GetAllUsers(ctx context.Context, params models.ListParams) ([]models.GetUser, int, error) {
+	return r.repo.GetAllUsers(ctx, params)
 }
 
-func (r *UserService) GetAllUsers() ([]models.GetUser, error) {
-	return r.repo.GetAllUsers()
+/*
+DeleteUser deletes the account of its owner and revokes all its tokens,
+so no device stays signed in to a deleted account
+*/
+func (s *UserService) DeleteUser(ctx context.Context, userId int) (int, error) {
+	const op = "service.user_service.DeleteUser"
+
+	var deletedUserId int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		deletedUserId, err = s.repo.DeleteUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		return s.tokenRepo.RevokeAllUserTokens(ctx, userId)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deletedUserId, nil
 }
 
-func (s *UserService) DeleteUser(userId int) (int, error) {
-	return s.repo.DeleteUser(userId)
+/*
+PurgeDeletedUsers permanently removes accounts which grace period
+is over and returns the number of removed accounts. The accounts
+are kept if their removal can't be recorded in the audit log
+*/
+func (s *UserService) PurgeDeletedUsers(ctx context.Context) (int, error) {
+	const op = "service.user_service.PurgeDeletedUsers"
+
+	var userIds []int
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		userIds, err = s.repo.PurgeDeletedUsers(ctx, time.Now().Add(-s.gracePeriod))
+		if err != nil {
+			return err
+		}
+
+		meta := models.AuditMeta{ActorId: models.SystemActorId}
+
+		for _, userId := range userIds {
+			if err := recordAudit(ctx, s.audit, meta, models.AuditUserPurge, userId, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return len(userIds), nil
 }
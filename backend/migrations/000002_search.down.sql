@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS reward_search_idx;
+ALTER TABLE reward DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS habit_search_idx;
+ALTER TABLE habit DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,24 @@
+CREATE TABLE audit_log (
+    id serial not null unique,
+    actor_id int not null,
+    target_user_id int,
+    action varchar(50) not null,
+    before jsonb,
+    after jsonb,
+    request_id varchar(64),
+    created_at timestamptz not null DEFAULT now()
+);
+
+CREATE INDEX audit_log_actor_idx ON audit_log (actor_id);
+CREATE INDEX audit_log_target_user_idx ON audit_log (target_user_id);
+
+-- audit_log is append-only: entries can be neither changed nor removed
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS user_account_deleted_at_idx;
+ALTER TABLE user_account DROP COLUMN IF EXISTS deleted_at;
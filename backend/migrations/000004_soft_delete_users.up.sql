@@ -0,0 +1,3 @@
+ALTER TABLE user_account ADD COLUMN deleted_at timestamptz;
+
+CREATE INDEX user_account_deleted_at_idx ON user_account (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE user_account DROP COLUMN IF EXISTS tokens_revoked_at;
+
+DROP TABLE IF EXISTS revoked_token;
+
+DROP TABLE IF EXISTS refresh_token;
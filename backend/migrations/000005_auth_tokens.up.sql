@@ -0,0 +1,22 @@
+CREATE TABLE refresh_token (
+    id serial not null unique,
+    user_id int references user_account (id) on delete cascade not null,
+    token_hash varchar(64) not null unique,
+    family_id varchar(32) not null,
+    expires_at timestamptz not null,
+    revoked_at timestamptz,
+    created_at timestamptz not null DEFAULT now()
+);
+
+CREATE INDEX refresh_token_user_id_idx ON refresh_token (user_id);
+CREATE INDEX refresh_token_family_id_idx ON refresh_token (family_id);
+
+-- access tokens revoked on logout, kept until they expire
+CREATE TABLE revoked_token (
+    jti varchar(32) primary key,
+    user_id int references user_account (id) on delete cascade not null,
+    expires_at timestamptz not null
+);
+
+-- access tokens issued before this moment are revoked ("log out everywhere")
+ALTER TABLE user_account ADD COLUMN tokens_revoked_at timestamptz;
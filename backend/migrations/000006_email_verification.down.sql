@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS used_token;
+
+ALTER TABLE user_account DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,10 @@
+ALTER TABLE user_account ADD COLUMN email_verified_at timestamptz;
+
+-- accounts created before email verification existed are not limited
+UPDATE user_account SET email_verified_at = now() WHERE email IS NOT NULL;
+
+-- one-time tokens (email verification, password reset) that have been used
+CREATE TABLE used_token (
+    jti varchar(32) primary key,
+    expires_at timestamptz not null
+);
@@ -0,0 +1,12 @@
+-- accounts of external identity providers (OpenID Connect) linked to users
+CREATE TABLE user_identity (
+    id serial not null unique,
+    user_id int references user_account (id) on delete cascade not null,
+    provider varchar(50) not null,
+    subject varchar(255) not null,
+    email varchar(255),
+    created_at timestamptz not null DEFAULT now(),
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX user_identity_user_id_idx ON user_identity (user_id);
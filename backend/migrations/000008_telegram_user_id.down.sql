@@ -0,0 +1,4 @@
+DELETE FROM used_token WHERE length(jti) > 32;
+ALTER TABLE used_token ALTER COLUMN jti TYPE varchar(32);
+
+ALTER TABLE user_account DROP COLUMN IF EXISTS tg_user_id;
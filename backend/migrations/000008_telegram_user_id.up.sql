@@ -0,0 +1,5 @@
+-- the numeric id of a telegram user never changes, unlike the user name
+ALTER TABLE user_account ADD COLUMN tg_user_id bigint UNIQUE;
+
+-- nonces of signed service requests are kept with used one-time tokens
+ALTER TABLE used_token ALTER COLUMN jti TYPE varchar(100);
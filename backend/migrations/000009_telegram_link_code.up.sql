@@ -0,0 +1,6 @@
+-- short-lived codes a web user links a telegram chat to the account with
+CREATE TABLE telegram_link_code (
+    code_hash varchar(64) primary key,
+    user_id int references user_account (id) on delete cascade not null unique,
+    expires_at timestamptz not null
+);
@@ -0,0 +1,7 @@
+ALTER TABLE user_account
+    DROP CONSTRAINT IF EXISTS user_account_role_fkey,
+    ALTER COLUMN role DROP NOT NULL;
+
+DROP TABLE IF EXISTS role_permission;
+DROP TABLE IF EXISTS permission;
+DROP TABLE IF EXISTS role;
@@ -0,0 +1,53 @@
+-- roles are named sets of permissions, a user has one role
+CREATE TABLE role (
+    name varchar(50) primary key,
+    description varchar(255),
+    created_at timestamptz not null DEFAULT now()
+);
+
+CREATE TABLE permission (
+    name varchar(50) primary key,
+    description varchar(255)
+);
+
+CREATE TABLE role_permission (
+    role_name varchar(50) references role (name) on delete cascade on update cascade not null,
+    permission varchar(50) references permission (name) on delete cascade not null,
+    PRIMARY KEY (role_name, permission)
+);
+
+INSERT INTO permission (name, description) VALUES
+    ('users:read', 'list users and see their accounts'),
+    ('users:write', 'delete and restore user accounts'),
+    ('habits:read', 'see habits, trackers and rewards of any user'),
+    ('habits:write', 'create, change and delete habits and trackers of any user'),
+    ('rewards:read', 'see the common reward list'),
+    ('rewards:write', 'create, change and delete rewards of the common list'),
+    ('rewards:assign', 'give rewards to users and take them back'),
+    ('roles:read', 'see roles and permissions'),
+    ('roles:write', 'create, change and delete roles'),
+    ('roles:assign', 'change the role of a user'),
+    ('audit:read', 'read the audit log'),
+    ('search:all', 'search habits and rewards of all users');
+
+INSERT INTO role (name, description) VALUES
+    ('user_basic', 'a regular user, manages only own data'),
+    ('admin', 'has every permission'),
+    ('moderator', 'manages rewards and sees users');
+
+INSERT INTO role_permission (role_name, permission)
+    SELECT 'admin', name FROM permission;
+
+INSERT INTO role_permission (role_name, permission) VALUES
+    ('moderator', 'users:read'),
+    ('moderator', 'habits:read'),
+    ('moderator', 'rewards:read'),
+    ('moderator', 'rewards:write'),
+    ('moderator', 'rewards:assign');
+
+-- a user can have only an existing role, renaming a role renames it for the users
+UPDATE user_account SET role = 'user_basic' WHERE role IS NULL OR role NOT IN (SELECT name FROM role);
+
+ALTER TABLE user_account
+    ALTER COLUMN role SET NOT NULL,
+    ADD CONSTRAINT user_account_role_fkey FOREIGN KEY (role) REFERENCES role (name) ON UPDATE CASCADE;
@@ -0,0 +1,14 @@
+-- long-lived tokens users create for scripts, stored by hash only
+CREATE TABLE personal_access_token (
+    id serial not null unique,
+    user_id int references user_account (id) on delete cascade not null,
+    name varchar(100) not null,
+    token_hash varchar(64) not null unique,
+    scopes varchar(20)[] not null,
+    expires_at timestamptz not null,
+    last_used_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz not null DEFAULT now()
+);
+
+CREATE INDEX personal_access_token_user_id_idx ON personal_access_token (user_id);
@@ -0,0 +1,12 @@
+-- responses of mutating requests replayed when a client repeats a request with the same key
+CREATE TABLE idempotency_key (
+    user_id int references user_account (id) on delete cascade not null,
+    idem_key varchar(255) not null,
+    request_hash varchar(64) not null,
+    -- status_code is null while the first request is in progress
+    status_code int,
+    response_body bytea,
+    created_at timestamptz not null DEFAULT now(),
+    expires_at timestamptz not null,
+    PRIMARY KEY (user_id, idem_key)
+);
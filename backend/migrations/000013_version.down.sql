@@ -0,0 +1,2 @@
+ALTER TABLE habit_tracker DROP COLUMN IF EXISTS version;
+ALTER TABLE habit DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+-- versions are compared to If-Match headers, so concurrent edits don't overwrite each other
+ALTER TABLE habit ADD COLUMN version int not null DEFAULT 1;
+ALTER TABLE habit_tracker ADD COLUMN version int not null DEFAULT 1;
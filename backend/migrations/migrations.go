@@ -0,0 +1,13 @@
+package migrations
+
+import "embed"
+
+// FS holds the migrations, they are built into the binary
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SQLiteFS holds the migrations of the SQLite schema in the sqlite directory
+//
+//go:embed sqlite/*.sql
+var SQLiteFS embed.FS
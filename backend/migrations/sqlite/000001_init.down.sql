@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS idempotency_key;
+DROP TABLE IF EXISTS personal_access_token;
+DROP TABLE IF EXISTS telegram_link_code;
+DROP TABLE IF EXISTS user_identity;
+DROP TABLE IF EXISTS used_token;
+DROP TABLE IF EXISTS revoked_token;
+DROP TABLE IF EXISTS refresh_token;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS user_reward;
+DROP TABLE IF EXISTS reward;
+DROP TABLE IF EXISTS user_habit;
+DROP TABLE IF EXISTS habit_tracker;
+DROP TABLE IF EXISTS habit;
+DROP TABLE IF EXISTS user_account;
+DROP TABLE IF EXISTS role_permission;
+DROP TABLE IF EXISTS permission;
+DROP TABLE IF EXISTS role;
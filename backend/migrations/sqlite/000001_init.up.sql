@@ -0,0 +1,200 @@
+-- the schema of backend/migrations squashed for SQLite, used for local development and demos
+CREATE TABLE role (
+    name text primary key,
+    description text,
+    created_at timestamp not null DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permission (
+    name text primary key,
+    description text
+);
+
+CREATE TABLE role_permission (
+    role_name text references role (name) on delete cascade on update cascade not null,
+    permission text references permission (name) on delete cascade not null,
+    PRIMARY KEY (role_name, permission)
+);
+
+CREATE TABLE user_account (
+    id integer primary key autoincrement,
+    user_name text unique,
+    tg_user_name text unique,
+    tg_user_id integer unique,
+    first_name text,
+    last_name text,
+    email text unique,
+    password_hash text,
+    role text not null DEFAULT 'user_basic' references role (name) on update cascade,
+    deleted_at timestamp,
+    tokens_revoked_at timestamp,
+    email_verified_at timestamp
+);
+
+CREATE INDEX user_account_deleted_at_idx ON user_account (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE habit (
+    id integer primary key autoincrement,
+    title text not null,
+    description text,
+    version integer not null DEFAULT 1
+);
+
+CREATE TABLE habit_tracker (
+    id integer primary key autoincrement,
+    habit_id integer,
+    unit_of_messure text,
+    goal text,
+    frequency text,
+    start_date date DEFAULT CURRENT_DATE,
+    end_date date,
+    counter integer,
+    done boolean DEFAULT false,
+    version integer not null DEFAULT 1
+);
+
+CREATE TABLE user_habit (
+    id integer primary key autoincrement,
+    user_id integer references user_account (id) ON DELETE CASCADE not null,
+    habit_id integer references habit (id) ON DELETE CASCADE not null,
+    habit_tracker_id integer,
+    UNIQUE (user_id, habit_id)
+);
+
+CREATE TABLE reward (
+    id integer primary key autoincrement,
+    title text not null,
+    description text,
+    UNIQUE (title, description)
+);
+
+CREATE TABLE user_reward (
+    id integer primary key autoincrement,
+    user_id integer references user_account (id) ON DELETE CASCADE not null,
+    habit_id integer references habit (id) ON DELETE CASCADE not null,
+    reward_id integer references reward (id) ON DELETE CASCADE not null,
+    UNIQUE (user_id, habit_id, reward_id)
+);
+
+CREATE TABLE audit_log (
+    id integer primary key autoincrement,
+    actor_id integer not null,
+    target_user_id integer,
+    action text not null,
+    before text,
+    after text,
+    request_id text,
+    created_at timestamp not null DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX audit_log_actor_idx ON audit_log (actor_id);
+CREATE INDEX audit_log_target_user_idx ON audit_log (target_user_id);
+
+-- audit_log is append-only: entries can be neither changed nor removed
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TABLE refresh_token (
+    id integer primary key autoincrement,
+    user_id integer references user_account (id) on delete cascade not null,
+    token_hash text not null unique,
+    family_id text not null,
+    expires_at timestamp not null,
+    revoked_at timestamp,
+    created_at timestamp not null DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX refresh_token_user_id_idx ON refresh_token (user_id);
+CREATE INDEX refresh_token_family_id_idx ON refresh_token (family_id);
+
+CREATE TABLE revoked_token (
+    jti text primary key,
+    user_id integer references user_account (id) on delete cascade not null,
+    expires_at timestamp not null
+);
+
+CREATE TABLE used_token (
+    jti text primary key,
+    expires_at timestamp not null
+);
+
+CREATE TABLE user_identity (
+    id integer primary key autoincrement,
+    user_id integer references user_account (id) on delete cascade not null,
+    provider text not null,
+    subject text not null,
+    email text,
+    created_at timestamp not null DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX user_identity_user_id_idx ON user_identity (user_id);
+
+CREATE TABLE telegram_link_code (
+    code_hash text primary key,
+    user_id integer references user_account (id) on delete cascade not null unique,
+    expires_at timestamp not null
+);
+
+-- scopes are kept as a JSON array, SQLite has no arrays
+CREATE TABLE personal_access_token (
+    id integer primary key autoincrement,
+    user_id integer references user_account (id) on delete cascade not null,
+    name text not null,
+    token_hash text not null unique,
+    scopes text not null,
+    expires_at timestamp not null,
+    last_used_at timestamp,
+    revoked_at timestamp,
+    created_at timestamp not null
+);
+
+CREATE INDEX personal_access_token_user_id_idx ON personal_access_token (user_id);
+
+CREATE TABLE idempotency_key (
+    user_id integer references user_account (id) on delete cascade not null,
+    idem_key text not null,
+    request_hash text not null,
+    -- status_code is null while the first request is in progress
+    status_code integer,
+    response_body blob,
+    created_at timestamp not null DEFAULT CURRENT_TIMESTAMP,
+    expires_at timestamp not null,
+    PRIMARY KEY (user_id, idem_key)
+);
+
+INSERT INTO permission (name, description) VALUES
+    ('users:read', 'list users and see their accounts'),
+    ('users:write', 'delete and restore user accounts'),
+    ('habits:read', 'see habits, trackers and rewards of any user'),
+    ('habits:write', 'create, change and delete habits and trackers of any user'),
+    ('rewards:read', 'see the common reward list'),
+    ('rewards:write', 'create, change and delete rewards of the common list'),
+    ('rewards:assign', 'give rewards to users and take them back'),
+    ('roles:read', 'see roles and permissions'),
+    ('roles:write', 'create, change and delete roles'),
+    ('roles:assign', 'change the role of a user'),
+    ('audit:read', 'read the audit log'),
+    ('search:all', 'search habits and rewards of all users');
+
+INSERT INTO role (name, description) VALUES
+    ('user_basic', 'a regular user, manages only own data'),
+    ('admin', 'has every permission'),
+    ('moderator', 'manages rewards and sees users');
+
+INSERT INTO role_permission (role_name, permission)
+    SELECT 'admin', name FROM permission;
+
+INSERT INTO role_permission (role_name, permission) VALUES
+    ('moderator', 'users:read'),
+    ('moderator', 'habits:read'),
+    ('moderator', 'rewards:read'),
+    ('moderator', 'rewards:write'),
+    ('moderator', 'rewards:assign');
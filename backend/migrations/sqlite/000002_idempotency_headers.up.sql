@@ -0,0 +1,2 @@
+-- headers of a response, like Content-Type and ETag, are replayed along with its body
+ALTER TABLE idempotency_key ADD COLUMN response_headers text;
@@ -1,6 +1,9 @@
 package errs
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 func Wrap(msg string, err error) error {
 	return fmt.Errorf("%s: %w", msg, err)
@@ -13,3 +16,88 @@ func WrapIfErr(msg string, err error) error {
 
 	return Wrap(msg, err)
 }
+
+/*
+kinds of errors a client can do something about. The delivery layer
+answers them with a certain status instead of an internal error
+*/
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrForbidden  = errors.New("forbidden")
+	ErrValidation = errors.New("validation failed")
+)
+
+// codes of the kinds, used when an error has no code of its own
+const (
+	CodeNotFound   = "not_found"
+	CodeConflict   = "conflict"
+	CodeForbidden  = "forbidden"
+	CodeValidation = "validation_failed"
+)
+
+/*
+Error is an error of a certain kind with a code clients can rely on,
+like "habit_not_found". errors.Is matches it with its kind and its cause
+*/
+type Error struct {
+	Kind error
+	Code string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+
+	return []error{e.Kind, e.Err}
+}
+
+func NotFound(code string, err error) error {
+	return &Error{Kind: ErrNotFound, Code: code, Err: err}
+}
+
+func Conflict(code string, err error) error {
+	return &Error{Kind: ErrConflict, Code: code, Err: err}
+}
+
+func Forbidden(code string, err error) error {
+	return &Error{Kind: ErrForbidden, Code: code, Err: err}
+}
+
+func Validation(code string, err error) error {
+	return &Error{Kind: ErrValidation, Code: code, Err: err}
+}
+
+/*
+Code returns the code of the first coded error in the chain, the code
+of its kind if it is wrapped without a code, or "" if err is of no kind
+*/
+func Code(err error) string {
+	var coded *Error
+	if errors.As(err, &coded) && coded.Code != "" {
+		return coded.Code
+	}
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, ErrConflict):
+		return CodeConflict
+	case errors.Is(err, ErrForbidden):
+		return CodeForbidden
+	case errors.Is(err, ErrValidation):
+		return CodeValidation
+	}
+
+	return ""
+}
@@ -0,0 +1,148 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+the probes of the services. /healthz tells the process is alive, it never
+looks at dependencies, so a restart is not caused by a database being down.
+/readyz runs the checks of the dependencies and tells if requests can be
+sent to the service. It fails as soon as the service starts to shut down,
+so a load balancer stops sending requests before the server is closed
+*/
+
+const (
+	statusOk       = "ok"
+	statusReady    = "ready"
+	statusNotReady = "not_ready"
+	statusDraining = "shutting_down"
+)
+
+// Check tells if a dependency of a service is ready, a nil error means it is
+type Check func(ctx context.Context) error
+
+type Health struct {
+	// timeout is the deadline of all the checks of a probe
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+func New(timeout time.Duration) *Health {
+	return &Health{
+		timeout: timeout,
+		checks:  make(map[string]Check),
+	}
+}
+
+// Add adds a check of a dependency, it is reported under the name
+func (h *Health) Add(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks[name] = check
+}
+
+// Shutdown makes the readiness probe fail from now on
+func (h *Health) Shutdown() {
+	h.draining.Store(true)
+}
+
+// Routes registers the probes in a router
+func (h *Health) Routes(router gin.IRoutes) {
+	router.GET("/healthz", h.live)
+	router.GET("/readyz", h.ready)
+}
+
+func (h *Health) live(c *gin.Context) {
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"status": statusOk,
+	})
+}
+
+/*
+ready runs all the checks at the same time. The response lists the result
+of every check, an error message for the failed ones
+*/
+func (h *Health) ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"status": statusDraining,
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	results := h.run(ctx)
+
+	status, code := statusReady, http.StatusOK
+	for _, result := range results {
+		if result != statusOk {
+			status, code = statusNotReady, http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	c.JSON(code, map[string]interface{}{
+		"status": status,
+		"checks": results,
+	})
+}
+
+// run runs the checks and returns "ok" or an error message by the name of a check
+func (h *Health) run(ctx context.Context) map[string]string {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = h.checks[name]
+	}
+	h.mu.RUnlock()
+
+	messages := make([]string, len(names))
+
+	var wg sync.WaitGroup
+
+	for i := range checks {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			messages[i] = statusOk
+			if err := checks[i](ctx); err != nil {
+				messages[i] = err.Error()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	results := make(map[string]string, len(names))
+	for i, name := range names {
+		results[name] = messages[i]
+	}
+
+	return results
+}
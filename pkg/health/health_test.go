@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Test_Health(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+
+	testTable := []struct {
+		name                 string
+		path                 string
+		checks               map[string]Check
+		shutdown             bool
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "Live",
+			path:                 "/healthz",
+			checks:               map[string]Check{"db": func(ctx context.Context) error { return errors.New("connection refused") }},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name:                 "Ready",
+			path:                 "/readyz",
+			checks:               map[string]Check{"db": ok, "migrations": ok},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"checks":{"db":"ok","migrations":"ok"},"status":"ready"}`,
+		},
+		{
+			name: "Not Ready",
+			path: "/readyz",
+			checks: map[string]Check{
+				"db":    ok,
+				"cache": func(ctx context.Context) error { return errors.New("connection refused") },
+			},
+			expectedStatusCode:   503,
+			expectedResponseBody: `{"checks":{"cache":"connection refused","db":"ok"},"status":"not_ready"}`,
+		},
+		{
+			name: "Check Timeout",
+			path: "/readyz",
+			checks: map[string]Check{
+				"db": func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+			expectedStatusCode:   503,
+			expectedResponseBody: `{"checks":{"db":"context deadline exceeded"},"status":"not_ready"}`,
+		},
+		{
+			name:                 "Shutting Down",
+			path:                 "/readyz",
+			checks:               map[string]Check{"db": ok},
+			shutdown:             true,
+			expectedStatusCode:   503,
+			expectedResponseBody: `{"status":"shutting_down"}`,
+		},
+		{
+			name:                 "Live While Shutting Down",
+			path:                 "/healthz",
+			shutdown:             true,
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Dependencies
+			ready := New(10 * time.Millisecond)
+			for name, check := range testCase.checks {
+				ready.Add(name, check)
+			}
+
+			if testCase.shutdown {
+				ready.Shutdown()
+			}
+
+			// Init Endpoint
+			r := gin.New()
+			ready.Routes(r)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", testCase.path, nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != testCase.expectedStatusCode {
+				t.Errorf("Expected status code: %d but got: %d", testCase.expectedStatusCode, w.Code)
+			}
+
+			if w.Body.String() != testCase.expectedResponseBody {
+				t.Errorf("Expected response body '%s' but got '%s'", testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
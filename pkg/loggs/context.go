@@ -0,0 +1,83 @@
+package loggs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"golang.org/x/exp/slog"
+)
+
+// RequestIdHeader carries the id of a request between the clients and the services
+const RequestIdHeader = "X-Request-ID"
+
+// maxRequestIdLen limits ids sent by clients, a longer one is replaced by a new id
+const maxRequestIdLen = 128
+
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	requestIdKey
+)
+
+// NewRequestId generates a random request id
+func NewRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+/*
+ValidRequestId reports if an id sent by a client can be logged as it is.
+Only letters, digits and "-_.:" are allowed, so an id can't forge log lines
+*/
+func ValidRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLen {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithLogger returns a copy of ctx carrying the logger of a request
+func WithLogger(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, log)
+}
+
+/*
+FromContext returns the logger of a request. The services log with it,
+so their messages have the request id. Without a logger in ctx, like
+in a background job, the default logger set by SetupLogger is returned
+*/
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return log
+	}
+
+	return slog.Default()
+}
+
+// WithRequestId returns a copy of ctx carrying the id of a request
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+// RequestId returns the id of a request, an empty string if ctx has none
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey).(string)
+
+	return requestId
+}
@@ -0,0 +1,52 @@
+package loggs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slog"
+)
+
+func Test_FromContext(t *testing.T) {
+	defaultLog := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLog) })
+
+	var base, request bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&base, nil)))
+
+	testTable := []struct {
+		name           string
+		ctx            context.Context
+		expectedOutput *bytes.Buffer
+	}{
+		{
+			name:           "Logger Of Request",
+			ctx:            WithLogger(context.Background(), slog.New(slog.NewTextHandler(&request, nil))),
+			expectedOutput: &request,
+		},
+		{
+			name:           "No Logger",
+			ctx:            context.Background(),
+			expectedOutput: &base,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			base.Reset()
+			request.Reset()
+
+			FromContext(testCase.ctx).Info("purge started")
+
+			if !strings.Contains(testCase.expectedOutput.String(), "purge started") {
+				t.Errorf("Expected the message in the output but got '%s'", testCase.expectedOutput.String())
+			}
+
+			if base.Len()+request.Len() != testCase.expectedOutput.Len() {
+				t.Errorf("Expected the message in one output only, base: '%s', request: '%s'", base.String(), request.String())
+			}
+		})
+	}
+}
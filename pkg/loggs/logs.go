@@ -13,6 +13,10 @@ const (
 	envProd  = "prod"
 )
 
+/*
+SetupLogger creates the logger of a service for the env and makes it the
+default one, so messages logged without a logger in the context are kept
+*/
 func SetupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 
@@ -29,6 +33,10 @@ func SetupLogger(env string) *slog.Logger {
 		)
 	}
 
+	if log != nil {
+		slog.SetDefault(log)
+	}
+
 	return log
 }
 
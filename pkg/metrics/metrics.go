@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+/*
+metrics of the HTTP servers of the services. Every service runs in its own
+process, so the metrics are kept in the default registry and are served
+by Handler at /metrics
+*/
+
+// unmatchedRoute labels requests which found no route, so a scan of random urls doesn't add new series
+const unmatchedRoute = "unmatched"
+
+var httpRequestDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by method, route and status code",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"method", "route", "status"},
+)
+
+/*
+HTTP is a gin middleware observing the duration of every request.
+Requests are labeled by the route pattern and not by the url,
+"/:client/api/habits/:id" and not "/web/api/habits/7"
+*/
+func HTTP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		httpRequestDuration.WithLabelValues(
+			c.Request.Method,
+			route,
+			strconv.Itoa(c.Writer.Status()),
+		).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the metrics of the default registry in the Prometheus text format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// Register adds a collector to the default registry, a collector registered before is kept
+func Register(collector prometheus.Collector) error {
+	if err := prometheus.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
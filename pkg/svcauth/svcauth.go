@@ -0,0 +1,74 @@
+/*
+Package svcauth signs requests between the services of the app.
+A request is signed with HMAC-SHA256 over its method, path with query,
+a timestamp, a nonce and a hash of the body, so a signed request can't be
+changed, and the timestamp with the nonce keep it from being replayed
+*/
+package svcauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ServiceIdHeader = "X-Service-Id"
+	TimestampHeader = "X-Timestamp"
+	NonceHeader     = "X-Nonce"
+	SignatureHeader = "X-Signature"
+)
+
+// Canonical returns the string a request signature is calculated over
+func Canonical(method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		method,
+		requestURI,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+func Sign(secret, canonical string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify compares signatures in constant time
+func Verify(secret, canonical, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, canonical)), []byte(signature))
+}
+
+/*
+SignRequest puts the service id, a timestamp, a new nonce and the signature
+to the headers of a request. The body has to be the same bytes the
+request sends
+*/
+func SignRequest(req *http.Request, serviceId, secret string, body []byte) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonceHex := hex.EncodeToString(nonce)
+
+	canonical := Canonical(req.Method, req.URL.RequestURI(), timestamp, nonceHex, body)
+
+	req.Header.Set(ServiceIdHeader, serviceId)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(NonceHeader, nonceHex)
+	req.Header.Set(SignatureHeader, Sign(secret, canonical))
+
+	return nil
+}
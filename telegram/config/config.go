@@ -5,13 +5,17 @@ import (
 	"os"
 	"time"
 
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Env        string `yaml:"env" env-required:"true"`
-	HTTPServer `yaml:"http_server"`
+	Env         string `yaml:"env" env-required:"true"`
+	HTTPServer  `yaml:"http_server"`
+	ServiceAuth `yaml:"service_auth"`
+	Health      `yaml:"health"`
+	Tracing     tracing.Config `yaml:"tracing"`
 }
 
 type HTTPServer struct {
@@ -20,6 +24,25 @@ type HTTPServer struct {
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
 }
 
+type Health struct {
+	// ReadyTimeout is the deadline of the checks of the readiness probe
+	ReadyTimeout time.Duration `yaml:"ready_timeout" env-default:"2s"`
+	/*
+		DrainDelay is how long the server is still up on shut down after
+		the readiness probe fails, so load balancers stop sending requests first
+	*/
+	DrainDelay time.Duration `yaml:"drain_delay" env-default:"0s"`
+	// MaxFetchAge is how old the last successful getUpdates can be for the bot to be ready
+	MaxFetchAge time.Duration `yaml:"max_fetch_age" env-default:"1m"`
+}
+
+// ServiceAuth is how the bot signs its requests to the backend
+type ServiceAuth struct {
+	ServiceId string `yaml:"service_id" env-default:"telegram"`
+	// Secret has to match the one the backend has for the service id
+	Secret string `env:"SERVICE_AUTH_SECRET"`
+}
+
 func MustLoad() *Config {
 	// Load environment variables from .env file
 	err := godotenv.Load("build/.env")
@@ -43,5 +66,9 @@ func MustLoad() *Config {
 		log.Fatalf("cannot read config: %s", err)
 	}
 
+	if cfg.ServiceAuth.Secret == "" {
+		log.Fatal("SERVICE_AUTH_SECRET is not set")
+	}
+
 	return &cfg
 }
@@ -1,25 +1,50 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"github.com/aidos-dev/habit-tracker/pkg/metrics"
+	"github.com/aidos-dev/habit-tracker/pkg/svcauth"
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
+	"github.com/aidos-dev/habit-tracker/telegram/config"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/exp/slog"
 )
 
 const (
-	backendURL = "http://habit-tracker:8000/telegram"
-	habitsUrl  = "/api/habits"
-	trackerUrl = "/tracker"
-	userQuery  = "?tgUser="
+	backendHost = "http://habit-tracker:8000"
+	backendURL  = backendHost + "/telegram"
+	// backendHealthUrl is the liveness probe of the backend, it needs no signature
+	backendHealthUrl = "/healthz"
+	habitsUrl        = "/api/habits"
+	trackerUrl       = "/tracker"
+	userQuery        = "?tgUserId="
+	// pageLimitQuery asks for the largest page of a list the backend gives
+	pageLimitQuery = "&limit=100"
+	cursorQuery    = "&cursor="
+
+	requestTimeout = 10 * time.Second
+	// maxAttempts is how many times a request is sent if the backend does not answer
+	maxAttempts = 2
+
+	idempotencyKeyHeader = "Idempotency-Key"
 )
 
 type AdapterHandler struct {
-	log    *slog.Logger
-	Engine *gin.Engine
+	log         *slog.Logger
+	Engine      *gin.Engine
+	client      *http.Client
+	serviceAuth config.ServiceAuth
 	// Router     *gin.RouterGroup
 
 	// EventCh      chan models.Event
@@ -28,10 +53,12 @@ type AdapterHandler struct {
 	// TrackerCh    chan models.HabitTracker
 }
 
-func NewAdapterHandler(log *slog.Logger) *AdapterHandler {
+func NewAdapterHandler(log *slog.Logger, serviceAuth config.ServiceAuth) *AdapterHandler {
 	return &AdapterHandler{
-		log:    log,
-		Engine: gin.New(),
+		log:         log,
+		Engine:      newEngine(),
+		client:      &http.Client{Timeout: requestTimeout, Transport: tracing.Transport(http.DefaultTransport)},
+		serviceAuth: serviceAuth,
 
 		// EventCh:      eventCh,
 		// StartHabitCh: startHabitCh,
@@ -40,6 +67,85 @@ func NewAdapterHandler(log *slog.Logger) *AdapterHandler {
 	}
 }
 
+// newEngine creates the router of the http server of the service, it serves the metrics
+func newEngine() *gin.Engine {
+	engine := gin.New()
+	engine.Use(metrics.HTTP(), tracing.Gin())
+
+	engine.GET("/metrics", metrics.Handler())
+
+	return engine
+}
+
+/*
+a.do sends a request to the backend signed with the secret of the bot.
+The backend trusts the telegram user id in the url only
+if the request is signed.
+The trace of ctx goes on in the backend, the trace context is sent in the headers.
+A POST, PUT or DELETE request gets an idempotency key and is sent
+once more with the same key if the backend did not answer, so
+a habit is not created twice when only the response is lost
+*/
+func (a *AdapterHandler) do(ctx context.Context, method, requestURL string, body []byte) (*http.Response, error) {
+	const op = "adapter: do"
+
+	var idempotencyKey string
+
+	if method == http.MethodPost || method == http.MethodPut || method == http.MethodDelete {
+		key := make([]byte, 16)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("%s: failed to generate idempotency key: %w", op, err)
+		}
+
+		idempotencyKey = hex.EncodeToString(key)
+	}
+
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to create a request: %w", op, err)
+		}
+
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		if idempotencyKey != "" {
+			req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+		}
+
+		// the backend logs the request under the id of the event it is sent for
+		if requestId := loggs.RequestId(ctx); requestId != "" {
+			req.Header.Set(loggs.RequestIdHeader, requestId)
+		}
+
+		// every attempt is signed again, the backend accepts a nonce only once
+		if err := svcauth.SignRequest(req, a.serviceAuth.ServiceId, a.serviceAuth.Secret, body); err != nil {
+			return nil, fmt.Errorf("%s: failed to sign a request: %w", op, err)
+		}
+
+		resp, err := a.client.Do(req)
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+
+		if idempotencyKey == "" && method != http.MethodGet {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", op, lastErr)
+}
+
+// userURL adds the telegram user id to the url of a backend endpoint
+func userURL(path string, tgUserId int64) string {
+	return backendURL + path + userQuery + strconv.FormatInt(tgUserId, 10)
+}
+
 /*
 a.readResponse method takes an http.Response as an argument.
 It checks the status code and decodes JSON values to a map of key as a string
@@ -67,3 +173,25 @@ func (a *AdapterHandler) readResponse(resp *http.Response) (map[string]interface
 
 	return response, nil
 }
+
+// Ping checks that the backend is reachable, it is a check of the readiness probe of the bot
+func (a *AdapterHandler) Ping(ctx context.Context) error {
+	const op = "adapter: Ping"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, backendHost+backendHealthUrl, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to create a request: %w", op, err)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: backend is not healthy. status: %d", op, resp.StatusCode)
+	}
+
+	return nil
+}
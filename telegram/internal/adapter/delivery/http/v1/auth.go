@@ -1,46 +1,48 @@
 package v1
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"golang.org/x/exp/slog"
 )
 
-func (a *AdapterHandler) SignUp(username string) {
+func (a *AdapterHandler) SignUp(ctx context.Context, username string, tgUserId int64) {
 	const (
 		op        = "telegram/internal/adapter/delivery/http/v1/auth.SignUp"
 		signUpUrl = "/auth/sign-up"
 	)
 
-	a.log.Info(fmt.Sprintf("%s: SignUp method called", op))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: SignUp method called", op))
 
 	// Perform the necessary logic for command1
-	a.log.Info(fmt.Sprintf("%s: Executing SignUp with text: %s", op, username))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: Executing SignUp with text: %s", op, username))
 
 	// Make an HTTP request to the backend service
 	requestURL := backendURL + signUpUrl
 
 	type Request struct {
 		Name string `json:"tg_user_name"`
+		Id   int64  `json:"tg_user_id"`
 	}
 
-	requestData := Request{Name: username}
+	requestData := Request{Name: username, Id: tgUserId}
 
 	requestBody, err := json.Marshal(requestData)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to encode to JSON", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to encode to JSON", op), sl.Err(err))
 		return
 	}
 
 	// Send a POST request
-	resp, err := http.Post(requestURL, "application/json", bytes.NewBuffer(requestBody))
+	resp, err := a.do(ctx, http.MethodPost, requestURL, requestBody)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to send http.Post request", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to send POST request", op), sl.Err(err))
 		return
 	}
 	defer resp.Body.Close()
@@ -48,12 +50,12 @@ func (a *AdapterHandler) SignUp(username string) {
 	// Read the response body
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to read the responseBody", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to read the responseBody", op), sl.Err(err))
 		return
 	}
 
 	// the line bellow only for debugging
-	a.log.Info(fmt.Sprintf("%s: response body", op), slog.Any("value", responseBody))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: response body", op), slog.Any("value", responseBody))
 
 	// c.JSON(http.StatusOK, map[string]interface{}{
 	// 	"tg_user_name": username,
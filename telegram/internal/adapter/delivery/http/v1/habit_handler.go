@@ -1,137 +1,162 @@
 package v1
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/models"
 	"golang.org/x/exp/slog"
 )
 
-func (a *AdapterHandler) CreateHabit(username string, habit models.Habit) int {
+func (a *AdapterHandler) CreateHabit(ctx context.Context, tgUserId int64, habit models.Habit) int {
 	const op = "telegram/internal/adapter/delivery/http/v1/habit_handler.CreateHabit"
 
-	a.log.Info(fmt.Sprintf("%s: CreateHabit method called", op))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: CreateHabit method called", op))
 
 	// Perform the necessary logic for command1
-	a.log.Info(fmt.Sprintf("%s: Executing CreateHabit with text: %s", op, username))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: Executing CreateHabit for telegram user: %d", op, tgUserId))
 
 	// Make an HTTP request to the backend service
-	requestURL := backendURL + habitsUrl + userQuery + username
+	requestURL := userURL(habitsUrl, tgUserId)
 
 	type Request struct {
-		Username    string `json:"tg_user_name"`
 		Title       string `json:"title"`
 		Description string `json:"description"`
 	}
 
 	requestData := Request{
-		Username:    username,
 		Title:       habit.Title,
 		Description: habit.Description,
 	}
 
 	requestBody, err := json.Marshal(requestData)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to encode to JSON", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to encode to JSON", op), sl.Err(err))
 		return 0
 	}
 
 	// Send a POST request
-	resp, err := http.Post(requestURL, "application/json", bytes.NewBuffer(requestBody))
+	resp, err := a.do(ctx, http.MethodPost, requestURL, requestBody)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to send http.Post request", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to send POST request", op), sl.Err(err))
 		return 0
 	}
 	defer resp.Body.Close()
 
 	response, err := a.readResponse(resp)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to get the response", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to get the response", op), sl.Err(err))
 		return 0
 	}
 
 	// Checking if the "habitId" field exists in the response
 	habitID, ok := response["habitId"].(float64)
 	if !ok {
-		a.log.Error(fmt.Sprintf("%s: habitId not found in response", op))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: habitId not found in response", op))
 		return 0
 	}
 
 	// Converting the float64 habitID to an integer
 	habitIDInt := int(habitID)
 
-	a.log.Info(
+	loggs.FromContext(ctx).Info(
 		fmt.Sprintf("%s: habit created:", op),
 		slog.Int("habitId", habitIDInt),
 	)
 
 	// // the line bellow only for debugging
-	// a.log.Info(fmt.Sprintf("%s: response body", op), slog.Any("value", responseBody))
+	// loggs.FromContext(ctx).Info(fmt.Sprintf("%s: response body", op), slog.Any("value", responseBody))
 
 	return habitIDInt
 }
 
-func (a *AdapterHandler) GetAllHabits(username string) string {
+func (a *AdapterHandler) GetAllHabits(ctx context.Context, tgUserId int64) string {
 	const op = "telegram/internal/adapter/delivery/http/v1/habit_handler.getAllHabits"
 
-	a.log.Info(fmt.Sprintf("%s: getAllHabits method called", op))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: getAllHabits method called", op))
 
 	// Perform the necessary logic for command1
-	a.log.Info(fmt.Sprintf("%s: Executing getAllHabits with text: %s", op, username))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: Executing getAllHabits for telegram user: %d", op, tgUserId))
 
 	// Make an HTTP request to the backend service
-	requestURL := backendURL + habitsUrl + userQuery + username
+	requestURL := userURL(habitsUrl, tgUserId) + pageLimitQuery
+
+	var allHabits []models.Habit
+
+	// the backend returns habits by pages, the pages are read until the last one
+	cursor := ""
+	for {
+		pageURL := requestURL
+		if cursor != "" {
+			pageURL += cursorQuery + url.QueryEscape(cursor)
+		}
+
+		page, err := a.getHabitPage(ctx, pageURL)
+		if err != nil {
+			loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to get habits", op), sl.Err(err))
+			return ""
+		}
+
+		allHabits = append(allHabits, page.Data...)
+
+		if page.NextCursor == "" || len(page.Data) == 0 {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	loggs.FromContext(ctx).Info(
+		fmt.Sprintf("%s: successfully got all habits from backend:", op),
+		slog.Int64("tgUserId", tgUserId),
+		slog.Any("All habits", allHabits),
+	)
+
+	habitsString := allHabitsToString(allHabits)
+
+	// Return all habits in one string
+	return habitsString
+}
+
+// habitPage is a page of the habits list of the backend
+type habitPage struct {
+	Data       []models.Habit `json:"data"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+func (a *AdapterHandler) getHabitPage(ctx context.Context, pageURL string) (habitPage, error) {
+	const op = "adapter: getHabitPage"
+
+	var page habitPage
 
 	// Send a GET request
-	resp, err := http.Get(requestURL)
+	resp, err := a.do(ctx, http.MethodGet, pageURL, nil)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to send http.Get request", op), sl.Err(err))
-		return ""
+		return page, fmt.Errorf("%s: failed to send GET request: %w", op, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		a.log.Error(fmt.Sprintf("%s: request failed. status: %d", op, resp.StatusCode), sl.Err(err))
-		return ""
+		return page, fmt.Errorf("%s: request failed. status: %d", op, resp.StatusCode)
 	}
 
 	// Read the response body
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to read the response body", op), sl.Err(err))
-		return ""
-	}
-
-	type allHabits struct {
-		Data []models.Habit
+		return page, fmt.Errorf("%s: failed to read the response body: %w", op, err)
 	}
 
-	// Decode the response body into the allHabits struct
-	var allHabitsData allHabits
-	if err := json.Unmarshal(responseBody, &allHabitsData); err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to decode the response", op), sl.Err(err))
-		return ""
+	if err := json.Unmarshal(responseBody, &page); err != nil {
+		return page, fmt.Errorf("%s: failed to decode the response: %w", op, err)
 	}
 
-	a.log.Info(
-		fmt.Sprintf("%s: successfully got all habits from backend:", op),
-		slog.String("username", username),
-		slog.Any("All habits", allHabitsData.Data),
-	)
-
-	habitsString := allHabitsToString(allHabitsData.Data)
-
-	// // the line bellow only for debugging
-	// a.log.Info(fmt.Sprintf("%s: response body", op), slog.Any("value", responseBody))
-
-	// Return all habits in one string
-	return habitsString
+	return page, nil
 }
 
 /*
@@ -167,36 +192,36 @@ func allHabitsToString(habitsSlice []models.Habit) string {
 	return allHabitsString
 }
 
-func (a *AdapterHandler) GetHabitById(habitId int, username string) (models.Habit, error) {
+func (a *AdapterHandler) GetHabitById(ctx context.Context, habitId int, tgUserId int64) (models.Habit, error) {
 	const op = "telegram/internal/adapter/delivery/http/v1/habit_handler.getHabitById"
 
-	a.log.Info(fmt.Sprintf("%s: getHabitById method called", op))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: getHabitById method called", op))
 
 	// Perform the necessary logic for command1
-	a.log.Info(fmt.Sprintf("%s: Executing getHabitById with text: %s", op, username))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: Executing getHabitById for telegram user: %d", op, tgUserId))
 
 	// Make an HTTP request to the backend service
-	requestURL := backendURL + habitsUrl + "/" + strconv.Itoa(habitId) + userQuery + username
+	requestURL := userURL(habitsUrl+"/"+strconv.Itoa(habitId), tgUserId)
 
 	var emptyHabit models.Habit
 
 	// Send a GET request
-	resp, err := http.Get(requestURL)
+	resp, err := a.do(ctx, http.MethodGet, requestURL, nil)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to send http.Get request", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to send GET request", op), sl.Err(err))
 		return emptyHabit, fmt.Errorf("failed to get a habit")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		a.log.Error(fmt.Sprintf("%s: request failed. status: %d", op, resp.StatusCode), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: request failed. status: %d", op, resp.StatusCode), sl.Err(err))
 		return emptyHabit, fmt.Errorf("failed to get a habit")
 	}
 
 	// Read the response body
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to read the response body", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to read the response body", op), sl.Err(err))
 		return emptyHabit, fmt.Errorf("failed to get a habit")
 	}
 
@@ -204,18 +229,18 @@ func (a *AdapterHandler) GetHabitById(habitId int, username string) (models.Habi
 
 	// Decode the response body into the habit
 	if err := json.Unmarshal(responseBody, &habit); err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to decode the response", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to decode the response", op), sl.Err(err))
 		return emptyHabit, fmt.Errorf("failed to get a habit")
 	}
 
-	a.log.Debug(
+	loggs.FromContext(ctx).Debug(
 		fmt.Sprintf("%s: successfully got a habit from backend:", op),
-		slog.String("username", username),
+		slog.Int64("tgUserId", tgUserId),
 		slog.Any("habit", habit),
 	)
 
 	// // the line bellow only for debugging
-	// a.log.Info(fmt.Sprintf("%s: response body", op), slog.Any("value", responseBody))
+	// loggs.FromContext(ctx).Info(fmt.Sprintf("%s: response body", op), slog.Any("value", responseBody))
 
 	// Return a habit
 	return habit, nil
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/handlers/slogdiscard"
+	"github.com/aidos-dev/habit-tracker/telegram/config"
+	"github.com/aidos-dev/habit-tracker/telegram/internal/models"
+)
+
+// handlerTransport sends requests of the adapter to a handler instead of the backend
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, req)
+
+	return recorder.Result(), nil
+}
+
+func Test_AdapterHandler_GetAllHabits(t *testing.T) {
+	// the backend has three habits and gives them by pages of two
+	pages := map[string]habitPage{
+		"":  {Data: []models.Habit{{Id: 1, Title: "running"}, {Id: 2, Title: "reading"}}, NextCursor: "2"},
+		"2": {Data: []models.Habit{{Id: 3, Title: "swimming"}}},
+	}
+
+	var cursors []string
+
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/telegram/api/habits" || r.URL.Query().Get("tgUserId") != "42" || r.URL.Query().Get("limit") != "100" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+
+		json.NewEncoder(w).Encode(pages[cursor])
+	})
+
+	a := NewAdapterHandler(slogdiscard.NewDiscardLogger(), config.ServiceAuth{ServiceId: "bot", Secret: "secret"})
+	a.client = &http.Client{Transport: handlerTransport{handler: backend}}
+
+	habits := a.GetAllHabits(context.Background(), 42)
+
+	for _, title := range []string{"running", "reading", "swimming"} {
+		if !strings.Contains(habits, title) {
+			t.Errorf("Expected habit %s in the list but got '%s'", title, habits)
+		}
+	}
+
+	if len(cursors) != 2 || cursors[1] != "2" {
+		t.Errorf("Expected the pages to be read by the next cursor but got cursors %q", cursors)
+	}
+}
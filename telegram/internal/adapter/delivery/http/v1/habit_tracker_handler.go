@@ -1,29 +1,30 @@
 package v1
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/models"
 	"golang.org/x/exp/slog"
 )
 
-func (a *AdapterHandler) UpdateHabitTracker(username string, habitId int, habitTracker models.HabitTracker) {
+func (a *AdapterHandler) UpdateHabitTracker(ctx context.Context, tgUserId int64, habitId int, habitTracker models.HabitTracker) {
 	const op = "telegram/internal/adapter/delivery/http/v1/habit_tracker_handler.UpdateHabitTracker"
 
-	a.log.Info(fmt.Sprintf("%s: UpdateHabitTracker method called", op))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: UpdateHabitTracker method called", op))
 
 	// Perform the necessary logic for command1
-	a.log.Info(fmt.Sprintf("%s: Executing UpdateHabitTracker with text: %s", op, username))
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: Executing UpdateHabitTracker for telegram user: %d", op, tgUserId))
 
 	// Make an HTTP request to the backend service
 	// http://localhost:8000/telegram/api/habits/7/tracker
-	requestURL := backendURL + habitsUrl + "/" + strconv.Itoa(habitId) + trackerUrl + userQuery + username
+	requestURL := userURL(habitsUrl+"/"+strconv.Itoa(habitId)+trackerUrl, tgUserId)
 
 	type Request struct {
 		UnitOfMessure string    `json:"unit_of_messure"`
@@ -41,7 +42,7 @@ func (a *AdapterHandler) UpdateHabitTracker(username string, habitId int, habitT
 		EndDate:       habitTracker.EndDate,
 	}
 
-	a.log.Info(
+	loggs.FromContext(ctx).Info(
 		fmt.Sprintf("%s: requestData struct prepared for Marshaling", op),
 		slog.Any("struct content", requestData),
 	)
@@ -49,28 +50,19 @@ func (a *AdapterHandler) UpdateHabitTracker(username string, habitId int, habitT
 	requestBody, err := json.Marshal(requestData)
 	if err != nil {
 		// c.String(http.StatusInternalServerError, err.Error())
-		a.log.Error(fmt.Sprintf("%s: failed to encode to JSON", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to encode to JSON", op), sl.Err(err))
 		return
 	}
 
-	a.log.Info(
+	loggs.FromContext(ctx).Info(
 		fmt.Sprintf("%s: requestData Marshaling", op),
 		slog.Any("Marshaled requestBody content", requestBody),
 	)
 
 	// Send a PUT request
-	req, err := http.NewRequest("PUT", requestURL, bytes.NewBuffer(requestBody))
+	resp, err := a.do(ctx, http.MethodPut, requestURL, requestBody)
 	if err != nil {
-		// c.String(http.StatusInternalServerError, err.Error())
-		a.log.Error(fmt.Sprintf("%s: failed to send http.Put request", op), sl.Err(err))
-		return
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to execute a request", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to execute a request", op), sl.Err(err))
 		return
 	}
 
@@ -78,18 +70,18 @@ func (a *AdapterHandler) UpdateHabitTracker(username string, habitId int, habitT
 
 	response, err := a.readResponse(resp)
 	if err != nil {
-		a.log.Error(fmt.Sprintf("%s: failed to get the response", op), sl.Err(err))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to get the response", op), sl.Err(err))
 		return
 	}
 
 	// Checking if the "status" field exists in the response
 	status, ok := response["status"].(string)
 	if !ok {
-		a.log.Error(fmt.Sprintf("%s: status not found in response", op))
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: status not found in response", op))
 		return
 	}
 
-	a.log.Info(
+	loggs.FromContext(ctx).Info(
 		fmt.Sprintf("%s: habit tracker has been updated:", op),
 		slog.String("status", status),
 	)
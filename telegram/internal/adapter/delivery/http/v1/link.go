@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"golang.org/x/exp/slog"
+)
+
+var (
+	ErrLinkCodeInvalid = errors.New("link code is invalid or expired")
+	// ErrLinkConflict means either side is already linked to another account
+	ErrLinkConflict = errors.New("accounts can't be linked")
+)
+
+/*
+LinkAccount sends a link code entered by a user to the backend.
+The telegram chat gets the web account the code was issued for.
+It returns true if habits of the telegram-only account were merged
+*/
+func (a *AdapterHandler) LinkAccount(ctx context.Context, username string, tgUserId int64, code string) (bool, error) {
+	const (
+		op      = "telegram/internal/adapter/delivery/http/v1/link.LinkAccount"
+		linkUrl = "/auth/link"
+	)
+
+	loggs.FromContext(ctx).Info(fmt.Sprintf("%s: Executing LinkAccount for telegram user: %d", op, tgUserId))
+
+	requestURL := backendURL + linkUrl
+
+	type Request struct {
+		Code     string `json:"code"`
+		Id       int64  `json:"tg_user_id"`
+		Username string `json:"tg_user_name"`
+	}
+
+	requestBody, err := json.Marshal(Request{Code: code, Id: tgUserId, Username: username})
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to encode to JSON: %w", op, err)
+	}
+
+	resp, err := a.do(ctx, http.MethodPost, requestURL, requestBody)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to send POST request: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		return false, ErrLinkCodeInvalid
+	case http.StatusConflict:
+		return false, ErrLinkConflict
+	}
+
+	response, err := a.readResponse(resp)
+	if err != nil {
+		loggs.FromContext(ctx).Error(fmt.Sprintf("%s: failed to get the response", op), sl.Err(err))
+		return false, err
+	}
+
+	mergedUserId, _ := response["mergedUserId"].(float64)
+
+	loggs.FromContext(ctx).Info(
+		fmt.Sprintf("%s: telegram account is linked", op),
+		slog.Int64("tgUserId", tgUserId),
+		slog.Bool("merged", mergedUserId != 0),
+	)
+
+	return mergedUserId != 0, nil
+}
@@ -6,9 +6,12 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/aidos-dev/habit-tracker/pkg/health"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
 	"github.com/aidos-dev/habit-tracker/telegram/config"
 	v1 "github.com/aidos-dev/habit-tracker/telegram/internal/adapter/delivery/http/v1"
 	server "github.com/aidos-dev/habit-tracker/telegram/internal/adapter/server/httpServer"
@@ -24,6 +27,8 @@ const (
 	tgBotHost   = "api.telegram.org"
 	storagePath = "files_storage"
 	batchSize   = 100
+	// serviceName names the service in the spans it sends
+	serviceName = "habit-tracker-telegram"
 )
 
 func Run() {
@@ -40,6 +45,12 @@ func Run() {
 	)
 	log.Debug("debug messages are enabled")
 
+	shutdownTracing, err := tracing.Setup(context.Background(), serviceName, cfg.Tracing)
+	if err != nil {
+		log.Error("failed to initialize tracing", sl.Err(err))
+		return
+	}
+
 	// get telegram token
 	telegramToken := config.MustToken()
 
@@ -61,6 +72,7 @@ func Run() {
 		startAskUnitOfMesCh  = make(chan bool)
 		receiveHabitIdCh     = make(chan bool)
 		continueTrackerCh    = make(chan bool)
+		startLinkCh          = make(chan bool)
 		errChan              = make(chan error)
 		// habitCh      chan models.Habit
 		// trackerCh    chan models.HabitTracker
@@ -79,10 +91,15 @@ func Run() {
 		StartAskUnitOfMesCh:  startAskUnitOfMesCh,
 		ReceiveHabitIdCh:     receiveHabitIdCh,
 		ContinueTrackerCh:    continueTrackerCh,
+		StartLinkCh:          startLinkCh,
 		ErrChan:              errChan,
 	}
 
-	adapter := v1.NewAdapterHandler(log)
+	adapter := v1.NewAdapterHandler(log, cfg.ServiceAuth)
+
+	ready := health.New(cfg.Health.ReadyTimeout)
+	ready.Routes(adapter.Engine)
+	ready.Add("backend", adapter.Ping)
 
 	srv := new(server.Server)
 
@@ -107,10 +124,14 @@ func Run() {
 	// processor
 	eventsProcessor := telegram.NewProcessor(log, tgClient, storage, adapter, mu, channels)
 
+	ready.Add("telegram", eventsProcessor.Ready(cfg.Health.MaxFetchAge))
+
 	go eventsProcessor.SendHello()
 
 	go eventsProcessor.SendHelp()
 
+	go eventsProcessor.LinkAccount()
+
 	/*
 		method CreateHabit runs in a separate goroutine and keeps listening
 		for chanels. This way it can handle a "dialog" with a user while
@@ -150,7 +171,16 @@ func Run() {
 
 	log.Info("Telegram service Shutting Down")
 
+	// the readiness probe fails first, the server is still up during the drain delay
+	ready.Shutdown()
+	time.Sleep(cfg.Health.DrainDelay)
+
 	if err := srv.Shutdown(context.Background()); err != nil {
 		log.Error("error occured on server shutting down: %s", sl.Err(err))
 	}
+
+	// spans of the last events are sent before the exit
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Error("failed to flush spans", sl.Err(err))
+	}
 }
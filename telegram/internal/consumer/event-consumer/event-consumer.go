@@ -1,11 +1,15 @@
 package event_consumer
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
 	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/events"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/slog"
 )
 
@@ -39,13 +43,15 @@ func (c Consumer) Start() error {
 			continue
 		}
 
+		updatesFetched.Add(float64(len(gotEvents)))
+
 		if len(gotEvents) == 0 {
 			time.Sleep(1 * time.Second)
 
 			continue
 		}
 
-		if err := c.handleEvents(gotEvents); err != nil {
+		if err := c.handleEvents(context.Background(), gotEvents); err != nil {
 			// log.Print(err)
 			c.log.Error(fmt.Sprintf("%s: failed to handle an event", op), sl.Err(err))
 
@@ -54,19 +60,45 @@ func (c Consumer) Start() error {
 	}
 }
 
-func (c *Consumer) handleEvents(events []events.Event) error {
+/*
+handleEvents processes a batch of events. Every batch is a trace and
+every event is a span of it, the spans of the processor are its children
+*/
+func (c *Consumer) handleEvents(ctx context.Context, events []events.Event) error {
 	const op = "telegram/internal/consumer/event-consumer/event-consumer.handleEvents"
 
+	ctx, span := tracing.Start(ctx, "consumer.handleEvents", attribute.Int("events", len(events)))
+	defer span.End()
+
 	for _, event := range events {
-		// log.Printf("got new event: %s", event.Text)
-		c.log.Info(
-			fmt.Sprintf("%s: got new event", op),
-			slog.String("event content", event.Text),
+		command := c.processor.Command(event)
+		start := time.Now()
+
+		/*
+			every event is a request of its own, the id is sent to the backend
+			along with the requests made for the event
+		*/
+		requestId := loggs.NewRequestId()
+		log := c.log.With(slog.String("request_id", requestId), slog.String("command", command))
+
+		// the text is not logged, it can hold a link code, the processor logs it redacted
+		log.Info(fmt.Sprintf("%s: got new event", op))
+
+		eventCtx, eventSpan := tracing.Start(ctx, "consumer.handleEvent",
+			attribute.String("command", command),
+			attribute.String("request.id", requestId),
 		)
+		eventCtx = loggs.WithLogger(loggs.WithRequestId(eventCtx, requestId), log)
+
+		err := c.processor.Process(eventCtx, event)
 
-		if err := c.processor.Process(event); err != nil {
+		tracing.End(eventSpan, err)
+		processingDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+
+		if err != nil {
 			// log.Printf("can't handle event: %s", err.Error())
-			c.log.Error(fmt.Sprintf("%s: failed to process an event", op), sl.Err(err))
+			processingFailures.WithLabelValues(command).Inc()
+			log.Error(fmt.Sprintf("%s: failed to process an event", op), sl.Err(err))
 
 			continue
 		}
@@ -0,0 +1,30 @@
+package event_consumer
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	updatesFetched = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "telegram_updates_fetched_total",
+		Help: "Number of updates fetched from the Telegram API",
+	})
+
+	processingDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "telegram_event_processing_duration_seconds",
+			Help:    "Duration of processing an event by command",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"command"},
+	)
+
+	processingFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telegram_event_processing_failures_total",
+			Help: "Number of events which failed to be processed by command",
+		},
+		[]string{"command"},
+	)
+)
@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aidos-dev/habit-tracker/pkg/errs"
@@ -39,6 +40,7 @@ func (p *Processor) CreateHabit() {
 		)
 
 		chatID := event.ChatId
+		userID := event.UserId
 		username := event.UserName
 		text := event.Text
 
@@ -117,7 +119,7 @@ func (p *Processor) CreateHabit() {
 				slog.Any("habit value", habit),
 			)
 
-			habitId := p.adapter.CreateHabit(username, habit)
+			habitId := p.adapter.CreateHabit(event.Ctx, userID, habit)
 
 			p.log.Debug(
 				fmt.Sprintf("%s: habit created", op),
@@ -208,12 +210,12 @@ func (p *Processor) CreateHabit() {
 	}
 }
 
-func (p *Processor) getHabitById(habitId int, username string) (models.Habit, error) {
+func (p *Processor) getHabitById(ctx context.Context, habitId int, userID int64) (models.Habit, error) {
 	const op = "telegram/internal/events/telegram/commands.getHabitById"
 	p.log.Debug(fmt.Sprintf("%s: getHabitById method called", op))
 
 	var emptyHabit models.Habit
-	habit, err := p.adapter.GetHabitById(habitId, username)
+	habit, err := p.adapter.GetHabitById(ctx, habitId, userID)
 	if err != nil {
 		return emptyHabit, err
 	}
@@ -238,7 +240,7 @@ func (p *Processor) AllHabits() {
 
 		event := <-p.eventCh
 
-		allHabitsData := p.adapter.GetAllHabits(event.UserName)
+		allHabitsData := p.adapter.GetAllHabits(event.Ctx, event.UserId)
 
 		p.tg.SendMessage(event.ChatId, fmt.Sprintf("%s\n\n%s", msgAllHabits, allHabitsData))
 
@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aidos-dev/habit-tracker/pkg/loggs/sl"
+	v1 "github.com/aidos-dev/habit-tracker/telegram/internal/adapter/delivery/http/v1"
+)
+
+// linkPayloadPrefix marks a /start payload of a deep link from the web client as a link code
+const linkPayloadPrefix = "link_"
+
+// isLinkCmd reports if a message is "/link <code>" or "/start link_<code>"
+func isLinkCmd(text string) bool {
+	return text == LinkCmd ||
+		strings.HasPrefix(text, LinkCmd+" ") ||
+		strings.HasPrefix(text, StartCmd+" "+linkPayloadPrefix)
+}
+
+// redactLinkCode hides the code of a link command, so a one-time code never gets to the logs
+func redactLinkCode(text string) string {
+	if !isLinkCmd(text) || linkCode(text) == "" {
+		return text
+	}
+
+	return LinkCmd + " [redacted]"
+}
+
+// linkCode gets a link code from a /link command or a deep link payload
+func linkCode(text string) string {
+	if payload, ok := strings.CutPrefix(text, StartCmd+" "+linkPayloadPrefix); ok {
+		return strings.TrimSpace(payload)
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(text, LinkCmd))
+}
+
+/*
+LinkAccount links the telegram chat of a user to the web account
+the entered code was issued for
+*/
+func (p *Processor) LinkAccount() {
+	const op = "telegram/internal/events/telegram/command_link.LinkAccount"
+
+	p.log.Info(fmt.Sprintf("%s: goroutine started", op))
+
+	for {
+		<-p.startLinkCh
+
+		p.log.Info(fmt.Sprintf("%s: method called", op))
+
+		p.mu.Lock()
+
+		event := <-p.eventCh
+
+		code := linkCode(event.Text)
+
+		var msg string
+
+		if code == "" {
+			msg = msgLinkUsage
+		} else {
+			merged, err := p.adapter.LinkAccount(event.Ctx, event.UserName, event.UserId, code)
+
+			switch {
+			case errors.Is(err, v1.ErrLinkCodeInvalid):
+				msg = msgLinkCodeInvalid
+			case errors.Is(err, v1.ErrLinkConflict):
+				msg = msgLinkConflict
+			case err != nil:
+				p.log.Error(fmt.Sprintf("%s: failed to link account", op), sl.Err(err))
+				msg = msgLinkFailed
+			case merged:
+				msg = msgLinkedMerged
+			default:
+				msg = msgLinked
+			}
+		}
+
+		err := p.tg.SendMessage(event.ChatId, msg)
+
+		p.errChan <- err
+
+		p.mu.Unlock()
+	}
+}
@@ -136,7 +136,7 @@ func (p *Processor) UpdateTracker() {
 		)
 
 		chatID := event.ChatId
-		username := event.UserName
+		userID := event.UserId
 		text := event.Text
 
 		if text == Cancel {
@@ -250,7 +250,7 @@ func (p *Processor) UpdateTracker() {
 				slog.Any("tracker value", tracker),
 			)
 
-			p.adapter.UpdateHabitTracker(username, habit.Id, tracker)
+			p.adapter.UpdateHabitTracker(event.Ctx, userID, habit.Id, tracker)
 			// log.Printf("CreateHabit: created habit id is: %v", habitId)
 
 			p.log.Debug(
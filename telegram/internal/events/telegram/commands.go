@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -8,6 +9,8 @@ import (
 	"time"
 
 	"github.com/aidos-dev/habit-tracker/pkg/errs"
+	"github.com/aidos-dev/habit-tracker/pkg/loggs"
+	"github.com/aidos-dev/habit-tracker/telegram/internal/events"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/models"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/storage"
 	"golang.org/x/exp/slog"
@@ -21,44 +24,75 @@ const (
 	UpdateTracker = "/update_tracker"
 	DeleteHabit   = "/delete_habit"
 	Cancel        = "/cancel"
+	LinkCmd       = "/link"
 )
 
-func (p *Processor) doCmd(text string, chatID int, username string) error {
+// messageCmd names a message which is not a command, like an answer in a dialog
+const messageCmd = "message"
+
+/*
+Command names the command of an event. Any other text is a "message",
+so a label of the metrics never holds what a user typed
+*/
+func (p *Processor) Command(event events.Event) string {
+	text := strings.TrimSpace(event.Text)
+
+	if isLinkCmd(text) {
+		return LinkCmd
+	}
+
+	switch text {
+	case StartCmd, HelpCmd, Habit, AllHabits, UpdateTracker, DeleteHabit, Cancel:
+		return text
+	}
+
+	return messageCmd
+}
+
+func (p *Processor) doCmd(ctx context.Context, text string, chatID int, userID int64, username string) error {
 	const op = "telegram/internal/events/telegram/commands.doCmd"
 
+	// the logger of the event has its request id
+	log := loggs.FromContext(ctx)
+
 	text = strings.TrimSpace(text)
 
-	p.log.Debug(
+	log.Debug(
 		fmt.Sprintf("%s: got new message", op),
-		slog.String("message text", text),
+		slog.String("message text", redactLinkCode(text)),
 		slog.String("from", username),
 		slog.Int("chatId", chatID),
 	)
 
 	event := models.Event{
+		Ctx:      ctx,
 		ChatId:   chatID,
+		UserId:   userID,
 		UserName: username,
 		Text:     text,
 	}
 
 	switch {
 
+	case isLinkCmd(text):
+		p.startLinkCh <- true
+		log.Debug(fmt.Sprintf("%s: switch sent true to startLinkCh", op))
 	case text == StartCmd:
 		p.startSendHelloCh <- true
-		p.log.Debug(fmt.Sprintf("%s: switch sent true to startSendHelloCh", op))
+		log.Debug(fmt.Sprintf("%s: switch sent true to startSendHelloCh", op))
 	case text == HelpCmd:
 		p.startSendHelpCh <- true
-		p.log.Debug(fmt.Sprintf("%s: switch sent true to startSendHelpCh", op))
+		log.Debug(fmt.Sprintf("%s: switch sent true to startSendHelpCh", op))
 	case text == Habit:
 		p.startCreateHabitCh <- true
-		p.log.Debug(fmt.Sprintf("%s: switch sent true to startCreateHabitCh", op))
+		log.Debug(fmt.Sprintf("%s: switch sent true to startCreateHabitCh", op))
 	case text == AllHabits:
 		p.startAllHabitsCh <- true
-		p.log.Debug(fmt.Sprintf("%s: switch sent true to startAllHabitsCh", op))
+		log.Debug(fmt.Sprintf("%s: switch sent true to startAllHabitsCh", op))
 	case text == UpdateTracker:
 
 		p.startChooseTrackerCh <- true
-		p.log.Debug(fmt.Sprintf("%s: switch sent true to startChooseTrackerCh", op))
+		log.Debug(fmt.Sprintf("%s: switch sent true to startChooseTrackerCh", op))
 
 	default:
 		/*
@@ -75,50 +109,50 @@ func (p *Processor) doCmd(text string, chatID int, username string) error {
 		*/
 		case <-p.continueHabitCh:
 			p.startCreateHabitCh <- true
-			p.log.Debug(fmt.Sprintf("%s: switch sent true to startCreateHabitCh", op))
+			log.Debug(fmt.Sprintf("%s: switch sent true to startCreateHabitCh", op))
 		case <-p.receiveHabitIdCh:
 
-			p.log.Debug(fmt.Sprintf("%s: receiveHabitIdCh received a signal", op))
+			log.Debug(fmt.Sprintf("%s: receiveHabitIdCh received a signal", op))
 
 			habitId, err := strconv.Atoi(text)
 			if err != nil {
 				p.tg.SendMessage(chatID, msgWrongIdFormat)
 			}
-			habit, err := p.getHabitById(habitId, username)
+			habit, err := p.getHabitById(ctx, habitId, userID)
 			if err != nil {
 				p.tg.SendMessage(chatID, msgWrongHabitId)
 			}
 
 			p.startAskUnitOfMesCh <- true
-			p.log.Debug(fmt.Sprintf("%s: switch sent true to startAskUnitOfMesCh", op))
+			log.Debug(fmt.Sprintf("%s: switch sent true to startAskUnitOfMesCh", op))
 
 			p.habitDataCh <- habit
-			p.log.Debug(
+			log.Debug(
 				fmt.Sprintf("%s: habit is sent to habitDataCh", op),
 				slog.Any("habit", habit),
 			)
 
 		case <-p.continueTrackerCh:
 			p.startUpdateTrackerCh <- true
-			p.log.Debug(fmt.Sprintf("%s: switch sent true to startUpdateTrackerCh", op))
+			log.Debug(fmt.Sprintf("%s: switch sent true to startUpdateTrackerCh", op))
 
 		default:
 
-			p.log.Debug(fmt.Sprintf("%s: the message couldn't find it's route", op))
+			log.Debug(fmt.Sprintf("%s: the message couldn't find it's route", op))
 			return nil
 		}
 
 	}
 
-	p.log.Debug(fmt.Sprintf("%s: switch case made its choise", op))
+	log.Debug(fmt.Sprintf("%s: switch case made its choise", op))
 
 	p.eventCh <- event
 
-	p.log.Debug(fmt.Sprintf("%s: event is sent to eventCh", op))
+	log.Debug(fmt.Sprintf("%s: event is sent to eventCh", op))
 
 	err := <-p.errChan
 
-	p.log.Debug(fmt.Sprintf("%s: err content is: %v", op, err))
+	log.Debug(fmt.Sprintf("%s: err content is: %v", op, err))
 
 	return err
 }
@@ -213,7 +247,7 @@ func (p *Processor) SendHello() {
 		chatID := event.ChatId
 		username := event.UserName
 
-		p.adapter.SignUp(username)
+		p.adapter.SignUp(event.Ctx, username, event.UserId)
 
 		p.log.Info(
 			fmt.Sprintf("%s: user started the bot", op),
@@ -26,6 +26,13 @@ const (
 	msgStartDate      = "Write the starting date for your habit in the format dd/mm/yyyy 🗓"
 	msgEndDate        = "Write the end date for you habit in the format dd/mm/yyyy 🗓"
 	timeFormat        = "02/01/2006"
+
+	msgLinkUsage       = "Send /link with the code from the web app, for example: /link ABCD2345"
+	msgLinked          = "Done! This chat now works with your web account 🔗"
+	msgLinkedMerged    = "Done! This chat now works with your web account and your habits from the bot were moved there 🔗"
+	msgLinkCodeInvalid = "The code is wrong or expired. Please get a new one in the web app 😕"
+	msgLinkConflict    = "This chat or the web account is already linked to another account 😬"
+	msgLinkFailed      = "Could not link the account, please try again later 😕"
 )
 
 /*
@@ -38,4 +45,5 @@ all_habits - Show all my habits
 delete_habit - Delete a habit
 update_tracker - Update a tracker fields of the habit
 cancel - Cancel the habit creation
+link - Link the chat to your web account
 */
@@ -1,15 +1,20 @@
 package telegram
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	v1 "github.com/aidos-dev/habit-tracker/telegram/internal/adapter/delivery/http/v1"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/clients/tgClient"
 	"golang.org/x/exp/slog"
 
 	"github.com/aidos-dev/habit-tracker/pkg/errs"
+	"github.com/aidos-dev/habit-tracker/pkg/health"
+	"github.com/aidos-dev/habit-tracker/pkg/tracing"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/events"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/models"
 	"github.com/aidos-dev/habit-tracker/telegram/internal/storage"
@@ -34,13 +39,18 @@ type Processor struct {
 	receiveHabitIdCh     chan bool
 	continueHabitCh      chan bool
 	continueTrackerCh    chan bool
+	startLinkCh          chan bool
 	errChan              chan error
 	// HabitCh      chan models.Habit
 	// TrackerCh    chan models.HabitTracker
+
+	// lastFetch is the unix time in nanoseconds of the last successful getUpdates, 0 before the first one
+	lastFetch atomic.Int64
 }
 
 type Meta struct {
 	ChatID   int
+	UserID   int64
 	Username string
 }
 
@@ -68,6 +78,7 @@ func NewProcessor(log *slog.Logger, client *tgClient.Client, storage storage.Sto
 		receiveHabitIdCh:     channels.ReceiveHabitIdCh,
 		continueHabitCh:      channels.ContinueHabitCh,
 		continueTrackerCh:    channels.ContinueTrackerCh,
+		startLinkCh:          channels.StartLinkCh,
 		errChan:              channels.ErrChan,
 		// HabitCh:      habitCh,
 		// TrackerCh:    trackerCh,
@@ -81,6 +92,8 @@ func (p *Processor) Fetch(limit int) ([]events.Event, error) {
 		return nil, errs.Wrap("can't get events", err)
 	}
 
+	p.lastFetch.Store(time.Now().UnixNano())
+
 	if len(updates) == 0 {
 		return nil, fmt.Errorf("no updates found")
 	}
@@ -96,16 +109,19 @@ func (p *Processor) Fetch(limit int) ([]events.Event, error) {
 	return res, nil
 }
 
-func (p *Processor) Process(event events.Event) error {
+func (p *Processor) Process(ctx context.Context, event events.Event) (err error) {
+	ctx, span := tracing.Start(ctx, "processor.Process")
+	defer func() { tracing.End(span, err) }()
+
 	switch event.Type {
 	case events.Message:
-		return p.processMessage(event)
+		return p.processMessage(ctx, event)
 	default:
 		return errs.Wrap("can't process message", ErrUnknownEventType)
 	}
 }
 
-func (p *Processor) processMessage(event events.Event) error {
+func (p *Processor) processMessage(ctx context.Context, event events.Event) error {
 	const op = "telegram/internal/events/telegram/telegram.processMessage"
 
 	meta, err := meta(event)
@@ -115,7 +131,7 @@ func (p *Processor) processMessage(event events.Event) error {
 
 	p.log.Debug(fmt.Sprintf("%s: New event", op), slog.Any("event content", event))
 
-	if err := p.doCmd(event.Text, meta.ChatID, meta.Username); err != nil {
+	if err := p.doCmd(ctx, event.Text, meta.ChatID, meta.UserID, meta.Username); err != nil {
 		return errs.Wrap("can't process message", err)
 	}
 
@@ -142,6 +158,7 @@ func event(upd models.Update) events.Event {
 	if updType == events.Message {
 		res.Meta = Meta{
 			ChatID:   upd.Message.Chat.ID,
+			UserID:   upd.Message.From.ID,
 			Username: upd.Message.From.Username,
 		}
 	}
@@ -164,3 +181,23 @@ func fetchType(upd models.Update) events.Type {
 
 	return events.Message
 }
+
+/*
+Ready is the check of the readiness probe of the bot. The bot is not ready
+before the first getUpdates succeeds, nor when the last one that succeeded
+is older than maxAge
+*/
+func (p *Processor) Ready(maxAge time.Duration) health.Check {
+	return func(ctx context.Context) error {
+		lastFetch := p.lastFetch.Load()
+		if lastFetch == 0 {
+			return errors.New("no updates fetched yet")
+		}
+
+		if age := time.Since(time.Unix(0, lastFetch)); age > maxAge {
+			return fmt.Errorf("last updates fetched %s ago", age.Round(time.Second))
+		}
+
+		return nil
+	}
+}
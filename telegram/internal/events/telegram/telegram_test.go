@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_Processor_Ready(t *testing.T) {
+	testTable := []struct {
+		name          string
+		lastFetch     time.Time
+		expectedError string
+	}{
+		{
+			name:          "No Updates Fetched",
+			expectedError: "no updates fetched yet",
+		},
+		{
+			name:      "Fetched Recently",
+			lastFetch: time.Now().Add(-10 * time.Second),
+		},
+		{
+			name:          "Fetched Long Ago",
+			lastFetch:     time.Now().Add(-2 * time.Minute),
+			expectedError: "last updates fetched 2m0s ago",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := &Processor{}
+			if !testCase.lastFetch.IsZero() {
+				p.lastFetch.Store(testCase.lastFetch.UnixNano())
+			}
+
+			err := p.Ready(time.Minute)(context.Background())
+
+			if testCase.expectedError == "" {
+				if err != nil {
+					t.Errorf("Expected the bot to be ready but got %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != testCase.expectedError {
+				t.Errorf("Expected error '%s' but got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
+
+func Test_redactLinkCode(t *testing.T) {
+	testTable := []struct {
+		name         string
+		text         string
+		expectedText string
+	}{
+		{name: "Link Command", text: "/link 4F7K2Q", expectedText: "/link [redacted]"},
+		{name: "Deep Link", text: "/start link_4F7K2Q", expectedText: "/link [redacted]"},
+		{name: "Link Without Code", text: "/link", expectedText: "/link"},
+		{name: "Other Command", text: "/start", expectedText: "/start"},
+		{name: "Message", text: "running", expectedText: "running"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			if text := redactLinkCode(testCase.text); text != testCase.expectedText {
+				t.Errorf("Expected text '%s' but got '%s'", testCase.expectedText, text)
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package events
 
+import "context"
+
 type Fetcher interface {
 	Fetch(limit int) ([]Event, error)
 }
 
 type Processor interface {
-	Process(e Event) error
+	Process(ctx context.Context, e Event) error
+	// Command names the command of an event, metrics of the events are labeled by it
+	Command(e Event) string
 }
 
 type Type int
@@ -14,5 +14,6 @@ type Channels struct {
 	StartAskUnitOfMesCh  chan bool
 	ReceiveHabitIdCh     chan bool
 	ContinueTrackerCh    chan bool
+	StartLinkCh          chan bool
 	ErrChan              chan error
 }
@@ -1,5 +1,7 @@
 package models
 
+import "context"
+
 type UpdatesResponse struct {
 	Ok     bool     `json:"ok"`
 	Result []Update `json:"result"`
@@ -17,6 +19,7 @@ type IncomingMessage struct {
 }
 
 type From struct {
+	ID       int64  `json:"id"`
 	Username string `json:"username"`
 }
 
@@ -33,8 +36,15 @@ type TgUserName struct {
 	Username string `json:"tg_user_name"`
 }
 
+/*
+Event is a message passed to the goroutines running the commands.
+Ctx carries the trace of the message, the goroutines pass it to the
+requests they send to the backend
+*/
 type Event struct {
+	Ctx      context.Context
 	ChatId   int
+	UserId   int64
 	UserName string
 	Text     string
 }